@@ -2,16 +2,24 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"os"
 
 	"github.com/sur309/openshift-mcp-server/pkg/integrated"
 )
 
 func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "Path to a YAML or JSON configuration file (defaults to $CONFIG_FILE). Environment variables override file values")
+	flag.Parse()
+
 	log.Println("Starting OpenShift AI MCP Server with Inference...")
 
-	// Load configuration from environment
-	config := integrated.LoadConfigFromEnv()
+	// Load configuration from file and environment
+	config, err := integrated.LoadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
 
 	// Create integrated server
 	server, err := integrated.NewIntegratedServer(config)
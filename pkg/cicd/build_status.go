@@ -0,0 +1,131 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// BuildStatus is the phase of an OpenShift build
+type BuildStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Phase is New, Pending, Running, Complete, Failed, Error or Cancelled
+	Phase          string     `json:"phase"`
+	Reason         string     `json:"reason,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	BuildConfig    string     `json:"build_config,omitempty"`
+	OutputImage    string     `json:"output_image,omitempty"`
+	StartTime      *time.Time `json:"start_time,omitempty"`
+	CompletionTime *time.Time `json:"completion_time,omitempty"`
+	// Duration is the time the build ran so far, or in total once it completed
+	Duration string `json:"duration,omitempty"`
+	Finished bool   `json:"finished"`
+}
+
+// GetBuildStatus returns the phase and duration of a build in a namespace
+func (ib *ImageBuilder) GetBuildStatus(ctx context.Context, namespace, name string) (*BuildStatus, error) {
+	if ib.dynamicClient == nil {
+		return nil, fmt.Errorf("Kubernetes client not available")
+	}
+	return GetBuildStatus(ctx, ib.dynamicClient.Resource(buildGVR).Namespace(namespace), name)
+}
+
+// CancelBuild cancels a build in a namespace that has not finished yet
+func (ib *ImageBuilder) CancelBuild(ctx context.Context, namespace, name string) (*BuildStatus, error) {
+	if ib.dynamicClient == nil {
+		return nil, fmt.Errorf("Kubernetes client not available")
+	}
+	return CancelBuild(ctx, ib.dynamicClient.Resource(buildGVR).Namespace(namespace), name)
+}
+
+// GetBuildStatus returns the phase and duration of a build
+func GetBuildStatus(ctx context.Context, builds dynamic.ResourceInterface, name string) (*BuildStatus, error) {
+	build, err := getBuild(ctx, builds, name)
+	if err != nil {
+		return nil, err
+	}
+	return buildStatus(build), nil
+}
+
+// CancelBuild sets status.cancelled on a build, which makes OpenShift stop its pod, and returns the
+// build status. Builds that already finished are returned unchanged, so cancelling is idempotent.
+func CancelBuild(ctx context.Context, builds dynamic.ResourceInterface, name string) (*BuildStatus, error) {
+	var status *BuildStatus
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		build, err := getBuild(ctx, builds, name)
+		if err != nil {
+			return err
+		}
+		if status = buildStatus(build); status.Finished {
+			return nil
+		}
+		if err := unstructured.SetNestedField(build.Object, true, "status", "cancelled"); err != nil {
+			return err
+		}
+		updated, err := builds.Update(ctx, build, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		status = buildStatus(updated)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel build %s: %w", name, err)
+	}
+	return status, nil
+}
+
+func getBuild(ctx context.Context, builds dynamic.ResourceInterface, name string) (*unstructured.Unstructured, error) {
+	build, err := builds.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("build %s not found, OpenShift builds are named <build config>-<number>", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build %s: %w", name, err)
+	}
+	return build, nil
+}
+
+// buildStatus reads the status of a build object
+func buildStatus(build *unstructured.Unstructured) *BuildStatus {
+	status := &BuildStatus{Name: build.GetName(), Namespace: build.GetNamespace()}
+	status.Phase, _, _ = unstructured.NestedString(build.Object, "status", "phase")
+	status.Reason, _, _ = unstructured.NestedString(build.Object, "status", "reason")
+	status.Message, _, _ = unstructured.NestedString(build.Object, "status", "message")
+	status.BuildConfig, _, _ = unstructured.NestedString(build.Object, "status", "config", "name")
+	status.OutputImage, _, _ = unstructured.NestedString(build.Object, "spec", "output", "to", "name")
+	switch status.Phase {
+	case "Complete", "Failed", "Error", "Cancelled":
+		status.Finished = true
+	}
+	status.StartTime = nestedTime(build, "status", "startTimestamp")
+	status.CompletionTime = nestedTime(build, "status", "completionTimestamp")
+	if status.StartTime != nil {
+		end := time.Now()
+		if status.CompletionTime != nil {
+			end = *status.CompletionTime
+		}
+		status.Duration = end.Sub(*status.StartTime).Round(time.Second).String()
+	}
+	return status
+}
+
+// nestedTime reads an RFC 3339 timestamp field, nil when it is not set
+func nestedTime(obj *unstructured.Unstructured, fields ...string) *time.Time {
+	value, found, _ := unstructured.NestedString(obj.Object, fields...)
+	if !found {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -3,16 +3,25 @@ package cicd
 import (
 	"context"
 	"fmt"
-	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/metrics"
 
 	// Networking for Ingress support
 	networkingv1 "k8s.io/api/networking/v1"
@@ -20,27 +29,114 @@ import (
 
 type DeploymentAutomation struct {
 	kubeClient      kubernetes.Interface
+	dynamicClient   dynamic.Interface // OpenShift DeploymentConfigs and ImageStreams
 	kubeConfig      *rest.Config
 	defaultTemplate *DeploymentTemplate
 }
 
 type DeploymentConfig struct {
-	Name          string
-	Namespace     string
-	Image         string
-	Tag           string
-	Replicas      int32
-	Port          int32
-	ServiceType   string
-	Labels        map[string]string
-	Annotations   map[string]string
-	EnvVars       map[string]string
-	Resources     *ResourceRequirements
-	Strategy      string // "recreate", "rolling", "blue-green"
-	ExposeIngress bool
-	IngressDomain string
+	Name        string
+	Namespace   string
+	Image       string
+	Tag         string
+	Replicas    int32
+	Port        int32
+	ServiceType string
+	Labels      map[string]string
+	Annotations map[string]string
+	EnvVars     map[string]string
+	Command     []string // overrides the image entrypoint when set
+	Args        []string // overrides the image CMD when set
+	Resources   *ResourceRequirements
+	// Secret used to pull the image from a private registry, created from inline credentials
+	ImagePullSecret *ImagePullSecret
+	// Creates or updates a HorizontalPodAutoscaler for the application when set. Replicas is then
+	// only the initial count, redeploying keeps the replicas chosen by the autoscaler.
+	Autoscaling *AutoscalingConfig
+	Strategy    string // "recreate", "rolling", "blue-green", rolling by default
+	// Rolling update bounds, absolute numbers or percentages (e.g. "1", "25%")
+	MaxSurge       string
+	MaxUnavailable string
+	ExposeIngress  bool
+	IngressDomain  string
+	// Ingress host, <name>.<IngressDomain> by default, and optional ingress class
+	IngressHost  string
+	IngressClass string
+	// Deploy an OpenShift DeploymentConfig with an image change trigger on an ImageStream for the
+	// image instead of a Deployment, so pushing a new image redeploys. Ignored on clusters
+	// without the OpenShift apps API.
+	UseDeploymentConfig bool
+	// ConfigMaps and Secrets mounted read-only in the container, by name to mount path
+	ConfigMapMounts map[string]string
+	SecretMounts    map[string]string
+	// Secrets whose keys are injected as environment variables
+	SecretEnvFrom []string
+	// Inline ConfigMap and Secret data by name, created or updated before deploying
+	ConfigMaps map[string]map[string]string
+	Secrets    map[string]map[string]string
+	// Persistent volume claims created on first deployment and mounted in the container
+	Volumes []VolumeSpec
+	// HTTP probe paths. Nil probes liveness and readiness on "/" and adds no startup probe, an
+	// empty path disables the probe for workloads that do not serve HTTP.
+	LivenessPath  *string
+	ReadinessPath *string
+	StartupPath   *string
+	// Probe timings, nil keeps the defaults of each probe
+	LivenessTiming  *ProbeTiming
+	ReadinessTiming *ProbeTiming
+	StartupTiming   *ProbeTiming
 }
 
+// ProbeTiming overrides the timing of a probe, zero fields keep the default
+type ProbeTiming struct {
+	InitialDelaySeconds int32
+	PeriodSeconds       int32
+	TimeoutSeconds      int32
+	FailureThreshold    int32
+}
+
+// defaultProbePath is probed when no path is configured, matching the manifests of the CI/CD tools
+const defaultProbePath = "/"
+
+// Default probe timings, shared with the manifests of the CI/CD tools
+var (
+	DefaultLivenessTiming  = ProbeTiming{InitialDelaySeconds: 30, PeriodSeconds: 10}
+	DefaultReadinessTiming = ProbeTiming{InitialDelaySeconds: 5, PeriodSeconds: 5}
+	// Allows up to five minutes for slow starting applications before liveness checks begin
+	DefaultStartupTiming = ProbeTiming{PeriodSeconds: 10, FailureThreshold: 30}
+)
+
+// WithDefaults returns the timing with its zero fields taken from defaults
+func (t ProbeTiming) WithDefaults(defaults ProbeTiming) ProbeTiming {
+	if t.InitialDelaySeconds != 0 {
+		defaults.InitialDelaySeconds = t.InitialDelaySeconds
+	}
+	if t.PeriodSeconds != 0 {
+		defaults.PeriodSeconds = t.PeriodSeconds
+	}
+	if t.TimeoutSeconds != 0 {
+		defaults.TimeoutSeconds = t.TimeoutSeconds
+	}
+	if t.FailureThreshold != 0 {
+		defaults.FailureThreshold = t.FailureThreshold
+	}
+	return defaults
+}
+
+// Deployment strategies accepted in DeploymentConfig.Strategy
+const (
+	StrategyRecreate  = "recreate"
+	StrategyRolling   = "rolling"
+	StrategyBlueGreen = "blue-green"
+)
+
+// SlotLabel marks the pods of the blue and green deployments of a blue-green application, the
+// service selects the active slot
+const SlotLabel = "deployment-slot"
+
+// greenSuffix names the deployment of the green slot, the blue slot keeps the application name
+const greenSuffix = "-green"
+
 type ResourceRequirements struct {
 	Requests map[string]string
 	Limits   map[string]string
@@ -55,17 +151,23 @@ type DeploymentTemplate struct {
 }
 
 type DeploymentResult struct {
-	Name        string
-	Namespace   string
-	Image       string
-	Status      string
-	Replicas    string
-	ServiceName string
-	IngressURL  string
-	DeployTime  time.Duration
-	Success     bool
-	Error       error
-	Logs        []string
+	Name     string
+	Strategy string
+	// Deployment serving traffic, <name>-green while the green slot of a blue-green application is active
+	ActiveDeployment string
+	Volumes          []VolumeStatus
+	Namespace        string
+	Image            string
+	Status           string
+	Replicas         string
+	ServiceName      string
+	IngressURL       string
+	DeployTime       time.Duration
+	Success          bool
+	Error            error
+	Logs             []string
+	// Image pull secret attached to the pods, empty when none is configured
+	PullSecret string
 }
 
 func NewDeploymentAutomation(kubeConfig *rest.Config) (*DeploymentAutomation, error) {
@@ -74,6 +176,10 @@ func NewDeploymentAutomation(kubeConfig *rest.Config) (*DeploymentAutomation, er
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
 
 	// Set default template
 	defaultTemplate := &DeploymentTemplate{
@@ -99,13 +205,16 @@ func NewDeploymentAutomation(kubeConfig *rest.Config) (*DeploymentAutomation, er
 
 	return &DeploymentAutomation{
 		kubeClient:      kubeClient,
+		dynamicClient:   dynamicClient,
 		kubeConfig:      kubeConfig,
 		defaultTemplate: defaultTemplate,
 	}, nil
 }
 
-func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config DeploymentConfig) (*DeploymentResult, error) {
+func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config DeploymentConfig) (result *DeploymentResult, err error) {
 	startTime := time.Now()
+	done := metrics.StartOperation(metrics.OperationDeploy)
+	defer func() { done(err == nil && result != nil && result.Success) }()
 	logs := []string{}
 
 	// Apply defaults
@@ -134,6 +243,42 @@ func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config De
 			config.EnvVars[k] = v
 		}
 	}
+	if config.Strategy == "" {
+		config.Strategy = StrategyRolling
+	}
+	if config.Autoscaling != nil {
+		if err := config.Autoscaling.validate(config.Replicas); err != nil {
+			return &DeploymentResult{
+				Strategy:   config.Strategy,
+				Success:    false,
+				Error:      err,
+				DeployTime: time.Since(startTime),
+				Logs:       logs,
+			}, nil
+		}
+		if config.Strategy == StrategyBlueGreen {
+			logs = append(logs, fmt.Sprintf("Warning: autoscaling is not supported with the %s strategy, no autoscaler is created", StrategyBlueGreen))
+			config.Autoscaling = nil
+		}
+	}
+	if config.UseDeploymentConfig && config.Strategy == StrategyBlueGreen {
+		return &DeploymentResult{
+			Strategy:   config.Strategy,
+			Success:    false,
+			Error:      fmt.Errorf("the %s strategy is not supported with DeploymentConfigs", StrategyBlueGreen),
+			DeployTime: time.Since(startTime),
+			Logs:       logs,
+		}, nil
+	}
+	if _, err := deploymentStrategy(config); err != nil {
+		return &DeploymentResult{
+			Strategy:   config.Strategy,
+			Success:    false,
+			Error:      err,
+			DeployTime: time.Since(startTime),
+			Logs:       logs,
+		}, nil
+	}
 
 	// Ensure namespace exists
 	if err := da.ensureNamespace(ctx, config.Namespace); err != nil {
@@ -146,8 +291,38 @@ func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config De
 	}
 	logs = append(logs, fmt.Sprintf("Ensured namespace %s exists", config.Namespace))
 
+	configLogs, err := da.applyConfigData(ctx, config)
+	logs = append(logs, configLogs...)
+	if err == nil {
+		configLogs, err = da.ensureVolumeClaims(ctx, config)
+		logs = append(logs, configLogs...)
+	}
+	if err == nil {
+		configLogs, err = da.applyPullSecret(ctx, &config)
+		logs = append(logs, configLogs...)
+	}
+	if err != nil {
+		return &DeploymentResult{
+			Strategy:   config.Strategy,
+			Success:    false,
+			Error:      err,
+			DeployTime: time.Since(startTime),
+			Logs:       logs,
+		}, nil
+	}
+
+	if config.Strategy == StrategyBlueGreen {
+		return da.deployBlueGreen(ctx, config, startTime, logs)
+	}
+	if config.UseDeploymentConfig {
+		if da.deploymentConfigsAvailable() {
+			return da.deployWithDeploymentConfig(ctx, config, startTime, logs)
+		}
+		logs = append(logs, "Warning: the OpenShift apps API is not available, deploying a Deployment instead of a DeploymentConfig")
+	}
+
 	// Create or update deployment
-	deployment, err := da.createOrUpdateDeployment(ctx, config)
+	deployment, err := da.createOrUpdateDeployment(ctx, config, "")
 	if err != nil {
 		return &DeploymentResult{
 			Success:    false,
@@ -156,10 +331,12 @@ func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config De
 			Logs:       logs,
 		}, nil
 	}
-	logs = append(logs, fmt.Sprintf("Created/updated deployment %s", deployment.Name))
+	logs = append(logs, fmt.Sprintf("Created/updated deployment %s with %s strategy", deployment.Name, config.Strategy))
+
+	logs = append(logs, da.applyAutoscaler(ctx, config, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name})...)
 
 	// Create or update service
-	service, err := da.createOrUpdateService(ctx, config)
+	service, err := da.createOrUpdateService(ctx, config, map[string]string{"app": config.Name})
 	if err != nil {
 		return &DeploymentResult{
 			Success:    false,
@@ -175,7 +352,7 @@ func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config De
 	if config.ExposeIngress {
 		ingress, err := da.createOrUpdateIngress(ctx, config)
 		if err != nil {
-			log.Printf("Warning: Failed to create ingress: %v", err)
+			klog.Warningf("Failed to create ingress: %v", err)
 			logs = append(logs, fmt.Sprintf("Warning: Failed to create ingress: %v", err))
 		} else {
 			if len(ingress.Spec.Rules) > 0 {
@@ -196,6 +373,13 @@ func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config De
 	}
 	logs = append(logs, fmt.Sprintf("Deployment %s is ready", config.Name))
 
+	// The green slot left by an earlier blue-green deployment would also match the service selector
+	if scaled, err := da.scaleDownDeployment(ctx, config.Namespace, config.Name+greenSuffix); err != nil {
+		logs = append(logs, fmt.Sprintf("Warning: Failed to scale down deployment %s%s: %v", config.Name, greenSuffix, err))
+	} else if scaled {
+		logs = append(logs, fmt.Sprintf("Scaled down deployment %s%s", config.Name, greenSuffix))
+	}
+
 	// Get final deployment status
 	deployment, err = da.kubeClient.AppsV1().Deployments(config.Namespace).Get(ctx, config.Name, metav1.GetOptions{})
 	if err != nil {
@@ -208,20 +392,170 @@ func (da *DeploymentAutomation) DeployApplication(ctx context.Context, config De
 	}
 
 	return &DeploymentResult{
-		Name:        config.Name,
-		Namespace:   config.Namespace,
-		Image:       fmt.Sprintf("%s:%s", config.Image, config.Tag),
-		Status:      "Ready",
-		Replicas:    fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, deployment.Status.Replicas),
-		ServiceName: service.Name,
-		IngressURL:  ingressURL,
-		DeployTime:  time.Since(startTime),
-		Success:     true,
-		Error:       nil,
-		Logs:        logs,
+		Name:             config.Name,
+		Strategy:         config.Strategy,
+		ActiveDeployment: config.Name,
+		Namespace:        config.Namespace,
+		Image:            fmt.Sprintf("%s:%s", config.Image, config.Tag),
+		Status:           "Ready",
+		Replicas:         fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, deployment.Status.Replicas),
+		ServiceName:      service.Name,
+		IngressURL:       ingressURL,
+		DeployTime:       time.Since(startTime),
+		Volumes:          da.volumeStatuses(ctx, config),
+		PullSecret:       config.pullSecretName(),
+		Success:          true,
+		Error:            nil,
+		Logs:             logs,
 	}, nil
 }
 
+// deployBlueGreen deploys the new version next to the one serving traffic, alternating between the
+// <name> (blue) and <name>-green deployments. Once the idle slot is ready the service selector is
+// repointed to it and the previous slot is scaled down, so traffic never reaches a pod that is not
+// ready.
+func (da *DeploymentAutomation) deployBlueGreen(ctx context.Context, config DeploymentConfig, startTime time.Time, logs []string) (*DeploymentResult, error) {
+	failed := func(err error) (*DeploymentResult, error) {
+		return &DeploymentResult{
+			Strategy:   config.Strategy,
+			Success:    false,
+			Error:      err,
+			DeployTime: time.Since(startTime),
+			Logs:       logs,
+		}, nil
+	}
+
+	activeSlot := "blue"
+	if existing, err := da.kubeClient.CoreV1().Services(config.Namespace).Get(ctx, config.Name, metav1.GetOptions{}); err == nil && existing.Spec.Selector[SlotLabel] == "green" {
+		activeSlot = "green"
+	}
+	targetSlot, target, previous := "green", config.Name+greenSuffix, config.Name
+	if activeSlot == "green" {
+		targetSlot, target, previous = "blue", config.Name, config.Name+greenSuffix
+	}
+
+	deployment, err := da.createOrUpdateDeployment(ctx, config, targetSlot)
+	if err != nil {
+		return failed(fmt.Errorf("failed to create/update deployment: %w", err))
+	}
+	logs = append(logs, fmt.Sprintf("Created/updated %s deployment %s, %s serves traffic until it is ready", targetSlot, deployment.Name, activeSlot))
+
+	if err := da.waitForDeployment(ctx, config.Namespace, target, 5*time.Minute); err != nil {
+		return failed(fmt.Errorf("deployment %s did not become ready, traffic stays on the %s slot: %w", target, activeSlot, err))
+	}
+	logs = append(logs, fmt.Sprintf("Deployment %s is ready", target))
+
+	service, err := da.createOrUpdateService(ctx, config, map[string]string{"app": config.Name, SlotLabel: targetSlot})
+	if err != nil {
+		return failed(fmt.Errorf("failed to switch service to the %s slot: %w", targetSlot, err))
+	}
+	logs = append(logs, fmt.Sprintf("Switched service %s to the %s slot", service.Name, targetSlot))
+
+	var ingressURL string
+	if config.ExposeIngress {
+		ingress, err := da.createOrUpdateIngress(ctx, config)
+		if err != nil {
+			klog.Warningf("Failed to create ingress: %v", err)
+			logs = append(logs, fmt.Sprintf("Warning: Failed to create ingress: %v", err))
+		} else if len(ingress.Spec.Rules) > 0 {
+			ingressURL = fmt.Sprintf("https://%s", ingress.Spec.Rules[0].Host)
+			logs = append(logs, fmt.Sprintf("Created/updated ingress %s with URL %s", ingress.Name, ingressURL))
+		}
+	}
+
+	if scaled, err := da.scaleDownDeployment(ctx, config.Namespace, previous); err != nil {
+		logs = append(logs, fmt.Sprintf("Warning: Failed to scale down deployment %s: %v", previous, err))
+	} else if scaled {
+		logs = append(logs, fmt.Sprintf("Scaled down %s deployment %s", activeSlot, previous))
+	}
+
+	deployment, err = da.kubeClient.AppsV1().Deployments(config.Namespace).Get(ctx, target, metav1.GetOptions{})
+	if err != nil {
+		return failed(fmt.Errorf("failed to get final deployment status: %w", err))
+	}
+
+	return &DeploymentResult{
+		Name:             config.Name,
+		Strategy:         config.Strategy,
+		ActiveDeployment: target,
+		Namespace:        config.Namespace,
+		Image:            fmt.Sprintf("%s:%s", config.Image, config.Tag),
+		Status:           "Ready",
+		Replicas:         fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, deployment.Status.Replicas),
+		ServiceName:      service.Name,
+		IngressURL:       ingressURL,
+		DeployTime:       time.Since(startTime),
+		Volumes:          da.volumeStatuses(ctx, config),
+		PullSecret:       config.pullSecretName(),
+		Success:          true,
+		Error:            nil,
+		Logs:             logs,
+	}, nil
+}
+
+// scaleDownDeployment scales a deployment to zero replicas, reporting false when it does not exist
+// or is already scaled down
+func (da *DeploymentAutomation) scaleDownDeployment(ctx context.Context, namespace, name string) (bool, error) {
+	scale, err := da.kubeClient.AppsV1().Deployments(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if scale.Spec.Replicas == 0 {
+		return false, nil
+	}
+	scale.Spec.Replicas = 0
+	if _, err := da.kubeClient.AppsV1().Deployments(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deploymentStrategy translates DeploymentConfig.Strategy into the deployment's update strategy.
+// Each slot of a blue-green application is updated in place and rolled out as a rolling update.
+func deploymentStrategy(config DeploymentConfig) (appsv1.DeploymentStrategy, error) {
+	switch config.Strategy {
+	case StrategyRecreate:
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}, nil
+	case StrategyRolling, StrategyBlueGreen, "":
+		rolling := &appsv1.RollingUpdateDeployment{}
+		if config.MaxSurge != "" {
+			maxSurge, err := parseRollingBound("max surge", config.MaxSurge)
+			if err != nil {
+				return appsv1.DeploymentStrategy{}, err
+			}
+			rolling.MaxSurge = &maxSurge
+		}
+		if config.MaxUnavailable != "" {
+			maxUnavailable, err := parseRollingBound("max unavailable", config.MaxUnavailable)
+			if err != nil {
+				return appsv1.DeploymentStrategy{}, err
+			}
+			rolling.MaxUnavailable = &maxUnavailable
+		}
+		return appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType, RollingUpdate: rolling}, nil
+	default:
+		return appsv1.DeploymentStrategy{}, fmt.Errorf("unsupported deployment strategy %q, expected %s, %s or %s", config.Strategy, StrategyRecreate, StrategyRolling, StrategyBlueGreen)
+	}
+}
+
+// parseRollingBound parses a rolling update bound, a non-negative number or a percentage
+func parseRollingBound(name, value string) (intstr.IntOrString, error) {
+	bound := intstr.Parse(value)
+	if bound.Type == intstr.Int && bound.IntVal < 0 {
+		return bound, fmt.Errorf("invalid %s %q, must not be negative", name, value)
+	}
+	if bound.Type == intstr.String {
+		percent, found := strings.CutSuffix(bound.StrVal, "%")
+		if n, err := strconv.Atoi(percent); !found || err != nil || n < 0 {
+			return bound, fmt.Errorf("invalid %s %q, expected a number or a percentage", name, value)
+		}
+	}
+	return bound, nil
+}
+
 func (da *DeploymentAutomation) ensureNamespace(ctx context.Context, namespace string) error {
 	_, err := da.kubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	if err != nil {
@@ -242,11 +576,87 @@ func (da *DeploymentAutomation) ensureNamespace(ctx context.Context, namespace s
 	return nil
 }
 
-func (da *DeploymentAutomation) createOrUpdateDeployment(ctx context.Context, config DeploymentConfig) (*appsv1.Deployment, error) {
+// createOrUpdateDeployment applies the deployment of an application. A blue-green slot deploys to
+// <name> for blue or <name>-green, with pods labelled with the slot.
+func (da *DeploymentAutomation) createOrUpdateDeployment(ctx context.Context, config DeploymentConfig, slot string) (*appsv1.Deployment, error) {
 	labels := config.Labels
 	labels["app"] = config.Name
 	labels["version"] = config.Tag
+	name := config.Name
+	selector := map[string]string{"app": config.Name}
+	if slot != "" {
+		labels = make(map[string]string, len(config.Labels)+1)
+		for k, v := range config.Labels {
+			labels[k] = v
+		}
+		labels[SlotLabel] = slot
+		selector[SlotLabel] = slot
+		if slot == "green" {
+			name += greenSuffix
+		}
+	}
+	strategy, err := deploymentStrategy(config)
+	if err != nil {
+		return nil, err
+	}
 
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   config.Namespace,
+			Labels:      labels,
+			Annotations: config.Annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &config.Replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+			Strategy: strategy,
+			Template: podTemplate(config, labels),
+		},
+	}
+
+	// Try to get existing deployment
+	deployments := da.kubeClient.AppsV1().Deployments(config.Namespace)
+	existingDeployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err == nil && slot != "" && existingDeployment.Spec.Selector.MatchLabels[SlotLabel] != slot {
+		// A deployment from before the application was deployed blue-green selects the pods of both
+		// slots. Its selector cannot be changed, the deployment is replaced, which is safe since only
+		// the idle slot is deployed to.
+		propagation := metav1.DeletePropagationBackground
+		if err := deployments.Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to replace deployment %s without the %s selector: %w", name, SlotLabel, err)
+		}
+		err = apierrors.NewNotFound(appsv1.Resource("deployments"), name)
+	}
+	if err != nil {
+		// Create new deployment
+		return deployments.Create(ctx, deployment, metav1.CreateOptions{})
+	} else {
+		// Update existing deployment, keeping its selector which cannot be changed. The pods of a
+		// former blue-green slot keep the slot label the selector requires.
+		deployment.ObjectMeta.ResourceVersion = existingDeployment.ObjectMeta.ResourceVersion
+		deployment.Spec.Selector = existingDeployment.Spec.Selector
+		if selectedSlot, exists := existingDeployment.Spec.Selector.MatchLabels[SlotLabel]; exists && slot == "" {
+			podLabels := make(map[string]string, len(labels)+1)
+			for k, v := range labels {
+				podLabels[k] = v
+			}
+			podLabels[SlotLabel] = selectedSlot
+			deployment.Spec.Template.Labels = podLabels
+		}
+		// The autoscaler owns the replica count
+		if config.Autoscaling != nil {
+			deployment.Spec.Replicas = existingDeployment.Spec.Replicas
+		}
+		return deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+	}
+}
+
+// podTemplate builds the pod template of an application, shared by Deployments and OpenShift
+// DeploymentConfigs
+func podTemplate(config DeploymentConfig, labels map[string]string) corev1.PodTemplateSpec {
 	// Prepare environment variables
 	var envVars []corev1.EnvVar
 	for k, v := range config.EnvVars {
@@ -271,77 +681,71 @@ func (da *DeploymentAutomation) createOrUpdateDeployment(ctx context.Context, co
 		}
 	}
 
-	deployment := &appsv1.Deployment{
+	template := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        config.Name,
-			Namespace:   config.Namespace,
-			Labels:      labels,
-			Annotations: config.Annotations,
+			Labels: labels,
 		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &config.Replicas,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": config.Name,
-				},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:    config.Name,
+					Image:   fmt.Sprintf("%s:%s", config.Image, config.Tag),
+					Command: config.Command,
+					Args:    config.Args,
+					Ports: []corev1.ContainerPort{
 						{
-							Name:  config.Name,
-							Image: fmt.Sprintf("%s:%s", config.Image, config.Tag),
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: config.Port,
-									Protocol:      corev1.ProtocolTCP,
-								},
-							},
-							Env:       envVars,
-							Resources: resources,
-							LivenessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/health",
-										Port: intstr.FromInt(int(config.Port)),
-									},
-								},
-								InitialDelaySeconds: 30,
-								PeriodSeconds:       10,
-							},
-							ReadinessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/health",
-										Port: intstr.FromInt(int(config.Port)),
-									},
-								},
-								InitialDelaySeconds: 5,
-								PeriodSeconds:       5,
-							},
+							ContainerPort: config.Port,
+							Protocol:      corev1.ProtocolTCP,
 						},
 					},
+					Env:            envVars,
+					Resources:      resources,
+					LivenessProbe:  httpProbe(config.LivenessPath, defaultProbePath, config.Port, DefaultLivenessTiming, config.LivenessTiming),
+					ReadinessProbe: httpProbe(config.ReadinessPath, defaultProbePath, config.Port, DefaultReadinessTiming, config.ReadinessTiming),
+					StartupProbe:   httpProbe(config.StartupPath, "", config.Port, DefaultStartupTiming, config.StartupTiming),
 				},
 			},
 		},
 	}
+	addConfigVolumes(&template.Spec, config)
+	addClaimVolumes(&template.Spec, config)
+	if config.ImagePullSecret != nil && config.ImagePullSecret.Name != "" {
+		template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: config.ImagePullSecret.Name}}
+	}
+	return template
+}
 
-	// Try to get existing deployment
-	existingDeployment, err := da.kubeClient.AppsV1().Deployments(config.Namespace).Get(ctx, config.Name, metav1.GetOptions{})
-	if err != nil {
-		// Create new deployment
-		return da.kubeClient.AppsV1().Deployments(config.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
-	} else {
-		// Update existing deployment
-		deployment.ObjectMeta.ResourceVersion = existingDeployment.ObjectMeta.ResourceVersion
-		return da.kubeClient.AppsV1().Deployments(config.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+// httpProbe builds an HTTP GET probe on path, or defaultPath when path is nil. It returns nil, no
+// probe, when the resolved path is empty.
+func httpProbe(path *string, defaultPath string, port int32, defaults ProbeTiming, timing *ProbeTiming) *corev1.Probe {
+	probePath := defaultPath
+	if path != nil {
+		probePath = *path
+	}
+	if probePath == "" {
+		return nil
+	}
+	if !strings.HasPrefix(probePath, "/") {
+		probePath = "/" + probePath
+	}
+	if timing != nil {
+		defaults = timing.WithDefaults(defaults)
+	}
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: probePath,
+				Port: intstr.FromInt(int(port)),
+			},
+		},
+		InitialDelaySeconds: defaults.InitialDelaySeconds,
+		PeriodSeconds:       defaults.PeriodSeconds,
+		TimeoutSeconds:      defaults.TimeoutSeconds,
+		FailureThreshold:    defaults.FailureThreshold,
 	}
 }
 
-func (da *DeploymentAutomation) createOrUpdateService(ctx context.Context, config DeploymentConfig) (*corev1.Service, error) {
+func (da *DeploymentAutomation) createOrUpdateService(ctx context.Context, config DeploymentConfig, selector map[string]string) (*corev1.Service, error) {
 	labels := config.Labels
 	labels["app"] = config.Name
 
@@ -361,14 +765,14 @@ func (da *DeploymentAutomation) createOrUpdateService(ctx context.Context, confi
 			Type: serviceType,
 			Ports: []corev1.ServicePort{
 				{
+					// Named like the port of the CI/CD manifests, which Routes and Ingresses target
+					Name:       "http",
 					Port:       config.Port,
 					TargetPort: intstr.FromInt(int(config.Port)),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
-			Selector: map[string]string{
-				"app": config.Name,
-			},
+			Selector: selector,
 		},
 	}
 
@@ -390,6 +794,10 @@ func (da *DeploymentAutomation) createOrUpdateIngress(ctx context.Context, confi
 	labels["app"] = config.Name
 
 	pathType := networkingv1.PathTypePrefix
+	var ingressClass *string
+	if config.IngressClass != "" {
+		ingressClass = &config.IngressClass
+	}
 
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
@@ -399,9 +807,13 @@ func (da *DeploymentAutomation) createOrUpdateIngress(ctx context.Context, confi
 			Annotations: config.Annotations,
 		},
 		Spec: networkingv1.IngressSpec{
+			IngressClassName: ingressClass,
 			Rules: []networkingv1.IngressRule{
 				{
 					Host: func() string {
+						if config.IngressHost != "" {
+							return config.IngressHost
+						}
 						if config.IngressDomain != "" {
 							return fmt.Sprintf("%s.%s", config.Name, config.IngressDomain)
 						}
@@ -430,6 +842,10 @@ func (da *DeploymentAutomation) createOrUpdateIngress(ctx context.Context, confi
 		},
 	}
 
+	if err := da.ensureIngressHostAvailable(ctx, config.Namespace, config.Name, ingress.Spec.Rules[0].Host); err != nil {
+		return nil, err
+	}
+
 	// Try to get existing ingress
 	existingIngress, err := da.kubeClient.NetworkingV1().Ingresses(config.Namespace).Get(ctx, config.Name, metav1.GetOptions{})
 	if err != nil {
@@ -442,6 +858,58 @@ func (da *DeploymentAutomation) createOrUpdateIngress(ctx context.Context, confi
 	}
 }
 
+// routeGVR is the OpenShift Route API, Routes can claim the same hosts as Ingresses
+var routeGVR = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+
+// ensureIngressHostAvailable fails if an Ingress other than namespace/name, or a Route other than
+// those of the same application, already claims host
+func (da *DeploymentAutomation) ensureIngressHostAvailable(ctx context.Context, namespace, name, host string) error {
+	ingresses, err := da.kubeClient.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to check ingress host %s: %w", host, err)
+	}
+	for _, existing := range ingresses.Items {
+		if existing.Namespace == namespace && existing.Name == name {
+			continue
+		}
+		for _, rule := range existing.Spec.Rules {
+			if rule.Host == host {
+				return fmt.Errorf("ingress host %s is already claimed by ingress %s/%s", host, existing.Namespace, existing.Name)
+			}
+		}
+	}
+
+	routes, err := da.dynamicClient.Resource(routeGVR).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		// Clusters without the Route API
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check route host %s: %w", host, err)
+	}
+	for _, route := range routes.Items {
+		if routeHost, _, _ := unstructured.NestedString(route.Object, "spec", "host"); routeHost != host {
+			continue
+		}
+		if route.GetNamespace() == namespace && (route.GetName() == name || ownedByIngress(route.GetOwnerReferences(), name)) {
+			continue
+		}
+		return fmt.Errorf("ingress host %s is already claimed by route %s/%s", host, route.GetNamespace(), route.GetName())
+	}
+	return nil
+}
+
+// ownedByIngress reports whether owner references name an Ingress, as on the Routes OpenShift
+// generates for Ingresses
+func ownedByIngress(owners []metav1.OwnerReference, name string) bool {
+	for _, owner := range owners {
+		if owner.Kind == "Ingress" && owner.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (da *DeploymentAutomation) waitForDeployment(ctx context.Context, namespace, name string, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -465,7 +933,9 @@ func (da *DeploymentAutomation) waitForDeployment(ctx context.Context, namespace
 	}
 }
 
-func (da *DeploymentAutomation) DeleteApplication(ctx context.Context, namespace, name string) error {
+// DeleteApplication deletes the resources of an application. Its persistent volume claims, and the
+// data on them, are kept when retainData is set.
+func (da *DeploymentAutomation) DeleteApplication(ctx context.Context, namespace, name string, retainData bool) error {
 	logs := []string{}
 
 	// Delete deployment
@@ -492,8 +962,22 @@ func (da *DeploymentAutomation) DeleteApplication(ctx context.Context, namespace
 		logs = append(logs, fmt.Sprintf("Deleted ingress %s", name))
 	}
 
+	// Delete persistent volume claims
+	if retainData {
+		logs = append(logs, fmt.Sprintf("Retained persistent volume claims of %s", name))
+	} else {
+		err = da.kubeClient.CoreV1().PersistentVolumeClaims(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("app=%s,app.kubernetes.io/managed-by=ai-mcp-openshift-server", name),
+		})
+		if err != nil {
+			logs = append(logs, fmt.Sprintf("Warning: Failed to delete persistent volume claims: %v", err))
+		} else {
+			logs = append(logs, fmt.Sprintf("Deleted persistent volume claims of %s", name))
+		}
+	}
+
 	for _, logEntry := range logs {
-		log.Println(logEntry)
+		klog.V(1).Info(logEntry)
 	}
 
 	return nil
@@ -0,0 +1,106 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultTargetCPUUtilization is the average CPU utilization, as a percentage of the CPU request,
+// the autoscaler aims for when none is configured
+const defaultTargetCPUUtilization = 80
+
+// AutoscalingConfig configures the HorizontalPodAutoscaler of an application
+type AutoscalingConfig struct {
+	// MinReplicas defaults to the replicas of the deployment
+	MinReplicas int32
+	MaxReplicas int32
+	// TargetCPUUtilization defaults to 80 percent of the CPU request
+	TargetCPUUtilization int32
+}
+
+// validate applies the defaults and checks the replica bounds and CPU target
+func (a *AutoscalingConfig) validate(replicas int32) error {
+	if a.MinReplicas == 0 {
+		a.MinReplicas = max(replicas, 1)
+	}
+	if a.TargetCPUUtilization == 0 {
+		a.TargetCPUUtilization = defaultTargetCPUUtilization
+	}
+	switch {
+	case a.MinReplicas < 1:
+		return fmt.Errorf("autoscaling min replicas must be at least 1, got %d", a.MinReplicas)
+	case a.MaxReplicas < a.MinReplicas:
+		return fmt.Errorf("autoscaling max replicas (%d) must be at least the min replicas (%d)", a.MaxReplicas, a.MinReplicas)
+	case a.TargetCPUUtilization < 1 || a.TargetCPUUtilization > 100:
+		return fmt.Errorf("autoscaling target CPU utilization must be between 1 and 100 percent, got %d", a.TargetCPUUtilization)
+	}
+	return nil
+}
+
+// hasCPURequest reports whether the container requests CPU, which utilization targets are
+// relative to
+func hasCPURequest(resources *ResourceRequirements) bool {
+	return resources != nil && resources.Requests["cpu"] != ""
+}
+
+// createOrUpdateAutoscaler points an autoscaling/v2 HorizontalPodAutoscaler at the application's
+// workload, a Deployment or an OpenShift DeploymentConfig
+func (da *DeploymentAutomation) createOrUpdateAutoscaler(ctx context.Context, config DeploymentConfig, target autoscalingv2.CrossVersionObjectReference) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	autoscaling := config.Autoscaling
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.Name,
+			Namespace: config.Namespace,
+			Labels:    config.Labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: target,
+			MinReplicas:    &autoscaling.MinReplicas,
+			MaxReplicas:    autoscaling.MaxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: "cpu",
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: &autoscaling.TargetCPUUtilization,
+					},
+				},
+			}},
+		},
+	}
+
+	autoscalers := da.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(config.Namespace)
+	existing, err := autoscalers.Get(ctx, config.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return autoscalers.Create(ctx, hpa, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	hpa.ResourceVersion = existing.ResourceVersion
+	return autoscalers.Update(ctx, hpa, metav1.UpdateOptions{})
+}
+
+// applyAutoscaler creates or updates the autoscaler of an application when autoscaling is
+// configured, returning the deployment log lines. A failure is only logged as a warning since the
+// application itself is deployed.
+func (da *DeploymentAutomation) applyAutoscaler(ctx context.Context, config DeploymentConfig, target autoscalingv2.CrossVersionObjectReference) []string {
+	if config.Autoscaling == nil {
+		return nil
+	}
+	logs := []string{}
+	if !hasCPURequest(config.Resources) {
+		logs = append(logs, fmt.Sprintf("Warning: %s has no CPU request, the autoscaler cannot compute CPU utilization until one is set", config.Name))
+	}
+	hpa, err := da.createOrUpdateAutoscaler(ctx, config, target)
+	if err != nil {
+		return append(logs, fmt.Sprintf("Warning: Failed to create/update horizontal pod autoscaler: %v", err))
+	}
+	return append(logs, fmt.Sprintf("Created/updated horizontal pod autoscaler %s scaling %s between %d and %d replicas at %d%% CPU",
+		hpa.Name, target.Name, config.Autoscaling.MinReplicas, config.Autoscaling.MaxReplicas, config.Autoscaling.TargetCPUUtilization))
+}
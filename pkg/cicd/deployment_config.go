@@ -0,0 +1,279 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var (
+	deploymentConfigGVR = schema.GroupVersionResource{Group: "apps.openshift.io", Version: "v1", Resource: "deploymentconfigs"}
+	imageStreamGVR      = schema.GroupVersionResource{Group: "image.openshift.io", Version: "v1", Resource: "imagestreams"}
+)
+
+// deploymentConfigsAvailable reports whether the cluster serves the OpenShift apps and image APIs
+func (da *DeploymentAutomation) deploymentConfigsAvailable() bool {
+	for _, gvr := range []schema.GroupVersionResource{deploymentConfigGVR, imageStreamGVR} {
+		if _, err := da.kubeClient.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String()); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// deployWithDeploymentConfig deploys an application as an OpenShift DeploymentConfig fed by an
+// ImageStream. The image stream periodically imports the tag from the registry and the image
+// change trigger rolls out every new image pushed to it, without another deployment call.
+func (da *DeploymentAutomation) deployWithDeploymentConfig(ctx context.Context, config DeploymentConfig, startTime time.Time, logs []string) (*DeploymentResult, error) {
+	failed := func(err error) (*DeploymentResult, error) {
+		return &DeploymentResult{
+			Strategy:   config.Strategy,
+			Success:    false,
+			Error:      err,
+			DeployTime: time.Since(startTime),
+			Logs:       logs,
+		}, nil
+	}
+
+	if err := da.applyImageStream(ctx, config); err != nil {
+		return failed(fmt.Errorf("failed to create/update image stream: %w", err))
+	}
+	logs = append(logs, fmt.Sprintf("Created/updated image stream %s tracking %s:%s", config.Name, config.Image, config.Tag))
+
+	if err := da.applyDeploymentConfig(ctx, config); err != nil {
+		return failed(fmt.Errorf("failed to create/update deployment config: %w", err))
+	}
+	logs = append(logs, fmt.Sprintf("Created/updated deployment config %s with %s strategy and image change trigger on %s:%s", config.Name, config.Strategy, config.Name, config.Tag))
+
+	logs = append(logs, da.applyAutoscaler(ctx, config, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps.openshift.io/v1", Kind: "DeploymentConfig", Name: config.Name})...)
+
+	service, err := da.createOrUpdateService(ctx, config, map[string]string{"app": config.Name})
+	if err != nil {
+		return failed(fmt.Errorf("failed to create/update service: %w", err))
+	}
+	logs = append(logs, fmt.Sprintf("Created/updated service %s", service.Name))
+
+	var ingressURL string
+	if config.ExposeIngress {
+		ingress, err := da.createOrUpdateIngress(ctx, config)
+		if err != nil {
+			logs = append(logs, fmt.Sprintf("Warning: Failed to create ingress: %v", err))
+		} else if len(ingress.Spec.Rules) > 0 {
+			ingressURL = fmt.Sprintf("https://%s", ingress.Spec.Rules[0].Host)
+			logs = append(logs, fmt.Sprintf("Created/updated ingress %s with URL %s", ingress.Name, ingressURL))
+		}
+	}
+
+	dc, err := da.waitForDeploymentConfig(ctx, config.Namespace, config.Name, 5*time.Minute)
+	if err != nil {
+		return failed(fmt.Errorf("deployment config did not become ready: %w", err))
+	}
+	logs = append(logs, fmt.Sprintf("Deployment config %s is ready", config.Name))
+
+	ready, _, _ := unstructured.NestedInt64(dc.Object, "status", "readyReplicas")
+	replicas, _, _ := unstructured.NestedInt64(dc.Object, "status", "replicas")
+	return &DeploymentResult{
+		Name:             config.Name,
+		Strategy:         config.Strategy,
+		ActiveDeployment: config.Name,
+		Namespace:        config.Namespace,
+		Image:            fmt.Sprintf("%s:%s", config.Image, config.Tag),
+		Status:           "Ready",
+		Replicas:         fmt.Sprintf("%d/%d", ready, replicas),
+		ServiceName:      service.Name,
+		IngressURL:       ingressURL,
+		DeployTime:       time.Since(startTime),
+		Volumes:          da.volumeStatuses(ctx, config),
+		PullSecret:       config.pullSecretName(),
+		Success:          true,
+		Error:            nil,
+		Logs:             logs,
+	}, nil
+}
+
+// applyImageStream creates the application's image stream or sets its tag, leaving other tags alone
+func (da *DeploymentAutomation) applyImageStream(ctx context.Context, config DeploymentConfig) error {
+	tag := map[string]interface{}{
+		"name": config.Tag,
+		"from": map[string]interface{}{
+			"kind": "DockerImage",
+			"name": fmt.Sprintf("%s:%s", config.Image, config.Tag),
+		},
+		"importPolicy":    map[string]interface{}{"scheduled": true},
+		"referencePolicy": map[string]interface{}{"type": "Source"},
+	}
+	client := da.dynamicClient.Resource(imageStreamGVR).Namespace(config.Namespace)
+	existing, err := client.Get(ctx, config.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		imageStream := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "image.openshift.io/v1",
+			"kind":       "ImageStream",
+			"metadata": map[string]interface{}{
+				"name":      config.Name,
+				"namespace": config.Namespace,
+				"labels":    toInterfaceMap(config.Labels),
+			},
+			"spec": map[string]interface{}{
+				"lookupPolicy": map[string]interface{}{"local": true},
+				"tags":         []interface{}{tag},
+			},
+		}}
+		_, err = client.Create(ctx, imageStream, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	tags, _, _ := unstructured.NestedSlice(existing.Object, "spec", "tags")
+	replaced := false
+	for i, existingTag := range tags {
+		if entry, ok := existingTag.(map[string]interface{}); ok && entry["name"] == config.Tag {
+			tags[i], replaced = tag, true
+		}
+	}
+	if !replaced {
+		tags = append(tags, tag)
+	}
+	if err := unstructured.SetNestedSlice(existing.Object, tags, "spec", "tags"); err != nil {
+		return err
+	}
+	_, err = client.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// applyDeploymentConfig creates or replaces the application's deployment config, triggered by
+// configuration changes and by new images on its image stream tag
+func (da *DeploymentAutomation) applyDeploymentConfig(ctx context.Context, config DeploymentConfig) error {
+	labels := config.Labels
+	labels["app"] = config.Name
+	labels["version"] = config.Tag
+	podTemplate := podTemplate(config, labels)
+	template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podTemplate)
+	if err != nil {
+		return err
+	}
+	strategy, err := deploymentConfigStrategy(config)
+	if err != nil {
+		return err
+	}
+	dc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps.openshift.io/v1",
+		"kind":       "DeploymentConfig",
+		"metadata": map[string]interface{}{
+			"name":        config.Name,
+			"namespace":   config.Namespace,
+			"labels":      toInterfaceMap(labels),
+			"annotations": toInterfaceMap(config.Annotations),
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(config.Replicas),
+			"selector": map[string]interface{}{"app": config.Name},
+			"strategy": strategy,
+			"template": template,
+			"triggers": []interface{}{
+				map[string]interface{}{"type": "ConfigChange"},
+				map[string]interface{}{
+					"type": "ImageChange",
+					"imageChangeParams": map[string]interface{}{
+						"automatic":      true,
+						"containerNames": []interface{}{config.Name},
+						"from": map[string]interface{}{
+							"kind": "ImageStreamTag",
+							"name": fmt.Sprintf("%s:%s", config.Name, config.Tag),
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	client := da.dynamicClient.Resource(deploymentConfigGVR).Namespace(config.Namespace)
+	existing, err := client.Get(ctx, config.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(ctx, dc, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	dc.SetResourceVersion(existing.GetResourceVersion())
+	// The autoscaler owns the replica count
+	if config.Autoscaling != nil {
+		if replicas, found, _ := unstructured.NestedInt64(existing.Object, "spec", "replicas"); found {
+			_ = unstructured.SetNestedField(dc.Object, replicas, "spec", "replicas")
+		}
+	}
+	_, err = client.Update(ctx, dc, metav1.UpdateOptions{})
+	return err
+}
+
+// deploymentConfigStrategy translates DeploymentConfig.Strategy into a DeploymentConfig strategy,
+// whose rolling parameters mirror those of a Deployment
+func deploymentConfigStrategy(config DeploymentConfig) (map[string]interface{}, error) {
+	strategy, err := deploymentStrategy(config)
+	if err != nil {
+		return nil, err
+	}
+	if strategy.RollingUpdate == nil {
+		return map[string]interface{}{"type": "Recreate"}, nil
+	}
+	params := map[string]interface{}{}
+	if bound := strategy.RollingUpdate.MaxSurge; bound != nil {
+		params["maxSurge"] = intOrStringValue(*bound)
+	}
+	if bound := strategy.RollingUpdate.MaxUnavailable; bound != nil {
+		params["maxUnavailable"] = intOrStringValue(*bound)
+	}
+	return map[string]interface{}{"type": "Rolling", "rollingParams": params}, nil
+}
+
+// waitForDeploymentConfig waits until the latest rollout of a deployment config is ready
+func (da *DeploymentAutomation) waitForDeploymentConfig(ctx context.Context, namespace, name string, timeout time.Duration) (*unstructured.Unstructured, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client := da.dynamicClient.Resource(deploymentConfigGVR).Namespace(namespace)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			dc, err := client.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get deployment config: %w", err)
+			}
+			replicas, _, _ := unstructured.NestedInt64(dc.Object, "spec", "replicas")
+			ready, _, _ := unstructured.NestedInt64(dc.Object, "status", "readyReplicas")
+			updated, _, _ := unstructured.NestedInt64(dc.Object, "status", "updatedReplicas")
+			observed, _, _ := unstructured.NestedInt64(dc.Object, "status", "observedGeneration")
+			// latestVersion stays 0 until the image change trigger resolved the image stream tag
+			latest, _, _ := unstructured.NestedInt64(dc.Object, "status", "latestVersion")
+			if latest > 0 && ready == replicas && updated == replicas && observed >= dc.GetGeneration() {
+				return dc, nil
+			}
+		}
+	}
+}
+
+func intOrStringValue(value intstr.IntOrString) interface{} {
+	if value.Type == intstr.Int {
+		return int64(value.IntVal)
+	}
+	return value.StrVal
+}
+
+func toInterfaceMap(values map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+	return result
+}
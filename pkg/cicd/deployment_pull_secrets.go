@@ -0,0 +1,166 @@
+package cicd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// defaultServiceAccount runs the pods of applications deployed without a service account
+const defaultServiceAccount = "default"
+
+// ImagePullSecret names an existing pull secret, or holds registry credentials turned into a
+// kubernetes.io/dockerconfigjson secret named Name (<app>-pull-secret by default)
+type ImagePullSecret struct {
+	Name     string
+	Registry string
+	Username string
+	Password string
+	Email    string
+}
+
+// HasCredentials reports whether the secret is created from inline credentials rather than
+// referencing an existing secret
+func (p *ImagePullSecret) HasCredentials() bool {
+	return p.Username != "" || p.Password != ""
+}
+
+// SecretName returns the name of the secret for an application
+func (p *ImagePullSecret) SecretName(app string) string {
+	if p.Name == "" && p.HasCredentials() {
+		return app + "-pull-secret"
+	}
+	return p.Name
+}
+
+// dockerConfigJSON encodes registry credentials in the .dockerconfigjson format
+func dockerConfigJSON(registry, username, password, email string) ([]byte, error) {
+	// The kubelet matches Docker Hub images against its legacy index URL
+	if registry == "docker.io" {
+		registry = "https://index.docker.io/v1/"
+	}
+	auth := map[string]string{
+		"username": username,
+		"password": password,
+		"auth":     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	}
+	if email != "" {
+		auth["email"] = email
+	}
+	return json.Marshal(map[string]interface{}{"auths": map[string]interface{}{registry: auth}})
+}
+
+// EnsurePullSecret creates or updates the pull secret of inline credentials, or checks that a
+// referenced secret exists and can be used to pull images. It returns the name of the secret.
+func EnsurePullSecret(ctx context.Context, secrets corev1client.SecretInterface, app string, pullSecret *ImagePullSecret, labels map[string]string) (string, error) {
+	name := pullSecret.SecretName(app)
+	if !pullSecret.HasCredentials() {
+		if name == "" {
+			return "", fmt.Errorf("image pull secret needs the name of an existing secret or registry credentials")
+		}
+		existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get image pull secret %s: %w", name, err)
+		}
+		if existing.Type != corev1.SecretTypeDockerConfigJson && existing.Type != corev1.SecretTypeDockercfg {
+			return "", fmt.Errorf("secret %s has type %s, image pull secrets must be of type %s", name, existing.Type, corev1.SecretTypeDockerConfigJson)
+		}
+		return name, nil
+	}
+
+	if pullSecret.Registry == "" || pullSecret.Username == "" || pullSecret.Password == "" {
+		return "", fmt.Errorf("image pull secret credentials need a registry, username and password")
+	}
+	config, err := dockerConfigJSON(pullSecret.Registry, pullSecret.Username, pullSecret.Password, pullSecret.Email)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode registry credentials: %w", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: config},
+	}
+	existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+	} else if err == nil {
+		if existing.Type != corev1.SecretTypeDockerConfigJson {
+			return "", fmt.Errorf("secret %s already exists with type %s", name, existing.Type)
+		}
+		secret.ResourceVersion = existing.ResourceVersion
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create/update image pull secret %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// LinkPullSecret adds a pull secret to the image pull secrets of the namespace's default service
+// account, so every pod of the namespace can pull with it. It reports whether the secret was added.
+func LinkPullSecret(ctx context.Context, serviceAccounts corev1client.ServiceAccountInterface, name string) (bool, error) {
+	linked := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		account, err := serviceAccounts.Get(ctx, defaultServiceAccount, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for _, reference := range account.ImagePullSecrets {
+			if reference.Name == name {
+				return nil
+			}
+		}
+		account.ImagePullSecrets = append(account.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+		if _, err := serviceAccounts.Update(ctx, account, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		linked = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to link image pull secret %s to service account %s: %w", name, defaultServiceAccount, err)
+	}
+	return linked, nil
+}
+
+// applyPullSecret prepares the pull secret of an application, returning the deployment log lines.
+// The resolved secret name is set on the configuration for the pod template.
+func (da *DeploymentAutomation) applyPullSecret(ctx context.Context, config *DeploymentConfig) ([]string, error) {
+	if config.ImagePullSecret == nil {
+		return nil, nil
+	}
+	logs := []string{}
+	name, err := EnsurePullSecret(ctx, da.kubeClient.CoreV1().Secrets(config.Namespace), config.Name, config.ImagePullSecret, config.Labels)
+	if err != nil {
+		return logs, err
+	}
+	pullSecret := *config.ImagePullSecret
+	pullSecret.Name = name
+	config.ImagePullSecret = &pullSecret
+	if pullSecret.HasCredentials() {
+		logs = append(logs, fmt.Sprintf("Created/updated image pull secret %s for %s", name, pullSecret.Registry))
+	}
+
+	// The service account may not exist yet in a namespace that was just created
+	if linked, err := LinkPullSecret(ctx, da.kubeClient.CoreV1().ServiceAccounts(config.Namespace), name); err != nil {
+		logs = append(logs, fmt.Sprintf("Warning: %v", err))
+	} else if linked {
+		logs = append(logs, fmt.Sprintf("Linked image pull secret %s to service account %s", name, defaultServiceAccount))
+	}
+	return logs, nil
+}
+
+// pullSecretName returns the pull secret attached to the pods of an application, empty for none
+func (c DeploymentConfig) pullSecretName() string {
+	if c.ImagePullSecret == nil {
+		return ""
+	}
+	return c.ImagePullSecret.Name
+}
@@ -0,0 +1,159 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// revisionAnnotation numbers the revisions of a deployment on it and on its ReplicaSets
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// RollbackResult is the outcome of rolling a deployment back to an earlier revision
+type RollbackResult struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	FromRevision int64  `json:"from_revision"`
+	ToRevision   int64  `json:"to_revision"`
+	Image        string `json:"image"`
+	// The rolled back template becomes a new revision of the deployment
+	NewRevision   int64  `json:"new_revision,omitempty"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"ready_replicas"`
+	Ready         bool   `json:"ready"`
+	Duration      string `json:"duration"`
+}
+
+// Rollback restores the pod template of a deployed application from its revision history, the
+// previous revision when toRevision is 0, and waits up to timeout for the rollout
+func (da *DeploymentAutomation) Rollback(ctx context.Context, namespace, name string, toRevision int64, timeout time.Duration) (*RollbackResult, error) {
+	return RollbackDeployment(ctx, da.kubeClient.AppsV1().Deployments(namespace), da.kubeClient.AppsV1().ReplicaSets(namespace), namespace, name, toRevision, timeout)
+}
+
+// RollbackDeployment copies the pod template of the ReplicaSet recording toRevision back into the
+// deployment, as kubectl rollout undo does. Revisions whose ReplicaSet was garbage collected past
+// the revision history limit cannot be restored.
+func RollbackDeployment(ctx context.Context, deployments appsv1client.DeploymentInterface, replicaSets appsv1client.ReplicaSetInterface, namespace, name string, toRevision int64, timeout time.Duration) (*RollbackResult, error) {
+	startTime := time.Now()
+	deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, deploymentNotFoundError(ctx, deployments, namespace, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment %s: %w", name, err)
+	}
+	if deployment.Spec.Paused {
+		return nil, fmt.Errorf("deployment %s is paused, resume it before rolling back", name)
+	}
+	history, err := revisionHistory(ctx, replicaSets, deployment)
+	if err != nil {
+		return nil, err
+	}
+	current := revisionOf(&deployment.ObjectMeta)
+
+	if toRevision == 0 {
+		for revision := range history {
+			if revision < current && revision > toRevision {
+				toRevision = revision
+			}
+		}
+		if toRevision == 0 {
+			return nil, fmt.Errorf("deployment %s has no revision before %d to roll back to", name, current)
+		}
+	}
+	if toRevision == current {
+		return nil, fmt.Errorf("deployment %s is already at revision %d", name, current)
+	}
+	target, exists := history[toRevision]
+	if !exists {
+		return nil, fmt.Errorf("revision %d of deployment %s has no ReplicaSet, available revisions: %s", toRevision, name, formatRevisions(history))
+	}
+
+	// The ReplicaSet's pod-template-hash label is added by the deployment controller
+	template := target.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := deployments.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if apiequality.Semantic.DeepEqual(latest.Spec.Template, *template) {
+			return nil
+		}
+		latest.Spec.Template = *template
+		_, err = deployments.Update(ctx, latest, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back deployment %s: %w", name, err)
+	}
+
+	result := &RollbackResult{Name: name, Namespace: namespace, FromRevision: current, ToRevision: toRevision}
+	if len(template.Spec.Containers) > 0 {
+		result.Image = template.Spec.Containers[0].Image
+	}
+	deployment, ready, err := awaitDeployment(ctx, deployments, name, timeout)
+	if err != nil {
+		return nil, err
+	}
+	result.NewRevision = revisionOf(&deployment.ObjectMeta)
+	result.Replicas = deployment.Status.Replicas
+	result.ReadyReplicas = deployment.Status.ReadyReplicas
+	result.Ready = ready
+	result.Duration = time.Since(startTime).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// revisionHistory returns the ReplicaSets owned by a deployment by revision
+func revisionHistory(ctx context.Context, replicaSets appsv1client.ReplicaSetInterface, deployment *appsv1.Deployment) (map[int64]*appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %s: %w", deployment.Name, err)
+	}
+	list, err := replicaSets.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ReplicaSets of deployment %s: %w", deployment.Name, err)
+	}
+	history := make(map[int64]*appsv1.ReplicaSet)
+	for i := range list.Items {
+		replicaSet := &list.Items[i]
+		if owner := metav1.GetControllerOf(replicaSet); owner == nil || owner.UID != deployment.UID {
+			continue
+		}
+		if revision := revisionOf(&replicaSet.ObjectMeta); revision > 0 {
+			history[revision] = replicaSet
+		}
+	}
+	return history, nil
+}
+
+func revisionOf(meta *metav1.ObjectMeta) int64 {
+	revision, _ := strconv.ParseInt(meta.Annotations[revisionAnnotation], 10, 64)
+	return revision
+}
+
+func formatRevisions(history map[int64]*appsv1.ReplicaSet) string {
+	if len(history) == 0 {
+		return "none"
+	}
+	revisions := make([]int64, 0, len(history))
+	for revision := range history {
+		revisions = append(revisions, revision)
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i] < revisions[j] })
+	formatted := make([]string, len(revisions))
+	for i, revision := range revisions {
+		formatted[i] = strconv.FormatInt(revision, 10)
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,111 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+)
+
+// scalePollInterval is how often a scaled or rolled back deployment is checked for readiness
+const scalePollInterval = 2 * time.Second
+
+// ScaleResult is the outcome of scaling a deployment
+type ScaleResult struct {
+	Name             string `json:"name"`
+	Namespace        string `json:"namespace"`
+	PreviousReplicas int32  `json:"previous_replicas"`
+	Replicas         int32  `json:"replicas"`
+	ReadyReplicas    int32  `json:"ready_replicas"`
+	// Ready is false when the timeout elapsed before every replica was ready
+	Ready    bool   `json:"ready"`
+	Duration string `json:"duration"`
+}
+
+// ScaleApplication changes the replica count of a deployed application without redeploying it,
+// waiting up to timeout for the new ready count
+func (da *DeploymentAutomation) ScaleApplication(ctx context.Context, namespace, name string, replicas int32, timeout time.Duration) (*ScaleResult, error) {
+	return ScaleDeployment(ctx, da.kubeClient.AppsV1().Deployments(namespace), namespace, name, replicas, timeout)
+}
+
+// ScaleDeployment sets the replicas of a deployment through its scale subresource and waits up to
+// timeout for the ready count to match, a zero timeout returns without waiting. Running out of
+// time is not an error, the result reports the replicas ready so far.
+func ScaleDeployment(ctx context.Context, deployments appsv1client.DeploymentInterface, namespace, name string, replicas int32, timeout time.Duration) (*ScaleResult, error) {
+	if replicas < 0 {
+		return nil, fmt.Errorf("replicas must not be negative, got %d", replicas)
+	}
+	startTime := time.Now()
+	scale, err := deployments.GetScale(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, deploymentNotFoundError(ctx, deployments, namespace, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale of deployment %s: %w", name, err)
+	}
+	result := &ScaleResult{Name: name, Namespace: namespace, PreviousReplicas: scale.Spec.Replicas, Replicas: replicas}
+	if scale.Spec.Replicas != replicas {
+		scale.Spec.Replicas = replicas
+		if _, err := deployments.UpdateScale(ctx, name, scale, metav1.UpdateOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to scale deployment %s: %w", name, err)
+		}
+	}
+
+	deployment, ready, err := awaitDeployment(ctx, deployments, name, timeout)
+	if err != nil {
+		return nil, err
+	}
+	result.ReadyReplicas = deployment.Status.ReadyReplicas
+	result.Ready = ready
+	result.Duration = time.Since(startTime).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// awaitDeployment polls a deployment until its rollout is complete and every desired replica is
+// ready, or timeout elapses. It reports whether the deployment became ready.
+func awaitDeployment(ctx context.Context, deployments appsv1client.DeploymentInterface, name string, timeout time.Duration) (*appsv1.Deployment, bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get deployment %s: %w", name, err)
+		}
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		// Replicas being removed still count in status.replicas until they terminate
+		ready := deployment.Status.ObservedGeneration >= deployment.Generation &&
+			deployment.Status.UpdatedReplicas == desired &&
+			deployment.Status.ReadyReplicas == desired &&
+			deployment.Status.Replicas == desired
+		if ready || !time.Now().Before(deadline) {
+			return deployment, ready, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(scalePollInterval):
+		}
+	}
+}
+
+// deploymentNotFoundError names the deployments that do exist in the namespace, to catch typos
+func deploymentNotFoundError(ctx context.Context, deployments appsv1client.DeploymentInterface, namespace, name string) error {
+	list, err := deployments.List(ctx, metav1.ListOptions{})
+	if err != nil || len(list.Items) == 0 {
+		return fmt.Errorf("deployment %s not found in namespace %s", name, namespace)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, deployment := range list.Items {
+		names = append(names, deployment.Name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("deployment %s not found in namespace %s, available deployments: %s", name, namespace, strings.Join(names, ", "))
+}
@@ -0,0 +1,242 @@
+package cicd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// applyConfigData creates or updates the ConfigMaps and Secrets given inline, then fails with the
+// list of every other referenced ConfigMap or Secret missing from the namespace
+func (da *DeploymentAutomation) applyConfigData(ctx context.Context, config DeploymentConfig) ([]string, error) {
+	core := da.kubeClient.CoreV1()
+	return ApplyConfigData(ctx, core.ConfigMaps(config.Namespace), core.Secrets(config.Namespace), config)
+}
+
+// ApplyConfigData creates or updates the inline ConfigMaps and Secrets of a deployment with the
+// given clients of its namespace, then fails with the list of every other ConfigMap or Secret the
+// deployment references that is missing
+func ApplyConfigData(ctx context.Context, configMaps corev1client.ConfigMapInterface, secrets corev1client.SecretInterface, config DeploymentConfig) ([]string, error) {
+	var logs []string
+	for _, name := range sortedKeys(config.ConfigMaps) {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: config.Namespace, Labels: config.Labels},
+			Data:       config.ConfigMaps[name],
+		}
+		existing, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			configMap.ResourceVersion = existing.ResourceVersion
+			_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+		} else if apierrors.IsNotFound(err) {
+			_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+		}
+		if err != nil {
+			return logs, fmt.Errorf("failed to create/update configmap %s: %w", name, err)
+		}
+		logs = append(logs, fmt.Sprintf("Created/updated configmap %s", name))
+	}
+	for _, name := range sortedKeys(config.Secrets) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: config.Namespace, Labels: config.Labels},
+			Type:       corev1.SecretTypeOpaque,
+			StringData: config.Secrets[name],
+		}
+		existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			secret.ResourceVersion = existing.ResourceVersion
+			_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		} else if apierrors.IsNotFound(err) {
+			_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		}
+		if err != nil {
+			return logs, fmt.Errorf("failed to create/update secret %s: %w", name, err)
+		}
+		logs = append(logs, fmt.Sprintf("Created/updated secret %s", name))
+	}
+
+	var missing []string
+	for _, name := range sortedKeys(config.ConfigMapMounts) {
+		if _, inline := config.ConfigMaps[name]; inline {
+			continue
+		}
+		if _, err := configMaps.Get(ctx, name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			missing = append(missing, "configmap/"+name)
+		} else if err != nil {
+			return logs, fmt.Errorf("failed to check configmap %s: %w", name, err)
+		}
+	}
+	secretRefs := append(sortedKeys(config.SecretMounts), config.SecretEnvFrom...)
+	seen := make(map[string]bool)
+	for _, name := range secretRefs {
+		if _, inline := config.Secrets[name]; inline || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, err := secrets.Get(ctx, name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			missing = append(missing, "secret/"+name)
+		} else if err != nil {
+			return logs, fmt.Errorf("failed to check secret %s: %w", name, err)
+		}
+	}
+	if len(missing) > 0 {
+		return logs, fmt.Errorf("referenced resources not found in namespace %s: %s", config.Namespace, strings.Join(missing, ", "))
+	}
+	return logs, nil
+}
+
+// addConfigVolumes mounts the referenced ConfigMaps and Secrets read-only in the application
+// container and loads the SecretEnvFrom secrets as environment variables. Volumes are added in
+// name order so an unchanged configuration does not trigger a rollout.
+func addConfigVolumes(spec *corev1.PodSpec, config DeploymentConfig) {
+	container := &spec.Containers[0]
+	for _, name := range sortedKeys(config.ConfigMapMounts) {
+		volume := VolumeName("cm", name)
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: volume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: volume, MountPath: config.ConfigMapMounts[name], ReadOnly: true})
+	}
+	for _, name := range sortedKeys(config.SecretMounts) {
+		volume := VolumeName("secret", name)
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: volume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: name},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: volume, MountPath: config.SecretMounts[name], ReadOnly: true})
+	}
+	for _, name := range config.SecretEnvFrom {
+		container.EnvFrom = append(container.EnvFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+		})
+	}
+}
+
+// VolumeName prefixes a resource name into a volume name, which must fit a 63 character DNS label
+func VolumeName(prefix, name string) string {
+	volume := prefix + "-" + strings.ReplaceAll(name, ".", "-")
+	if len(volume) > 63 {
+		volume = strings.TrimRight(volume[:63], "-")
+	}
+	return volume
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// VolumeSpec requests a PersistentVolumeClaim named <app>-<Name> mounted at MountPath
+type VolumeSpec struct {
+	Name         string
+	Size         string // e.g. "1Gi"
+	StorageClass string // cluster default when empty
+	AccessMode   string // ReadWriteOnce by default
+	MountPath    string
+}
+
+// VolumeStatus is a claim of a deployed application, Capacity is empty until it is bound
+type VolumeStatus struct {
+	ClaimName string `json:"claim_name"`
+	MountPath string `json:"mount_path"`
+	Phase     string `json:"phase"`
+	Capacity  string `json:"capacity,omitempty"`
+}
+
+func claimName(app string, volume VolumeSpec) string {
+	return app + "-" + volume.Name
+}
+
+// ensureVolumeClaims creates the claims of the application that do not exist yet. Existing claims
+// are left untouched, their data is kept across deployments.
+func (da *DeploymentAutomation) ensureVolumeClaims(ctx context.Context, config DeploymentConfig) ([]string, error) {
+	var logs []string
+	client := da.kubeClient.CoreV1().PersistentVolumeClaims(config.Namespace)
+	for _, volume := range config.Volumes {
+		if volume.Name == "" || volume.MountPath == "" {
+			return logs, fmt.Errorf("volume requires a name and a mount path")
+		}
+		size, err := resource.ParseQuantity(volume.Size)
+		if err != nil {
+			return logs, fmt.Errorf("invalid size %q for volume %s: %w", volume.Size, volume.Name, err)
+		}
+		accessMode := corev1.ReadWriteOnce
+		if volume.AccessMode != "" {
+			accessMode = corev1.PersistentVolumeAccessMode(volume.AccessMode)
+		}
+		name := claimName(config.Name, volume)
+		if _, err := client.Get(ctx, name, metav1.GetOptions{}); err == nil {
+			logs = append(logs, fmt.Sprintf("Using existing persistent volume claim %s", name))
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			return logs, fmt.Errorf("failed to check persistent volume claim %s: %w", name, err)
+		}
+		claim := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: config.Namespace,
+				Labels:    map[string]string{"app": config.Name, "app.kubernetes.io/managed-by": "ai-mcp-openshift-server"},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{accessMode},
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+				},
+			},
+		}
+		if volume.StorageClass != "" {
+			claim.Spec.StorageClassName = &volume.StorageClass
+		}
+		if _, err := client.Create(ctx, claim, metav1.CreateOptions{}); err != nil {
+			return logs, fmt.Errorf("failed to create persistent volume claim %s: %w", name, err)
+		}
+		logs = append(logs, fmt.Sprintf("Created persistent volume claim %s (%s, %s)", name, volume.Size, accessMode))
+	}
+	return logs, nil
+}
+
+// addClaimVolumes mounts the application's persistent volume claims in its container
+func addClaimVolumes(spec *corev1.PodSpec, config DeploymentConfig) {
+	container := &spec.Containers[0]
+	for _, volume := range config.Volumes {
+		name := VolumeName("data", volume.Name)
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName(config.Name, volume)},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: volume.MountPath})
+	}
+}
+
+// volumeStatuses reports the phase and bound capacity of the application's claims
+func (da *DeploymentAutomation) volumeStatuses(ctx context.Context, config DeploymentConfig) []VolumeStatus {
+	var statuses []VolumeStatus
+	for _, volume := range config.Volumes {
+		status := VolumeStatus{ClaimName: claimName(config.Name, volume), MountPath: volume.MountPath, Phase: "Unknown"}
+		if claim, err := da.kubeClient.CoreV1().PersistentVolumeClaims(config.Namespace).Get(ctx, status.ClaimName, metav1.GetOptions{}); err == nil {
+			status.Phase = string(claim.Status.Phase)
+			if capacity, bound := claim.Status.Capacity[corev1.ResourceStorage]; bound {
+				status.Capacity = capacity.String()
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,128 @@
+package cicd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ecrTokenValidity is how long ECR authorization tokens are valid when the expiry cannot be read
+const ecrTokenValidity = 12 * time.Hour
+
+// ecrTokenRefreshMargin is how long before expiry a cached ECR token is refreshed
+const ecrTokenRefreshMargin = 5 * time.Minute
+
+// ecrRegistryPattern matches <account>.dkr.ecr[-fips].<region>.amazonaws.com[.cn]
+var ecrRegistryPattern = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+type ecrToken struct {
+	username  string
+	password  string
+	expiresAt time.Time
+}
+
+var (
+	ecrTokensMu sync.Mutex
+	ecrTokens   = make(map[string]*ecrToken)
+)
+
+// IsECRRegistry reports whether a registry host or image reference is an Amazon ECR registry
+func IsECRRegistry(registry string) bool {
+	return ecrRegistryPattern.MatchString(ecrHost(registry))
+}
+
+// ecrHost strips the scheme and any repository path from a registry URL or image reference
+func ecrHost(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	host, _, _ := strings.Cut(registry, "/")
+	return host
+}
+
+// ECRCredentials exchanges the AWS credentials of the environment for a short-lived ECR login
+// (user AWS and a token password). Tokens are cached per registry and refreshed shortly before
+// they expire.
+func ECRCredentials(ctx context.Context, registry string) (string, string, error) {
+	host := ecrHost(registry)
+	match := ecrRegistryPattern.FindStringSubmatch(host)
+	if match == nil {
+		return "", "", fmt.Errorf("%s is not an ECR registry", registry)
+	}
+	account, region := match[1], match[2]
+
+	ecrTokensMu.Lock()
+	defer ecrTokensMu.Unlock()
+	if token, cached := ecrTokens[host]; cached && time.Until(token.expiresAt) > ecrTokenRefreshMargin {
+		return token.username, token.password, nil
+	}
+	token, err := fetchECRToken(ctx, account, region)
+	if err != nil {
+		return "", "", err
+	}
+	ecrTokens[host] = token
+	return token.username, token.password, nil
+}
+
+// fetchECRToken calls the ECR GetAuthorizationToken API through the AWS CLI, which resolves
+// credentials from the environment, shared config files, SSO and instance roles
+func fetchECRToken(ctx context.Context, account, region string) (*ecrToken, error) {
+	if _, err := exec.LookPath("aws"); err != nil {
+		return nil, fmt.Errorf("aws CLI not found in PATH, it is required to obtain ECR authorization tokens")
+	}
+	cmd := exec.CommandContext(ctx, "aws", "ecr", "get-authorization-token",
+		"--region", region, "--registry-ids", account, "--output", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("ECR GetAuthorizationToken failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("ECR GetAuthorizationToken failed: %w", err)
+	}
+	return parseECRToken(output)
+}
+
+// parseECRToken decodes a GetAuthorizationToken response, whose token is base64 of AWS:<password>
+func parseECRToken(output []byte) (*ecrToken, error) {
+	var response struct {
+		AuthorizationData []struct {
+			AuthorizationToken string          `json:"authorizationToken"`
+			ExpiresAt          json.RawMessage `json:"expiresAt"`
+		} `json:"authorizationData"`
+	}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse ECR authorization token response: %w", err)
+	}
+	if len(response.AuthorizationData) == 0 {
+		return nil, fmt.Errorf("ECR returned no authorization data")
+	}
+	data := response.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(data.AuthorizationToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ECR authorization token: %w", err)
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return nil, fmt.Errorf("unexpected ECR authorization token format")
+	}
+	return &ecrToken{username: username, password: password, expiresAt: parseECRExpiry(data.ExpiresAt)}, nil
+}
+
+// parseECRExpiry reads expiresAt, which the CLI prints as an ISO 8601 string or epoch seconds
+func parseECRExpiry(raw json.RawMessage) time.Time {
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		if expiresAt, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return expiresAt
+		}
+	}
+	var epoch float64
+	if err := json.Unmarshal(raw, &epoch); err == nil && epoch > 0 {
+		return time.Unix(int64(epoch), 0)
+	}
+	return time.Now().Add(ecrTokenValidity)
+}
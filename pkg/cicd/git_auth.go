@@ -0,0 +1,101 @@
+package cicd
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHKey is a private key used to access Git repositories over SSH, read from Path or given
+// inline as PEM
+type SSHKey struct {
+	Path       string
+	PEM        string
+	Passphrase string
+}
+
+// IsSSHURL reports whether a Git URL uses the SSH transport, git@host:org/repo or ssh://
+func IsSSHURL(url string) bool {
+	if strings.HasPrefix(url, "ssh://") || strings.HasPrefix(url, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(url, "://") {
+		return false
+	}
+	userHost, _, found := strings.Cut(url, ":")
+	return found && strings.Contains(userHost, "@")
+}
+
+// pem returns the key content, reading it from Path when it was not given inline
+func (k *SSHKey) pem() ([]byte, error) {
+	if k.PEM != "" {
+		return []byte(k.PEM), nil
+	}
+	data, err := os.ReadFile(k.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	return data, nil
+}
+
+// AuthMethod returns the go-git SSH transport auth for the key, verifying host keys against the
+// user's known_hosts
+func (k *SSHKey) AuthMethod() (transport.AuthMethod, error) {
+	data, err := k.pem()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := gitssh.NewPublicKeys("git", data, k.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH key: %w", err)
+	}
+	return auth, nil
+}
+
+// GitSSHCommand returns a GIT_SSH_COMMAND using the key for git commands run as processes. Keys
+// given inline or protected by a passphrase are written unencrypted to a temporary file only
+// readable by the current user, which cleanup removes.
+func (k *SSHKey) GitSSHCommand() (string, func(), error) {
+	path, cleanup := k.Path, func() {}
+	if k.PEM != "" || k.Passphrase != "" {
+		data, err := k.pem()
+		if err != nil {
+			return "", nil, err
+		}
+		if k.Passphrase != "" {
+			key, err := ssh.ParseRawPrivateKeyWithPassphrase(data, []byte(k.Passphrase))
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to decrypt SSH key: %w", err)
+			}
+			block, err := ssh.MarshalPrivateKey(key, "")
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to encode SSH key: %w", err)
+			}
+			data = pem.EncodeToMemory(block)
+		}
+		// CreateTemp creates the file with mode 0600, as ssh requires
+		file, err := os.CreateTemp("", "mcp-git-key-*")
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to write SSH key: %w", err)
+		}
+		cleanup = func() { _ = os.Remove(file.Name()) }
+		if _, err := file.Write(data); err != nil {
+			_ = file.Close()
+			cleanup()
+			return "", nil, fmt.Errorf("failed to write SSH key: %w", err)
+		}
+		if err := file.Close(); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to write SSH key: %w", err)
+		}
+		path = file.Name()
+	}
+	// Unknown hosts are trusted on first use and recorded in known_hosts, changed keys are rejected
+	command := fmt.Sprintf("ssh -i '%s' -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new", strings.ReplaceAll(path, "'", `'\''`))
+	return command, cleanup, nil
+}
@@ -3,17 +3,19 @@ package cicd
 import (
 	"context"
 	"fmt"
-	"log"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/memory"
+	"k8s.io/klog/v2"
 )
 
 type GitWatcher struct {
+	mu           sync.RWMutex // guards repositories and webhooks
 	repositories map[string]*Repository
 	webhooks     map[string]*WebhookConfig
 	pollInterval time.Duration
@@ -24,7 +26,7 @@ type Repository struct {
 	URL         string
 	Branch      string
 	LastCommit  string
-	Credentials *http.BasicAuth
+	Credentials transport.AuthMethod // HTTP basic auth or an SSH key, see SSHKey
 	LocalPath   string
 }
 
@@ -55,14 +57,16 @@ func NewGitWatcher(pollInterval time.Duration) *GitWatcher {
 	}
 }
 
-func (gw *GitWatcher) AddRepository(url, branch string, credentials *http.BasicAuth) error {
+func (gw *GitWatcher) AddRepository(url, branch string, credentials transport.AuthMethod) error {
 	key := fmt.Sprintf("%s:%s", url, branch)
 
-	// Clone repository to get initial commit
+	// Clone the watched branch to get its initial commit
 	repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
-		URL:      url,
-		Auth:     credentials,
-		Progress: nil,
+		URL:           url,
+		Auth:          credentials,
+		ReferenceName: plumbing.ReferenceName(fmt.Sprintf("refs/heads/%s", branch)),
+		SingleBranch:  true,
+		Progress:      nil,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to clone repository %s: %w", url, err)
@@ -73,24 +77,30 @@ func (gw *GitWatcher) AddRepository(url, branch string, credentials *http.BasicA
 		return fmt.Errorf("failed to get HEAD reference: %w", err)
 	}
 
+	gw.mu.Lock()
 	gw.repositories[key] = &Repository{
 		URL:         url,
 		Branch:      branch,
 		LastCommit:  ref.Hash().String(),
 		Credentials: credentials,
 	}
+	gw.mu.Unlock()
 
-	log.Printf("Added repository %s (branch: %s) for monitoring", url, branch)
+	klog.V(1).Infof("Added repository %s (branch: %s) for monitoring", url, branch)
 	return nil
 }
 
 func (gw *GitWatcher) RemoveRepository(url, branch string) {
 	key := fmt.Sprintf("%s:%s", url, branch)
+	gw.mu.Lock()
 	delete(gw.repositories, key)
-	log.Printf("Removed repository %s (branch: %s) from monitoring", url, branch)
+	gw.mu.Unlock()
+	klog.V(1).Infof("Removed repository %s (branch: %s) from monitoring", url, branch)
 }
 
 func (gw *GitWatcher) AddCommitCallback(callback CommitCallback) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
 	gw.callbacks = append(gw.callbacks, callback)
 }
 
@@ -98,12 +108,12 @@ func (gw *GitWatcher) StartPolling(ctx context.Context) {
 	ticker := time.NewTicker(gw.pollInterval)
 	defer ticker.Stop()
 
-	log.Printf("Starting Git polling with interval %v", gw.pollInterval)
+	klog.V(1).Infof("Starting Git polling with interval %v", gw.pollInterval)
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Stopping Git polling")
+			klog.V(1).Info("Stopping Git polling")
 			return
 		case <-ticker.C:
 			gw.checkRepositories()
@@ -111,10 +121,21 @@ func (gw *GitWatcher) StartPolling(ctx context.Context) {
 	}
 }
 
+// checkRepositories polls every repository without an active webhook, those are triggered by
+// their push events instead
 func (gw *GitWatcher) checkRepositories() {
+	gw.mu.RLock()
+	repositories := make(map[string]*Repository, len(gw.repositories))
 	for key, repo := range gw.repositories {
+		if _, hooked := gw.webhooks[NormalizeRepoURL(repo.URL)]; !hooked {
+			repositories[key] = repo
+		}
+	}
+	gw.mu.RUnlock()
+
+	for key, repo := range repositories {
 		if err := gw.checkRepository(repo); err != nil {
-			log.Printf("Error checking repository %s: %v", key, err)
+			klog.Errorf("Error checking repository %s: %v", key, err)
 		}
 	}
 }
@@ -141,7 +162,7 @@ func (gw *GitWatcher) checkRepository(repo *Repository) error {
 
 	// Check if there's a new commit
 	if currentCommit != repo.LastCommit {
-		log.Printf("New commit detected in %s:%s - %s", repo.URL, repo.Branch, currentCommit)
+		klog.V(2).Infof("New commit detected in %s:%s - %s", repo.URL, repo.Branch, currentCommit)
 
 		// Get commit details
 		commit, err := gitRepo.CommitObject(ref.Hash())
@@ -152,7 +173,7 @@ func (gw *GitWatcher) checkRepository(repo *Repository) error {
 		// Get changed files
 		files, err := gw.getChangedFiles(gitRepo, repo.LastCommit, currentCommit)
 		if err != nil {
-			log.Printf("Failed to get changed files: %v", err)
+			klog.Warningf("Failed to get changed files: %v", err)
 			files = []string{} // Continue with empty file list
 		}
 
@@ -167,18 +188,28 @@ func (gw *GitWatcher) checkRepository(repo *Repository) error {
 			Files:      files,
 		}
 
-		// Update last commit
-		repo.LastCommit = currentCommit
+		gw.dispatch(event)
+	}
 
-		// Trigger callbacks
-		for _, callback := range gw.callbacks {
-			if err := callback(event); err != nil {
-				log.Printf("Callback error for commit %s: %v", currentCommit, err)
-			}
+	return nil
+}
+
+// dispatch records a new commit on the watched repository, if any, and triggers the callbacks
+func (gw *GitWatcher) dispatch(event CommitEvent) {
+	gw.mu.Lock()
+	for _, repo := range gw.repositories {
+		if repo.Branch == event.Branch && NormalizeRepoURL(repo.URL) == NormalizeRepoURL(event.RepoURL) {
+			repo.LastCommit = event.CommitHash
 		}
 	}
+	callbacks := append([]CommitCallback(nil), gw.callbacks...)
+	gw.mu.Unlock()
 
-	return nil
+	for _, callback := range callbacks {
+		if err := callback(event); err != nil {
+			klog.Errorf("Callback error for commit %s: %v", event.CommitHash, err)
+		}
+	}
 }
 
 func (gw *GitWatcher) getChangedFiles(repo *git.Repository, oldCommit, newCommit string) ([]string, error) {
@@ -234,30 +265,11 @@ func (gw *GitWatcher) getChangedFiles(repo *git.Repository, oldCommit, newCommit
 }
 
 func (gw *GitWatcher) GetRepositories() map[string]*Repository {
+	gw.mu.RLock()
+	defer gw.mu.RUnlock()
 	repos := make(map[string]*Repository)
 	for k, v := range gw.repositories {
 		repos[k] = v
 	}
 	return repos
 }
-
-func (gw *GitWatcher) AddWebhook(repoURL string, config *WebhookConfig) {
-	gw.webhooks[repoURL] = config
-	log.Printf("Added webhook for repository %s", repoURL)
-}
-
-func (gw *GitWatcher) HandleWebhook(repoURL string, payload []byte) error {
-	_, exists := gw.webhooks[repoURL]
-	if !exists {
-		return fmt.Errorf("no webhook configured for repository %s", repoURL)
-	}
-
-	// TODO: Implement webhook payload parsing for different Git providers
-	// For now, just trigger a repository check
-	repo, exists := gw.repositories[repoURL]
-	if exists {
-		return gw.checkRepository(repo)
-	}
-
-	return fmt.Errorf("repository %s not being monitored", repoURL)
-}
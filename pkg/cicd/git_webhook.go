@@ -0,0 +1,319 @@
+package cicd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// WebhookPath is where the webhook receiver is mounted
+const WebhookPath = "/webhooks/git"
+
+// maxWebhookPayload bounds the size of a webhook request body
+const maxWebhookPayload = 5 * 1024 * 1024
+
+// NormalizeRepoURL reduces the HTTPS and SSH forms of a repository URL to host/owner/repo so push
+// payloads match the URL a webhook was registered with
+func NormalizeRepoURL(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://"} {
+		url = strings.TrimPrefix(url, prefix)
+	}
+	if at := strings.Index(url, "@"); at >= 0 {
+		url = url[at+1:]
+	}
+	// git@github.com:org/repo.git, or a host with a port
+	if host, path, found := strings.Cut(url, ":"); found && !strings.Contains(host, "/") {
+		if port, rest, hasPath := strings.Cut(path, "/"); hasPath && port != "" && strings.Trim(port, "0123456789") == "" {
+			path = rest
+		}
+		url = host + "/" + path
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+}
+
+// AddWebhook registers the secret and accepted events of a repository's webhook. Repositories with
+// a webhook are no longer polled.
+func (gw *GitWatcher) AddWebhook(repoURL string, config *WebhookConfig) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	gw.webhooks[NormalizeRepoURL(repoURL)] = config
+	klog.V(1).Infof("Added webhook for repository %s", repoURL)
+}
+
+// RemoveWebhook unregisters a repository's webhook, polling resumes for it
+func (gw *GitWatcher) RemoveWebhook(repoURL string) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	delete(gw.webhooks, NormalizeRepoURL(repoURL))
+}
+
+// pushPayload is a push event parsed from a GitHub or GitLab payload
+type pushPayload struct {
+	event    string
+	repoURLs []string // every URL form the provider reports for the repository
+	commit   CommitEvent
+}
+
+// HandleWebhook authenticates a push payload against the registered webhook of its repository and
+// fires the commit callbacks. It returns a nil event for events that are accepted but ignored,
+// such as tag pushes, branch deletions or events the webhook was not registered for.
+func (gw *GitWatcher) HandleWebhook(header http.Header, payload []byte) (*CommitEvent, error) {
+	var push *pushPayload
+	var err error
+	var verify func(secret string) bool
+	switch {
+	case header.Get("X-GitHub-Event") != "":
+		push, err = parseGitHubPush(header.Get("X-GitHub-Event"), payload)
+		verify = func(secret string) bool {
+			return validGitHubSignature(secret, header.Get("X-Hub-Signature-256"), payload)
+		}
+	case header.Get("X-Gitlab-Event") != "":
+		push, err = parseGitLabPush(header.Get("X-Gitlab-Event"), payload)
+		// GitLab sends the secret token itself instead of a signature
+		verify = func(secret string) bool {
+			return subtle.ConstantTimeCompare([]byte(secret), []byte(header.Get("X-Gitlab-Token"))) == 1
+		}
+	default:
+		return nil, fmt.Errorf("unsupported webhook, expected a GitHub or GitLab push event")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gw.mu.RLock()
+	var config *WebhookConfig
+	for _, url := range push.repoURLs {
+		if config = gw.webhooks[NormalizeRepoURL(url)]; config != nil {
+			break
+		}
+	}
+	gw.mu.RUnlock()
+	if config == nil {
+		return nil, fmt.Errorf("no webhook configured for repository %s", push.repoURLs[0])
+	}
+	if !verify(config.Secret) {
+		return nil, errWebhookSignature
+	}
+	if !webhookAccepts(config, push.event) || push.commit.CommitHash == "" {
+		return nil, nil
+	}
+
+	klog.V(2).Infof("Push to %s:%s received by webhook - %s", push.commit.RepoURL, push.commit.Branch, push.commit.CommitHash)
+	event := push.commit
+	gw.dispatch(event)
+	return &event, nil
+}
+
+// errWebhookSignature is returned when a payload is not signed with the webhook secret
+var errWebhookSignature = fmt.Errorf("webhook signature does not match the configured secret")
+
+// webhookAccepts reports whether a webhook was registered for an event, all push events by default
+func webhookAccepts(config *WebhookConfig, event string) bool {
+	if len(config.Events) == 0 {
+		return event == "push"
+	}
+	for _, accepted := range config.Events {
+		if accepted == event || accepted == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// validGitHubSignature checks the X-Hub-Signature-256 header, sha256=<hex HMAC of the payload>
+func validGitHubSignature(secret, signature string, payload []byte) bool {
+	digest, found := strings.CutPrefix(signature, "sha256=")
+	if !found {
+		return false
+	}
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// branchFromRef returns the branch of refs/heads/<branch>, empty for tags and other refs
+func branchFromRef(ref string) string {
+	branch, found := strings.CutPrefix(ref, "refs/heads/")
+	if !found {
+		return ""
+	}
+	return branch
+}
+
+// deletedCommit is the "after" commit of a push deleting a branch
+const deletedCommit = "0000000000000000000000000000000000000000"
+
+func parseGitHubPush(event string, payload []byte) (*pushPayload, error) {
+	var body struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Repository struct {
+			CloneURL string `json:"clone_url"`
+			HTMLURL  string `json:"html_url"`
+			SSHURL   string `json:"ssh_url"`
+		} `json:"repository"`
+		HeadCommit *struct {
+			ID        string    `json:"id"`
+			Message   string    `json:"message"`
+			Timestamp time.Time `json:"timestamp"`
+			Author    struct {
+				Name string `json:"name"`
+			} `json:"author"`
+			Added    []string `json:"added"`
+			Removed  []string `json:"removed"`
+			Modified []string `json:"modified"`
+		} `json:"head_commit"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub payload: %w", err)
+	}
+	if body.Repository.CloneURL == "" && body.Repository.HTMLURL == "" {
+		return nil, fmt.Errorf("GitHub payload has no repository")
+	}
+	push := &pushPayload{
+		event:    event,
+		repoURLs: []string{body.Repository.CloneURL, body.Repository.HTMLURL, body.Repository.SSHURL},
+	}
+	branch := branchFromRef(body.Ref)
+	if event != "push" || branch == "" || body.After == deletedCommit {
+		return push, nil
+	}
+	push.commit = CommitEvent{RepoURL: body.Repository.CloneURL, Branch: branch, CommitHash: body.After, Timestamp: time.Now()}
+	if commit := body.HeadCommit; commit != nil {
+		push.commit.Message = commit.Message
+		push.commit.Author = commit.Author.Name
+		if !commit.Timestamp.IsZero() {
+			push.commit.Timestamp = commit.Timestamp
+		}
+		push.commit.Files = concatFiles(commit.Added, commit.Removed, commit.Modified)
+	}
+	return push, nil
+}
+
+func parseGitLabPush(event string, payload []byte) (*pushPayload, error) {
+	var body struct {
+		Ref         string `json:"ref"`
+		After       string `json:"after"`
+		CheckoutSHA string `json:"checkout_sha"`
+		Project     struct {
+			GitHTTPURL string `json:"git_http_url"`
+			GitSSHURL  string `json:"git_ssh_url"`
+			WebURL     string `json:"web_url"`
+		} `json:"project"`
+		Commits []struct {
+			ID        string    `json:"id"`
+			Message   string    `json:"message"`
+			Timestamp time.Time `json:"timestamp"`
+			Author    struct {
+				Name string `json:"name"`
+			} `json:"author"`
+			Added    []string `json:"added"`
+			Removed  []string `json:"removed"`
+			Modified []string `json:"modified"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab payload: %w", err)
+	}
+	if body.Project.GitHTTPURL == "" && body.Project.WebURL == "" {
+		return nil, fmt.Errorf("GitLab payload has no project")
+	}
+	// GitLab names events "Push Hook"; normalize to the event names used by GitHub
+	if event == "Push Hook" {
+		event = "push"
+	}
+	push := &pushPayload{
+		event:    event,
+		repoURLs: []string{body.Project.GitHTTPURL, body.Project.WebURL, body.Project.GitSSHURL},
+	}
+	branch := branchFromRef(body.Ref)
+	commitHash := body.CheckoutSHA
+	if commitHash == "" {
+		commitHash = body.After
+	}
+	if event != "push" || branch == "" || commitHash == "" || commitHash == deletedCommit {
+		return push, nil
+	}
+	push.commit = CommitEvent{RepoURL: body.Project.GitHTTPURL, Branch: branch, CommitHash: commitHash, Timestamp: time.Now()}
+	for _, commit := range body.Commits {
+		if commit.ID == commitHash {
+			push.commit.Message = commit.Message
+			push.commit.Author = commit.Author.Name
+			if !commit.Timestamp.IsZero() {
+				push.commit.Timestamp = commit.Timestamp
+			}
+		}
+		push.commit.Files = append(push.commit.Files, concatFiles(commit.Added, commit.Removed, commit.Modified)...)
+	}
+	return push, nil
+}
+
+func concatFiles(lists ...[]string) []string {
+	files := make([]string, 0)
+	for _, list := range lists {
+		files = append(files, list...)
+	}
+	return files
+}
+
+// WebhookHandler receives GitHub and GitLab push webhooks at WebhookPath
+func (gw *GitWatcher) WebhookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		// Read one byte past the limit so an oversized body is rejected rather than truncated
+		payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayload+1))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if len(payload) > maxWebhookPayload {
+			writeWebhookResponse(w, http.StatusRequestEntityTooLarge, map[string]interface{}{"error": fmt.Sprintf("request body exceeds %d bytes", maxWebhookPayload)})
+			return
+		}
+		// GitHub pings a webhook when it is created
+		if r.Header.Get("X-GitHub-Event") == "ping" {
+			writeWebhookResponse(w, http.StatusOK, map[string]interface{}{"status": "pong"})
+			return
+		}
+
+		event, err := gw.HandleWebhook(r.Header, payload)
+		switch {
+		case err == errWebhookSignature:
+			writeWebhookResponse(w, http.StatusUnauthorized, map[string]interface{}{"error": err.Error()})
+		case err != nil:
+			writeWebhookResponse(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		case event == nil:
+			writeWebhookResponse(w, http.StatusAccepted, map[string]interface{}{"status": "ignored"})
+		default:
+			writeWebhookResponse(w, http.StatusOK, map[string]interface{}{
+				"status": "triggered",
+				"repo":   event.RepoURL,
+				"branch": event.Branch,
+				"commit": event.CommitHash,
+			})
+		}
+	})
+}
+
+func writeWebhookResponse(w http.ResponseWriter, status int, body map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -1,10 +1,15 @@
 package cicd
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -15,15 +20,23 @@ import (
 	// OpenShift build APIs - using simplified approach
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/metrics"
 )
 
 type ImageBuilder struct {
 	dockerClient     *client.Client
 	kubeConfig       *rest.Config
 	kubeClient       kubernetes.Interface
+	dynamicClient    dynamic.Interface
 	defaultNamespace string
 }
 
@@ -32,13 +45,17 @@ type BuildConfig struct {
 	Namespace     string
 	SourceRepo    string
 	SourceBranch  string
+	SourceCommit  string // commit built by s2i builds, the head of SourceBranch if empty
 	Dockerfile    string
-	ContextPath   string
+	ContextPath   string // local directory holding the build context, for docker builds and language detection
+	ContextDir    string // sub-directory of SourceRepo holding the sources, for builds cloning the repository
 	ImageName     string
 	ImageTag      string
 	BuildArgs     map[string]string
 	Labels        map[string]string
-	BuildStrategy string // "docker" or "openshift"
+	BuildStrategy string    // "docker", "kubernetes" or "s2i"
+	BuilderImage  string    // s2i builder image, auto-selected from the detected language if empty
+	LogWriter     io.Writer // receives build log lines as they are streamed, optional
 }
 
 type BuildResult struct {
@@ -47,40 +64,102 @@ type BuildResult struct {
 	FullImageName string
 	BuildTime     time.Duration
 	BuildLogs     string
+	BuilderImage  string
 	Success       bool
 	Error         error
 }
 
+var buildConfigGVR = schema.GroupVersionResource{Group: "build.openshift.io", Version: "v1", Resource: "buildconfigs"}
+
+var buildGVR = schema.GroupVersionResource{Group: "build.openshift.io", Version: "v1", Resource: "builds"}
+
+// openShiftBuildTimeout bounds how long an s2i build is awaited
+const openShiftBuildTimeout = 30 * time.Minute
+
+// buildPollInterval is how often the phase of an OpenShift build is checked
+const buildPollInterval = 3 * time.Second
+
+// s2iBuilderImages maps detected languages to the builder image streams shipped in the openshift namespace
+var s2iBuilderImages = map[string]string{
+	"nodejs": "nodejs:latest",
+	"python": "python:latest",
+	"java":   "java:latest",
+	"golang": "golang:latest",
+	"ruby":   "ruby:latest",
+	"php":    "php:latest",
+	"dotnet": "dotnet:latest",
+}
+
+// UBIBuilderImages are the UBI 9 language images by detected language. s2i builds use them when the
+// cluster has no image stream for the language in the openshift namespace, and the UBI validator
+// suggests them for the matching base images.
+var UBIBuilderImages = map[string]string{
+	"nodejs": "registry.access.redhat.com/ubi9/nodejs-20:latest",
+	"python": "registry.access.redhat.com/ubi9/python-311:latest",
+	"java":   "registry.access.redhat.com/ubi9/openjdk-17:latest",
+	"golang": "registry.access.redhat.com/ubi9/go-toolset:latest",
+	"ruby":   "registry.access.redhat.com/ubi9/ruby-33:latest",
+	"php":    "registry.access.redhat.com/ubi9/php-82:latest",
+	"dotnet": "registry.access.redhat.com/ubi9/dotnet-80:latest",
+}
+
+// s2iLanguageMarkers lists the files that identify a language, checked in order
+var s2iLanguageMarkers = []struct {
+	file     string
+	language string
+}{
+	{"package.json", "nodejs"},
+	{"requirements.txt", "python"},
+	{"setup.py", "python"},
+	{"pyproject.toml", "python"},
+	{"pom.xml", "java"},
+	{"build.gradle", "java"},
+	{"go.mod", "golang"},
+	{"Gemfile", "ruby"},
+	{"composer.json", "php"},
+}
+
 func NewImageBuilder(kubeConfig *rest.Config, defaultNamespace string) (*ImageBuilder, error) {
 	// Initialize Docker client
 	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
-		log.Printf("Warning: Failed to initialize Docker client: %v", err)
+		klog.Warningf("Failed to initialize Docker client: %v", err)
 	}
 
 	var kubeClient kubernetes.Interface
+	var dynamicClient dynamic.Interface
 	if kubeConfig != nil {
 		// Initialize Kubernetes client
 		kubeClient, err = kubernetes.NewForConfig(kubeConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 		}
+		// OpenShift build APIs are accessed dynamically
+		dynamicClient, err = dynamic.NewForConfig(kubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+		}
 	}
 
 	return &ImageBuilder{
 		dockerClient:     dockerClient,
 		kubeConfig:       kubeConfig,
 		kubeClient:       kubeClient,
+		dynamicClient:    dynamicClient,
 		defaultNamespace: defaultNamespace,
 	}, nil
 }
 
-func (ib *ImageBuilder) BuildImage(ctx context.Context, config BuildConfig) (*BuildResult, error) {
+func (ib *ImageBuilder) BuildImage(ctx context.Context, config BuildConfig) (result *BuildResult, err error) {
 	startTime := time.Now()
+	done := metrics.StartOperation(metrics.OperationBuild)
+	defer func() { done(err == nil && result != nil && result.Success) }()
 
 	switch config.BuildStrategy {
 	case "kubernetes":
 		return ib.buildWithKubernetes(ctx, config, startTime)
+	case "s2i":
+		return ib.buildWithS2I(ctx, config, startTime)
 	case "docker":
 		fallthrough
 	default:
@@ -131,10 +210,10 @@ func (ib *ImageBuilder) buildWithDocker(ctx context.Context, config BuildConfig,
 	}
 	defer response.Body.Close()
 
-	// Read build logs
-	buildLogs, err := io.ReadAll(response.Body)
+	// Read build logs, copying the output of every step to the log writer as it is written
+	buildLogs, err := streamDockerBuildLogs(response.Body, config.LogWriter)
 	if err != nil {
-		log.Printf("Warning: Failed to read build logs: %v", err)
+		klog.Warningf("Failed to read build logs: %v", err)
 	}
 
 	return &BuildResult{
@@ -142,12 +221,42 @@ func (ib *ImageBuilder) buildWithDocker(ctx context.Context, config BuildConfig,
 		ImageTag:      config.ImageTag,
 		FullImageName: fmt.Sprintf("%s:%s", config.ImageName, config.ImageTag),
 		BuildTime:     time.Since(startTime),
-		BuildLogs:     string(buildLogs),
+		BuildLogs:     buildLogs,
 		Success:       true,
 		Error:         nil,
 	}, nil
 }
 
+// streamDockerBuildLogs reads the JSON message stream of a Docker build and returns it whole,
+// writing the build output and errors it reports to logWriter line by line
+func streamDockerBuildLogs(body io.Reader, logWriter io.Writer) (string, error) {
+	var logs strings.Builder
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		logs.Write(line)
+		logs.WriteByte('\n')
+		if logWriter == nil {
+			continue
+		}
+		var message struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+		}
+		if json.Unmarshal(line, &message) != nil {
+			continue
+		}
+		if message.Error != "" {
+			message.Stream = message.Error + "\n"
+		}
+		if message.Stream != "" {
+			_, _ = io.WriteString(logWriter, message.Stream)
+		}
+	}
+	return logs.String(), scanner.Err()
+}
+
 func (ib *ImageBuilder) buildWithKubernetes(ctx context.Context, config BuildConfig, startTime time.Time) (*BuildResult, error) {
 	if ib.kubeClient == nil {
 		return nil, fmt.Errorf("Kubernetes client not available")
@@ -178,7 +287,7 @@ func (ib *ImageBuilder) buildWithKubernetes(ctx context.Context, config BuildCon
 								"/bin/sh",
 								"-c",
 								fmt.Sprintf("git clone %s /workspace && cd /workspace && docker build -t %s:%s -f %s %s",
-									config.SourceRepo, config.ImageName, config.ImageTag, config.Dockerfile, config.ContextPath),
+									config.SourceRepo, config.ImageName, config.ImageTag, config.Dockerfile, path.Join(".", config.ContextDir)),
 							},
 							SecurityContext: &corev1.SecurityContext{
 								Privileged: &[]bool{true}[0], // Required for Docker-in-Docker
@@ -230,6 +339,193 @@ func (ib *ImageBuilder) buildWithKubernetes(ctx context.Context, config BuildCon
 	}, nil
 }
 
+// buildWithS2I creates an OpenShift BuildConfig with a Source-to-Image strategy and starts a build,
+// so applications without a Dockerfile can be built
+func (ib *ImageBuilder) buildWithS2I(ctx context.Context, config BuildConfig, startTime time.Time) (*BuildResult, error) {
+	if ib.dynamicClient == nil {
+		return nil, fmt.Errorf("Kubernetes client not available")
+	}
+
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = ib.defaultNamespace
+	}
+
+	builderImage := config.BuilderImage
+	if builderImage == "" {
+		language := detectLanguage(config.ContextPath, config.SourceRepo)
+		image, ok := s2iBuilderImages[language]
+		if !ok {
+			return &BuildResult{
+				Success:   false,
+				Error:     fmt.Errorf("could not detect application language for s2i build, specify a builder image"),
+				BuildTime: time.Since(startTime),
+			}, nil
+		}
+		builderImage = ib.availableBuilderImage(ctx, language, image)
+	}
+
+	sourceBranch := config.SourceBranch
+	if sourceBranch == "" {
+		sourceBranch = "main"
+	}
+	gitSource := map[string]interface{}{"uri": config.SourceRepo, "ref": sourceBranch}
+	source := map[string]interface{}{"type": "Git", "git": gitSource}
+	if contextDir := path.Clean(config.ContextDir); contextDir != "." && contextDir != "/" {
+		source["contextDir"] = strings.TrimPrefix(contextDir, "/")
+	}
+
+	env := make([]interface{}, 0, len(config.BuildArgs))
+	for k, v := range config.BuildArgs {
+		env = append(env, map[string]interface{}{"name": k, "value": v})
+	}
+
+	labels := make(map[string]interface{}, len(config.Labels))
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+
+	buildConfig := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "build.openshift.io/v1",
+		"kind":       "BuildConfig",
+		"metadata": map[string]interface{}{
+			"name":      config.Name,
+			"namespace": namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"source": source,
+			"strategy": map[string]interface{}{
+				"type": "Source",
+				"sourceStrategy": map[string]interface{}{
+					"from": s2iBuilderReference(builderImage),
+					"env":  env,
+				},
+			},
+			"output": map[string]interface{}{
+				"to": map[string]interface{}{
+					"kind": "DockerImage",
+					"name": fmt.Sprintf("%s:%s", config.ImageName, config.ImageTag),
+				},
+			},
+		},
+	}}
+
+	buildConfigs := ib.dynamicClient.Resource(buildConfigGVR).Namespace(namespace)
+	existing, err := buildConfigs.Get(ctx, config.Name, metav1.GetOptions{})
+	if err == nil {
+		buildConfig.SetResourceVersion(existing.GetResourceVersion())
+		_, err = buildConfigs.Update(ctx, buildConfig, metav1.UpdateOptions{})
+	} else if apierrors.IsNotFound(err) {
+		_, err = buildConfigs.Create(ctx, buildConfig, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return &BuildResult{
+			BuilderImage: builderImage,
+			Success:      false,
+			Error:        fmt.Errorf("failed to create s2i build config: %w", err),
+			BuildTime:    time.Since(startTime),
+		}, nil
+	}
+
+	// Start a build from the BuildConfig
+	buildRequest := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "build.openshift.io/v1",
+		"kind":       "BuildRequest",
+		"metadata":   map[string]interface{}{"name": config.Name},
+	}}
+	if config.SourceCommit != "" {
+		buildRequest.Object["revision"] = map[string]interface{}{"type": "Git", "git": map[string]interface{}{"commit": config.SourceCommit}}
+	}
+	build, err := buildConfigs.Create(ctx, buildRequest, metav1.CreateOptions{}, "instantiate")
+	if err != nil {
+		return &BuildResult{
+			BuilderImage: builderImage,
+			Success:      false,
+			Error:        fmt.Errorf("failed to start s2i build: %w", err),
+			BuildTime:    time.Since(startTime),
+		}, nil
+	}
+
+	buildLogs, err := ib.waitForBuildCompletion(ctx, namespace, build.GetName(), config.LogWriter)
+	if err != nil {
+		return &BuildResult{
+			BuilderImage: builderImage,
+			BuildLogs:    buildLogs,
+			Success:      false,
+			Error:        fmt.Errorf("s2i build failed: %w", err),
+			BuildTime:    time.Since(startTime),
+		}, nil
+	}
+
+	return &BuildResult{
+		ImageName:     config.ImageName,
+		ImageTag:      config.ImageTag,
+		FullImageName: fmt.Sprintf("%s:%s", config.ImageName, config.ImageTag),
+		BuildTime:     time.Since(startTime),
+		BuildLogs:     buildLogs,
+		BuilderImage:  builderImage,
+		Success:       true,
+		Error:         nil,
+	}, nil
+}
+
+// availableBuilderImage returns the builder image stream of a language when the openshift namespace
+// has it, the UBI builder image of the language otherwise
+func (ib *ImageBuilder) availableBuilderImage(ctx context.Context, language, imageStreamTag string) string {
+	ubiImage, hasUBI := UBIBuilderImages[language]
+	if !hasUBI {
+		return imageStreamTag
+	}
+	name, _, _ := strings.Cut(imageStreamTag, ":")
+	_, err := ib.dynamicClient.Resource(imageStreamGVR).Namespace("openshift").Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		klog.V(1).Infof("No %s image stream in the openshift namespace, building with %s", name, ubiImage)
+		return ubiImage
+	}
+	return imageStreamTag
+}
+
+// s2iBuilderReference returns the sourceStrategy "from" reference for a builder image.
+// Plain "name:tag" values refer to image streams in the openshift namespace.
+func s2iBuilderReference(builderImage string) map[string]interface{} {
+	if strings.Contains(builderImage, "/") {
+		return map[string]interface{}{"kind": "DockerImage", "name": builderImage}
+	}
+	return map[string]interface{}{"kind": "ImageStreamTag", "namespace": "openshift", "name": builderImage}
+}
+
+// detectLanguage identifies the application language from marker files in the build context,
+// falling back to hints in the repository name
+func detectLanguage(contextPath, sourceRepo string) string {
+	if contextPath != "" {
+		for _, marker := range s2iLanguageMarkers {
+			if _, err := os.Stat(filepath.Join(contextPath, marker.file)); err == nil {
+				return marker.language
+			}
+		}
+	}
+
+	repo := strings.ToLower(sourceRepo)
+	switch {
+	case strings.Contains(repo, "node") || strings.Contains(repo, "express") || strings.Contains(repo, "react"):
+		return "nodejs"
+	case strings.Contains(repo, "python") || strings.Contains(repo, "django") || strings.Contains(repo, "flask"):
+		return "python"
+	case strings.Contains(repo, "java") || strings.Contains(repo, "spring") || strings.Contains(repo, "quarkus"):
+		return "java"
+	case strings.Contains(repo, "golang") || strings.Contains(repo, "-go"):
+		return "golang"
+	case strings.Contains(repo, "ruby") || strings.Contains(repo, "rails"):
+		return "ruby"
+	case strings.Contains(repo, "php") || strings.Contains(repo, "laravel"):
+		return "php"
+	case strings.Contains(repo, "dotnet") || strings.Contains(repo, "aspnet"):
+		return "dotnet"
+	}
+	return ""
+}
+
 func (ib *ImageBuilder) createBuildContext(contextPath, dockerfile string) (io.ReadCloser, error) {
 	// If contextPath is empty, use current directory
 	if contextPath == "" {
@@ -243,36 +539,91 @@ func (ib *ImageBuilder) createBuildContext(contextPath, dockerfile string) (io.R
 	})
 }
 
-func (ib *ImageBuilder) waitForBuildCompletion(ctx context.Context, namespace, buildName string) (string, error) {
-	// Wait for Kubernetes job completion (simplified version)
-	for i := 0; i < 60; i++ { // Wait up to 5 minutes
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		case <-time.After(5 * time.Second):
-			job, err := ib.kubeClient.BatchV1().Jobs(namespace).Get(ctx, buildName, metav1.GetOptions{})
-			if err != nil {
-				return "", fmt.Errorf("failed to get job status: %w", err)
+// waitForBuildCompletion waits for an OpenShift build to end and returns its log. The log is
+// followed while the build runs, and fetched at once when the build ended before it could be.
+func (ib *ImageBuilder) waitForBuildCompletion(ctx context.Context, namespace, buildName string, logWriter io.Writer) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, openShiftBuildTimeout)
+	defer cancel()
+
+	builds := ib.dynamicClient.Resource(buildGVR).Namespace(namespace)
+	var logs strings.Builder
+	followed, streamed := false, false
+	for {
+		build, err := builds.Get(ctx, buildName, metav1.GetOptions{})
+		if err != nil {
+			return logs.String(), fmt.Errorf("failed to get build status: %w", err)
+		}
+		phase, _, _ := unstructured.NestedString(build.Object, "status", "phase")
+		switch phase {
+		case "Complete", "Failed", "Error", "Cancelled":
+			if !streamed {
+				buildLogs, err := ib.getBuildLogs(ctx, namespace, buildName)
+				if err != nil {
+					klog.Warningf("Failed to get logs of build %s: %v", buildName, err)
+				}
+				logs.Reset()
+				logs.WriteString(buildLogs)
 			}
-
-			// Check job completion
-			if job.Status.Succeeded > 0 {
-				logs, err := ib.getBuildLogs(ctx, namespace, buildName)
-				return logs, err
+			if phase == "Complete" {
+				return logs.String(), nil
+			}
+			message, _, _ := unstructured.NestedString(build.Object, "status", "message")
+			if message == "" {
+				message, _, _ = unstructured.NestedString(build.Object, "status", "reason")
 			}
-			if job.Status.Failed > 0 {
-				logs, _ := ib.getBuildLogs(ctx, namespace, buildName)
-				return logs, fmt.Errorf("build job failed")
+			return logs.String(), fmt.Errorf("build %s %s: %s", buildName, strings.ToLower(phase), message)
+		case "Running":
+			if !followed {
+				followed = true
+				if err := ib.streamBuildLogs(ctx, namespace, buildName, &logs, logWriter); err != nil {
+					klog.Warningf("Failed to stream logs of build %s: %v", buildName, err)
+				} else {
+					streamed = true
+				}
 			}
 		}
+
+		select {
+		case <-ctx.Done():
+			return logs.String(), fmt.Errorf("build %s did not complete: %w", buildName, ctx.Err())
+		case <-time.After(buildPollInterval):
+		}
 	}
-	return "", fmt.Errorf("build timeout")
 }
 
+// buildLogRequest returns a request for the log subresource of an OpenShift build
+func (ib *ImageBuilder) buildLogRequest(namespace, buildName string) *rest.Request {
+	return ib.kubeClient.Discovery().RESTClient().Get().
+		AbsPath("/apis", buildGVR.Group, buildGVR.Version, "namespaces", namespace, buildGVR.Resource, buildName, "log")
+}
+
+// streamBuildLogs follows the log of a running build until the build pod exits, copying every line
+// to logs and to logWriter as it is written
+func (ib *ImageBuilder) streamBuildLogs(ctx context.Context, namespace, buildName string, logs *strings.Builder, logWriter io.Writer) error {
+	stream, err := ib.buildLogRequest(namespace, buildName).Param("follow", "true").Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close() }()
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text() + "\n"
+		logs.WriteString(line)
+		if logWriter != nil {
+			_, _ = io.WriteString(logWriter, line)
+		}
+	}
+	return scanner.Err()
+}
+
+// getBuildLogs returns the log an OpenShift build has written so far
 func (ib *ImageBuilder) getBuildLogs(ctx context.Context, namespace, buildName string) (string, error) {
-	// This would need to use the OpenShift build logs API
-	// For now, return a placeholder
-	return fmt.Sprintf("Build logs for %s in namespace %s", buildName, namespace), nil
+	data, err := ib.buildLogRequest(namespace, buildName).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get build logs: %w", err)
+	}
+	return string(data), nil
 }
 
 func (ib *ImageBuilder) ListImages(ctx context.Context, namespace string) ([]string, error) {
@@ -0,0 +1,607 @@
+package cicd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// registryAPIPageSize is the page size requested from paginated registry endpoints
+const registryAPIPageSize = 100
+
+// registryAPIMaxPages bounds how many pages a single listing follows
+const registryAPIMaxPages = 50
+
+// dockerHubAPI is the Docker Hub API, Docker Hub does not implement the v2 catalog endpoint
+const dockerHubAPI = "https://hub.docker.com/v2"
+
+// dockerHubRegistry serves the Registry v2 API of Docker Hub
+const dockerHubRegistry = "https://registry-1.docker.io"
+
+var (
+	registryChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	registryNextLink       = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+)
+
+// RegistryClient calls the Docker Registry HTTP API v2, answering Basic and Bearer auth challenges
+// with its credentials
+type RegistryClient struct {
+	Host       string
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+	baseURL    string
+
+	mu     sync.Mutex
+	tokens map[string]string // scope to bearer token
+}
+
+// NewRegistryClient returns a client for a registry host or URL. URLs with the http scheme are
+// called without TLS.
+func NewRegistryClient(registry, username, password string) *RegistryClient {
+	host := strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	c := &RegistryClient{
+		Host:       host,
+		Username:   username,
+		Password:   password,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		tokens:     make(map[string]string),
+	}
+	switch {
+	case c.isDockerHub():
+		c.baseURL = dockerHubRegistry
+	case strings.HasPrefix(registry, "http://"):
+		c.baseURL = "http://" + host
+	default:
+		c.baseURL = "https://" + host
+	}
+	return c
+}
+
+// isDockerHub reports whether the client calls Docker Hub, which serves the registry API from a
+// different host and has no catalog endpoint
+func (c *RegistryClient) isDockerHub() bool {
+	return c.Host == "docker.io" || c.Host == "index.docker.io" || c.Host == "registry-1.docker.io"
+}
+
+// do sends a request to a /v2/ path, authenticating with the token for scope when challenged
+func (c *RegistryClient) do(ctx context.Context, method, path, scope string, header http.Header) (*http.Response, error) {
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return c.HTTPClient.Do(req)
+	}
+
+	c.mu.Lock()
+	token := c.tokens[scope]
+	c.mu.Unlock()
+	authorization := ""
+	if token != "" {
+		authorization = "Bearer " + token
+	}
+	resp, err := send(authorization)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_ = resp.Body.Close()
+	authorization, err = c.authorize(ctx, challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+	return send(authorization)
+}
+
+// Login verifies the client credentials and returns how they were checked. Docker Hub credentials
+// are exchanged for a JWT; other registries are challenged on /v2/ and the token (or Basic
+// credentials) must then be accepted with a 200.
+func (c *RegistryClient) Login(ctx context.Context) (string, error) {
+	if c.isDockerHub() {
+		body, _ := json.Marshal(map[string]string{"username": c.Username, "password": c.Password})
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, dockerHubAPI+"/users/login", strings.NewReader(string(body)))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("request to %s failed: %v", dockerHubAPI+"/users/login", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("docker hub rejected the credentials for %s (%s)", c.Username, resp.Status)
+		}
+		return "docker hub jwt", nil
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, "/v2/", "", nil)
+	if err != nil {
+		return "", fmt.Errorf("login to %s failed: %v", c.Host, err)
+	}
+	_ = resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("registry %s rejected the credentials for %s (%s)", c.Host, c.Username, resp.Status)
+	default:
+		return "", &RegistryError{Status: resp.StatusCode, host: c.Host, path: "/v2/"}
+	}
+	scheme, _, _ := strings.Cut(resp.Request.Header.Get("Authorization"), " ")
+	switch scheme {
+	case "Bearer":
+		return "bearer token", nil
+	case "Basic":
+		return "basic", nil
+	}
+	return "anonymous", nil
+}
+
+// authorize answers a WWW-Authenticate challenge with Basic credentials or a Bearer token
+func (c *RegistryClient) authorize(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.Username == "" {
+			return "", fmt.Errorf("registry %s requires authentication, configure credentials with 'registry_configure'", c.Host)
+		}
+		req, _ := http.NewRequest(http.MethodGet, c.baseURL, nil)
+		req.SetBasicAuth(c.Username, c.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("registry %s returned an unsupported auth challenge '%s'", c.Host, challenge)
+	}
+
+	values := make(map[string]string)
+	for _, match := range registryChallengeParam.FindAllStringSubmatch(params, -1) {
+		values[match[1]] = match[2]
+	}
+	if values["realm"] == "" {
+		return "", fmt.Errorf("registry %s returned a bearer challenge without realm", c.Host)
+	}
+	query := url.Values{}
+	if values["service"] != "" {
+		query.Set("service", values["service"])
+	}
+	if scope == "" {
+		scope = values["scope"]
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, values["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm '%s': %v", values["realm"], err)
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token from %s: %v", values["realm"], err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request to %s failed with status %s, check the registry credentials", values["realm"], resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %v", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	c.mu.Lock()
+	c.tokens[scope] = token.Token
+	c.mu.Unlock()
+	return "Bearer " + token.Token, nil
+}
+
+// getJSON fetches a /v2/ path into v and returns the next page path from the Link header
+func (c *RegistryClient) getJSON(ctx context.Context, path, scope string, v interface{}) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, scope, nil)
+	if err != nil {
+		return "", fmt.Errorf("request to %s failed: %v", c.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", &RegistryError{Status: resp.StatusCode, host: c.Host, path: path, body: strings.TrimSpace(string(body))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("failed to parse response from %s: %v", c.Host, err)
+	}
+	return nextLink(resp.Header.Get("Link")), nil
+}
+
+// RegistryError is a non-success response from a registry
+type RegistryError struct {
+	Status int
+	host   string
+	path   string
+	body   string
+}
+
+func (e *RegistryError) Error() string {
+	msg := fmt.Sprintf("registry %s returned %d %s for %s", e.host, e.Status, http.StatusText(e.Status), e.path)
+	if e.body != "" {
+		msg += ": " + e.body
+	}
+	return msg
+}
+
+// nextLink returns the path of the next page from a Link header, if any
+func nextLink(link string) string {
+	match := registryNextLink.FindStringSubmatch(link)
+	if match == nil {
+		return ""
+	}
+	next, err := url.Parse(match[1])
+	if err != nil {
+		return ""
+	}
+	return next.RequestURI()
+}
+
+// Catalog lists repositories through /v2/_catalog, following pagination until limit repositories
+// matched keep or the registry has no more pages
+func (c *RegistryClient) Catalog(ctx context.Context, limit int, keep func(string) bool) ([]string, bool, error) {
+	if c.isDockerHub() {
+		return nil, false, fmt.Errorf("docker.io does not support catalog listing, use 'registry_search' to find Docker Hub repositories")
+	}
+	repositories := make([]string, 0)
+	pageSize := registryAPIPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+	path := fmt.Sprintf("/v2/_catalog?n=%d", pageSize)
+	for page := 0; path != "" && page < registryAPIMaxPages; page++ {
+		var body struct {
+			Repositories []string `json:"repositories"`
+		}
+		next, err := c.getJSON(ctx, path, "registry:catalog:*", &body)
+		if err != nil {
+			var regErr *RegistryError
+			if errors.As(err, &regErr) && (regErr.Status == http.StatusNotFound || regErr.Status == http.StatusUnauthorized || regErr.Status == http.StatusForbidden) {
+				return nil, false, fmt.Errorf("registry %s does not allow catalog listing (%d %s); list tags of a known repository with 'registry_tags' instead", c.Host, regErr.Status, http.StatusText(regErr.Status))
+			}
+			return nil, false, err
+		}
+		for _, repository := range body.Repositories {
+			if keep(repository) {
+				repositories = append(repositories, repository)
+				if limit > 0 && len(repositories) >= limit {
+					return repositories, true, nil
+				}
+			}
+		}
+		path = next
+	}
+	return repositories, path != "", nil
+}
+
+// manifestAccept lists the manifest media types the client understands, single-image manifests first
+var manifestAccept = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// repositoryScope is the token scope for an action on a repository
+func repositoryScope(name, actions string) string {
+	return "repository:" + name + ":" + actions
+}
+
+// Tags lists the tags of a repository through /v2/<name>/tags/list, following pagination
+func (c *RegistryClient) Tags(ctx context.Context, name string) ([]string, error) {
+	tags := make([]string, 0)
+	path := fmt.Sprintf("/v2/%s/tags/list?n=%d", name, registryAPIPageSize)
+	for page := 0; path != "" && page < registryAPIMaxPages; page++ {
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		next, err := c.getJSON(ctx, path, repositoryScope(name, "pull"), &body)
+		if err != nil {
+			var regErr *RegistryError
+			if errors.As(err, &regErr) && regErr.Status == http.StatusNotFound {
+				return nil, fmt.Errorf("repository %s not found on %s", name, c.Host)
+			}
+			return nil, err
+		}
+		tags = append(tags, body.Tags...)
+		path = next
+	}
+	return tags, nil
+}
+
+// Repositories lists every repository through /v2/_catalog, or on Docker Hub the repositories
+// owned by the client user
+func (c *RegistryClient) Repositories(ctx context.Context) ([]string, error) {
+	if c.isDockerHub() {
+		return c.dockerHubRepositories(ctx)
+	}
+	repositories, _, err := c.Catalog(ctx, 0, func(string) bool { return true })
+	return repositories, err
+}
+
+// TagMetadata describes the image a tag points to
+type TagMetadata struct {
+	Digest    string    `json:"digest"`
+	SizeBytes int64     `json:"size_bytes"`
+	Created   time.Time `json:"created"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+}
+
+// TagMetadata reads the manifest of a tag and its config blob. For multi-arch images the
+// linux/amd64 image, or the first listed one, is described.
+func (c *RegistryClient) TagMetadata(ctx context.Context, name, tag string) (*TagMetadata, error) {
+	manifest, digest, err := c.Manifest(ctx, name, tag)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &TagMetadata{Digest: digest}
+	if len(manifest.Manifests) > 0 {
+		selected := manifest.Manifests[0]
+		for _, m := range manifest.Manifests {
+			if m.Platform.OS == "linux" && m.Platform.Architecture == "amd64" {
+				selected = m
+				break
+			}
+		}
+		if manifest, _, err = c.Manifest(ctx, name, selected.Digest); err != nil {
+			return nil, err
+		}
+	}
+	metadata.SizeBytes = manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		metadata.SizeBytes += layer.Size
+	}
+	if manifest.Config.Digest != "" {
+		config, err := c.ImageConfig(ctx, name, manifest.Config.Digest)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Created, metadata.OS, metadata.Arch = config.Created, config.OS, config.Architecture
+	}
+	return metadata, nil
+}
+
+// RegistryImageConfig is the subset of an image config blob read by the client
+type RegistryImageConfig struct {
+	Created      time.Time `json:"created"`
+	OS           string    `json:"os"`
+	Architecture string    `json:"architecture"`
+	Config       struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// ImageConfig fetches the config blob of an image manifest
+func (c *RegistryClient) ImageConfig(ctx context.Context, name, digest string) (*RegistryImageConfig, error) {
+	config := &RegistryImageConfig{}
+	if _, err := c.getJSON(ctx, "/v2/"+name+"/blobs/"+digest, repositoryScope(name, "pull"), config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// RegistryManifest is the subset of an image manifest or index read by the client
+type RegistryManifest struct {
+	MediaType   string            `json:"mediaType"`
+	Annotations map[string]string `json:"annotations"`
+	Config      struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+		Size      int64  `json:"size"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+	Manifests []struct {
+		MediaType   string            `json:"mediaType"`
+		Digest      string            `json:"digest"`
+		Size        int64             `json:"size"`
+		Annotations map[string]string `json:"annotations"`
+		Platform    struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// Manifest fetches the manifest for a tag or digest and returns it with its content digest
+func (c *RegistryClient) Manifest(ctx context.Context, name, reference string) (*RegistryManifest, string, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/v2/"+name+"/manifests/"+reference, repositoryScope(name, "pull"), http.Header{"Accept": manifestAccept})
+	if err != nil {
+		return nil, "", fmt.Errorf("request to %s failed: %v", c.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, "", &RegistryError{Status: resp.StatusCode, host: c.Host, path: "/v2/" + name + "/manifests/" + reference, body: strings.TrimSpace(string(body))}
+	}
+	manifest := &RegistryManifest{}
+	if err := json.NewDecoder(resp.Body).Decode(manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to parse manifest of %s:%s: %v", name, reference, err)
+	}
+	// Docker schema 2 manifests may leave the media type to the Content-Type header
+	if manifest.MediaType == "" {
+		manifest.MediaType, _, _ = strings.Cut(resp.Header.Get("Content-Type"), ";")
+	}
+	return manifest, resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// ErrDeleteUnsupported is returned when a registry does not allow deleting manifests
+var ErrDeleteUnsupported = errors.New("registry does not support deleting manifests")
+
+// DeleteTag resolves the digest a tag points to and deletes that manifest, which removes every
+// tag pointing at the same digest
+func (c *RegistryClient) DeleteTag(ctx context.Context, name, tag string) (string, error) {
+	scope := repositoryScope(name, "pull,push,delete")
+	resp, err := c.do(ctx, http.MethodHead, "/v2/"+name+"/manifests/"+tag, scope, http.Header{"Accept": manifestAccept})
+	if err != nil {
+		return "", fmt.Errorf("request to %s failed: %v", c.Host, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("tag %s not found in %s/%s", tag, c.Host, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &RegistryError{Status: resp.StatusCode, host: c.Host, path: "/v2/" + name + "/manifests/" + tag}
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return the digest of %s:%s", c.Host, name, tag)
+	}
+
+	resp, err = c.do(ctx, http.MethodDelete, "/v2/"+name+"/manifests/"+digest, scope, nil)
+	if err != nil {
+		return digest, fmt.Errorf("request to %s failed: %v", c.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK, http.StatusNoContent:
+		return digest, nil
+	case http.StatusMethodNotAllowed:
+		return digest, ErrDeleteUnsupported
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return digest, &RegistryError{Status: resp.StatusCode, host: c.Host, path: "/v2/" + name + "/manifests/" + digest, body: strings.TrimSpace(string(body))}
+	}
+}
+
+// RegistryProbe is the result of an unauthenticated request to a registry's /v2/ endpoint
+type RegistryProbe struct {
+	Reachable  bool   `json:"reachable"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	Auth       string `json:"auth"` // anonymous, required or unknown
+	LatencyMs  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Probe times a GET of /v2/ without credentials. 200 means anonymous access is allowed and 401
+// that the registry is reachable but needs a login; anything else is reported as a problem.
+func (c *RegistryClient) Probe(ctx context.Context, timeout time.Duration) *RegistryProbe {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result := &RegistryProbe{Auth: "unknown"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v2/", nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			result.Error = fmt.Sprintf("no response within %s", timeout)
+		} else {
+			result.Error = err.Error()
+		}
+		return result
+	}
+	_ = resp.Body.Close()
+	result.HTTPStatus = resp.StatusCode
+	switch resp.StatusCode {
+	case http.StatusOK:
+		result.Reachable, result.Auth = true, "anonymous"
+	case http.StatusUnauthorized:
+		result.Reachable, result.Auth = true, "required"
+	default:
+		result.Error = fmt.Sprintf("unexpected response %s, %s may not be a Registry v2 endpoint", resp.Status, c.baseURL)
+	}
+	return result
+}
+
+// dockerHubRepositories lists the repositories of the Docker Hub user of the client through the Docker
+// Hub API, logging in first so private repositories are included
+func (c *RegistryClient) dockerHubRepositories(ctx context.Context) ([]string, error) {
+	if c.Username == "" {
+		return nil, fmt.Errorf("listing Docker Hub repositories requires a username")
+	}
+	authorization := ""
+	if c.Password != "" {
+		body, _ := json.Marshal(map[string]string{"username": c.Username, "password": c.Password})
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, dockerHubAPI+"/users/login", strings.NewReader(string(body)))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("docker hub login failed: %v", err)
+		}
+		var login struct {
+			Token string `json:"token"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&login)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || err != nil {
+			return nil, fmt.Errorf("docker hub rejected the credentials for %s (%s)", c.Username, resp.Status)
+		}
+		authorization = "JWT " + login.Token
+	}
+
+	repositories := make([]string, 0)
+	next := fmt.Sprintf("%s/repositories/%s/?page_size=%d", dockerHubAPI, url.PathEscape(c.Username), registryAPIPageSize)
+	for page := 0; next != "" && page < registryAPIMaxPages; page++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request to docker hub failed: %v", err)
+		}
+		var body struct {
+			Next    string `json:"next"`
+			Results []struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"results"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("docker hub returned %s listing the repositories of %s", resp.Status, c.Username)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse docker hub response: %v", err)
+		}
+		for _, result := range body.Results {
+			repositories = append(repositories, result.Namespace+"/"+result.Name)
+		}
+		next = body.Next
+	}
+	return repositories, nil
+}
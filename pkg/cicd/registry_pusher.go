@@ -6,13 +6,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
 	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/metrics"
 )
 
 type RegistryPusher struct {
@@ -55,7 +58,7 @@ func NewRegistryPusher(kubeConfig *rest.Config) (*RegistryPusher, error) {
 	// Initialize Docker client
 	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
-		log.Printf("Warning: Failed to initialize Docker client: %v", err)
+		klog.Warningf("Failed to initialize Docker client: %v", err)
 	}
 
 	return &RegistryPusher{
@@ -67,16 +70,18 @@ func NewRegistryPusher(kubeConfig *rest.Config) (*RegistryPusher, error) {
 
 func (rp *RegistryPusher) AddRegistry(name string, config *RegistryConfig) {
 	rp.registries[name] = config
-	log.Printf("Added registry configuration for %s (%s)", name, config.URL)
+	klog.V(1).Infof("Added registry configuration for %s (%s)", name, config.URL)
 }
 
 func (rp *RegistryPusher) RemoveRegistry(name string) {
 	delete(rp.registries, name)
-	log.Printf("Removed registry configuration for %s", name)
+	klog.V(1).Infof("Removed registry configuration for %s", name)
 }
 
-func (rp *RegistryPusher) PushImage(ctx context.Context, config PushConfig) (*PushResult, error) {
+func (rp *RegistryPusher) PushImage(ctx context.Context, config PushConfig) (result *PushResult, err error) {
 	startTime := time.Now()
+	done := metrics.StartOperation(metrics.OperationPush)
+	defer func() { done(err == nil && result != nil && result.Success) }()
 
 	// Determine registry configuration
 	var registryConfig *RegistryConfig
@@ -131,6 +136,18 @@ func (rp *RegistryPusher) pushWithDocker(ctx context.Context, config PushConfig,
 			Email:    registryConfig.Email,
 		}
 	}
+	// ECR only accepts short-lived tokens obtained with the AWS credentials
+	if authConfig.Password == "" && IsECRRegistry(targetImage) {
+		username, password, err := ECRCredentials(ctx, targetImage)
+		if err != nil {
+			return &PushResult{
+				Success:   false,
+				Error:     fmt.Errorf("failed to authenticate with ECR: %w", err),
+				PushTime:  time.Since(startTime),
+			}, nil
+		}
+		authConfig.Username, authConfig.Password = username, password
+	}
 
 	authConfigBytes, err := json.Marshal(authConfig)
 	if err != nil {
@@ -159,7 +176,7 @@ func (rp *RegistryPusher) pushWithDocker(ctx context.Context, config PushConfig,
 	// Read push logs
 	pushLogs, err := io.ReadAll(pushResponse)
 	if err != nil {
-		log.Printf("Warning: Failed to read push logs: %v", err)
+		klog.Warningf("Failed to read push logs: %v", err)
 	}
 
 	return &PushResult{
@@ -181,10 +198,12 @@ func (rp *RegistryPusher) ListRepositories(ctx context.Context, registryName str
 		return nil, fmt.Errorf("registry configuration not found: %s", registryName)
 	}
 
-	// This would require implementing registry API calls for different registries
-	// For now, return a placeholder
-	log.Printf("Listing repositories for registry %s (%s)", registryName, registryConfig.URL)
-	return []string{}, nil
+	klog.V(2).Infof("Listing repositories for registry %s (%s)", registryName, registryConfig.URL)
+	repositories, err := NewRegistryClient(registryConfig.URL, registryConfig.Username, registryConfig.Password).Repositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories of %s: %v", registryName, err)
+	}
+	return repositories, nil
 }
 
 func (rp *RegistryPusher) ListTags(ctx context.Context, registryName, repository string) ([]string, error) {
@@ -193,10 +212,16 @@ func (rp *RegistryPusher) ListTags(ctx context.Context, registryName, repository
 		return nil, fmt.Errorf("registry configuration not found: %s", registryName)
 	}
 
-	// This would require implementing registry API calls for different registries
-	// For now, return a placeholder
-	log.Printf("Listing tags for %s in registry %s (%s)", repository, registryName, registryConfig.URL)
-	return []string{}, nil
+	klog.V(2).Infof("Listing tags for %s in registry %s (%s)", repository, registryName, registryConfig.URL)
+	client := NewRegistryClient(registryConfig.URL, registryConfig.Username, registryConfig.Password)
+	if client.isDockerHub() && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	tags, err := client.Tags(ctx, repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s in %s: %v", repository, registryName, err)
+	}
+	return tags, nil
 }
 
 func (rp *RegistryPusher) DeleteImage(ctx context.Context, registryName, repository, tag string) error {
@@ -207,7 +232,7 @@ func (rp *RegistryPusher) DeleteImage(ctx context.Context, registryName, reposit
 
 	// This would require implementing registry API calls for different registries
 	// For now, return a placeholder success
-	log.Printf("Deleting %s:%s from registry %s (%s)", repository, tag, registryName, registryConfig.URL)
+	klog.V(2).Infof("Deleting %s:%s from registry %s (%s)", repository, tag, registryName, registryConfig.URL)
 	return nil
 }
 
@@ -229,7 +254,7 @@ func (rp *RegistryPusher) GetImageInfo(ctx context.Context, registryName, reposi
 		Labels:     make(map[string]string),
 	}
 
-	log.Printf("Getting info for %s:%s from registry %s (%s)", repository, tag, registryName, registryConfig.URL)
+	klog.V(2).Infof("Getting info for %s:%s from registry %s (%s)", repository, tag, registryName, registryConfig.URL)
 	return info, nil
 }
 
@@ -255,7 +280,7 @@ func (rp *RegistryPusher) ValidateRegistryAccess(ctx context.Context, registryNa
 		return fmt.Errorf("incomplete registry credentials for %s", registryName)
 	}
 
-	log.Printf("Registry access validated for %s (%s)", registryName, registryConfig.URL)
+	klog.V(1).Infof("Registry access validated for %s (%s)", registryName, registryConfig.URL)
 	return nil
 }
 
@@ -27,6 +27,27 @@ type StaticConfig struct {
 	JwksURL              string   `toml:"jwks_url,omitempty"`
 	CertificateAuthority string   `toml:"certificate_authority,omitempty"`
 	ServerURL            string   `toml:"server_url,omitempty"`
+	// Cosign public key (file path or KMS URI) trusted by verify_image
+	ImageVerificationKey string `toml:"image_verification_key,omitempty"`
+	// Keyless verification policy used by verify_image when no key is configured
+	ImageVerificationIdentity string `toml:"image_verification_identity,omitempty"`
+	ImageVerificationIssuer   string `toml:"image_verification_issuer,omitempty"`
+	// Destination for persisted build logs: a directory (e.g. a mounted PVC) or s3://bucket/prefix
+	BuildLogSink string `toml:"build_log_sink,omitempty"`
+	// How long registry manifest and digest lookups are cached (e.g. "5m"), "0" disables caching
+	RegistryCacheTTL string `toml:"registry_cache_ttl,omitempty"`
+	// How long read tools serve the last cluster data they fetched while the cluster is unreachable
+	// (e.g. "30s"), "0" disables the fallback
+	LiveDataTTL string `toml:"live_data_ttl,omitempty"`
+	// UBI release ("8" or "9") of the base images the UBI validator suggests, 9 when unset
+	UBIVersion string `toml:"ubi_version,omitempty"`
+	// JSON file of image name to UBI image mappings overriding or extending the built-in ones,
+	// e.g. to point at an internal mirror
+	UBIImageMappingsFile string `toml:"ubi_image_mappings_file,omitempty"`
+	// Registry images are pushed to and namespace applications are deployed to when the CI/CD
+	// tools are not given one, quay.io and no default namespace when unset
+	DefaultRegistry  string `toml:"default_registry,omitempty"`
+	DefaultNamespace string `toml:"default_namespace,omitempty"`
 }
 
 type GroupVersionKind struct {
@@ -101,7 +101,7 @@ func AuthorizationMiddleware(requireOAuth bool, serverURL string, oidcProvider *
 			// 2. b. If this is not the only token in the headers, the token in here is used
 			// only for authentication and authorization. Therefore, we need to send TokenReview request
 			// with the other token in the headers (TODO: still need to validate aud and exp of this token separately).
-			_, _, err = mcpServer.VerifyTokenAPIServer(r.Context(), token, audience)
+			userInfo, _, err := mcpServer.VerifyTokenAPIServer(r.Context(), token, audience)
 			if err != nil {
 				klog.V(1).Infof("Authentication failed - token validation error: %s %s from %s, error: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
 
@@ -113,6 +113,9 @@ func AuthorizationMiddleware(requireOAuth bool, serverURL string, oidcProvider *
 				http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 				return
 			}
+			if userInfo != nil && userInfo.Username != "" {
+				r = r.WithContext(mcp.WithVerifiedUser(r.Context(), userInfo.Username))
+			}
 
 			next.ServeHTTP(w, r)
 		})
@@ -0,0 +1,54 @@
+package integrated
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireBearerToken(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	call := func(handler http.HandlerFunc, authorization string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/infer", nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		return recorder
+	}
+
+	t.Run("no-op without a configured token", func(t *testing.T) {
+		if code := call(requireBearerToken("", ok), "").Code; code != http.StatusOK {
+			t.Fatalf("Expected 200 without a configured token, got %d", code)
+		}
+	})
+
+	handler := requireBearerToken("s3cret", ok)
+	t.Run("rejects a missing token", func(t *testing.T) {
+		recorder := call(handler, "")
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 without a token, got %d", recorder.Code)
+		}
+		if recorder.Header().Get("WWW-Authenticate") == "" {
+			t.Fatalf("Expected a WWW-Authenticate header on 401")
+		}
+	})
+	t.Run("rejects a wrong token", func(t *testing.T) {
+		if code := call(handler, "Bearer wrong").Code; code != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 with a wrong token, got %d", code)
+		}
+	})
+	t.Run("rejects a non-bearer scheme", func(t *testing.T) {
+		if code := call(handler, "Basic s3cret").Code; code != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 with a basic scheme, got %d", code)
+		}
+	})
+	t.Run("accepts the matching token", func(t *testing.T) {
+		if code := call(handler, "Bearer s3cret").Code; code != http.StatusOK {
+			t.Fatalf("Expected 200 with the matching token, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package integrated
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// ErrBackendUnavailable is returned while the breaker is open
+var ErrBackendUnavailable = errors.New("inference backend unavailable")
+
+// circuitBreaker stops calling the inference backend after threshold consecutive failures.
+// Once cooldown has elapsed a single probe request is let through (half-open): its success
+// closes the breaker again, its failure re-opens it for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a request may be sent to the backend
+func (b *circuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrBackendUnavailable
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrBackendUnavailable
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+// Record reports the outcome of a request that was allowed through
+func (b *circuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Status returns the breaker state for readiness reporting
+func (b *circuitBreaker) Status() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := map[string]interface{}{
+		"state":                b.state,
+		"consecutive_failures": b.failures,
+		"failure_threshold":    b.threshold,
+		"cooldown_seconds":     int(b.cooldown.Seconds()),
+	}
+	if b.state == BreakerOpen {
+		remaining := b.cooldown - b.now().Sub(b.openedAt)
+		if remaining < 0 {
+			remaining = 0
+		}
+		status["retry_after_seconds"] = int(remaining.Seconds())
+	}
+	return status
+}
+
+// Wrap fast-fails requests with 503 while the breaker is open and counts 5xx responses from
+// next as backend failures.
+func (b *circuitBreaker) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := b.Allow(); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   err.Error(),
+				"breaker": b.Status(),
+			})
+			return
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			b.Record(recorder.status < http.StatusInternalServerError)
+		}()
+		next(recorder, r)
+	}
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
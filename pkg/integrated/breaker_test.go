@@ -0,0 +1,76 @@
+package integrated
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(2, 30*time.Second)
+	breaker.now = func() time.Time { return now }
+
+	status := http.StatusInternalServerError
+	handler := breaker.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+	call := func() int {
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodPost, "/infer", nil))
+		return recorder.Code
+	}
+
+	t.Run("opens after threshold failures", func(t *testing.T) {
+		call()
+		if breaker.Status()["state"] != BreakerClosed {
+			t.Fatalf("Expected breaker to stay closed after one failure, got %v", breaker.Status()["state"])
+		}
+		call()
+		if breaker.Status()["state"] != BreakerOpen {
+			t.Fatalf("Expected breaker to open, got %v", breaker.Status()["state"])
+		}
+	})
+	t.Run("fast-fails while open", func(t *testing.T) {
+		status = http.StatusOK
+		if code := call(); code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected 503 while open, got %d", code)
+		}
+	})
+	t.Run("half-open probe failure re-opens", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		now = now.Add(31 * time.Second)
+		if code := call(); code != http.StatusInternalServerError {
+			t.Fatalf("Expected probe to reach the backend, got %d", code)
+		}
+		if breaker.Status()["state"] != BreakerOpen {
+			t.Fatalf("Expected failed probe to re-open breaker, got %v", breaker.Status()["state"])
+		}
+	})
+	t.Run("half-open probe success closes", func(t *testing.T) {
+		status = http.StatusOK
+		now = now.Add(31 * time.Second)
+		if code := call(); code != http.StatusOK {
+			t.Fatalf("Expected probe to reach the backend, got %d", code)
+		}
+		if breaker.Status()["state"] != BreakerClosed {
+			t.Fatalf("Expected successful probe to close breaker, got %v", breaker.Status()["state"])
+		}
+	})
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(1, time.Second)
+	breaker.now = func() time.Time { return now }
+	breaker.Record(false)
+
+	now = now.Add(2 * time.Second)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected first probe to be allowed, got %v", err)
+	}
+	if err := breaker.Allow(); err != ErrBackendUnavailable {
+		t.Fatalf("Expected concurrent request to fast-fail during probe, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package integrated
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sur309/openshift-mcp-server/pkg/mcp"
+)
+
+// LoadConfig reads the configuration file at path (YAML or JSON) on top of the defaults and
+// applies environment variable overrides. An empty path only applies the environment.
+// The resulting configuration is validated.
+func LoadConfig(path string) (*IntegratedConfig, error) {
+	config := DefaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnvOverrides(config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return config, nil
+}
+
+// Configuration loading from environment
+func LoadConfigFromEnv() *IntegratedConfig {
+	config := DefaultConfig()
+	_ = applyEnvOverrides(config)
+	return config
+}
+
+// applyEnvOverrides overrides config values with the environment. Values that cannot be
+// parsed are left unchanged and reported.
+func applyEnvOverrides(config *IntegratedConfig) error {
+	var errs []error
+	envInt := func(name string, target *int) {
+		if value := os.Getenv(name); value != "" {
+			if p, err := strconv.Atoi(value); err == nil {
+				*target = p
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", name, value))
+			}
+		}
+	}
+
+	envInt("MCP_PORT", &config.MCPPort)
+	envInt("INFERENCE_PORT", &config.InferencePort)
+	// If generic PORT is set, use it for inference
+	envInt("PORT", &config.InferencePort)
+	envInt("LOG_LEVEL", &config.LogLevel)
+	envInt("INFERENCE_TIMEOUT_SECONDS", &config.InferenceTimeoutSeconds)
+	envInt("INFERENCE_FAILURE_THRESHOLD", &config.InferenceFailureThreshold)
+	envInt("INFERENCE_COOLDOWN_SECONDS", &config.InferenceCooldownSeconds)
+
+	// SHUTDOWN_TIMEOUT is a number of seconds or a duration such as 2m
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			config.ShutdownTimeoutSeconds = seconds
+		} else if timeout, err := time.ParseDuration(value); err == nil {
+			config.ShutdownTimeoutSeconds = int(timeout.Seconds())
+		} else {
+			errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT: %q is not a number of seconds or a duration", value))
+		}
+	}
+
+	if profile := os.Getenv("MCP_PROFILE"); profile != "" {
+		config.MCPProfile = profile
+	}
+
+	if readOnly := os.Getenv("MCP_READ_ONLY"); readOnly == "true" {
+		config.MCPReadOnly = true
+	}
+
+	if modelsPath := os.Getenv("MODELS_PATH"); modelsPath != "" {
+		config.ModelsPath = modelsPath
+	}
+
+	if backend := os.Getenv("INFERENCE_BACKEND_URL"); backend != "" {
+		config.InferenceBackendURL = backend
+	}
+
+	if registry := os.Getenv("DEFAULT_REGISTRY"); registry != "" {
+		config.DefaultRegistry = registry
+	}
+
+	if namespace := os.Getenv("DEFAULT_NAMESPACE"); namespace != "" {
+		config.DefaultNamespace = namespace
+	}
+
+	if kubeConfig := os.Getenv("KUBECONFIG"); kubeConfig != "" {
+		config.KubeConfig = kubeConfig
+	}
+
+	if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+		config.MCPTLSCert = certFile
+	}
+
+	if keyFile := os.Getenv("TLS_KEY_FILE"); keyFile != "" {
+		config.MCPTLSKey = keyFile
+	}
+
+	if authToken := os.Getenv("MCP_AUTH_TOKEN"); authToken != "" {
+		config.AuthToken = authToken
+	}
+
+	return errors.Join(errs...)
+}
+
+// InferenceBackend returns the URL of the model server inference requests are proxied to, the
+// backend URL or an http(s) models path. Empty when the mock responses are served.
+func (c *IntegratedConfig) InferenceBackend() string {
+	if c.InferenceBackendURL != "" {
+		return c.InferenceBackendURL
+	}
+	if strings.HasPrefix(c.ModelsPath, "http://") || strings.HasPrefix(c.ModelsPath, "https://") {
+		return c.ModelsPath
+	}
+	return ""
+}
+
+// TLSEnabled reports whether the servers serve TLS instead of plain HTTP
+func (c *IntegratedConfig) TLSEnabled() bool {
+	return c.MCPTLSCert != "" && c.MCPTLSKey != ""
+}
+
+// Validate reports every invalid value in the configuration
+func (c *IntegratedConfig) Validate() error {
+	var errs []error
+	if mcp.ProfileFromString(c.MCPProfile) == nil {
+		errs = append(errs, fmt.Errorf("mcp_profile: unknown profile %q, valid profiles are %v", c.MCPProfile, mcp.ProfileNames))
+	}
+	if c.MCPPort < 1 || c.MCPPort > 65535 {
+		errs = append(errs, fmt.Errorf("mcp_port: %d is not a valid port", c.MCPPort))
+	}
+	if c.InferencePort < 1 || c.InferencePort > 65535 {
+		errs = append(errs, fmt.Errorf("inference_port: %d is not a valid port", c.InferencePort))
+	}
+	if c.MCPPort == c.InferencePort {
+		errs = append(errs, fmt.Errorf("mcp_port and inference_port must differ, both are %d", c.MCPPort))
+	}
+	if backend := c.InferenceBackend(); backend != "" {
+		if _, err := parseBackendURL(backend); err != nil {
+			errs = append(errs, fmt.Errorf("inference_backend_url: %w", err))
+		}
+	}
+	if c.InferenceTimeoutSeconds < 1 {
+		errs = append(errs, fmt.Errorf("inference_timeout_seconds: %d must be at least 1", c.InferenceTimeoutSeconds))
+	}
+	if c.InferenceFailureThreshold < 1 {
+		errs = append(errs, fmt.Errorf("inference_failure_threshold: %d must be at least 1", c.InferenceFailureThreshold))
+	}
+	if c.InferenceCooldownSeconds < 1 {
+		errs = append(errs, fmt.Errorf("inference_cooldown_seconds: %d must be at least 1", c.InferenceCooldownSeconds))
+	}
+	if c.ShutdownTimeoutSeconds < 1 {
+		errs = append(errs, fmt.Errorf("shutdown_timeout_seconds: %d must be at least 1", c.ShutdownTimeoutSeconds))
+	}
+	if c.LogLevel < 0 {
+		errs = append(errs, fmt.Errorf("log_level: %d must not be negative", c.LogLevel))
+	}
+	if (c.MCPTLSCert == "") != (c.MCPTLSKey == "") {
+		errs = append(errs, fmt.Errorf("mcp_tls_cert and mcp_tls_key must be set together"))
+	}
+	if c.MCPTLSCert != "" {
+		if _, err := os.Stat(c.MCPTLSCert); err != nil {
+			errs = append(errs, fmt.Errorf("mcp_tls_cert: %w", err))
+		}
+	}
+	if c.MCPTLSKey != "" {
+		if _, err := os.Stat(c.MCPTLSKey); err != nil {
+			errs = append(errs, fmt.Errorf("mcp_tls_key: %w", err))
+		}
+	}
+	names := make(map[string]bool, len(c.Registries))
+	for i, registry := range c.Registries {
+		if registry.Name == "" || registry.URL == "" {
+			errs = append(errs, fmt.Errorf("registries[%d]: name and url are required", i))
+		}
+		if names[registry.Name] {
+			errs = append(errs, fmt.Errorf("registries[%d]: duplicate registry name %q", i, registry.Name))
+		}
+		names[registry.Name] = true
+		if registry.PasswordEnv != "" && os.Getenv(registry.PasswordEnv) == "" {
+			errs = append(errs, fmt.Errorf("registries[%d]: password_env %s is not set", i, registry.PasswordEnv))
+		}
+	}
+	// Like KUBECONFIG, kubeconfig may list several files
+	for _, path := range filepath.SplitList(c.KubeConfig) {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("kubeconfig: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
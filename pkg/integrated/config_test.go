@@ -0,0 +1,167 @@
+package integrated
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	configPath := writeConfig(t, "config.yaml", `
+mcp_profile: full
+mcp_port: 9091
+default_registry: ghcr.io
+`)
+
+	config, err := LoadConfig(configPath)
+	t.Run("reads file values", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if config.MCPProfile != "full" || config.MCPPort != 9091 || config.DefaultRegistry != "ghcr.io" {
+			t.Fatalf("Unexpected config values: %+v", config)
+		}
+	})
+	t.Run("keeps defaults for missing values", func(t *testing.T) {
+		if config.InferencePort != 8080 {
+			t.Fatalf("Expected default inference port 8080, got %d", config.InferencePort)
+		}
+	})
+}
+
+func TestLoadConfigJSONFile(t *testing.T) {
+	configPath := writeConfig(t, "config.json", `{"mcp_port": 9092, "default_namespace": "apps"}`)
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.MCPPort != 9092 || config.DefaultNamespace != "apps" {
+		t.Fatalf("Unexpected config values: %+v", config)
+	}
+}
+
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	configPath := writeConfig(t, "config.yaml", "mcp_port: 9091\ndefault_registry: ghcr.io\n")
+	t.Setenv("MCP_PORT", "9093")
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.MCPPort != 9093 {
+		t.Fatalf("Expected MCP_PORT to override file value, got %d", config.MCPPort)
+	}
+	if config.DefaultRegistry != "ghcr.io" {
+		t.Fatalf("Expected file value to be kept, got %s", config.DefaultRegistry)
+	}
+}
+
+func TestLoadConfigShutdownTimeout(t *testing.T) {
+	for value, expected := range map[string]int{"45": 45, "2m": 120} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("SHUTDOWN_TIMEOUT", value)
+			config, err := LoadConfig("")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if config.ShutdownTimeoutSeconds != expected {
+				t.Fatalf("Expected shutdown timeout %d, got %d", expected, config.ShutdownTimeoutSeconds)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content  string
+		env      map[string]string
+		expected string
+	}{
+		"unknown field":    {content: "mcp_prot: 9091\n", expected: "mcp_prot"},
+		"unknown profile":  {content: "mcp_profile: nope\n", expected: "unknown profile"},
+		"invalid port":     {content: "mcp_port: 70000\n", expected: "mcp_port"},
+		"same ports":       {content: "mcp_port: 8080\n", expected: "must differ"},
+		"invalid env port": {env: map[string]string{"MCP_PORT": "abc"}, expected: "MCP_PORT"},
+		"tls without key":  {env: map[string]string{"TLS_CERT_FILE": "cert.pem"}, expected: "must be set together"},
+		"invalid backend":  {content: "inference_backend_url: model:8080\n", expected: "inference_backend_url"},
+		"invalid timeout":  {env: map[string]string{"SHUTDOWN_TIMEOUT": "soon"}, expected: "SHUTDOWN_TIMEOUT"},
+		"missing tls":      {content: "mcp_tls_cert: /missing/tls.crt\nmcp_tls_key: /missing/tls.key\n", expected: "mcp_tls_cert"},
+		"registry url":     {content: "registries:\n- name: internal\n", expected: "registries[0]: name and url are required"},
+		"registry name":    {content: "registries:\n- {name: internal, url: registry.example.com}\n- {name: internal, url: quay.io}\n", expected: "duplicate registry name"},
+		"registry secret":  {content: "registries:\n- {name: internal, url: registry.example.com, username: ci, password_env: MISSING_REGISTRY_PASSWORD}\n", expected: "MISSING_REGISTRY_PASSWORD is not set"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			configPath := writeConfig(t, "config.yaml", tc.content)
+			config, err := LoadConfig(configPath)
+			if err == nil {
+				t.Fatalf("Expected error, got config %+v", config)
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("Expected error to contain %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRegistries(t *testing.T) {
+	t.Setenv("INTERNAL_REGISTRY_PASSWORD", "s3cret")
+	configPath := writeConfig(t, "config.yaml", `
+registries:
+- name: internal
+  url: registry.example.com
+  username: ci
+  password_env: INTERNAL_REGISTRY_PASSWORD
+  default: true
+- name: quay
+  url: quay.io
+`)
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(config.Registries) != 2 {
+		t.Fatalf("Expected 2 registries, got %+v", config.Registries)
+	}
+	if internal := config.Registries[0]; internal.Name != "internal" || internal.Username != "ci" || internal.PasswordEnv != "INTERNAL_REGISTRY_PASSWORD" || !internal.Default {
+		t.Fatalf("Unexpected registry %+v", internal)
+	}
+}
+
+func TestLoadConfigKubeconfigList(t *testing.T) {
+	first := writeConfig(t, "first.kubeconfig", "apiVersion: v1\nkind: Config\n")
+	second := writeConfig(t, "second.kubeconfig", "apiVersion: v1\nkind: Config\n")
+	t.Run("accepts every existing file", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", strings.Join([]string{first, "", second}, string(os.PathListSeparator)))
+		if _, err := LoadConfig(""); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+	t.Run("reports a missing file", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.kubeconfig")
+		t.Setenv("KUBECONFIG", strings.Join([]string{first, missing}, string(os.PathListSeparator)))
+		_, err := LoadConfig("")
+		if err == nil || !strings.Contains(err.Error(), missing) {
+			t.Fatalf("Expected an error naming %s, got %v", missing, err)
+		}
+	})
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("Expected error for missing file, got nil")
+	}
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file %s: %v", path, err)
+	}
+	return path
+}
@@ -0,0 +1,33 @@
+package integrated
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// drainGate rejects new requests with 503 once shutdown begins, while the requests and
+// operations in flight finish
+type drainGate struct {
+	draining atomic.Bool
+}
+
+// Start makes the gate reject every new request
+func (g *drainGate) Start() {
+	g.draining.Store(true)
+}
+
+// Wrap serves requests with next until the gate starts draining
+func (g *drainGate) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.draining.Load() {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "server is shutting down"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,35 @@
+package integrated
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDrainGate(t *testing.T) {
+	gate := &drainGate{}
+	handler := gate.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+		return recorder
+	}
+
+	t.Run("serves requests before shutdown", func(t *testing.T) {
+		if code := call().Code; code != http.StatusOK {
+			t.Fatalf("Expected 200 before shutdown, got %d", code)
+		}
+	})
+	t.Run("rejects requests once draining", func(t *testing.T) {
+		gate.Start()
+		recorder := call()
+		if recorder.Code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected 503 while draining, got %d", recorder.Code)
+		}
+		if recorder.Header().Get("Retry-After") == "" {
+			t.Fatalf("Expected a Retry-After header while draining")
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package integrated
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// proxiedHeaders are the request headers forwarded to the inference backend. Authorization is
+// not forwarded, it carries the token of this server.
+var proxiedHeaders = []string{"Content-Type", "Accept", "Accept-Encoding"}
+
+// inferenceProxy forwards inference requests to a model server such as an OpenShift AI / KServe
+// endpoint
+type inferenceProxy struct {
+	backend *url.URL
+	client  *http.Client
+}
+
+func newInferenceProxy(backend string, timeout time.Duration) (*inferenceProxy, error) {
+	backendURL, err := parseBackendURL(backend)
+	if err != nil {
+		return nil, err
+	}
+	return &inferenceProxy{backend: backendURL, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// parseBackendURL checks that backend is an absolute http(s) URL
+func parseBackendURL(backend string) (*url.URL, error) {
+	backendURL, err := url.Parse(backend)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inference backend URL %q: %w", backend, err)
+	}
+	if (backendURL.Scheme != "http" && backendURL.Scheme != "https") || backendURL.Host == "" {
+		return nil, fmt.Errorf("invalid inference backend URL %q: must be an http or https URL", backend)
+	}
+	return backendURL, nil
+}
+
+// Forward returns a handler proxying requests to path below the backend URL, returning the
+// upstream response as is. Upstream failures are translated to 502, or 504 on timeout.
+func (p *inferenceProxy) Forward(path string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		target := p.backend.JoinPath(path)
+		target.RawQuery = r.URL.RawQuery
+		upstream, err := http.NewRequestWithContext(r.Context(), r.Method, target.String(), r.Body)
+		if err != nil {
+			writeUpstreamError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, header := range proxiedHeaders {
+			if value := r.Header.Get(header); value != "" {
+				upstream.Header.Set(header, value)
+			}
+		}
+
+		response, err := p.client.Do(upstream)
+		if err != nil {
+			writeUpstreamError(w, upstreamErrorStatus(err), fmt.Errorf("inference backend %s: %w", p.backend.Host, err))
+			return
+		}
+		defer func() { _ = response.Body.Close() }()
+		for header, values := range response.Header {
+			for _, value := range values {
+				w.Header().Add(header, value)
+			}
+		}
+		w.WriteHeader(response.StatusCode)
+		_, _ = io.Copy(w, response.Body)
+	}
+}
+
+// upstreamErrorStatus maps a failed backend call to 504 when it timed out, 502 otherwise
+func upstreamErrorStatus(err error) int {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func writeUpstreamError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  err.Error(),
+		"status": http.StatusText(status),
+	})
+}
@@ -0,0 +1,103 @@
+package integrated
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInferenceProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/models/demo/infer":
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("Expected the Authorization header not to be forwarded")
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"echo":` + string(body) + `,"query":"` + r.URL.RawQuery + `"}`))
+		case "/v2/models/demo/models":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"model not loaded"}`))
+		case "/v2/models/demo/slow/infer":
+			time.Sleep(500 * time.Millisecond)
+		}
+	}))
+	defer backend.Close()
+
+	proxy, err := newInferenceProxy(backend.URL+"/v2/models/demo", 5*time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	call := func(handler http.HandlerFunc, target, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer s3cret")
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		return recorder
+	}
+
+	t.Run("forwards the body and returns the upstream response", func(t *testing.T) {
+		recorder := call(proxy.Forward("/infer"), "/infer?verbose=true", `{"inputs":[1]}`)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", recorder.Code)
+		}
+		if body := recorder.Body.String(); body != `{"echo":{"inputs":[1]},"query":"verbose=true"}` {
+			t.Fatalf("Unexpected upstream response: %s", body)
+		}
+		if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Fatalf("Expected upstream content type, got %s", contentType)
+		}
+	})
+	t.Run("returns upstream errors as is", func(t *testing.T) {
+		recorder := call(proxy.Forward("/models"), "/models", "")
+		if recorder.Code != http.StatusInternalServerError || !strings.Contains(recorder.Body.String(), "model not loaded") {
+			t.Fatalf("Expected the upstream 500, got %d %s", recorder.Code, recorder.Body.String())
+		}
+	})
+	t.Run("translates timeouts to 504", func(t *testing.T) {
+		slow, err := newInferenceProxy(backend.URL+"/v2/models/demo/slow", 50*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if code := call(slow.Forward("/infer"), "/infer", "{}").Code; code != http.StatusGatewayTimeout {
+			t.Fatalf("Expected 504, got %d", code)
+		}
+	})
+	t.Run("translates unreachable backends to 502", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		unreachable, err := newInferenceProxy(closed.URL, time.Second)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if code := call(unreachable.Forward("/infer"), "/infer", "{}").Code; code != http.StatusBadGateway {
+			t.Fatalf("Expected 502, got %d", code)
+		}
+	})
+}
+
+func TestInferenceBackend(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config   IntegratedConfig
+		expected string
+	}{
+		"mock by default":  {config: IntegratedConfig{ModelsPath: "/app/models"}, expected: ""},
+		"backend url":      {config: IntegratedConfig{ModelsPath: "/app/models", InferenceBackendURL: "http://model:8080"}, expected: "http://model:8080"},
+		"models path url":  {config: IntegratedConfig{ModelsPath: "https://model.apps.example.com"}, expected: "https://model.apps.example.com"},
+		"backend url wins": {config: IntegratedConfig{ModelsPath: "https://a", InferenceBackendURL: "https://b"}, expected: "https://b"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if backend := tc.config.InferenceBackend(); backend != tc.expected {
+				t.Fatalf("Expected backend %q, got %q", tc.expected, backend)
+			}
+		})
+	}
+	if _, err := newInferenceProxy("model:8080", time.Second); err == nil {
+		t.Fatal("Expected error for a backend URL without scheme, got nil")
+	}
+}
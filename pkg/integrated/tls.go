@@ -0,0 +1,114 @@
+package integrated
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// certReloader serves the certificate of a key pair and reloads it when the files change, so
+// rotated certificates take effect without a restart
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	closeWatch func() error
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the key pair, keeping the current certificate when it cannot be loaded
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate %s and key %s: %w", r.certFile, r.keyFile, err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// TLSConfig returns a server TLS configuration serving the reloaded certificate
+func (r *certReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Watch reloads the certificate when its files change. The directories are watched rather than
+// the files, mounted Secrets are updated by swapping a symlink which replaces the files.
+func (r *certReloader) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod || !r.affects(event.Name) {
+					continue
+				}
+				// A half-written pair fails to load and is picked up by the next event
+				if err := r.reload(); err != nil {
+					log.Printf("TLS certificate not reloaded: %v", err)
+				} else {
+					log.Printf("Reloaded TLS certificate %s", r.certFile)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("TLS certificate watch error: %v", err)
+			}
+		}
+	}()
+	r.closeWatch = watcher.Close
+	return nil
+}
+
+// affects reports whether a change of the named file can change the key pair: the files
+// themselves or the ..data symlink of a mounted Secret
+func (r *certReloader) affects(name string) bool {
+	name = filepath.Clean(name)
+	return name == filepath.Clean(r.certFile) || name == filepath.Clean(r.keyFile) || strings.HasPrefix(filepath.Base(name), "..")
+}
+
+// Close stops watching the certificate files
+func (r *certReloader) Close() {
+	if r.closeWatch != nil {
+		_ = r.closeWatch()
+	}
+}
@@ -0,0 +1,104 @@
+package integrated
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeKeyPair(t, certFile, keyFile, "first")
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer reloader.Close()
+	t.Run("serves the loaded certificate", func(t *testing.T) {
+		if name := servedCommonName(t, reloader); name != "first" {
+			t.Fatalf("Expected certificate first, got %s", name)
+		}
+	})
+	t.Run("reloads a rotated certificate", func(t *testing.T) {
+		if err := reloader.Watch(); err != nil {
+			t.Fatalf("Expected no error watching, got %v", err)
+		}
+		writeKeyPair(t, certFile, keyFile, "second")
+		deadline := time.Now().Add(5 * time.Second)
+		for servedCommonName(t, reloader) != "second" {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected certificate second to be reloaded")
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	t.Run("keeps the certificate when the files are invalid", func(t *testing.T) {
+		if err := os.WriteFile(certFile, []byte("invalid"), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", certFile, err)
+		}
+		if err := reloader.reload(); err == nil {
+			t.Fatalf("Expected error reloading an invalid certificate")
+		}
+		if name := servedCommonName(t, reloader); name != "second" {
+			t.Fatalf("Expected certificate second to be kept, got %s", name)
+		}
+	})
+}
+
+func TestNewCertReloaderInvalid(t *testing.T) {
+	if _, err := newCertReloader(filepath.Join(t.TempDir(), "missing.crt"), filepath.Join(t.TempDir(), "missing.key")); err == nil {
+		t.Fatal("Expected error for missing key pair, got nil")
+	}
+}
+
+func servedCommonName(t *testing.T, reloader *certReloader) string {
+	t.Helper()
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse served certificate: %v", err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func writeKeyPair(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	// The key is written first, a certificate without its key fails to load
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", keyFile, err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", certFile, err)
+	}
+}
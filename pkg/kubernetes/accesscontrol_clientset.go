@@ -12,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	authenticationv1 "k8s.io/client-go/kubernetes/typed/authentication/v1"
 	authorizationv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -105,6 +106,46 @@ func (a *AccessControlClientset) Services(namespace string) (corev1.ServiceInter
 	return a.delegate.CoreV1().Services(namespace), nil
 }
 
+func (a *AccessControlClientset) ConfigMaps(namespace string) (corev1.ConfigMapInterface, error) {
+	gvk := &schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+	if !isAllowed(a.staticConfig, gvk) {
+		return nil, isNotAllowedError(gvk)
+	}
+	return a.delegate.CoreV1().ConfigMaps(namespace), nil
+}
+
+func (a *AccessControlClientset) Secrets(namespace string) (corev1.SecretInterface, error) {
+	gvk := &schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"}
+	if !isAllowed(a.staticConfig, gvk) {
+		return nil, isNotAllowedError(gvk)
+	}
+	return a.delegate.CoreV1().Secrets(namespace), nil
+}
+
+func (a *AccessControlClientset) ServiceAccounts(namespace string) (corev1.ServiceAccountInterface, error) {
+	gvk := &schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ServiceAccount"}
+	if !isAllowed(a.staticConfig, gvk) {
+		return nil, isNotAllowedError(gvk)
+	}
+	return a.delegate.CoreV1().ServiceAccounts(namespace), nil
+}
+
+func (a *AccessControlClientset) Deployments(namespace string) (appsv1.DeploymentInterface, error) {
+	gvk := &schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	if !isAllowed(a.staticConfig, gvk) {
+		return nil, isNotAllowedError(gvk)
+	}
+	return a.delegate.AppsV1().Deployments(namespace), nil
+}
+
+func (a *AccessControlClientset) ReplicaSets(namespace string) (appsv1.ReplicaSetInterface, error) {
+	gvk := &schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}
+	if !isAllowed(a.staticConfig, gvk) {
+		return nil, isNotAllowedError(gvk)
+	}
+	return a.delegate.AppsV1().ReplicaSets(namespace), nil
+}
+
 func (a *AccessControlClientset) SelfSubjectAccessReviews() (authorizationv1.SelfSubjectAccessReviewInterface, error) {
 	gvk := &schema.GroupVersionKind{Group: authorizationv1api.GroupName, Version: authorizationv1api.SchemeGroupVersion.Version, Kind: "SelfSubjectAccessReview"}
 	if !isAllowed(a.staticConfig, gvk) {
@@ -0,0 +1,16 @@
+package kubernetes
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Builds returns the dynamic client of the OpenShift Builds of a namespace
+func (k *Kubernetes) Builds(namespace string) (dynamic.ResourceInterface, error) {
+	gvk := &schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "Build"}
+	if !isAllowed(k.manager.staticConfig, gvk) {
+		return nil, isNotAllowedError(gvk)
+	}
+	gvr := gvk.GroupVersion().WithResource("builds")
+	return k.manager.dynamicClient.Resource(gvr).Namespace(k.NamespaceOrDefault(namespace)), nil
+}
@@ -76,6 +76,11 @@ func (m *Manager) ToRESTConfig() (*rest.Config, error) {
 	return m.cfg, nil
 }
 
+// ToRESTConfig returns the rest.Config of the (possibly derived) client
+func (k *Kubernetes) ToRESTConfig() (*rest.Config, error) {
+	return k.manager.ToRESTConfig()
+}
+
 // ToRawKubeConfigLoader returns the clientcmd.ClientConfig object (genericclioptions.RESTClientGetter)
 func (m *Manager) ToRawKubeConfigLoader() clientcmd.ClientConfig {
 	return m.clientCmdConfig
@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// Deployments returns the typed Deployments client of a namespace
+func (k *Kubernetes) Deployments(namespace string) (appsv1.DeploymentInterface, error) {
+	return k.manager.accessControlClientSet.Deployments(k.NamespaceOrDefault(namespace))
+}
+
+// ReplicaSets returns the typed ReplicaSets client of a namespace, holding the revision history
+// of its Deployments
+func (k *Kubernetes) ReplicaSets(namespace string) (appsv1.ReplicaSetInterface, error) {
+	return k.manager.accessControlClientSet.ReplicaSets(k.NamespaceOrDefault(namespace))
+}
+
+// Pods returns the typed Pods client of a namespace, to select the pods of a Deployment by label
+// and read their logs
+func (k *Kubernetes) Pods(namespace string) (corev1.PodInterface, error) {
+	return k.manager.accessControlClientSet.Pods(k.NamespaceOrDefault(namespace))
+}
+
+// ConfigMaps returns the typed ConfigMaps client of a namespace, to create the configuration
+// mounted in deployed applications
+func (k *Kubernetes) ConfigMaps(namespace string) (corev1.ConfigMapInterface, error) {
+	return k.manager.accessControlClientSet.ConfigMaps(k.NamespaceOrDefault(namespace))
+}
+
+// Secrets returns the typed Secrets client of a namespace, to create image pull secrets
+func (k *Kubernetes) Secrets(namespace string) (corev1.SecretInterface, error) {
+	return k.manager.accessControlClientSet.Secrets(k.NamespaceOrDefault(namespace))
+}
+
+// ServiceAccounts returns the typed ServiceAccounts client of a namespace, to link image pull
+// secrets to its default service account
+func (k *Kubernetes) ServiceAccounts(namespace string) (corev1.ServiceAccountInterface, error) {
+	return k.manager.accessControlClientSet.ServiceAccounts(k.NamespaceOrDefault(namespace))
+}
@@ -14,3 +14,13 @@ func (m *Manager) IsOpenShift(_ context.Context) bool {
 	}.String())
 	return err == nil
 }
+
+// HasRouteAPI reports whether the cluster serves OpenShift Routes, clusters without it are exposed
+// through Ingresses
+func (m *Manager) HasRouteAPI(_ context.Context) bool {
+	_, err := m.discoveryClient.ServerResourcesForGroupVersion(schema.GroupVersion{
+		Group:   "route.openshift.io",
+		Version: "v1",
+	}.String())
+	return err == nil
+}
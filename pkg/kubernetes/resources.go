@@ -59,6 +59,24 @@ func (k *Kubernetes) ResourcesGet(ctx context.Context, gvk *schema.GroupVersionK
 }
 
 func (k *Kubernetes) ResourcesCreateOrUpdate(ctx context.Context, resource string) ([]*unstructured.Unstructured, error) {
+	parsedResources, err := parseResources(resource)
+	if err != nil {
+		return nil, err
+	}
+	return k.resourcesCreateOrUpdate(ctx, parsedResources)
+}
+
+// ResourcesApplyDryRun applies the resources with a server-side dry run, returning the objects
+// the server would persist without changing the cluster
+func (k *Kubernetes) ResourcesApplyDryRun(ctx context.Context, resource string) ([]*unstructured.Unstructured, error) {
+	parsedResources, err := parseResources(resource)
+	if err != nil {
+		return nil, err
+	}
+	return k.resourcesApply(ctx, parsedResources, true)
+}
+
+func parseResources(resource string) ([]*unstructured.Unstructured, error) {
 	separator := regexp.MustCompile(`\r?\n---\r?\n`)
 	resources := separator.Split(resource, -1)
 	var parsedResources []*unstructured.Unstructured
@@ -69,7 +87,7 @@ func (k *Kubernetes) ResourcesCreateOrUpdate(ctx context.Context, resource strin
 		}
 		parsedResources = append(parsedResources, &obj)
 	}
-	return k.resourcesCreateOrUpdate(ctx, parsedResources)
+	return parsedResources, nil
 }
 
 func (k *Kubernetes) ResourcesDelete(ctx context.Context, gvk *schema.GroupVersionKind, namespace, name string) error {
@@ -133,6 +151,14 @@ func (k *Kubernetes) resourcesListAsTable(ctx context.Context, gvk *schema.Group
 }
 
 func (k *Kubernetes) resourcesCreateOrUpdate(ctx context.Context, resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	return k.resourcesApply(ctx, resources, false)
+}
+
+func (k *Kubernetes) resourcesApply(ctx context.Context, resources []*unstructured.Unstructured, dryRun bool) ([]*unstructured.Unstructured, error) {
+	options := metav1.ApplyOptions{FieldManager: version.BinaryName}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
 	for i, obj := range resources {
 		gvk := obj.GroupVersionKind()
 		gvr, rErr := k.resourceFor(&gvk)
@@ -145,14 +171,12 @@ func (k *Kubernetes) resourcesCreateOrUpdate(ctx context.Context, resources []*u
 		if namespaced, nsErr := k.isNamespaced(&gvk); nsErr == nil && namespaced {
 			namespace = k.NamespaceOrDefault(namespace)
 		}
-		resources[i], rErr = k.manager.dynamicClient.Resource(*gvr).Namespace(namespace).Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
-			FieldManager: version.BinaryName,
-		})
+		resources[i], rErr = k.manager.dynamicClient.Resource(*gvr).Namespace(namespace).Apply(ctx, obj.GetName(), obj, options)
 		if rErr != nil {
 			return nil, rErr
 		}
 		// Clear the cache to ensure the next operation is performed on the latest exposed APIs (will change after the CRD creation)
-		if gvk.Kind == "CustomResourceDefinition" {
+		if gvk.Kind == "CustomResourceDefinition" && !dryRun {
 			k.manager.accessControlRESTMapper.Reset()
 		}
 	}
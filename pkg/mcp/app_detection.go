@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"k8s.io/klog/v2"
+)
+
+// appDetectionTTL is how long the result of inspecting a repository is reused
+const appDetectionTTL = 10 * time.Minute
+
+// AppDetection is the application type and port of a repository and the signal they were derived from
+type AppDetection struct {
+	Type   string `json:"type"`
+	Port   int    `json:"port"`
+	Signal string `json:"signal"` // marker file, Dockerfile EXPOSE, or the repository name as a last resort
+	Error  string `json:"error,omitempty"`
+}
+
+// appMarkers maps files in the build context to the application type and its conventional port, checked in order
+var appMarkers = []struct {
+	file    string
+	appType string
+	port    int
+}{
+	{"package.json", "nodejs", 3000},
+	{"requirements.txt", "python", 8000},
+	{"pyproject.toml", "python", 8000},
+	{"go.mod", "golang", 8080},
+	{"pom.xml", "java", 8080},
+	{"build.gradle", "java", 8080},
+	{"Gemfile", "ruby", 3000},
+}
+
+var (
+	appDetectionsMu sync.Mutex
+	appDetections   = make(map[string]appDetectionEntry)
+)
+
+type appDetectionEntry struct {
+	detection  *AppDetection
+	detectedAt time.Time
+}
+
+// detectRepoApp detects the application type and port of a configured repository
+func detectRepoApp(ctx context.Context, config *RepoConfig) *AppDetection {
+	return detectApp(ctx, config.URL, config.Branch, config.BuildContext, config.DockerFile, config.Name)
+}
+
+// detectApp inspects the files at the tip of a branch for marker files and a Dockerfile EXPOSE.
+// The name-based heuristic is only used when the repository cannot be read.
+func detectApp(ctx context.Context, url, branch, buildContext, dockerfile, name string) *AppDetection {
+	key := url + "@" + branch + ":" + buildContext + ":" + dockerfile
+	appDetectionsMu.Lock()
+	entry, cached := appDetections[key]
+	appDetectionsMu.Unlock()
+	if cached && time.Since(entry.detectedAt) < appDetectionTTL {
+		return entry.detection
+	}
+
+	detection, err := detectAppFromSource(ctx, url, branch, buildContext, dockerfile)
+	if err != nil {
+		klog.V(2).Infof("Falling back to name-based application detection for %s: %v", url, err)
+		port, appType := detectAppDetails(name)
+		// Unreadable repositories are retried on the next call
+		return &AppDetection{Type: appType, Port: port, Signal: "repository name", Error: err.Error()}
+	}
+
+	appDetectionsMu.Lock()
+	appDetections[key] = appDetectionEntry{detection: detection, detectedAt: time.Now()}
+	appDetectionsMu.Unlock()
+	return detection
+}
+
+// detectAppFromSource fetches the tip of a branch without a working tree and reads its marker files
+func detectAppFromSource(ctx context.Context, url, branch, buildContext, dockerfile string) (*AppDetection, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	auth, err := gitAuthForURL(url)
+	if err != nil {
+		return nil, err
+	}
+	options := &git.CloneOptions{URL: url, Auth: auth, Depth: 1, SingleBranch: true, NoCheckout: true}
+	if branch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %v", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree: %v", err)
+	}
+
+	contextDir := path.Clean(strings.TrimPrefix(buildContext, "./"))
+	detection := &AppDetection{Type: "web", Port: 8080, Signal: "default"}
+	for _, marker := range appMarkers {
+		if _, err := tree.File(path.Join(contextDir, marker.file)); err == nil {
+			detection = &AppDetection{Type: marker.appType, Port: marker.port, Signal: "marker file " + marker.file}
+			break
+		}
+	}
+
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	dockerfilePath := path.Join(contextDir, strings.TrimPrefix(dockerfile, "./"))
+	if port, ok := dockerfileExposedPort(tree, dockerfilePath); ok {
+		detection.Port = port
+		if detection.Signal == "default" {
+			detection.Signal = "EXPOSE in " + dockerfilePath
+		} else {
+			detection.Signal += ", EXPOSE in " + dockerfilePath
+		}
+	}
+	return detection, nil
+}
+
+// dockerfileExposedPort returns the first port exposed by the Dockerfile at path in tree
+func dockerfileExposedPort(tree *object.Tree, dockerfilePath string) (int, bool) {
+	file, err := tree.File(dockerfilePath)
+	if err != nil {
+		return 0, false
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return 0, false
+	}
+	defer func() { _ = reader.Close() }()
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "EXPOSE") {
+			continue
+		}
+		// EXPOSE 8080/tcp
+		if port, err := strconv.Atoi(strings.SplitN(fields[1], "/", 2)[0]); err == nil && port > 0 && port <= 65535 {
+			return port, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/klog/v2"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+// defaultAuditCapacity is the number of tool invocations retained in memory
+const defaultAuditCapacity = 1000
+
+var allJWTSignatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.EdDSA, jose.HS256, jose.HS384, jose.HS512, jose.RS256, jose.RS384, jose.RS512,
+	jose.ES256, jose.ES384, jose.ES512, jose.PS256, jose.PS384, jose.PS512,
+}
+
+// AuditRecord describes a single tool invocation
+type AuditRecord struct {
+	ID        uint64    `json:"id"`
+	Tool      string    `json:"tool"`
+	User      string    `json:"user"`
+	Timestamp time.Time `json:"timestamp"`
+	Outcome   string    `json:"outcome"` // "success" or "error"
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+}
+
+// auditLog is a bounded in-memory ring of recent tool invocations
+type auditLog struct {
+	mu      sync.Mutex
+	records []AuditRecord
+	next    int
+	full    bool
+	seq     uint64
+}
+
+func newAuditLog(capacity int) *auditLog {
+	return &auditLog{records: make([]AuditRecord, capacity)}
+}
+
+func (a *auditLog) add(record AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.seq++
+	record.ID = a.seq
+	a.records[a.next] = record
+	a.next = (a.next + 1) % len(a.records)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// recent returns up to limit records matching the filter, newest first
+func (a *auditLog) recent(limit int, match func(AuditRecord) bool) []AuditRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	count := a.next
+	if a.full {
+		count = len(a.records)
+	}
+	result := make([]AuditRecord, 0)
+	for i := 0; i < count && len(result) < limit; i++ {
+		idx := (a.next - 1 - i + len(a.records)) % len(a.records)
+		if match(a.records[idx]) {
+			result = append(result, a.records[idx])
+		}
+	}
+	return result
+}
+
+// toolCallAuditMiddleware records every tool invocation in the server's audit log
+func (s *Server) toolCallAuditMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, ctr)
+		record := AuditRecord{
+			Tool:      ctr.Params.Name,
+			User:      auditUser(ctx),
+			Timestamp: start,
+			Outcome:   "success",
+			Duration:  time.Since(start).String(),
+		}
+		if err != nil {
+			record.Outcome = "error"
+			record.Error = err.Error()
+		} else if result != nil && result.IsError {
+			record.Outcome = "error"
+			if len(result.Content) > 0 {
+				if text, ok := result.Content[0].(mcp.TextContent); ok {
+					record.Error = text.Text
+				}
+			}
+		}
+		s.audit.add(record)
+		return result, err
+	}
+}
+
+// verifiedUserKey is the context key of the user the HTTP authorization middleware verified
+type verifiedUserKey struct{}
+
+// WithVerifiedUser records the user a request's bearer token was verified for, reported as the
+// caller in the audit log
+func WithVerifiedUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, verifiedUserKey{}, user)
+}
+
+// auditUser identifies the caller from the verified user, if any. The subject of a bearer token
+// that was not verified is recorded as unverified:<subject>, anyone can forge it.
+func auditUser(ctx context.Context) string {
+	if user, ok := ctx.Value(verifiedUserKey{}).(string); ok && user != "" {
+		return user
+	}
+	authorization, ok := ctx.Value(internalk8s.OAuthAuthorizationHeader).(string)
+	if !ok || !strings.HasPrefix(authorization, "Bearer ") {
+		return "anonymous"
+	}
+	token, err := jwt.ParseSigned(strings.TrimPrefix(authorization, "Bearer "), allJWTSignatureAlgorithms)
+	if err != nil {
+		return "unknown"
+	}
+	claims := jwt.Claims{}
+	if err := token.UnsafeClaimsWithoutVerification(&claims); err != nil || claims.Subject == "" {
+		return "unknown"
+	}
+	return "unverified:" + claims.Subject
+}
+
+// initActivity initializes server activity reporting MCP tools
+func (s *Server) initActivity() []server.ServerTool {
+	klog.V(1).Info("Initializing activity reporting tools")
+
+	return []server.ServerTool{
+		{Tool: mcp.NewTool("activity_report",
+			mcp.WithDescription("Report the most recent tool invocations handled by this server, including tool name, caller, timestamp, outcome and duration. Useful to review what the server has been doing without access to its logs."),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of invocations to return, newest first. Defaults to 50.")),
+			mcp.WithString("tool", mcp.Description("Only include invocations of this tool name.")),
+			mcp.WithString("outcome", mcp.Description("Only include invocations with this outcome: 'success' or 'error'.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Activity: Report Recent Tool Invocations"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.activityReport},
+	}
+}
+
+// activityReport handles reporting recent tool invocations from the audit log
+func (s *Server) activityReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		args = make(map[string]interface{})
+	}
+
+	limit := getIntArg(args, "limit", 50)
+	tool := getStringArg(args, "tool", "")
+	outcome := getStringArg(args, "outcome", "")
+	if outcome != "" && outcome != "success" && outcome != "error" {
+		return NewTextResult("", fmt.Errorf("invalid outcome '%s', must be 'success' or 'error'", outcome)), nil
+	}
+
+	records := s.audit.recent(limit, func(r AuditRecord) bool {
+		return (tool == "" || r.Tool == tool) && (outcome == "" || r.Outcome == outcome)
+	})
+
+	result := map[string]interface{}{
+		"invocations": records,
+		"total":       len(records),
+		"filters": map[string]interface{}{
+			"limit":   limit,
+			"tool":    tool,
+			"outcome": outcome,
+		},
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,41 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+func TestAuditUser(t *testing.T) {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("a-key-chosen-by-whoever-forges-the-token")}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	forged, err := jwt.Signed(signer).Claims(jwt.Claims{Subject: "system:admin"}).Serialize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	withToken := context.WithValue(context.Background(), internalk8s.OAuthAuthorizationHeader, "Bearer "+forged)
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{"no token", context.Background(), "anonymous"},
+		{"malformed token", context.WithValue(context.Background(), internalk8s.OAuthAuthorizationHeader, "Bearer not-a-jwt"), "unknown"},
+		{"unverified token", withToken, "unverified:system:admin"},
+		{"verified user", WithVerifiedUser(withToken, "jane@example.com"), "jane@example.com"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if user := auditUser(c.ctx); user != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, user)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// buildLogResponseLines is the number of trailing build output lines kept in a tool response
+// once the full log has been persisted
+const buildLogResponseLines = 100
+
+var buildLogNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// buildLogSink returns the configured build log destination: a directory (e.g. a mounted PVC)
+// or an s3://bucket/prefix URL
+func (s *Server) buildLogSink() string {
+	if s.configuration != nil && s.configuration.StaticConfig != nil && s.configuration.StaticConfig.BuildLogSink != "" {
+		return s.configuration.StaticConfig.BuildLogSink
+	}
+	return os.Getenv("BUILD_LOG_SINK")
+}
+
+// persistBuildLog stores the full log of a build in the configured sink and returns a reference to it
+func (s *Server) persistBuildLog(ctx context.Context, imageName, buildLog string) (string, error) {
+	return s.persistBuildArtifact(ctx, imageName, ".log", []byte(buildLog))
+}
+
+// persistBuildArtifact stores a file produced by a build of an image, such as its log or SBOM, in
+// the configured build log sink and returns a reference to it
+func (s *Server) persistBuildArtifact(ctx context.Context, imageName, extension string, data []byte) (string, error) {
+	sink := s.buildLogSink()
+	if sink == "" {
+		return "", fmt.Errorf("no build log sink configured, set build_log_sink or BUILD_LOG_SINK")
+	}
+
+	name := fmt.Sprintf("%s/%s%s",
+		strings.Trim(buildLogNameSanitizer.ReplaceAllString(imageName, "_"), "_"),
+		time.Now().UTC().Format("20060102T150405Z"), extension)
+
+	if strings.HasPrefix(sink, "s3://") {
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(sink, "s3://"), "/")
+		if bucket == "" {
+			return "", fmt.Errorf("invalid build log sink '%s', expected s3://bucket[/prefix]", sink)
+		}
+		key := name
+		if prefix = strings.Trim(prefix, "/"); prefix != "" {
+			key = prefix + "/" + name
+		}
+		return putS3Object(ctx, bucket, key, data)
+	}
+
+	path := filepath.Join(sink, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create build log directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	klog.V(2).Infof("Persisted build artifact of %s to %s", imageName, path)
+	return path, nil
+}
+
+// buildLogWriter logs the output of a running build line by line, so its progress can be followed
+// in the server log before the tool returns
+type buildLogWriter struct {
+	name string
+}
+
+func (w buildLogWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			klog.V(2).Infof("[build %s] %s", w.name, line)
+		}
+	}
+	return len(p), nil
+}
+
+// tailLines returns the last n lines of output and whether it was truncated
+func tailLines(output string, n int) ([]string, bool) {
+	lines := strings.Split(output, "\n")
+	if len(lines) <= n {
+		return lines, false
+	}
+	return lines[len(lines)-n:], true
+}
+
+// putS3Object uploads an object to an S3-compatible store through the aws CLI, which resolves
+// credentials from the environment, shared config files, SSO and instance roles, and the endpoint
+// of S3-compatible stores from AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL
+func putS3Object(ctx context.Context, bucket, key string, body []byte) (string, error) {
+	if _, err := exec.LookPath("aws"); err != nil {
+		return "", fmt.Errorf("aws CLI not found in PATH, it is required for s3 build log sinks")
+	}
+	object := "s3://" + bucket + "/" + key
+	cmd := exec.CommandContext(ctx, "aws", "s3", "cp", "-", object, "--only-show-errors")
+	cmd.Stdin = bytes.NewReader(body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %v: %s", object, err, strings.TrimSpace(string(output)))
+	}
+	klog.V(2).Infof("Persisted build artifact to %s", object)
+	return object, nil
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// BranchCheck compares the branch a pipeline watches with the branches of its Git remote
+type BranchCheck struct {
+	ConfiguredBranch string   `json:"configured_branch"`
+	DefaultBranch    string   `json:"default_branch,omitempty"`
+	Branches         []string `json:"branches,omitempty"`
+	Exists           bool     `json:"exists"`
+	Warning          string   `json:"warning,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// listRemoteRefs lists the references advertised by a Git remote without cloning it
+func listRemoteRefs(ctx context.Context, url string) ([]*plumbing.Reference, error) {
+	auth, err := gitAuthForURL(url)
+	if err != nil {
+		return nil, err
+	}
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{url}})
+	return remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+}
+
+// checkPipelineBranch warns when the configured branch does not exist on the remote, which
+// leaves a pipeline that never triggers. A remote that cannot be listed is reported in Error
+// rather than as a missing branch.
+func checkPipelineBranch(ctx context.Context, config *RepoConfig) *BranchCheck {
+	check := &BranchCheck{ConfiguredBranch: config.Branch}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	refs, err := listRemoteRefs(ctx, config.URL)
+	if err != nil {
+		check.Error = fmt.Sprintf("failed to list branches of %s: %v", config.URL, err)
+		return check
+	}
+
+	heads := make(map[plumbing.Hash][]string)
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		switch {
+		case ref.Name() == plumbing.HEAD:
+			head = ref
+		case ref.Name().IsBranch():
+			branch := ref.Name().Short()
+			check.Branches = append(check.Branches, branch)
+			heads[ref.Hash()] = append(heads[ref.Hash()], branch)
+			if branch == config.Branch {
+				check.Exists = true
+			}
+		}
+	}
+	sort.Strings(check.Branches)
+
+	// Servers advertise HEAD as a symbolic reference; older ones only send its hash
+	if head != nil {
+		if head.Type() == plumbing.SymbolicReference {
+			check.DefaultBranch = head.Target().Short()
+		} else if candidates := heads[head.Hash()]; len(candidates) == 1 {
+			check.DefaultBranch = candidates[0]
+		}
+	}
+
+	if !check.Exists {
+		check.Warning = fmt.Sprintf("branch '%s' does not exist on %s, the pipeline will never trigger", config.Branch, config.URL)
+		if check.DefaultBranch != "" {
+			check.Warning += fmt.Sprintf("; the default branch is '%s'", check.DefaultBranch)
+		}
+	}
+	return check
+}
+
+// pipelineDiagnose checks a repository's pipeline configuration against its Git remote
+func (s *Server) pipelineDiagnose(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	branchCheck := checkPipelineBranch(ctx, config)
+	issues := make([]string, 0)
+	suggestions := make([]string, 0)
+	switch {
+	case branchCheck.Error != "":
+		issues = append(issues, branchCheck.Error)
+		suggestions = append(suggestions, "Check that the repository URL is correct and reachable from the server")
+	case branchCheck.Warning != "":
+		issues = append(issues, branchCheck.Warning)
+		if branchCheck.DefaultBranch != "" {
+			suggestions = append(suggestions, fmt.Sprintf("Re-add the repository with 'repo_add' and branch '%s'", branchCheck.DefaultBranch))
+		}
+	}
+
+	status := "healthy"
+	if len(issues) > 0 {
+		status = "issues_found"
+	}
+	result := map[string]interface{}{
+		"repository":   config.Name,
+		"url":          config.URL,
+		"status":       status,
+		"branch_check": branchCheck,
+		"issues":       issues,
+		"suggestions":  suggestions,
+	}
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+// buildStatus reports the phase of an OpenShift build
+func (s *Server) buildStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, builds, err := s.buildsFor(ctx, request)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	status, err := cicd.GetBuildStatus(ctx, builds, name)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	jsonResult, _ := json.MarshalIndent(status, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// buildCancel cancels an OpenShift build that has not finished
+func (s *Server) buildCancel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, builds, err := s.buildsFor(ctx, request)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	before, err := cicd.GetBuildStatus(ctx, builds, name)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	status, err := cicd.CancelBuild(ctx, builds, name)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	result := map[string]interface{}{"build": status}
+	if before.Finished {
+		result["status"] = "unchanged"
+		result["message"] = fmt.Sprintf("Build %s already finished with phase %s", name, before.Phase)
+	} else {
+		result["status"] = "cancelled"
+		result["message"] = fmt.Sprintf("Cancellation of build %s requested, OpenShift stops its pod", name)
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// buildsFor reads the build name and namespace arguments and returns the Builds client of the
+// namespace. Without a namespace, builds of a build config named after a repository default to
+// the repository's namespace.
+func (s *Server) buildsFor(ctx context.Context, request mcp.CallToolRequest) (string, dynamic.ResourceInterface, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("invalid arguments format")
+	}
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return "", nil, fmt.Errorf("name parameter is required")
+	}
+	namespace := getStringArg(args, "namespace", "")
+	if separator := strings.LastIndex(name, "-"); namespace == "" && separator > 0 {
+		if config := findRepo(name[:separator]); config != nil {
+			namespace = config.Namespace
+		}
+	}
+
+	if s.k == nil {
+		return "", nil, fmt.Errorf("kubernetes manager is not initialized")
+	}
+	k8s, err := s.k.Derived(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to access cluster: %v", err)
+	}
+	if !s.k.IsOpenShift(ctx) {
+		return "", nil, fmt.Errorf("builds are only available on OpenShift clusters")
+	}
+	builds, err := k8s.Builds(namespace)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, builds, nil
+}
@@ -0,0 +1,297 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+// defaultLiveDataTTL bounds how long last-known-good cluster data is served when the API server is
+// unreachable, unless live_data_ttl is configured
+const defaultLiveDataTTL = 30 * time.Second
+
+// managedByLabel identifies resources deployed by the CI/CD tools
+const managedByLabel = "app.kubernetes.io/managed-by=ai-mcp-openshift-server"
+
+var (
+	deploymentGVK = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	routeGVK      = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+	serviceGVK    = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}
+	podGVK        = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}
+	ingressGVK    = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+)
+
+// liveDataEntry is a cached result of a successful cluster lookup
+type liveDataEntry struct {
+	value     interface{}
+	fetchedAt time.Time
+}
+
+// liveDataCache keeps the last-known-good result of read-only cluster lookups for ttl
+type liveDataCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]liveDataEntry
+}
+
+// LiveDataStatus describes the freshness of data returned by a read tool
+type LiveDataStatus struct {
+	Source    string `json:"source"` // "live", "cache" or "unavailable"
+	Stale     bool   `json:"stale"`
+	FetchedAt string `json:"fetched_at,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func newLiveDataCache(ttl time.Duration) *liveDataCache {
+	return &liveDataCache{ttl: ttl, entries: make(map[string]liveDataEntry)}
+}
+
+// liveDataTTL returns the configured last-known-good TTL, zero disables the fallback
+func liveDataTTL(configuredTTL string) time.Duration {
+	if configuredTTL == "" {
+		return defaultLiveDataTTL
+	}
+	ttl, err := time.ParseDuration(configuredTTL)
+	if err != nil || ttl < 0 {
+		klog.Warningf("Invalid live_data_ttl %q, using %s", configuredTTL, defaultLiveDataTTL)
+		return defaultLiveDataTTL
+	}
+	return ttl
+}
+
+func (c *liveDataCache) get(key string) (liveDataEntry, bool) {
+	if c == nil {
+		return liveDataEntry{}, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.fetchedAt) > c.ttl {
+		return liveDataEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *liveDataCache) evict(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+func (c *liveDataCache) put(key string, value interface{}) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = liveDataEntry{value: value, fetchedAt: time.Now()}
+}
+
+// cachedClusterRead runs a read-only cluster lookup, falling back to the last-known-good
+// value when the API server cannot be reached. Errors the API server answered with are returned
+// instead, a NotFound also evicts the cached value. Only use it for tools that do not mutate state.
+func (s *Server) cachedClusterRead(ctx context.Context, key string, fetch func(k *internalk8s.Kubernetes) (interface{}, error)) (interface{}, LiveDataStatus, error) {
+	value, err := s.liveClusterRead(ctx, fetch)
+	if err == nil {
+		s.liveData.put(key, value)
+		return value, LiveDataStatus{Source: "live", FetchedAt: time.Now().Format(time.RFC3339)}, nil
+	}
+	if !isClusterUnavailable(err) {
+		if apierrors.IsNotFound(err) {
+			s.liveData.evict(key)
+		}
+		return nil, LiveDataStatus{Source: "live", FetchedAt: time.Now().Format(time.RFC3339), Error: err.Error()}, err
+	}
+
+	klog.V(1).Infof("Live cluster lookup %q failed, trying cache: %v", key, err)
+	if entry, ok := s.liveData.get(key); ok {
+		return entry.value, LiveDataStatus{
+			Source:    "cache",
+			Stale:     true,
+			FetchedAt: entry.fetchedAt.Format(time.RFC3339),
+			Error:     err.Error(),
+		}, nil
+	}
+	return nil, LiveDataStatus{Source: "unavailable", Stale: true, Error: err.Error()}, nil
+}
+
+// errClusterNotConfigured is returned by cluster lookups when the server has no cluster connection
+var errClusterNotConfigured = errors.New("kubernetes manager is not initialized")
+
+// isClusterUnavailable reports whether a cluster lookup failed because the API server could not
+// be reached or could not serve the request, rather than answering with an error about the request
+func isClusterUnavailable(err error) bool {
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.Is(err, errClusterNotConfigured) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
+func (s *Server) liveClusterRead(ctx context.Context, fetch func(k *internalk8s.Kubernetes) (interface{}, error)) (interface{}, error) {
+	if s.k == nil {
+		return nil, errClusterNotConfigured
+	}
+	k, err := s.k.Derived(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fetch(k)
+}
+
+// fetchManagedApplications lists the Deployments created by the CI/CD tools
+func fetchManagedApplications(ctx context.Context, k *internalk8s.Kubernetes, namespace string) ([]map[string]interface{}, error) {
+	list, err := k.ResourcesList(ctx, &deploymentGVK, namespace, internalk8s.ResourceListOptions{
+		ListOptions: metav1.ListOptions{LabelSelector: managedByLabel},
+	})
+	if err != nil {
+		return nil, err
+	}
+	deployments, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected deployment list type %T", list)
+	}
+	apps := make([]map[string]interface{}, 0)
+	for _, item := range deployments.Items {
+		apps = append(apps, summarizeDeployment(&item))
+	}
+	return apps, nil
+}
+
+// summarizeDeployment extracts the readiness information reported by the read tools
+func summarizeDeployment(deployment *unstructured.Unstructured) map[string]interface{} {
+	replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	ready, _, _ := unstructured.NestedInt64(deployment.Object, "status", "readyReplicas")
+	image := ""
+	if containers, found, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers"); found && len(containers) > 0 {
+		if container, ok := containers[0].(map[string]interface{}); ok {
+			image, _ = container["image"].(string)
+		}
+	}
+	return map[string]interface{}{
+		"name":      deployment.GetName(),
+		"namespace": deployment.GetNamespace(),
+		"image":     image,
+		"replicas":  fmt.Sprintf("%d/%d", ready, replicas),
+		"ready":     replicas > 0 && ready == replicas,
+		"created":   deployment.GetCreationTimestamp().Format(time.RFC3339),
+	}
+}
+
+// fetchRouteHost returns the host of the Route exposing an application
+func fetchRouteHost(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) (string, error) {
+	route, err := k.ResourcesGet(ctx, &routeGVK, namespace, name)
+	if err != nil {
+		return "", err
+	}
+	host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+	if host == "" {
+		return "", fmt.Errorf("route %s/%s has no host assigned", namespace, name)
+	}
+	return host, nil
+}
+
+// fetchIngressURL returns the URL of the Ingress exposing an application, from its host or the
+// address the ingress controller assigned when it matches any host
+func fetchIngressURL(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) (string, error) {
+	ingress, err := k.ResourcesGet(ctx, &ingressGVK, namespace, name)
+	if err != nil {
+		return "", err
+	}
+	scheme := "http"
+	if tls, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "tls"); len(tls) > 0 {
+		scheme = "https"
+	}
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	for _, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		if host, _, _ := unstructured.NestedString(rule, "host"); host != "" {
+			return scheme + "://" + host, nil
+		}
+	}
+	addresses, _, _ := unstructured.NestedSlice(ingress.Object, "status", "loadBalancer", "ingress")
+	for _, a := range addresses {
+		address, _ := a.(map[string]interface{})
+		for _, field := range []string{"hostname", "ip"} {
+			if value, _, _ := unstructured.NestedString(address, field); value != "" {
+				return scheme + "://" + value, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("ingress %s/%s has no host or address assigned", namespace, name)
+}
+
+// fetchAppURL returns the external URL of an application, from its Route or else its Ingress
+func fetchAppURL(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) (string, error) {
+	host, routeErr := fetchRouteHost(ctx, k, namespace, name)
+	if routeErr == nil {
+		return "https://" + host, nil
+	}
+	url, err := fetchIngressURL(ctx, k, namespace, name)
+	if err != nil {
+		return "", fmt.Errorf("no Route (%w) or Ingress (%w) exposes %s/%s", routeErr, err, namespace, name)
+	}
+	return url, nil
+}
+
+// LiveImage describes the image a Deployment is running
+type LiveImage struct {
+	Deployed       bool     `json:"deployed"`
+	Image          string   `json:"image,omitempty"`
+	RunningDigests []string `json:"running_digests,omitempty"`
+}
+
+// fetchLiveImage returns the image of an application's Deployment and the digests its pods are running
+func fetchLiveImage(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) (*LiveImage, error) {
+	deployment, err := k.ResourcesGet(ctx, &deploymentGVK, namespace, name)
+	if apierrors.IsNotFound(err) {
+		return &LiveImage{Deployed: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	live := &LiveImage{Deployed: true, Image: summarizeDeployment(deployment)["image"].(string)}
+
+	list, err := k.ResourcesList(ctx, &podGVK, namespace, internalk8s.ResourceListOptions{
+		ListOptions: metav1.ListOptions{LabelSelector: "app=" + name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected pod list type %T", list)
+	}
+	seen := make(map[string]bool)
+	for _, pod := range pods.Items {
+		statuses, _, _ := unstructured.NestedSlice(pod.Object, "status", "containerStatuses")
+		for _, status := range statuses {
+			container, ok := status.(map[string]interface{})
+			if !ok || container["name"] != name {
+				continue
+			}
+			imageID, _ := container["imageID"].(string)
+			if idx := strings.LastIndex(imageID, "@"); idx >= 0 && !seen[imageID[idx+1:]] {
+				seen[imageID[idx+1:]] = true
+				live.RunningDigests = append(live.RunningDigests, imageID[idx+1:])
+			}
+		}
+	}
+	return live, nil
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+// configDataArgs reads the config_map_mounts, secret_mounts and secret_env_from arguments into the
+// manifest data, and returns them with the inline config_maps and secrets to create before
+// deploying. It returns nil when the application references no ConfigMap or Secret.
+func configDataArgs(args map[string]interface{}, data *ManifestData) (*cicd.DeploymentConfig, error) {
+	config := &cicd.DeploymentConfig{Name: data.AppName, Namespace: data.Namespace}
+	var err error
+	if config.ConfigMapMounts, err = getStringMapArg(args, "config_map_mounts"); err != nil {
+		return nil, err
+	}
+	if config.SecretMounts, err = getStringMapArg(args, "secret_mounts"); err != nil {
+		return nil, err
+	}
+	if config.SecretEnvFrom, err = getStringSliceArg(args, "secret_env_from"); err != nil {
+		return nil, err
+	}
+	for _, mounts := range []map[string]string{config.ConfigMapMounts, config.SecretMounts} {
+		for name, path := range mounts {
+			if !strings.HasPrefix(path, "/") {
+				return nil, fmt.Errorf("mount path of %s must be absolute, got '%s'", name, path)
+			}
+		}
+	}
+	if config.ConfigMaps, err = inlineConfigDataArg(args, "config_maps"); err != nil {
+		return nil, err
+	}
+	if config.Secrets, err = inlineConfigDataArg(args, "secrets"); err != nil {
+		return nil, err
+	}
+	data.ConfigMapMounts = config.ConfigMapMounts
+	data.SecretMounts = config.SecretMounts
+	data.SecretEnvFrom = config.SecretEnvFrom
+	if len(config.ConfigMapMounts) == 0 && len(config.SecretMounts) == 0 && len(config.SecretEnvFrom) == 0 &&
+		len(config.ConfigMaps) == 0 && len(config.Secrets) == 0 {
+		return nil, nil
+	}
+	return config, nil
+}
+
+// inlineConfigDataArg reads an object argument of ConfigMap or Secret names to their key/value data
+func inlineConfigDataArg(args map[string]interface{}, key string) (map[string]map[string]string, error) {
+	value, exists := args[key]
+	if !exists || value == nil {
+		return nil, nil
+	}
+	items, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object of names to key/value data", key)
+	}
+	result := make(map[string]map[string]string, len(items))
+	for name := range items {
+		data, err := getStringMapArg(items, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s", key, err)
+		}
+		result[name] = data
+	}
+	return result, nil
+}
+
+// applyConfigData creates the inline ConfigMaps and Secrets of an application in its namespace and
+// checks that every other one it references exists
+func applyConfigData(ctx context.Context, k *internalk8s.Kubernetes, config *cicd.DeploymentConfig) ([]string, error) {
+	configMaps, err := k.ConfigMaps(config.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := k.Secrets(config.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	config.Labels = map[string]string{"app": config.Name, "app.kubernetes.io/managed-by": "ai-mcp-openshift-server"}
+	return cicd.ApplyConfigData(ctx, configMaps, secrets, *config)
+}
@@ -0,0 +1,329 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// executionHistoryLimit is the number of executions kept per repository
+	executionHistoryLimit = 20
+	// defaultAwaitTimeout and maxAwaitTimeout bound how long cicd_await_next blocks
+	defaultAwaitTimeout = 5 * time.Minute
+	maxAwaitTimeout     = 30 * time.Minute
+)
+
+// PipelineExecution is one run of a repository's deploy pipeline
+type PipelineExecution struct {
+	ID          string           `json:"id"`
+	Repository  string           `json:"repository"`
+	Commit      string           `json:"commit,omitempty"`
+	Environment string           `json:"environment,omitempty"`
+	Status      string           `json:"status"` // running, succeeded, failed
+	Stages      []ExecutionStage `json:"stages"`
+	AppURL      string           `json:"app_url,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	StartedAt   time.Time        `json:"started_at"`
+	FinishedAt  *time.Time       `json:"finished_at,omitempty"`
+	sequence    int
+}
+
+// ExecutionStage is the outcome of one stage of a pipeline execution
+type ExecutionStage struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // succeeded, failed, skipped
+	Message string `json:"message,omitempty"`
+}
+
+// executionHistory records recent pipeline executions per repository and lets callers wait for changes
+type executionHistory struct {
+	mu         sync.Mutex
+	sequence   int
+	executions map[string][]*PipelineExecution
+	changed    chan struct{}
+}
+
+// In-memory pipeline execution history, keyed like repositoryStore
+var pipelineExecutions = newExecutionHistory()
+
+func newExecutionHistory() *executionHistory {
+	return &executionHistory{
+		executions: make(map[string][]*PipelineExecution),
+		changed:    make(chan struct{}),
+	}
+}
+
+// notify wakes every waiter; callers must hold h.mu
+func (h *executionHistory) notify() {
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// start records a new running execution for a repository
+func (h *executionHistory) start(repository, commit, environment string) *PipelineExecution {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sequence++
+	execution := &PipelineExecution{
+		ID:          fmt.Sprintf("%s-%d", repository, h.sequence),
+		Repository:  repository,
+		Commit:      commit,
+		Environment: environment,
+		Status:      "running",
+		Stages:      []ExecutionStage{},
+		StartedAt:   time.Now(),
+		sequence:    h.sequence,
+	}
+	history := append(h.executions[repository], execution)
+	if len(history) > executionHistoryLimit {
+		history = history[len(history)-executionHistoryLimit:]
+	}
+	h.executions[repository] = history
+	h.notify()
+	return execution
+}
+
+// stage appends a stage result to a running execution
+func (h *executionHistory) stage(execution *PipelineExecution, name, status, message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	execution.Stages = append(execution.Stages, ExecutionStage{Name: name, Status: status, Message: message})
+}
+
+// finish completes an execution; a non-empty errMessage marks it failed
+func (h *executionHistory) finish(execution *PipelineExecution, appURL, errMessage string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	execution.FinishedAt = &now
+	execution.AppURL = appURL
+	execution.Status = "succeeded"
+	if errMessage != "" {
+		execution.Status = "failed"
+		execution.Error = errMessage
+	}
+	h.notify()
+	go notifyExecution(copyExecution(execution))
+}
+
+// latestSequence returns the sequence number of the most recently started execution
+func (h *executionHistory) latestSequence() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sequence
+}
+
+// recent returns copies of a repository's executions, newest first
+func (h *executionHistory) recent(repository string, limit int) []PipelineExecution {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	history := h.executions[repository]
+	result := make([]PipelineExecution, 0, limit)
+	for i := len(history) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, copyExecution(history[i]))
+	}
+	return result
+}
+
+// find returns a copy of the execution of a repository with the given ID, or its most recent
+// execution when id is empty
+func (h *executionHistory) find(repository, id string) (*PipelineExecution, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	history := h.executions[repository]
+	for i := len(history) - 1; i >= 0; i-- {
+		if id == "" || history[i].ID == id {
+			execution := copyExecution(history[i])
+			return &execution, true
+		}
+	}
+	return nil, false
+}
+
+// await blocks until an execution of repository completes. When commit is set the most recent
+// execution of that commit is awaited (it may already have completed), otherwise the first
+// execution started after afterSequence.
+func (h *executionHistory) await(ctx context.Context, repository, commit string, afterSequence int) (*PipelineExecution, error) {
+	for {
+		h.mu.Lock()
+		var candidate *PipelineExecution
+		history := h.executions[repository]
+		if commit != "" {
+			for i := len(history) - 1; i >= 0; i-- {
+				if strings.HasPrefix(history[i].Commit, commit) {
+					candidate = history[i]
+					break
+				}
+			}
+		} else {
+			for _, execution := range history {
+				if execution.sequence > afterSequence {
+					candidate = execution
+					break
+				}
+			}
+		}
+		if candidate != nil && candidate.FinishedAt != nil {
+			execution := copyExecution(candidate)
+			h.mu.Unlock()
+			return &execution, nil
+		}
+		changed := h.changed
+		h.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func copyExecution(execution *PipelineExecution) PipelineExecution {
+	c := *execution
+	c.Stages = append([]ExecutionStage{}, execution.Stages...)
+	return c
+}
+
+// executionLog renders a pipeline execution and the objects it applied as a plain text log
+func executionLog(execution *PipelineExecution, objects []ManifestApplyResult) string {
+	var log strings.Builder
+	fmt.Fprintf(&log, "execution %s of %s\n", execution.ID, execution.Repository)
+	if execution.Commit != "" {
+		fmt.Fprintf(&log, "commit: %s\n", execution.Commit)
+	}
+	if execution.Environment != "" {
+		fmt.Fprintf(&log, "environment: %s\n", execution.Environment)
+	}
+	fmt.Fprintf(&log, "started: %s\n", execution.StartedAt.UTC().Format(time.RFC3339))
+	for _, stage := range execution.Stages {
+		fmt.Fprintf(&log, "stage %s: %s", stage.Name, stage.Status)
+		if stage.Message != "" {
+			fmt.Fprintf(&log, " (%s)", stage.Message)
+		}
+		log.WriteString("\n")
+	}
+	for _, object := range objects {
+		fmt.Fprintf(&log, "%s %s/%s: %s", object.Kind, object.Namespace, object.Name, object.Action)
+		if object.Error != "" {
+			fmt.Fprintf(&log, " (%s)", object.Error)
+		}
+		log.WriteString("\n")
+	}
+	if execution.FinishedAt != nil {
+		fmt.Fprintf(&log, "finished: %s, %s\n", execution.FinishedAt.UTC().Format(time.RFC3339), execution.Status)
+	}
+	if execution.Error != "" {
+		fmt.Fprintf(&log, "error: %s\n", execution.Error)
+	}
+	return log.String()
+}
+
+// cicdAwaitNext waits for the next pipeline execution of a repository to complete
+func (s *Server) cicdAwaitNext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	timeout := defaultAwaitTimeout
+	if seconds := getIntArg(args, "timeout", 0); seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if timeout > maxAwaitTimeout {
+		return NewTextResult("", fmt.Errorf("timeout must not exceed %d seconds", int(maxAwaitTimeout.Seconds()))), nil
+	}
+	commit := getStringArg(args, "commit", "")
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	execution, err := pipelineExecutions.await(waitCtx, config.Name, commit, pipelineExecutions.latestSequence())
+	if err != nil {
+		if ctx.Err() != nil {
+			return NewTextResult("", fmt.Errorf("wait for '%s' cancelled: %v", config.Name, ctx.Err())), nil
+		}
+		target := "the next execution"
+		if commit != "" {
+			target = fmt.Sprintf("an execution of commit '%s'", commit)
+		}
+		return NewTextResult("", fmt.Errorf("timed out after %s waiting for %s of '%s'", timeout, target, config.Name)), nil
+	}
+
+	result := map[string]interface{}{
+		"status":    execution.Status,
+		"execution": execution,
+	}
+	if execution.FinishedAt != nil {
+		result["duration"] = execution.FinishedAt.Sub(execution.StartedAt).Round(time.Millisecond).String()
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// cicdPipelineStatus returns one pipeline execution of a repository, by default its most recent,
+// with the stage it failed at and the repository's recent executions
+func (s *Server) cicdPipelineStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	limit := getIntArg(args, "limit", 5)
+	if limit <= 0 || limit > executionHistoryLimit {
+		limit = executionHistoryLimit
+	}
+	runID := getStringArg(args, "run_id", "")
+	execution, found := pipelineExecutions.find(config.Name, runID)
+	if !found && runID != "" {
+		return NewTextResult("", fmt.Errorf("pipeline execution '%s' of '%s' not found, only the last %d executions are kept", runID, config.Name, executionHistoryLimit)), nil
+	}
+
+	result := map[string]interface{}{
+		"repository":        config.Name,
+		"status":            config.Status,
+		"recent_executions": pipelineExecutions.recent(config.Name, limit),
+	}
+	if !found {
+		result["message"] = fmt.Sprintf("'%s' has no pipeline executions yet", config.Name)
+		jsonResult, _ := json.MarshalIndent(result, "", "  ")
+		return NewTextResult(string(jsonResult), nil), nil
+	}
+	result["execution"] = execution
+	if execution.FinishedAt != nil {
+		result["duration"] = execution.FinishedAt.Sub(execution.StartedAt).Round(time.Millisecond).String()
+	}
+	for _, stage := range execution.Stages {
+		if stage.Status == "failed" {
+			result["failed_stage"] = stage.Name
+			break
+		}
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
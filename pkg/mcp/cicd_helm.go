@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Helm chart scaffolding. Chart.yaml and values.yaml are rendered from the manifest data, the chart
+// templates use Helm template syntax and are copied as is.
+const helmChartTemplate = `apiVersion: v2
+name: {{.AppName}}
+description: Helm chart for {{.AppName}}
+type: application
+version: 0.1.0
+appVersion: "{{.ImageTag}}"
+`
+
+const helmValuesTemplate = `name: {{.AppName}}
+replicaCount: {{.Replicas}}
+
+image:
+  repository: {{.ImageName}}
+  tag: "{{.ImageTag}}"
+  pullPolicy: Always
+
+port: {{.Port}}
+
+service:
+  port: {{.ServicePort}}
+
+# OpenShift Route exposing the service, the cluster assigns a host when empty
+route:
+  enabled: {{eq .RouteType "route"}}
+  host: "{{.RouteHost}}"
+
+env:
+{{- range $name, $value := .Env}}
+  {{$name}}: {{printf "%q" $value}}
+{{- else}} {}
+{{- end}}
+
+resources:
+  requests:
+    cpu: "{{.CPURequest}}"
+    memory: "{{.MemoryRequest}}"
+  limits:
+    cpu: "{{.CPULimit}}"
+    memory: "{{.MemoryLimit}}"
+
+probes:
+  livenessPath: {{.LivenessPath}}
+  readinessPath: {{.ReadinessPath}}
+`
+
+const helmDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Values.name }}
+  labels:
+    app: {{ .Values.name }}
+    app.kubernetes.io/managed-by: {{ .Release.Service }}
+    helm.sh/chart: {{ .Chart.Name }}-{{ .Chart.Version }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      app: {{ .Values.name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Values.name }}
+    spec:
+      securityContext:
+        runAsNonRoot: true
+        seccompProfile:
+          type: RuntimeDefault
+      containers:
+      - name: {{ .Values.name }}
+        image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+        imagePullPolicy: {{ .Values.image.pullPolicy }}
+        securityContext:
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+          runAsNonRoot: true
+        ports:
+        - containerPort: {{ .Values.port }}
+          name: http
+        env:
+        - name: PORT
+          value: {{ .Values.port | quote }}
+        {{- range $name, $value := .Values.env }}
+        - name: {{ $name }}
+          value: {{ $value | quote }}
+        {{- end }}
+        resources:
+          {{- toYaml .Values.resources | nindent 10 }}
+        livenessProbe:
+          httpGet:
+            path: {{ .Values.probes.livenessPath }}
+            port: http
+          initialDelaySeconds: 30
+          periodSeconds: 10
+        readinessProbe:
+          httpGet:
+            path: {{ .Values.probes.readinessPath }}
+            port: http
+          initialDelaySeconds: 5
+          periodSeconds: 5
+`
+
+const helmServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Values.name }}
+  labels:
+    app: {{ .Values.name }}
+spec:
+  selector:
+    app: {{ .Values.name }}
+  ports:
+  - name: http
+    port: {{ .Values.service.port }}
+    targetPort: http
+`
+
+const helmRouteTemplate = `{{- if .Values.route.enabled }}
+apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: {{ .Values.name }}
+  labels:
+    app: {{ .Values.name }}
+spec:
+  {{- with .Values.route.host }}
+  host: {{ . }}
+  {{- end }}
+  to:
+    kind: Service
+    name: {{ .Values.name }}
+  port:
+    targetPort: http
+  tls:
+    termination: edge
+    insecureEdgeTerminationPolicy: Redirect
+{{- end }}
+`
+
+// generateHelmChart scaffolds a Helm chart for the manifest data, keyed by path relative to the chart directory
+func generateHelmChart(data ManifestData) (map[string]string, error) {
+	if err := validateEnvNames(data.Env); err != nil {
+		return nil, err
+	}
+	// Same defaults as the generated manifests
+	if data.ServicePort == 0 {
+		data.ServicePort = 80
+	}
+	data.CPURequest = valueOrDefault(data.CPURequest, "50m")
+	data.CPULimit = valueOrDefault(data.CPULimit, "200m")
+	data.MemoryRequest = valueOrDefault(data.MemoryRequest, "64Mi")
+	data.MemoryLimit = valueOrDefault(data.MemoryLimit, "256Mi")
+	data.LivenessPath = valueOrDefault(data.LivenessPath, "/")
+	data.ReadinessPath = valueOrDefault(data.ReadinessPath, "/")
+
+	files := map[string]string{
+		"templates/deployment.yaml": helmDeploymentTemplate,
+		"templates/service.yaml":    helmServiceTemplate,
+		"templates/route.yaml":      helmRouteTemplate,
+	}
+	var err error
+	if files["Chart.yaml"], err = renderTemplate("helm-chart", helmChartTemplate, data); err != nil {
+		return nil, err
+	}
+	if files["values.yaml"], err = renderTemplate("helm-values", helmValuesTemplate, data); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Scaffold a Helm chart for a repo
+func (s *Server) repoGenerateHelm(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	detection := detectRepoApp(ctx, config)
+	data := ManifestData{
+		AppName:   config.Name,
+		Namespace: config.Namespace,
+		ImageName: config.ImageName,
+		ImageTag:  getStringArg(args, "image_tag", "latest"),
+		Port:      detection.Port,
+		Replicas:  getIntArg(args, "replicas", 1),
+		RouteHost: getStringArg(args, "host", ""),
+	}
+	if data.Replicas < 1 {
+		return NewTextResult("", fmt.Errorf("replicas must be at least 1")), nil
+	}
+	if err := containerSpecArgs(args, &data); err != nil {
+		return NewTextResult("", err), nil
+	}
+	routeType, err := s.resolveRouteType(ctx, getStringArg(args, "route_type", routeTypeAuto))
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	data.RouteType = routeType
+	files, err := generateHelmChart(data)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to generate Helm chart: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"status":     "success",
+		"repository": config.Name,
+		"app_type":   detection.Type,
+		"detection":  detection,
+		"files":      files,
+		"next_steps": []string{
+			fmt.Sprintf("Write the files to a '%s' chart directory and commit it to your GitOps repository", config.Name),
+			fmt.Sprintf("Preview the manifests with 'helm template %s ./%s'", config.Name, config.Name),
+			fmt.Sprintf("Install with 'helm upgrade --install %s ./%s -n %s'", config.Name, config.Name, config.Namespace),
+		},
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGenerateHelmChart(t *testing.T) {
+	files, err := generateHelmChart(ManifestData{
+		AppName:      "app",
+		Namespace:    "dev",
+		ImageName:    "quay.io/team/app",
+		ImageTag:     "v1",
+		Port:         8080,
+		Replicas:     2,
+		RouteType:    routeTypeRoute,
+		Env:          map[string]string{"MODE": "prod"},
+		MemoryLimit:  "1Gi",
+		LivenessPath: "/healthz",
+	})
+	if err != nil {
+		t.Fatalf("failed to generate chart: %v", err)
+	}
+
+	buffered := make([]*loader.BufferedFile, 0, len(files))
+	for name, content := range files {
+		buffered = append(buffered, &loader.BufferedFile{Name: name, Data: []byte(content)})
+	}
+	chart, err := loader.LoadFiles(buffered)
+	if err != nil {
+		t.Fatalf("invalid chart: %v", err)
+	}
+	values, err := chartutil.ToRenderValues(chart, nil, chartutil.ReleaseOptions{Name: "app", Namespace: "dev", IsInstall: true}, chartutil.DefaultCapabilities)
+	if err != nil {
+		t.Fatalf("invalid values: %v", err)
+	}
+	rendered, err := engine.Render(chart, values)
+	if err != nil {
+		t.Fatalf("failed to render chart: %v", err)
+	}
+
+	objects := make(map[string]map[string]interface{})
+	for name, manifest := range rendered {
+		if strings.TrimSpace(manifest) == "" {
+			continue
+		}
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(manifest), &object); err != nil {
+			t.Fatalf("invalid YAML rendered from %s: %v\n%s", name, err, manifest)
+		}
+		objects[object["kind"].(string)] = object
+	}
+	for _, kind := range []string{"Deployment", "Service", "Route"} {
+		if _, exists := objects[kind]; !exists {
+			t.Errorf("expected a %s to be rendered, got %v", kind, rendered)
+		}
+	}
+	deployment, _ := yaml.Marshal(objects["Deployment"])
+	for _, expected := range []string{"replicas: 2", "image: quay.io/team/app:v1", "path: /healthz", "memory: 1Gi", "value: prod"} {
+		if !strings.Contains(string(deployment), expected) {
+			t.Errorf("expected %q in the rendered Deployment:\n%s", expected, deployment)
+		}
+	}
+}
+
+func TestGenerateHelmChartWithoutRoute(t *testing.T) {
+	files, err := generateHelmChart(ManifestData{AppName: "app", ImageName: "quay.io/team/app", ImageTag: "v1", Port: 8080, Replicas: 1, RouteType: routeTypeIngress})
+	if err != nil {
+		t.Fatalf("failed to generate chart: %v", err)
+	}
+	var values struct {
+		Env   map[string]string `json:"env"`
+		Route struct {
+			Enabled bool `json:"enabled"`
+		} `json:"route"`
+		Probes map[string]string `json:"probes"`
+	}
+	if err := yaml.Unmarshal([]byte(files["values.yaml"]), &values); err != nil {
+		t.Fatalf("invalid values.yaml: %v\n%s", err, files["values.yaml"])
+	}
+	if values.Route.Enabled || len(values.Env) != 0 || values.Probes["readinessPath"] != "/" {
+		t.Errorf("unexpected values %+v", values)
+	}
+}
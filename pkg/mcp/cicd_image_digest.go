@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// resolveImageDigest resolves an image reference to its manifest digest, reusing cached lookups unless noCache is set
+func (s *Server) resolveImageDigest(ctx context.Context, image string, noCache bool) (string, error) {
+	digest, err := s.registryCache.lookup(registryCacheKey("digest", image), noCache, func() (interface{}, error) {
+		return fetchImageDigest(ctx, image)
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest.(string), nil
+}
+
+// fetchImageDigest resolves an image reference to its manifest digest using skopeo
+func fetchImageDigest(ctx context.Context, image string) (string, error) {
+	if _, err := exec.LookPath("skopeo"); err != nil {
+		return "", fmt.Errorf("skopeo not found in PATH, digest comparison skipped")
+	}
+	output, err := exec.CommandContext(ctx, "skopeo", "inspect", "--format", "{{.Digest}}", "docker://"+image).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("failed to resolve digest: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("failed to resolve digest: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
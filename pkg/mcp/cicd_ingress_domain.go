@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+var (
+	ingressConfigGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Ingress"}
+	dnsConfigGVK     = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "DNS"}
+)
+
+// defaultClusterDomain is only used when the ingress domain can neither be discovered nor configured
+const defaultClusterDomain = "apps.rosa.sgaikwad.15fi.p3.openshiftapps.com"
+
+// clusterDomainTTL is how long the discovered ingress domain is reused, it only changes when the
+// cluster ingress is reconfigured
+const clusterDomainTTL = 10 * time.Minute
+
+// clusterDomain caches the discovered ingress domain of the cluster
+var clusterDomain struct {
+	sync.Mutex
+	value     string
+	fetchedAt time.Time
+}
+
+// clusterIngressDomain returns the domain the router generates Route hosts under. It is read from
+// the cluster Ingress config, or derived from the cluster DNS base domain, then $CLUSTER_INGRESS_DOMAIN.
+func (s *Server) clusterIngressDomain(ctx context.Context) string {
+	clusterDomain.Lock()
+	if clusterDomain.value != "" && time.Since(clusterDomain.fetchedAt) < clusterDomainTTL {
+		defer clusterDomain.Unlock()
+		return clusterDomain.value
+	}
+	clusterDomain.Unlock()
+
+	domain, err := s.liveClusterRead(ctx, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		return fetchClusterIngressDomain(ctx, k)
+	})
+	if err == nil {
+		clusterDomain.Lock()
+		clusterDomain.value, clusterDomain.fetchedAt = domain.(string), time.Now()
+		clusterDomain.Unlock()
+		return domain.(string)
+	}
+
+	klog.V(2).Infof("Cluster ingress domain discovery failed: %v", err)
+	if domain := os.Getenv("CLUSTER_INGRESS_DOMAIN"); domain != "" {
+		return domain
+	}
+	return defaultClusterDomain
+}
+
+// fetchClusterIngressDomain reads spec.domain of the cluster Ingress config, or apps.<baseDomain> from the cluster DNS config
+func fetchClusterIngressDomain(ctx context.Context, k *internalk8s.Kubernetes) (string, error) {
+	if ingress, err := k.ResourcesGet(ctx, &ingressConfigGVK, "", "cluster"); err == nil {
+		if domain, _, _ := unstructured.NestedString(ingress.Object, "spec", "domain"); domain != "" {
+			return domain, nil
+		}
+	}
+	dns, err := k.ResourcesGet(ctx, &dnsConfigGVK, "", "cluster")
+	if err != nil {
+		return "", fmt.Errorf("failed to read cluster ingress or DNS config: %v", err)
+	}
+	baseDomain, _, _ := unstructured.NestedString(dns.Object, "spec", "baseDomain")
+	if baseDomain == "" {
+		return "", fmt.Errorf("cluster DNS config has no base domain")
+	}
+	return "apps." + baseDomain, nil
+}
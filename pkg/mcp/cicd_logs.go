@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deploymentLogs returns the logs of the newest pod of an application, or of all of its pods
+func (s *Server) deploymentLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	namespace := getStringArg(args, "namespace", "")
+	if config := findRepo(name); config != nil {
+		name = config.Name
+		if namespace == "" {
+			namespace = config.Namespace
+		}
+	}
+	if namespace == "" {
+		return NewTextResult("", fmt.Errorf("namespace parameter is required for applications not added with 'repo_add'")), nil
+	}
+	options := &corev1.PodLogOptions{
+		Container: getStringArg(args, "container", ""),
+		Previous:  getBoolArg(args, "previous", false),
+	}
+	if tailLines := int64(getIntArg(args, "tail_lines", 100)); tailLines > 0 {
+		options.TailLines = &tailLines
+	}
+	if since := getStringArg(args, "since", ""); since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil || duration <= 0 {
+			return NewTextResult("", fmt.Errorf("invalid since '%s', expected a duration such as '10m' or '1h'", since)), nil
+		}
+		sinceSeconds := int64(duration.Seconds())
+		options.SinceSeconds = &sinceSeconds
+	}
+	allPods := getBoolArg(args, "all_pods", false)
+
+	if s.k == nil {
+		return NewTextResult("", fmt.Errorf("kubernetes manager is not initialized")), nil
+	}
+	k8s, err := s.k.Derived(ctx)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to access cluster: %v", err)), nil
+	}
+	pods, err := k8s.Pods(namespace)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: "app=" + name})
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to list pods of application '%s': %v", name, err)), nil
+	}
+	if len(list.Items) == 0 {
+		return NewTextResult("", fmt.Errorf("no pods found for application '%s' in namespace '%s' (selector app=%s)", name, namespace, name)), nil
+	}
+	// Newest first, the pod of the latest rollout
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[j].CreationTimestamp.Before(&list.Items[i].CreationTimestamp)
+	})
+	selected := list.Items[:1]
+	if allPods {
+		selected = list.Items
+	}
+
+	// Pods whose containers never started have no logs, report their phase instead
+	if !options.Previous && !anyContainerStarted(selected) {
+		var report strings.Builder
+		fmt.Fprintf(&report, "No pod of application '%s' has started yet:\n", name)
+		for i := range selected {
+			fmt.Fprintf(&report, "- %s: %s\n", selected[i].Name, podPhaseSummary(&selected[i]))
+		}
+		return NewTextResult(report.String(), nil), nil
+	}
+
+	var output strings.Builder
+	for i := range selected {
+		pod := &selected[i]
+		logs, err := pods.GetLogs(pod.Name, options).Do(ctx).Raw()
+		if allPods {
+			fmt.Fprintf(&output, "==> pod/%s (%s) <==\n", pod.Name, podPhaseSummary(pod))
+		}
+		switch {
+		case err != nil && !allPods:
+			return NewTextResult("", fmt.Errorf("failed to get logs of pod '%s' (%s): %v", pod.Name, podPhaseSummary(pod), err)), nil
+		case err != nil:
+			fmt.Fprintf(&output, "failed to get logs: %v\n", err)
+		case len(logs) == 0 && !allPods:
+			return NewTextResult(fmt.Sprintf("Pod %s (%s) has not logged anything", pod.Name, podPhaseSummary(pod)), nil), nil
+		default:
+			output.Write(logs)
+		}
+		if allPods && i < len(selected)-1 {
+			output.WriteString("\n")
+		}
+	}
+	return NewTextResult(output.String(), nil), nil
+}
+
+// anyContainerStarted reports whether a container of any of the pods is running or has run
+func anyContainerStarted(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Running != nil || status.State.Terminated != nil || status.RestartCount > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// podPhaseSummary describes a pod's phase, with the reason a container is waiting if any
+func podPhaseSummary(pod *corev1.Pod) string {
+	summary := string(pod.Status.Phase)
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" {
+			summary += ", " + waiting.Reason
+			if waiting.Message != "" {
+				summary += ": " + waiting.Message
+			}
+			break
+		}
+	}
+	return summary
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// notificationClient posts pipeline notifications, a slow endpoint must not hold up anything else
+var notificationClient = &http.Client{Timeout: 10 * time.Second}
+
+// ExecutionNotification is the JSON payload posted to a repository's notify_url when a pipeline
+// execution completes
+type ExecutionNotification struct {
+	Repository  string `json:"repository"`
+	Commit      string `json:"commit,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Status      string `json:"status"`
+	Stage       string `json:"stage,omitempty"` // last stage reached
+	Duration    string `json:"duration"`
+	AppURL      string `json:"app_url,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// validateNotifyURL checks a notification endpoint is an absolute http(s) URL
+func validateNotifyURL(notifyURL string) error {
+	u, err := url.Parse(notifyURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("notify_url must be an http or https URL, got '%s'", notifyURL)
+	}
+	return nil
+}
+
+// newExecutionNotification summarizes a completed execution
+func newExecutionNotification(execution PipelineExecution) ExecutionNotification {
+	notification := ExecutionNotification{
+		Repository:  execution.Repository,
+		Commit:      execution.Commit,
+		Environment: execution.Environment,
+		Status:      execution.Status,
+		AppURL:      execution.AppURL,
+		Error:       execution.Error,
+	}
+	if len(execution.Stages) > 0 {
+		notification.Stage = execution.Stages[len(execution.Stages)-1].Name
+	}
+	if execution.FinishedAt != nil {
+		notification.Duration = execution.FinishedAt.Sub(execution.StartedAt).Round(time.Millisecond).String()
+	}
+	return notification
+}
+
+// notificationBody encodes a notification for an endpoint. Slack incoming webhooks only accept a
+// message text, every other endpoint receives the notification itself.
+func notificationBody(notifyURL string, notification ExecutionNotification) ([]byte, error) {
+	if u, err := url.Parse(notifyURL); err == nil && u.Host == "hooks.slack.com" {
+		text := fmt.Sprintf("Pipeline of *%s* %s", notification.Repository, notification.Status)
+		if notification.Commit != "" {
+			text += fmt.Sprintf(" for commit `%s`", commitImageTag(notification.Commit))
+		}
+		if notification.Stage != "" {
+			text += fmt.Sprintf(" at stage %s", notification.Stage)
+		}
+		text += fmt.Sprintf(" in %s", notification.Duration)
+		if notification.Error != "" {
+			text += ": " + notification.Error
+		} else if notification.AppURL != "" {
+			text += ": " + notification.AppURL
+		}
+		return json.Marshal(map[string]string{"text": text})
+	}
+	return json.Marshal(notification)
+}
+
+// notifyExecution posts a completed execution to its repository's notify_url, if any. Delivery
+// failures are only logged, they never affect the pipeline.
+func notifyExecution(execution PipelineExecution) {
+	config := findRepo(execution.Repository)
+	if config == nil || config.NotifyURL == "" {
+		return
+	}
+	body, err := notificationBody(config.NotifyURL, newExecutionNotification(execution))
+	if err != nil {
+		klog.Warningf("Failed to encode the notification of pipeline execution %s: %v", execution.ID, err)
+		return
+	}
+	resp, err := notificationClient.Post(config.NotifyURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		klog.Warningf("Failed to notify pipeline execution %s: %v", execution.ID, err)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		klog.Warningf("Notification endpoint of %s rejected pipeline execution %s: %s", execution.Repository, execution.ID, resp.Status)
+	}
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifyExecution(t *testing.T) {
+	received := make(chan ExecutionNotification, 1)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification ExecutionNotification
+		if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+			t.Errorf("invalid notification: %v", err)
+		}
+		received <- notification
+	}))
+	defer endpoint.Close()
+
+	putRepo("notify-test", &RepoConfig{Name: "notify-test", NotifyURL: endpoint.URL})
+	t.Cleanup(func() { removeRepo("notify-test") })
+
+	history := newExecutionHistory()
+	execution := history.start("notify-test", "a1b2c3d4e5f6", "dev")
+	history.stage(execution, "build", "succeeded", "")
+	history.stage(execution, "push", "failed", "unauthorized")
+	history.finish(execution, "", "push failed: unauthorized")
+
+	select {
+	case notification := <-received:
+		if notification.Status != "failed" || notification.Stage != "push" || notification.Commit != "a1b2c3d4e5f6" {
+			t.Errorf("unexpected notification %+v", notification)
+		}
+		if notification.Error != "push failed: unauthorized" || notification.Duration == "" {
+			t.Errorf("expected the error and duration, got %+v", notification)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+}
+
+func TestNotificationBody(t *testing.T) {
+	notification := ExecutionNotification{Repository: "app", Commit: "a1b2c3d4e5f6", Status: "succeeded", Stage: "deploy", Duration: "42s", AppURL: "https://app.example.com"}
+	body, err := notificationBody("https://hooks.slack.com/services/T000/B000/XXX", notification)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var message map[string]string
+	if err := json.Unmarshal(body, &message); err != nil {
+		t.Fatalf("invalid Slack message: %v", err)
+	}
+	if text := message["text"]; !strings.Contains(text, "*app* succeeded") || !strings.Contains(text, "`a1b2c3d4`") || !strings.Contains(text, "https://app.example.com") {
+		t.Errorf("unexpected Slack message %q", text)
+	}
+	if err := validateNotifyURL("ftp://example.com"); err == nil {
+		t.Error("expected non-http notify_url to be rejected")
+	}
+}
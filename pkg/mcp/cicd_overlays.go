@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Kustomize templates for GitOps overlay generation
+const baseKustomizationTemplate = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+- service.yaml
+- route.yaml
+`
+
+const overlayKustomizationTemplate = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: {{.Namespace}}
+resources:
+- ../../base
+images:
+- name: {{.ImageName}}
+  newTag: "{{.ImageTag}}"
+replicas:
+- name: {{.AppName}}
+  count: {{.Replicas}}
+{{- if .Patch}}
+patches:
+- path: deployment-patch.yaml
+{{- end}}
+`
+
+const overlayDeploymentPatchTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.AppName}}
+spec:
+  template:
+    spec:
+      containers:
+      - name: {{.AppName}}
+{{- if .Env}}
+        env:
+{{- range $name, $value := .Env}}
+        - name: {{$name}}
+          value: {{printf "%q" $value}}
+{{- end}}
+{{- end}}
+{{- if .Resources}}
+        resources:
+          requests:
+            memory: "{{.MemoryRequest}}"
+            cpu: "{{.CPURequest}}"
+          limits:
+            memory: "{{.MemoryLimit}}"
+            cpu: "{{.CPULimit}}"
+{{- end}}
+`
+
+// defaultOverlayEnvironments are generated even when no overrides are configured for them
+var defaultOverlayEnvironments = []struct {
+	name     string
+	replicas int
+}{
+	{"dev", 1},
+	{"staging", 2},
+	{"prod", 3},
+}
+
+// overlayData is the template data for one environment overlay
+type overlayData struct {
+	ManifestData
+	Patch     bool
+	Resources bool
+}
+
+// generateOverlays renders a kustomize base and one overlay per environment, keyed by file path
+func generateOverlays(config *RepoConfig, port int, imageTag string) (map[string]string, []string, error) {
+	base := ManifestData{
+		AppName:   config.Name,
+		Namespace: config.Namespace,
+		ImageName: config.ImageName,
+		ImageTag:  imageTag,
+		Port:      port,
+		Replicas:  1,
+		Version:   "1.0.0",
+	}
+	manifests, err := generateManifests(base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := map[string]string{
+		"base/deployment.yaml": manifests["deployment.yaml"],
+		"base/service.yaml":    manifests["service.yaml"],
+		"base/route.yaml":      manifests["route.yaml"],
+	}
+	if files["base/kustomization.yaml"], err = renderTemplate("base-kustomization", baseKustomizationTemplate, base); err != nil {
+		return nil, nil, err
+	}
+
+	// Default environments first, then any other configured environment in name order
+	environments := make([]string, 0)
+	defaults := make(map[string]int)
+	for _, env := range defaultOverlayEnvironments {
+		environments = append(environments, env.name)
+		defaults[env.name] = env.replicas
+	}
+	configured := make([]string, 0)
+	for name := range config.Environments {
+		if _, isDefault := defaults[name]; !isDefault {
+			configured = append(configured, name)
+		}
+	}
+	sort.Strings(configured)
+	environments = append(environments, configured...)
+
+	for _, environment := range environments {
+		data := overlayData{ManifestData: base}
+		data.Namespace = fmt.Sprintf("%s-%s", config.Namespace, environment)
+		if replicas, ok := defaults[environment]; ok {
+			data.Replicas = replicas
+		}
+		override := config.Environments[environment]
+		applyEnvironmentOverride(&data.ManifestData, override)
+		if err := validateEnvNames(data.Env); err != nil {
+			return nil, nil, fmt.Errorf("environment '%s': %v", environment, err)
+		}
+		if override != nil {
+			data.Resources = override.CPURequest != "" || override.CPULimit != "" || override.MemoryRequest != "" || override.MemoryLimit != ""
+		}
+		data.Patch = len(data.Env) > 0 || data.Resources
+		// Unset resource overrides keep the base values
+		data.CPURequest = valueOrDefault(data.CPURequest, "50m")
+		data.CPULimit = valueOrDefault(data.CPULimit, "200m")
+		data.MemoryRequest = valueOrDefault(data.MemoryRequest, "64Mi")
+		data.MemoryLimit = valueOrDefault(data.MemoryLimit, "256Mi")
+
+		dir := "overlays/" + environment + "/"
+		if files[dir+"kustomization.yaml"], err = renderTemplate("overlay-kustomization", overlayKustomizationTemplate, data); err != nil {
+			return nil, nil, err
+		}
+		if data.Patch {
+			if files[dir+"deployment-patch.yaml"], err = renderTemplate("overlay-patch", overlayDeploymentPatchTemplate, data); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return files, environments, nil
+}
+
+func renderTemplate(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// Generate a kustomize base and per-environment overlays for a repo
+func (s *Server) repoGenerateOverlays(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	detection := detectRepoApp(ctx, config)
+	port, appType := detection.Port, detection.Type
+	files, environments, err := generateOverlays(config, port, getStringArg(args, "image_tag", "latest"))
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to generate overlays: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"status":       "success",
+		"repository":   config.Name,
+		"app_type":     appType,
+		"detection":    detection,
+		"environments": environments,
+		"files":        files,
+		"next_steps": []string{
+			"Commit the files to your GitOps repository",
+			"Preview an environment with 'kubectl kustomize overlays/<environment>'",
+			"Point an Argo CD or Flux application at each overlay directory",
+		},
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
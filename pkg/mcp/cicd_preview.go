@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+// ManifestPreview reports what applying a single manifest object would do
+type ManifestPreview struct {
+	Kind      string                 `json:"kind"`
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Action    string                 `json:"action"` // "create", "update", "unchanged" or "failed"
+	Error     string                 `json:"error,omitempty"`
+	Changes   []FieldChange          `json:"changes,omitempty"`
+	Object    map[string]interface{} `json:"object,omitempty"` // as returned by the server-side dry run
+}
+
+// FieldChange is a field of a manifest whose value differs from the live object
+type FieldChange struct {
+	Path    string      `json:"path"`
+	Current interface{} `json:"current"` // nil when the live object does not set the field
+	Desired interface{} `json:"desired"`
+}
+
+// previewManifestObjects reports, for each object of a multi-document manifest, whether it would be
+// created or updated. With dryRun each object goes through a server-side dry-run apply, validating
+// it without persisting anything; with diff the fields it sets are compared with the live object.
+func previewManifestObjects(ctx context.Context, k *internalk8s.Kubernetes, manifest string, dryRun, diff bool) []ManifestPreview {
+	previews := make([]ManifestPreview, 0)
+	newNamespaces := make(map[string]bool)
+	for _, doc := range manifestSeparator.Split(manifest, -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+			previews = append(previews, ManifestPreview{Action: "failed", Error: fmt.Sprintf("invalid manifest: %v", err)})
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		preview := ManifestPreview{Kind: gvk.Kind, Name: obj.GetName(), Namespace: obj.GetNamespace(), Action: "create"}
+		live, err := k.ResourcesGet(ctx, &gvk, obj.GetNamespace(), obj.GetName())
+		switch {
+		case err == nil:
+			preview.Action = "update"
+			if diff {
+				preview.Changes = diffObjects(obj.Object, live.Object)
+				if len(preview.Changes) == 0 {
+					preview.Action = "unchanged"
+				}
+			}
+		case apierrors.IsNotFound(err):
+			if gvk.Kind == "Namespace" {
+				newNamespaces[obj.GetName()] = true
+			}
+		default:
+			preview.Action = "failed"
+			preview.Error = err.Error()
+		}
+
+		if dryRun && preview.Action != "failed" {
+			if newNamespaces[obj.GetNamespace()] {
+				// The server rejects a dry run in a namespace that only the dry run created
+				preview.Error = fmt.Sprintf("not validated, namespace %s does not exist yet", obj.GetNamespace())
+			} else if applied, err := k.ResourcesApplyDryRun(ctx, doc); err != nil {
+				preview.Action = "failed"
+				preview.Error = err.Error()
+			} else if len(applied) > 0 {
+				preview.Object = applied[0].Object
+			}
+		}
+		previews = append(previews, preview)
+	}
+	return previews
+}
+
+// diffObjects compares the fields set by a desired object with a live object. Fields only the
+// live object sets (defaults, status, metadata added by the server) are not changes.
+func diffObjects(desired, live map[string]interface{}) []FieldChange {
+	changes := make([]FieldChange, 0)
+	var walk func(path string, desired, live interface{})
+	walk = func(path string, desired, live interface{}) {
+		switch d := desired.(type) {
+		case map[string]interface{}:
+			l, _ := live.(map[string]interface{})
+			keys := make([]string, 0, len(d))
+			for key := range d {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(strings.TrimPrefix(path+"."+key, "."), d[key], l[key])
+			}
+		case []interface{}:
+			l, _ := live.([]interface{})
+			if len(l) != len(d) {
+				changes = append(changes, FieldChange{Path: path, Current: live, Desired: desired})
+				return
+			}
+			for i := range d {
+				walk(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])
+			}
+		default:
+			// Numbers decode as float64 from the manifest and int64 from the cluster, compare their text
+			if (live == nil) != (desired == nil) || fmt.Sprint(desired) != fmt.Sprint(live) {
+				changes = append(changes, FieldChange{Path: path, Current: live, Desired: desired})
+			}
+		}
+	}
+	walk("", desired, live)
+	return changes
+}
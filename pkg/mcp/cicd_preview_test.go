@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestDiffObjects(t *testing.T) {
+	var desired, live map[string]interface{}
+	if err := yaml.Unmarshal([]byte(`
+metadata:
+  name: app
+  labels:
+    app: app
+spec:
+  replicas: 2
+  ports:
+  - port: 80
+`), &desired); err != nil {
+		t.Fatalf("failed to parse desired object: %v", err)
+	}
+	live = map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "app", "uid": "1234"},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"ports":    []interface{}{map[string]interface{}{"port": int64(80), "protocol": "TCP"}},
+		},
+		"status": map[string]interface{}{"readyReplicas": int64(1)},
+	}
+
+	changes := diffObjects(desired, live)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", changes)
+	}
+	if changes[0].Path != "metadata.labels.app" || changes[0].Current != nil || changes[0].Desired != "app" {
+		t.Errorf("unexpected label change %+v", changes[0])
+	}
+	if changes[1].Path != "spec.replicas" || changes[1].Current != int64(1) {
+		t.Errorf("unexpected replicas change %+v", changes[1])
+	}
+
+	if changes := diffObjects(desired, desired); len(changes) != 0 {
+		t.Errorf("expected no changes against itself, got %v", changes)
+	}
+}
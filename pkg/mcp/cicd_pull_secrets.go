@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+// pullSecretArgs reads the image_pull_secret, registry_username and registry_password arguments,
+// returning nil when the image is pulled without a secret. Inline credentials are for the
+// registry of the image.
+func pullSecretArgs(args map[string]interface{}, image string) (*cicd.ImagePullSecret, error) {
+	pullSecret := &cicd.ImagePullSecret{
+		Name:     getStringArg(args, "image_pull_secret", ""),
+		Registry: extractRegistryFromImage(image),
+		Username: getStringArg(args, "registry_username", ""),
+		Password: getStringArg(args, "registry_password", ""),
+	}
+	if (pullSecret.Username == "") != (pullSecret.Password == "") {
+		return nil, fmt.Errorf("registry_username and registry_password must be given together")
+	}
+	if pullSecret.Name == "" && !pullSecret.HasCredentials() {
+		return nil, nil
+	}
+	return pullSecret, nil
+}
+
+// applyPullSecret creates the pull secret of inline credentials, or checks the referenced one,
+// and links it to the namespace's default service account. Failing to link only warns since the
+// Deployment references the secret itself.
+func applyPullSecret(ctx context.Context, k *internalk8s.Kubernetes, namespace, app string, pullSecret *cicd.ImagePullSecret) (string, []string, error) {
+	warnings := make([]string, 0)
+	secrets, err := k.Secrets(namespace)
+	if err != nil {
+		return "", warnings, err
+	}
+	labels := map[string]string{"app": app, "app.kubernetes.io/managed-by": "ai-mcp-openshift-server"}
+	name, err := cicd.EnsurePullSecret(ctx, secrets, app, pullSecret, labels)
+	if err != nil {
+		return "", warnings, err
+	}
+	serviceAccounts, err := k.ServiceAccounts(namespace)
+	if err == nil {
+		_, err = cicd.LinkPullSecret(ctx, serviceAccounts, name)
+	}
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	return name, warnings, nil
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+// rollbackApplication restores an earlier revision of an application's Deployment
+func (s *Server) rollbackApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	namespace := getStringArg(args, "namespace", "")
+	if config := findRepo(name); config != nil {
+		name = config.Name
+		if namespace == "" {
+			namespace = config.Namespace
+		}
+	}
+	if namespace == "" {
+		return NewTextResult("", fmt.Errorf("namespace parameter is required for applications not added with 'repo_add'")), nil
+	}
+	revision := getIntArg(args, "revision", 0)
+	if revision < 0 {
+		return NewTextResult("", fmt.Errorf("revision must be a positive number")), nil
+	}
+	timeout := getIntArg(args, "timeout", 300)
+	if timeout > maxScaleTimeout {
+		timeout = maxScaleTimeout
+	}
+
+	if s.k == nil {
+		return NewTextResult("", fmt.Errorf("kubernetes manager is not initialized")), nil
+	}
+	k8s, err := s.k.Derived(ctx)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to access cluster: %v", err)), nil
+	}
+	deployments, err := k8s.Deployments(namespace)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	replicaSets, err := k8s.ReplicaSets(namespace)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	result, err := cicd.RollbackDeployment(ctx, deployments, replicaSets, namespace, name, int64(revision), time.Duration(timeout)*time.Second)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	status := "ready"
+	switch {
+	case result.Ready:
+	case timeout == 0:
+		status = "rolling_out"
+	default:
+		status = "timeout"
+	}
+	jsonResult, _ := json.MarshalIndent(map[string]interface{}{
+		"status":   status,
+		"rollback": result,
+		"message":  fmt.Sprintf("Rolled back %s from revision %d to revision %d (%s)", name, result.FromRevision, result.ToRevision, result.Image),
+	}, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
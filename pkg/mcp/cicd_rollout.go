@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deployStrategyArg reads the strategy argument of repo_deploy, defaulting to the strategy the
+// repository was last deployed with
+func deployStrategyArg(args map[string]interface{}, config *RepoConfig) (string, error) {
+	strategy := getStringArg(args, "strategy", config.Strategy)
+	switch strategy {
+	case "":
+		return cicd.StrategyRolling, nil
+	case cicd.StrategyRolling, cicd.StrategyRecreate, cicd.StrategyBlueGreen:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("strategy must be '%s', '%s' or '%s', got '%s'", cicd.StrategyRolling, cicd.StrategyRecreate, cicd.StrategyBlueGreen, strategy)
+}
+
+// deployedBlueGreen reports whether the live Deployment of an application selects a blue-green slot
+func deployedBlueGreen(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) bool {
+	deployments, err := k.Deployments(namespace)
+	if err != nil {
+		return false
+	}
+	deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err != nil || deployment.Spec.Selector == nil {
+		return false
+	}
+	_, exists := deployment.Spec.Selector.MatchLabels[cicd.SlotLabel]
+	return exists
+}
+
+// rolloutConfig translates the manifest data of an application into the configuration of
+// cicd.DeploymentAutomation. Unset resources keep the automation defaults.
+func rolloutConfig(data ManifestData, configData *cicd.DeploymentConfig, strategy string) cicd.DeploymentConfig {
+	config := cicd.DeploymentConfig{
+		Name:            data.AppName,
+		Namespace:       data.Namespace,
+		Image:           data.ImageName,
+		Tag:             data.ImageTag,
+		Replicas:        int32(max(data.Replicas, 1)),
+		Port:            int32(data.Port),
+		EnvVars:         map[string]string{"PORT": fmt.Sprintf("%d", data.Port)},
+		Command:         data.Command,
+		Args:            data.Args,
+		Strategy:        strategy,
+		LivenessPath:    &data.LivenessPath,
+		ReadinessPath:   &data.ReadinessPath,
+		StartupPath:     &data.StartupPath,
+		LivenessTiming:  &data.LivenessTiming,
+		ReadinessTiming: &data.ReadinessTiming,
+		StartupTiming:   &data.StartupTiming,
+	}
+	for name, value := range data.Env {
+		config.EnvVars[name] = value
+	}
+	if data.DisableLivenessProbe {
+		config.LivenessPath = new(string)
+	} else if data.LivenessPath == "" {
+		config.LivenessPath = nil
+	}
+	if data.DisableReadinessProbe {
+		config.ReadinessPath = new(string)
+	} else if data.ReadinessPath == "" {
+		config.ReadinessPath = nil
+	}
+	if data.CPURequest != "" || data.CPULimit != "" || data.MemoryRequest != "" || data.MemoryLimit != "" {
+		config.Resources = &cicd.ResourceRequirements{Requests: map[string]string{}, Limits: map[string]string{}}
+		for name, quantity := range map[string]string{"cpu": data.CPURequest, "memory": data.MemoryRequest} {
+			if quantity != "" {
+				config.Resources.Requests[name] = quantity
+			}
+		}
+		for name, quantity := range map[string]string{"cpu": data.CPULimit, "memory": data.MemoryLimit} {
+			if quantity != "" {
+				config.Resources.Limits[name] = quantity
+			}
+		}
+	}
+	if data.ImagePullSecret != "" {
+		config.ImagePullSecret = &cicd.ImagePullSecret{Name: data.ImagePullSecret}
+	}
+	if configData != nil {
+		config.ConfigMapMounts = configData.ConfigMapMounts
+		config.SecretMounts = configData.SecretMounts
+		config.SecretEnvFrom = configData.SecretEnvFrom
+	}
+	return config
+}
+
+// rolloutApplication deploys an application with cicd.DeploymentAutomation, which alternates the
+// blue-green slots the generated manifests cannot describe, then applies the generated Route or
+// Ingress in front of its service. It is used for the blue-green strategy and for applications
+// whose live Deployment is a blue-green slot.
+func rolloutApplication(ctx context.Context, k *internalk8s.Kubernetes, data ManifestData, configData *cicd.DeploymentConfig, strategy string, manifests map[string]string) (*cicd.DeploymentResult, []ManifestApplyResult, error) {
+	restConfig, err := k.ToRESTConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the cluster configuration: %v", err)
+	}
+	automation, err := cicd.NewDeploymentAutomation(restConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	rollout, err := automation.DeployApplication(ctx, rolloutConfig(data, configData, strategy))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !rollout.Success {
+		return rollout, []ManifestApplyResult{{Kind: "Deployment", Name: data.AppName, Namespace: data.Namespace, Action: "failed", Critical: true, Error: rollout.Error.Error()}}, nil
+	}
+	objects := []ManifestApplyResult{
+		{Kind: "Deployment", Name: rollout.ActiveDeployment, Namespace: data.Namespace, Action: "updated", Critical: true},
+		{Kind: "Service", Name: rollout.ServiceName, Namespace: data.Namespace, Action: "updated", Critical: true},
+	}
+	objects = append(objects, applyManifestObjects(ctx, k, manifests[exposureManifest(data.RouteType)])...)
+	return rollout, objects, nil
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+func TestDeployStrategyArg(t *testing.T) {
+	config := &RepoConfig{}
+	if strategy, err := deployStrategyArg(map[string]interface{}{}, config); err != nil || strategy != cicd.StrategyRolling {
+		t.Errorf("expected the rolling default, got %q, %v", strategy, err)
+	}
+	config.Strategy = cicd.StrategyBlueGreen
+	if strategy, err := deployStrategyArg(map[string]interface{}{}, config); err != nil || strategy != cicd.StrategyBlueGreen {
+		t.Errorf("expected the stored strategy, got %q, %v", strategy, err)
+	}
+	if strategy, err := deployStrategyArg(map[string]interface{}{"strategy": "recreate"}, config); err != nil || strategy != cicd.StrategyRecreate {
+		t.Errorf("expected the argument to win, got %q, %v", strategy, err)
+	}
+	if _, err := deployStrategyArg(map[string]interface{}{"strategy": "canary"}, config); err == nil {
+		t.Error("expected an unknown strategy to be rejected")
+	}
+}
+
+func TestRolloutConfig(t *testing.T) {
+	data := ManifestData{
+		AppName: "app", Namespace: "dev", ImageName: "quay.io/team/app", ImageTag: "v2", Port: 8080,
+		Env: map[string]string{"MODE": "prod"}, MemoryLimit: "1Gi", DisableReadinessProbe: true,
+		ImagePullSecret: "app-pull-secret",
+	}
+	configData := &cicd.DeploymentConfig{ConfigMapMounts: map[string]string{"app-config": "/etc/app"}}
+	config := rolloutConfig(data, configData, cicd.StrategyBlueGreen)
+	if config.Replicas != 1 || config.Tag != "v2" || config.Strategy != cicd.StrategyBlueGreen {
+		t.Errorf("unexpected rollout configuration %+v", config)
+	}
+	if config.EnvVars["PORT"] != "8080" || config.EnvVars["MODE"] != "prod" {
+		t.Errorf("expected PORT and the application env vars, got %v", config.EnvVars)
+	}
+	if config.LivenessPath != nil || config.ReadinessPath == nil || *config.ReadinessPath != "" {
+		t.Errorf("expected the default liveness probe and no readiness probe, got %v, %v", config.LivenessPath, config.ReadinessPath)
+	}
+	if config.Resources == nil || config.Resources.Limits["memory"] != "1Gi" || len(config.Resources.Requests) != 0 {
+		t.Errorf("expected only the memory limit, got %+v", config.Resources)
+	}
+	if config.ImagePullSecret == nil || config.ImagePullSecret.Name != "app-pull-secret" || config.ConfigMapMounts["app-config"] != "/etc/app" {
+		t.Errorf("expected the pull secret and mounts to be kept, got %+v", config)
+	}
+}
+
+func TestRecreateStrategyManifest(t *testing.T) {
+	data := ManifestData{AppName: "app", Namespace: "dev", ImageName: "quay.io/team/app", ImageTag: "v1", Port: 8080, Replicas: 1, Strategy: cicd.StrategyRecreate}
+	manifests, err := generateManifests(data)
+	if err != nil {
+		t.Fatalf("failed to generate manifests: %v", err)
+	}
+	if !strings.Contains(manifests["deployment.yaml"], "type: Recreate") {
+		t.Errorf("expected a Recreate strategy, got %s", manifests["deployment.yaml"])
+	}
+}
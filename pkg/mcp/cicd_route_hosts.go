@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+// HostConflict describes an existing Route or Ingress that already claims a host
+type HostConflict struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Host      string `json:"host"`
+}
+
+// findHostConflicts lists Routes and Ingresses in all namespaces that claim host, ignoring the
+// Route or Ingress named name in namespace, which is the object about to be created or updated.
+// It also returns every claimed host so an unused alternative can be suggested.
+func findHostConflicts(ctx context.Context, k *internalk8s.Kubernetes, host, namespace, name string) ([]HostConflict, map[string]bool, error) {
+	conflicts := make([]HostConflict, 0)
+	claimed := make(map[string]bool)
+	collect := func(kind, objNamespace, objName, objHost string) {
+		if objHost == "" {
+			return
+		}
+		claimed[objHost] = true
+		if objHost == host && !(objNamespace == namespace && objName == name) {
+			conflicts = append(conflicts, HostConflict{Kind: kind, Namespace: objNamespace, Name: objName, Host: objHost})
+		}
+	}
+
+	// Routes only exist on OpenShift, so a failed lookup is not fatal
+	if list, err := k.ResourcesList(ctx, &routeGVK, "", internalk8s.ResourceListOptions{}); err != nil {
+		klog.V(1).Infof("Skipping Route host check: %v", err)
+	} else if routes, ok := list.(*unstructured.UnstructuredList); ok {
+		for _, route := range routes.Items {
+			routeHost, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+			collect("Route", route.GetNamespace(), route.GetName(), routeHost)
+		}
+	}
+
+	list, err := k.ResourcesList(ctx, &ingressGVK, "", internalk8s.ResourceListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list ingresses: %v", err)
+	}
+	if ingresses, ok := list.(*unstructured.UnstructuredList); ok {
+		for _, ingress := range ingresses.Items {
+			rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+			for _, rule := range rules {
+				if r, ok := rule.(map[string]interface{}); ok {
+					ruleHost, _ := r["host"].(string)
+					collect("Ingress", ingress.GetNamespace(), ingress.GetName(), ruleHost)
+				}
+			}
+		}
+	}
+	return conflicts, claimed, nil
+}
+
+// suggestAlternativeHost returns a variant of host, numbering its first label, that is not claimed
+func suggestAlternativeHost(host string, claimed map[string]bool) string {
+	label, domain, _ := strings.Cut(host, ".")
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", label, i)
+		if domain != "" {
+			candidate += "." + domain
+		}
+		if !claimed[candidate] {
+			return candidate
+		}
+	}
+}
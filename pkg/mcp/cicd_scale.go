@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+// maxScaleTimeout bounds how long scale_application and rollback_application wait for the rollout
+const maxScaleTimeout = 600
+
+// scaleApplication sets the replica count of an application's Deployment
+func (s *Server) scaleApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	replicas, ok := args["replicas"].(float64)
+	if !ok {
+		return NewTextResult("", fmt.Errorf("replicas parameter is required")), nil
+	}
+	if replicas < 0 || replicas != float64(int32(replicas)) {
+		return NewTextResult("", fmt.Errorf("replicas must be a non-negative integer")), nil
+	}
+	namespace := getStringArg(args, "namespace", "")
+	if config := findRepo(name); config != nil {
+		name = config.Name
+		if namespace == "" {
+			namespace = config.Namespace
+		}
+	}
+	if namespace == "" {
+		return NewTextResult("", fmt.Errorf("namespace parameter is required for applications not added with 'repo_add'")), nil
+	}
+	timeout := getIntArg(args, "timeout", 120)
+	if timeout > maxScaleTimeout {
+		timeout = maxScaleTimeout
+	}
+
+	if s.k == nil {
+		return NewTextResult("", fmt.Errorf("kubernetes manager is not initialized")), nil
+	}
+	k8s, err := s.k.Derived(ctx)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to access cluster: %v", err)), nil
+	}
+	deployments, err := k8s.Deployments(namespace)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	result, err := cicd.ScaleDeployment(ctx, deployments, namespace, name, int32(replicas), time.Duration(timeout)*time.Second)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	status := "ready"
+	switch {
+	case result.Ready:
+	case timeout == 0:
+		status = "scaling"
+	default:
+		status = "timeout"
+	}
+	jsonResult, _ := json.MarshalIndent(map[string]interface{}{
+		"status": status,
+		"scale":  result,
+	}, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
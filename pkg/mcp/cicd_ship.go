@@ -0,0 +1,303 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+const (
+	// defaultShipReadyTimeout and maxShipReadyTimeout bound how long ship waits for the rollout
+	defaultShipReadyTimeout = 5 * time.Minute
+	maxShipReadyTimeout     = 30 * time.Minute
+	shipReadyPollInterval   = 5 * time.Second
+)
+
+// shipRun collects the stages of a ship and mirrors them into the pipeline execution history
+type shipRun struct {
+	execution *PipelineExecution
+	stages    []ExecutionStage
+}
+
+func (r *shipRun) stage(name, status, message string) {
+	r.stages = append(r.stages, ExecutionStage{Name: name, Status: status, Message: message})
+	pipelineExecutions.stage(r.execution, name, status, message)
+}
+
+// ship clones, builds, pushes and deploys a repository, rolling the deployment back if it does
+// not become ready
+func (s *Server) ship(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	url, ok := args["url"].(string)
+	if !ok || url == "" {
+		return NewTextResult("", fmt.Errorf("url parameter is required")), nil
+	}
+
+	namespace := getStringArg(args, "namespace", s.defaultNamespace())
+	if namespace == "" {
+		return NewTextResult("", fmt.Errorf("namespace parameter is required")), nil
+	}
+
+	repoName := getStringArg(args, "name", extractRepoName(url))
+	branch := getStringArg(args, "branch", "main")
+	registry, err := resolveRepoRegistry(getStringArg(args, "image_registry", s.defaultRegistry()))
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	imageName := getStringArg(args, "image_name", generateImageName(repoName, registry))
+	// A unique default tag keeps cleanup from ever deleting a tag other deployments use
+	imageTag := getStringArg(args, "image_tag", "ship-"+time.Now().UTC().Format("20060102150405"))
+	image := imageName + ":" + imageTag
+	port := getIntArg(args, "container_port", detectApp(ctx, url, branch, getStringArg(args, "build_context", "."), getStringArg(args, "dockerfile", "Dockerfile"), repoName).Port)
+	cleanupTag := getBoolArg(args, "cleanup_tag_on_failure", false)
+	readyTimeout := time.Duration(getIntArg(args, "ready_timeout", int(defaultShipReadyTimeout.Seconds()))) * time.Second
+	if readyTimeout <= 0 || readyTimeout > maxShipReadyTimeout {
+		return NewTextResult("", fmt.Errorf("ready_timeout must be between 1 and %d seconds", int(maxShipReadyTimeout.Seconds()))), nil
+	}
+
+	if s.k == nil {
+		return NewTextResult("", fmt.Errorf("kubernetes manager is not initialized")), nil
+	}
+	k, err := s.k.Derived(ctx)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to access cluster: %v", err)), nil
+	}
+
+	// Settings of a previous configuration ship does not set are kept
+	config := copyRepo(repoName)
+	added := config.URL == ""
+	config.URL = url
+	config.Name = repoName
+	config.Branch = branch
+	config.BuildContext = getStringArg(args, "build_context", ".")
+	config.DockerFile = getStringArg(args, "dockerfile", "Dockerfile")
+	config.ImageName = imageName
+	config.Registry = registry
+	config.Namespace = namespace
+	config.LastCommit = getStringArg(args, "commit", "")
+	config.Status = "shipping"
+	if notifyURL := getStringArg(args, "notify_url", ""); notifyURL != "" {
+		if err := validateNotifyURL(notifyURL); err != nil {
+			return NewTextResult("", err), nil
+		}
+		config.NotifyURL = notifyURL
+	}
+	putRepo(repoName, config)
+	if added {
+		s.watchRepo(config)
+	}
+
+	run := &shipRun{execution: pipelineExecutions.start(repoName, config.LastCommit, "")}
+	result := map[string]interface{}{
+		"repository":   repoName,
+		"image":        image,
+		"namespace":    namespace,
+		"execution_id": run.execution.ID,
+	}
+	fail := func(stage string, err error) (*mcp.CallToolResult, error) {
+		setRepoStatus(config, "failed")
+		pipelineExecutions.finish(run.execution, "", fmt.Sprintf("%s failed: %v", stage, err))
+		result["status"] = "failed"
+		result["failed_stage"] = stage
+		result["error"] = err.Error()
+		result["stages"] = run.stages
+		jsonResult, _ := json.MarshalIndent(result, "", "  ")
+		return NewTextResult(string(jsonResult), nil), nil
+	}
+
+	// Build with UBI and security validation, the build clones the repository itself
+	buildResult, err := s.performContainerBuildWithValidation(ctx, ContainerBuildConfig{
+		SourceType:   "git",
+		Source:       url,
+		Dockerfile:   config.DockerFile,
+		BuildContext: config.BuildContext,
+		ImageName:    image,
+		Registry:     registry,
+	}, branch, config.LastCommit, false, true, true, false, true)
+	if err != nil {
+		run.stage("build", "failed", err.Error())
+		return fail("build", err)
+	}
+	run.stage("build", "succeeded", fmt.Sprintf("built %s", image))
+	if validation, ok := buildResult["validation"]; ok {
+		result["validation"] = validation
+	}
+
+	auth := repoRegistryAuth(registry)
+	if _, err := s.performContainerPush(ctx, image, registry, auth.Username, auth.Password, nil, false, false); err != nil {
+		run.stage("push", "failed", err.Error())
+		return fail("push", err)
+	}
+	run.stage("push", "succeeded", fmt.Sprintf("pushed %s", image))
+
+	// Remember the live Deployment so a failed rollout can be reverted to it
+	previous, err := k.ResourcesGet(ctx, &deploymentGVK, namespace, repoName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			run.stage("deploy", "failed", err.Error())
+			return fail("deploy", fmt.Errorf("failed to read current deployment: %v", err))
+		}
+		previous = nil
+	}
+
+	deployErr := s.shipDeploy(ctx, k, run, config, imageTag, port, readyTimeout)
+	if deployErr == nil {
+		appURL := s.generateRouteURL(ctx, repoName, namespace)
+		setRepoStatus(config, "deployed")
+		pipelineExecutions.finish(run.execution, appURL, "")
+		result["status"] = "success"
+		result["message"] = fmt.Sprintf("Shipped %s to namespace '%s'", image, namespace)
+		result["url"] = appURL
+		result["stages"] = run.stages
+		jsonResult, _ := json.MarshalIndent(result, "", "  ")
+		return NewTextResult(string(jsonResult), nil), nil
+	}
+
+	if err := rollbackShip(ctx, k, previous, namespace, repoName); err != nil {
+		run.stage("rollback", "failed", err.Error())
+	} else if previous != nil {
+		run.stage("rollback", "succeeded", "restored the previous deployment")
+	} else {
+		run.stage("rollback", "succeeded", "removed the resources created by this ship")
+	}
+
+	if cleanupTag {
+		if err := deleteRemoteImage(ctx, image); err != nil {
+			run.stage("cleanup_tag", "failed", err.Error())
+		} else {
+			s.registryCache.invalidate(image)
+			run.stage("cleanup_tag", "succeeded", fmt.Sprintf("deleted %s", image))
+		}
+	}
+	return fail("deploy", deployErr)
+}
+
+// shipDeploy applies the generated manifests and waits for the Deployment to become ready
+func (s *Server) shipDeploy(ctx context.Context, k *internalk8s.Kubernetes, run *shipRun, config *RepoConfig, imageTag string, port int, readyTimeout time.Duration) error {
+	manifests, err := generateManifests(ManifestData{
+		AppName:   config.Name,
+		Namespace: config.Namespace,
+		ImageName: config.ImageName,
+		ImageTag:  imageTag,
+		Port:      port,
+		Replicas:  1,
+		Version:   imageTag,
+	})
+	if err != nil {
+		run.stage("deploy", "failed", err.Error())
+		return err
+	}
+
+	nsYAML := fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n  labels:\n    app.kubernetes.io/managed-by: ai-mcp-openshift-server\n", config.Namespace)
+	combinedYAML := combineManifests(nsYAML, manifests)
+	warnings := make([]string, 0)
+	for _, object := range deployManifestObjects(ctx, k, combinedYAML) {
+		if object.Action != "failed" {
+			continue
+		}
+		if object.Critical {
+			err := fmt.Errorf("failed to apply %s %s: %s", object.Kind, object.Name, object.Error)
+			run.stage("deploy", "failed", err.Error())
+			return err
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %s was not applied: %s", object.Kind, object.Name, object.Error))
+	}
+	run.stage("deploy", "succeeded", strings.Join(warnings, "; "))
+
+	if err := waitForDeploymentReady(ctx, k, config.Namespace, config.Name, readyTimeout); err != nil {
+		run.stage("wait_ready", "failed", err.Error())
+		return err
+	}
+	run.stage("wait_ready", "succeeded", "all replicas ready")
+	return nil
+}
+
+// waitForDeploymentReady polls the Deployment until its latest generation is fully rolled out
+func waitForDeploymentReady(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(shipReadyPollInterval)
+	defer ticker.Stop()
+
+	lastState := "not observed"
+	for {
+		deployment, err := k.ResourcesGet(ctx, &deploymentGVK, namespace, name)
+		if err == nil {
+			generation := deployment.GetGeneration()
+			observed, _, _ := unstructured.NestedInt64(deployment.Object, "status", "observedGeneration")
+			replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+			updated, _, _ := unstructured.NestedInt64(deployment.Object, "status", "updatedReplicas")
+			summary := summarizeDeployment(deployment)
+			lastState = fmt.Sprintf("%s replicas ready, %d/%d updated", summary["replicas"], updated, replicas)
+			if ready, _ := summary["ready"].(bool); ready && observed >= generation && updated == replicas {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("deployment %s/%s did not become ready within %s: %s", namespace, name, timeout, lastState)
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollbackShip restores the Deployment that was live before the ship, or removes the objects the
+// ship created when there was none
+func rollbackShip(ctx context.Context, k *internalk8s.Kubernetes, previous *unstructured.Unstructured, namespace, name string) error {
+	if previous == nil {
+		var errs []string
+		for _, gvk := range []schema.GroupVersionKind{routeGVK, serviceGVK, deploymentGVK} {
+			if err := k.ResourcesDelete(ctx, &gvk, namespace, name); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Sprintf("%s: %v", gvk.Kind, err))
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to remove created resources: %s", strings.Join(errs, "; "))
+		}
+		return nil
+	}
+
+	restored := previous.DeepCopy()
+	unstructured.RemoveNestedField(restored.Object, "status")
+	unstructured.RemoveNestedField(restored.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(restored.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(restored.Object, "metadata", "uid")
+	unstructured.RemoveNestedField(restored.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(restored.Object, "metadata", "generation")
+	manifest, err := yaml.Marshal(restored.Object)
+	if err != nil {
+		return fmt.Errorf("failed to serialize previous deployment: %v", err)
+	}
+	if _, err := k.ResourcesCreateOrUpdate(ctx, string(manifest)); err != nil {
+		return fmt.Errorf("failed to restore previous deployment: %v", err)
+	}
+	return nil
+}
+
+// deleteRemoteImage deletes an image tag from its registry using skopeo
+func deleteRemoteImage(ctx context.Context, image string) error {
+	if _, err := exec.LookPath("skopeo"); err != nil {
+		return fmt.Errorf("skopeo not found in PATH, pushed tag was not deleted")
+	}
+	output, err := exec.CommandContext(ctx, "skopeo", "delete", "docker://"+image).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %s", image, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -4,20 +4,30 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
-)
-
-// MCP-compliant logging setup
-var (
-	// Use stderr for logging to avoid corrupting JSON-RPC messages
-	mcpLogger = log.New(os.Stderr, "[MCP-CICD] ", log.LstdFlags|log.Lshortfile)
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+	"github.com/sur309/openshift-mcp-server/pkg/metrics"
 )
 
 // Repository configuration for CI/CD monitoring
@@ -32,7 +42,34 @@ type RepoConfig struct {
 	Namespace    string `json:"namespace"`
 	LastCommit   string `json:"last_commit,omitempty"`
 	Status       string `json:"status"`
+	LastError    string `json:"last_error,omitempty"`
 	Webhook      string `json:"webhook,omitempty"`
+	// Secret and accepted events of the push webhook registered with git_add_webhook
+	WebhookSecret string   `json:"webhook_secret,omitempty"`
+	WebhookEvents []string `json:"webhook_events,omitempty"`
+	// Private key used to clone SSH URLs, given to repo_add as a path or stored from PEM content
+	SSHKeyPath       string `json:"ssh_key_path,omitempty"`
+	SSHKeyPassphrase string `json:"ssh_key_passphrase,omitempty"`
+	// Per-environment deployment overrides keyed by environment name (e.g. dev, staging, prod)
+	Environments map[string]*EnvironmentOverride `json:"environments,omitempty"`
+	// Endpoint notified when a pipeline execution completes, a Slack incoming webhook or any HTTP endpoint
+	NotifyURL string `json:"notify_url,omitempty"`
+	// Active repositories run their pipeline on new commits, see cicd_set_pipeline_active
+	Active bool `json:"active"`
+	// Rollout strategy of repo_deploy, kept for the deployments of later commits
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// Environment-specific deployment overrides for a repository
+type EnvironmentOverride struct {
+	Namespace     string            `json:"namespace,omitempty"`
+	ImageTag      string            `json:"image_tag,omitempty"`
+	Replicas      int               `json:"replicas,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+	CPURequest    string            `json:"cpu_request,omitempty"`
+	CPULimit      string            `json:"cpu_limit,omitempty"`
+	MemoryRequest string            `json:"memory_request,omitempty"`
+	MemoryLimit   string            `json:"memory_limit,omitempty"`
 }
 
 // In-memory repository store (in production, this would be persistent storage)
@@ -55,8 +92,15 @@ metadata:
   labels:
     app: {{.AppName}}
     version: "{{.Version}}"
+    app.kubernetes.io/managed-by: ai-mcp-openshift-server
 spec:
+{{- if not .MaxReplicas}}
   replicas: {{.Replicas}}
+{{- end}}
+{{- if eq .Strategy "recreate"}}
+  strategy:
+    type: Recreate
+{{- end}}
   selector:
     matchLabels:
       app: {{.AppName}}
@@ -70,10 +114,26 @@ spec:
         runAsNonRoot: true
         seccompProfile:
           type: RuntimeDefault
+{{- if .ImagePullSecret}}
+      imagePullSecrets:
+      - name: {{.ImagePullSecret}}
+{{- end}}
       containers:
       - name: {{.AppName}}
         image: {{.ImageName}}:{{.ImageTag}}
         imagePullPolicy: Always
+{{- if .Command}}
+        command:
+{{- range .Command}}
+        - {{printf "%q" .}}
+{{- end}}
+{{- end}}
+{{- if .Args}}
+        args:
+{{- range .Args}}
+        - {{printf "%q" .}}
+{{- end}}
+{{- end}}
         securityContext:
           allowPrivilegeEscalation: false
           capabilities:
@@ -87,25 +147,85 @@ spec:
         env:
         - name: PORT
           value: "{{.Port}}"
+{{- range $name, $value := .Env}}
+        - name: {{$name}}
+          value: {{printf "%q" $value}}
+{{- end}}
+{{- if .SecretEnvFrom}}
+        envFrom:
+{{- range .SecretEnvFrom}}
+        - secretRef:
+            name: {{.}}
+{{- end}}
+{{- end}}
+{{- if or .ConfigMapMounts .SecretMounts}}
+        volumeMounts:
+{{- range $name, $path := .ConfigMapMounts}}
+        - name: {{volumeName "cm" $name}}
+          mountPath: {{printf "%q" $path}}
+          readOnly: true
+{{- end}}
+{{- range $name, $path := .SecretMounts}}
+        - name: {{volumeName "secret" $name}}
+          mountPath: {{printf "%q" $path}}
+          readOnly: true
+{{- end}}
+{{- end}}
         resources:
           requests:
-            memory: "64Mi"
-            cpu: "50m"
+            memory: "{{.MemoryRequest}}"
+            cpu: "{{.CPURequest}}"
           limits:
-            memory: "256Mi"
-            cpu: "200m"
+            memory: "{{.MemoryLimit}}"
+            cpu: "{{.CPULimit}}"
+{{- if not .DisableLivenessProbe}}
         livenessProbe:
           httpGet:
-            path: /
+            path: {{.LivenessPath}}
             port: http
-          initialDelaySeconds: 30
-          periodSeconds: 10
+{{- template "probeTiming" .LivenessTiming}}
+{{- end}}
+{{- if not .DisableReadinessProbe}}
         readinessProbe:
           httpGet:
-            path: /
+            path: {{.ReadinessPath}}
             port: http
-          initialDelaySeconds: 5
-          periodSeconds: 5
+{{- template "probeTiming" .ReadinessTiming}}
+{{- end}}
+{{- if .StartupPath}}
+        startupProbe:
+          httpGet:
+            path: {{.StartupPath}}
+            port: http
+{{- template "probeTiming" .StartupTiming}}
+{{- end}}
+{{- if or .ConfigMapMounts .SecretMounts}}
+      volumes:
+{{- range $name, $path := .ConfigMapMounts}}
+      - name: {{volumeName "cm" $name}}
+        configMap:
+          name: {{$name}}
+{{- end}}
+{{- range $name, $path := .SecretMounts}}
+      - name: {{volumeName "secret" $name}}
+        secret:
+          secretName: {{$name}}
+{{- end}}
+{{- end}}
+{{- define "probeTiming"}}
+{{- if .InitialDelaySeconds}}
+          initialDelaySeconds: {{.InitialDelaySeconds}}
+{{- end}}
+{{- if .PeriodSeconds}}
+          periodSeconds: {{.PeriodSeconds}}
+{{- end}}
+{{- if .TimeoutSeconds}}
+          timeoutSeconds: {{.TimeoutSeconds}}
+{{- end}}
+{{- if .FailureThreshold}}
+          failureThreshold: {{.FailureThreshold}}
+{{- end}}
+{{- end}}
 `
 
 const serviceTemplate = `apiVersion: v1
@@ -120,8 +240,8 @@ spec:
     app: {{.AppName}}
   ports:
   - name: http
-    port: 80
-    targetPort: {{.Port}}
+    port: {{.ServicePort}}
+    targetPort: {{.TargetPort}}
   type: ClusterIP
 `
 
@@ -135,6 +255,9 @@ metadata:
   annotations:
     haproxy.router.openshift.io/timeout: 60s
 spec:
+{{- if .RouteHost}}
+  host: {{.RouteHost}}
+{{- end}}
   to:
     kind: Service
     name: {{.AppName}}
@@ -147,6 +270,60 @@ spec:
   wildcardPolicy: None
 `
 
+// ingressTemplate exposes applications on clusters without the OpenShift Route API
+const ingressTemplate = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: {{.AppName}}
+  namespace: {{.Namespace}}
+  labels:
+    app: {{.AppName}}
+spec:
+{{- if .IngressClass}}
+  ingressClassName: {{.IngressClass}}
+{{- end}}
+  rules:
+{{- if .RouteHost}}
+  - host: {{.RouteHost}}
+    http:
+{{- else}}
+  - http:
+{{- end}}
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: {{.AppName}}
+            port:
+              name: http
+`
+
+// The Deployment leaves its replicas to the autoscaler, so redeploying does not reset them
+const hpaTemplate = `apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{.AppName}}
+  namespace: {{.Namespace}}
+  labels:
+    app: {{.AppName}}
+    app.kubernetes.io/managed-by: ai-mcp-openshift-server
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{.AppName}}
+  minReplicas: {{.MinReplicas}}
+  maxReplicas: {{.MaxReplicas}}
+  metrics:
+  - type: Resource
+    resource:
+      name: cpu
+      target:
+        type: Utilization
+        averageUtilization: {{.TargetCPUUtilization}}
+`
+
 // Template data for manifest generation
 type ManifestData struct {
 	AppName   string
@@ -156,14 +333,297 @@ type ManifestData struct {
 	Port      int
 	Replicas  int
 	Version   string
+	// Optional, defaults are applied by generateManifests
+	ServicePort   int
+	TargetPort    string // container port number or name, defaults to the named container port
+	Env           map[string]string
+	Command       []string // overrides the image entrypoint
+	Args          []string // overrides the image CMD
+	CPURequest    string
+	CPULimit      string
+	MemoryRequest string
+	MemoryLimit   string
+	LivenessPath  string // HTTP path of the liveness probe, defaults to /
+	ReadinessPath string // HTTP path of the readiness probe, defaults to /
+	StartupPath   string // HTTP path of the startup probe, none when empty
+	// Optional, drop the liveness or readiness probe of workloads that do not serve HTTP
+	DisableLivenessProbe  bool
+	DisableReadinessProbe bool
+	// Optional, zero fields keep the timings of cicd.DefaultLivenessTiming and the like
+	LivenessTiming  cicd.ProbeTiming
+	ReadinessTiming cicd.ProbeTiming
+	StartupTiming   cicd.ProbeTiming
+	// Optional, ConfigMaps and Secrets mounted read-only by name to mount path, and Secrets whose
+	// keys are loaded as environment variables
+	ConfigMapMounts map[string]string
+	SecretMounts    map[string]string
+	SecretEnvFrom   []string
+	// Optional, "recreate" replaces every pod at once instead of the default rolling update
+	Strategy string
+	// Optional, the cluster assigns a host when empty. Also the host of an Ingress, which matches
+	// any host without one.
+	RouteHost string
+	// Optional, exposes the application with an Ingress instead of a Route when "ingress"
+	RouteType    string
+	IngressClass string
+	// Optional, secret used to pull the image from a private registry
+	ImagePullSecret string
+	// Optional, a HorizontalPodAutoscaler is generated when MaxReplicas is set. MinReplicas
+	// defaults to Replicas and TargetCPUUtilization to 80 percent of the CPU request.
+	MinReplicas          int
+	MaxReplicas          int
+	TargetCPUUtilization int
+}
+
+// autoscalingArgs reads the min_replicas, max_replicas and target_cpu_utilization arguments into
+// the manifest data
+func autoscalingArgs(args map[string]interface{}, data *ManifestData) error {
+	data.MinReplicas = getIntArg(args, "min_replicas", 0)
+	data.MaxReplicas = getIntArg(args, "max_replicas", 0)
+	data.TargetCPUUtilization = getIntArg(args, "target_cpu_utilization", 0)
+	if data.MaxReplicas == 0 && (data.MinReplicas != 0 || data.TargetCPUUtilization != 0) {
+		return fmt.Errorf("max_replicas is required to enable autoscaling")
+	}
+	return nil
+}
+
+// containerSpecArgs reads the cpu_request, cpu_limit, memory_request, memory_limit, env and probe
+// arguments into the manifest data. Env vars are merged with those already set.
+func containerSpecArgs(args map[string]interface{}, data *ManifestData) error {
+	data.CPURequest = getStringArg(args, "cpu_request", data.CPURequest)
+	data.CPULimit = getStringArg(args, "cpu_limit", data.CPULimit)
+	data.MemoryRequest = getStringArg(args, "memory_request", data.MemoryRequest)
+	data.MemoryLimit = getStringArg(args, "memory_limit", data.MemoryLimit)
+	for _, quantity := range []string{data.CPURequest, data.CPULimit, data.MemoryRequest, data.MemoryLimit} {
+		if _, err := resource.ParseQuantity(quantity); quantity != "" && err != nil {
+			return fmt.Errorf("invalid resource quantity '%s': %v", quantity, err)
+		}
+	}
+	// An explicitly empty path disables the probe, for workloads that do not serve HTTP
+	if path, exists := args["liveness_path"].(string); exists {
+		data.LivenessPath, data.DisableLivenessProbe = path, path == ""
+	}
+	if path, exists := args["readiness_path"].(string); exists {
+		data.ReadinessPath, data.DisableReadinessProbe = path, path == ""
+	}
+	data.StartupPath = getStringArg(args, "startup_path", data.StartupPath)
+	for _, path := range []string{data.LivenessPath, data.ReadinessPath, data.StartupPath} {
+		if path != "" && !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("probe paths must start with '/', got '%s'", path)
+		}
+	}
+	for arg, timing := range map[string]*cicd.ProbeTiming{"liveness_timing": &data.LivenessTiming, "readiness_timing": &data.ReadinessTiming, "startup_timing": &data.StartupTiming} {
+		if err := probeTimingArg(args, arg, timing); err != nil {
+			return err
+		}
+	}
+	if env, exists := args["env"].(map[string]interface{}); exists {
+		merged := make(map[string]string, len(data.Env)+len(env))
+		for name, value := range data.Env {
+			merged[name] = value
+		}
+		for name, value := range env {
+			merged[name] = fmt.Sprintf("%v", value)
+		}
+		if err := validateEnvNames(merged); err != nil {
+			return err
+		}
+		data.Env = merged
+	}
+	return nil
+}
+
+// probeTimingArg reads a probe timing object argument with initial_delay_seconds, period_seconds,
+// timeout_seconds and failure_threshold fields, unset fields keep the current value
+func probeTimingArg(args map[string]interface{}, arg string, timing *cicd.ProbeTiming) error {
+	fields, exists := args[arg].(map[string]interface{})
+	if !exists {
+		return nil
+	}
+	for field, value := range map[string]*int32{
+		"initial_delay_seconds": &timing.InitialDelaySeconds,
+		"period_seconds":        &timing.PeriodSeconds,
+		"timeout_seconds":       &timing.TimeoutSeconds,
+		"failure_threshold":     &timing.FailureThreshold,
+	} {
+		seconds := getIntArg(fields, field, int(*value))
+		if seconds < 0 {
+			return fmt.Errorf("%s.%s must not be negative, got %d", arg, field, seconds)
+		}
+		*value = int32(seconds)
+	}
+	return nil
+}
+
+// Route types accepted by the route_type argument
+const (
+	routeTypeAuto    = "auto"
+	routeTypeRoute   = "route"
+	routeTypeIngress = "ingress"
+)
+
+// exposureManifest returns the file of the object exposing the application, route.yaml or ingress.yaml
+func exposureManifest(routeType string) string {
+	if routeType == routeTypeIngress {
+		return "ingress.yaml"
+	}
+	return "route.yaml"
+}
+
+// resolveRouteType turns the route_type argument into route or ingress, auto picks a Route when
+// the cluster serves the Route API
+func (s *Server) resolveRouteType(ctx context.Context, routeType string) (string, error) {
+	switch routeType {
+	case routeTypeRoute, routeTypeIngress:
+		return routeType, nil
+	case "", routeTypeAuto:
+		if s.k != nil && !s.k.HasRouteAPI(ctx) {
+			return routeTypeIngress, nil
+		}
+		return routeTypeRoute, nil
+	}
+	return "", fmt.Errorf("route_type must be 'auto', 'route' or 'ingress', got '%s'", routeType)
+}
+
+// manifestFiles lists the generated manifest files in the order they are applied
+var manifestFiles = []string{"deployment.yaml", "service.yaml", "route.yaml", "ingress.yaml", "hpa.yaml"}
+
+// combineManifests joins the generated manifests in the order they are applied, after the namespace
+func combineManifests(nsYAML string, manifests map[string]string) string {
+	combined := nsYAML
+	for _, file := range manifestFiles {
+		if manifest, exists := manifests[file]; exists {
+			combined += "\n---\n" + manifest
+		}
+	}
+	return combined
+}
+
+// manifestsOutput renders a repo_generate_manifests result: "json" (the default) and "yaml" encode
+// the whole result, "raw" returns only the manifests as a multi-document YAML stream
+func manifestsOutput(format string, result map[string]interface{}, manifests map[string]string) (string, error) {
+	switch format {
+	case "", "json":
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		return string(jsonResult), err
+	case "yaml":
+		yamlResult, err := yaml.Marshal(result)
+		return string(yamlResult), err
+	case "raw":
+		documents := make([]string, 0, len(manifests))
+		for _, file := range manifestFiles {
+			if manifest := strings.TrimSpace(manifests[file]); manifest != "" {
+				documents = append(documents, manifest)
+			}
+		}
+		return "---\n" + strings.Join(documents, "\n---\n") + "\n", nil
+	}
+	return "", fmt.Errorf("format must be 'json', 'yaml' or 'raw', got '%s'", format)
+}
+
+// applyEnvironmentOverride applies the non-empty fields of an environment override to the manifest data
+func applyEnvironmentOverride(data *ManifestData, override *EnvironmentOverride) {
+	if override == nil {
+		return
+	}
+	if override.Namespace != "" {
+		data.Namespace = override.Namespace
+	}
+	if override.ImageTag != "" {
+		data.ImageTag = override.ImageTag
+	}
+	if override.Replicas > 0 {
+		data.Replicas = override.Replicas
+	}
+	if len(override.Env) > 0 {
+		data.Env = override.Env
+	}
+	if override.CPURequest != "" {
+		data.CPURequest = override.CPURequest
+	}
+	if override.CPULimit != "" {
+		data.CPULimit = override.CPULimit
+	}
+	if override.MemoryRequest != "" {
+		data.MemoryRequest = override.MemoryRequest
+	}
+	if override.MemoryLimit != "" {
+		data.MemoryLimit = override.MemoryLimit
+	}
+}
+
+// lookupEnvironment returns the overrides for an environment, nil when no environment is selected
+func lookupEnvironment(config *RepoConfig, environment string) (*EnvironmentOverride, error) {
+	if environment == "" {
+		return nil, nil
+	}
+	override, exists := config.Environments[environment]
+	if !exists {
+		return nil, fmt.Errorf("environment '%s' is not configured for repository '%s', use 'repo_env_set' to add it", environment, config.Name)
+	}
+	return override, nil
 }
 
 // Generate manifests from templates
 func generateManifests(data ManifestData) (map[string]string, error) {
 	manifests := make(map[string]string)
+	if err := validateEnvNames(data.Env); err != nil {
+		return nil, err
+	}
+
+	// Default ports: the Service listens on 80 and targets the named container port
+	if data.ServicePort == 0 {
+		data.ServicePort = 80
+	}
+	if data.TargetPort == "" {
+		data.TargetPort = "http"
+	}
+
+	// Default resources
+	if data.CPURequest == "" {
+		data.CPURequest = "50m"
+	}
+	if data.CPULimit == "" {
+		data.CPULimit = "200m"
+	}
+	if data.MemoryRequest == "" {
+		data.MemoryRequest = "64Mi"
+	}
+	if data.MemoryLimit == "" {
+		data.MemoryLimit = "256Mi"
+	}
+
+	// Default probes check the root path
+	if data.LivenessPath == "" {
+		data.LivenessPath = "/"
+	}
+	if data.ReadinessPath == "" {
+		data.ReadinessPath = "/"
+	}
+	data.LivenessTiming = data.LivenessTiming.WithDefaults(cicd.DefaultLivenessTiming)
+	data.ReadinessTiming = data.ReadinessTiming.WithDefaults(cicd.DefaultReadinessTiming)
+	data.StartupTiming = data.StartupTiming.WithDefaults(cicd.DefaultStartupTiming)
+
+	// Autoscaling defaults, utilization is relative to the CPU request defaulted above
+	if data.MaxReplicas > 0 {
+		if data.MinReplicas == 0 {
+			data.MinReplicas = max(data.Replicas, 1)
+		}
+		if data.TargetCPUUtilization == 0 {
+			data.TargetCPUUtilization = 80
+		}
+		switch {
+		case data.MinReplicas < 1:
+			return nil, fmt.Errorf("min_replicas must be at least 1, got %d", data.MinReplicas)
+		case data.MaxReplicas < data.MinReplicas:
+			return nil, fmt.Errorf("max_replicas (%d) must be at least min_replicas (%d)", data.MaxReplicas, data.MinReplicas)
+		case data.TargetCPUUtilization < 1 || data.TargetCPUUtilization > 100:
+			return nil, fmt.Errorf("target_cpu_utilization must be between 1 and 100 percent, got %d", data.TargetCPUUtilization)
+		}
+	}
 
 	// Parse and execute deployment template
-	deployTmpl, err := template.New("deployment").Parse(deploymentTemplate)
+	deployTmpl, err := template.New("deployment").Funcs(template.FuncMap{"volumeName": cicd.VolumeName}).Parse(deploymentTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse deployment template: %v", err)
 	}
@@ -186,8 +646,12 @@ func generateManifests(data ManifestData) (map[string]string, error) {
 	}
 	manifests["service.yaml"] = serviceBuf.String()
 
-	// Parse and execute route template
-	routeTmpl, err := template.New("route").Parse(routeTemplate)
+	// Parse and execute the route template, or the ingress template for clusters without Routes
+	exposure := routeTemplate
+	if data.RouteType == routeTypeIngress {
+		exposure = ingressTemplate
+	}
+	routeTmpl, err := template.New("route").Parse(exposure)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse route template: %v", err)
 	}
@@ -196,12 +660,197 @@ func generateManifests(data ManifestData) (map[string]string, error) {
 	if err := routeTmpl.Execute(&routeBuf, data); err != nil {
 		return nil, fmt.Errorf("failed to execute route template: %v", err)
 	}
-	manifests["route.yaml"] = routeBuf.String()
+	manifests[exposureManifest(data.RouteType)] = routeBuf.String()
+
+	if data.MaxReplicas > 0 {
+		hpaTmpl, err := template.New("hpa").Parse(hpaTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse autoscaler template: %v", err)
+		}
+
+		var hpaBuf bytes.Buffer
+		if err := hpaTmpl.Execute(&hpaBuf, data); err != nil {
+			return nil, fmt.Errorf("failed to execute autoscaler template: %v", err)
+		}
+		manifests["hpa.yaml"] = hpaBuf.String()
+	}
+
+	if err := validateManifestPorts(manifests, exposureManifest(data.RouteType)); err != nil {
+		return nil, err
+	}
 
 	return manifests, nil
 }
 
+// validateManifestPorts checks that every Service port targets a port the container listens on and
+// that the Route or Ingress targets a Service port that exists, so a mismatch fails before anything
+// is applied instead of surfacing as connection-refused
+func validateManifestPorts(manifests map[string]string, exposure string) error {
+	objects := make(map[string]map[string]interface{})
+	for _, file := range []string{"deployment.yaml", "service.yaml", exposure} {
+		obj := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(manifests[file]), &obj); err != nil {
+			return fmt.Errorf("invalid generated %s: %v", file, err)
+		}
+		objects[file] = obj
+	}
+
+	// Container ports by name and number
+	containerPorts := make(map[string]int64)
+	containerNumbers := make(map[int64]bool)
+	containers, _, _ := unstructured.NestedSlice(objects["deployment.yaml"], "spec", "template", "spec", "containers")
+	for _, c := range containers {
+		container, _ := c.(map[string]interface{})
+		ports, _, _ := unstructured.NestedSlice(container, "ports")
+		for _, p := range ports {
+			port, _ := p.(map[string]interface{})
+			number := toInt64(port["containerPort"])
+			if number < 1 || number > 65535 {
+				return fmt.Errorf("container port %d is out of range 1-65535", number)
+			}
+			containerNumbers[number] = true
+			if name, _, _ := unstructured.NestedString(port, "name"); name != "" {
+				containerPorts[name] = number
+			}
+		}
+	}
+
+	// Service ports must resolve to a container port
+	servicePorts := make(map[string]bool)
+	serviceTargets := make(map[int64]bool)
+	ports, _, _ := unstructured.NestedSlice(objects["service.yaml"], "spec", "ports")
+	for _, p := range ports {
+		port, _ := p.(map[string]interface{})
+		number := toInt64(port["port"])
+		if number < 1 || number > 65535 {
+			return fmt.Errorf("service port %d is out of range 1-65535", number)
+		}
+		switch target := port["targetPort"].(type) {
+		case string:
+			resolved, exists := containerPorts[target]
+			if !exists {
+				return fmt.Errorf("service port %d targets port '%s', but the container has no port with that name", number, target)
+			}
+			serviceTargets[resolved] = true
+		case int64, float64:
+			resolved := toInt64(target)
+			if !containerNumbers[resolved] {
+				return fmt.Errorf("service port %d targets port %d, but the container does not listen on it", number, resolved)
+			}
+			serviceTargets[resolved] = true
+		}
+		if name, _, _ := unstructured.NestedString(port, "name"); name != "" {
+			servicePorts[name] = true
+		}
+	}
+
+	// The Ingress must target a Service port by name
+	if exposure == "ingress.yaml" {
+		rules, _, _ := unstructured.NestedSlice(objects[exposure], "spec", "rules")
+		for _, r := range rules {
+			rule, _ := r.(map[string]interface{})
+			paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+			for _, p := range paths {
+				path, _ := p.(map[string]interface{})
+				if name, _, _ := unstructured.NestedString(path, "backend", "service", "port", "name"); name != "" && !servicePorts[name] {
+					return fmt.Errorf("ingress targets port '%s', but the service has no port with that name", name)
+				}
+			}
+		}
+		return nil
+	}
+
+	// The Route must target a Service port by name, or by target port number
+	routeTarget, _, _ := unstructured.NestedFieldNoCopy(objects[exposure], "spec", "port", "targetPort")
+	switch target := routeTarget.(type) {
+	case string:
+		if !servicePorts[target] {
+			return fmt.Errorf("route targets port '%s', but the service has no port with that name", target)
+		}
+	case int64, float64:
+		if !serviceTargets[toInt64(target)] {
+			return fmt.Errorf("route targets port %d, but the service does not target it", toInt64(target))
+		}
+	}
+	return nil
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// ManifestApplyResult reports the outcome of applying a single manifest object
+type ManifestApplyResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Action    string `json:"action"` // "created", "updated" or "failed"
+	Critical  bool   `json:"critical"`
+	Error     string `json:"error,omitempty"`
+}
+
+// manifestSeparator matches the document separator used by ResourcesCreateOrUpdate
+var manifestSeparator = regexp.MustCompile(`\r?\n---\r?\n`)
+
+// applyManifestObjects splits a multi-document manifest and applies each object individually,
+// so a failure on one object (e.g. a Route on a non-OpenShift cluster) does not hide the others.
+// Routes and autoscalers are not critical, the application runs without them.
+func applyManifestObjects(ctx context.Context, k *internalk8s.Kubernetes, manifest string) []ManifestApplyResult {
+	results := make([]ManifestApplyResult, 0)
+	for _, doc := range manifestSeparator.Split(manifest, -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+			results = append(results, ManifestApplyResult{Action: "failed", Critical: true, Error: fmt.Sprintf("invalid manifest: %v", err)})
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		result := ManifestApplyResult{
+			Kind:      gvk.Kind,
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Action:    "created",
+			Critical:  gvk.Kind != "Route" && gvk.Kind != "HorizontalPodAutoscaler",
+		}
+		if _, err := k.ResourcesGet(ctx, &gvk, obj.GetNamespace(), obj.GetName()); err == nil {
+			result.Action = "updated"
+		}
+		if _, err := k.ResourcesCreateOrUpdate(ctx, doc); err != nil {
+			result.Action = "failed"
+			result.Error = err.Error()
+			klog.Warningf("Failed to apply %s %s: %v", gvk.Kind, obj.GetName(), err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// deployManifestObjects applies the manifests of an application deployment as a tracked operation,
+// recording how long it took and whether every critical object was applied
+func deployManifestObjects(ctx context.Context, k *internalk8s.Kubernetes, manifest string) []ManifestApplyResult {
+	done := metrics.StartOperation(metrics.OperationDeploy)
+	results := applyManifestObjects(ctx, k, manifest)
+	applied := len(results) > 0
+	for _, result := range results {
+		if result.Action == "failed" && result.Critical {
+			applied = false
+		}
+	}
+	done(applied)
+	return results
+}
+
 // Detect application type and default port from repository structure
+// detectAppDetails guesses the application type and port from the repository name, it is the
+// fallback of detectApp when the repository contents cannot be read
 func detectAppDetails(repoName string) (port int, appType string) {
 	// Simple detection based on repository name and common patterns
 	lowerName := strings.ToLower(repoName)
@@ -230,43 +879,59 @@ func detectAppDetails(repoName string) (port int, appType string) {
 	return 8080, "web"
 }
 
-// MCP-compliant error formatting
-func formatMCPError(code, message string) string {
+// MCP-compliant error formatting, an optional cause is reported as details with its error chain
+func formatMCPError(code, message string, cause ...error) string {
+	mcpError := map[string]interface{}{
+		"code":    code,
+		"message": message,
+		"type":    "tool_execution_error",
+	}
+	if len(cause) > 0 && cause[0] != nil {
+		mcpError["details"] = errorChain(cause[0])
+	}
 	errorResponse := map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    code,
-			"message": message,
-			"type":    "tool_execution_error",
-		},
-		"timestamp": fmt.Sprintf("%d", os.Getpid()), // Simple timestamp
+		"error":     mcpError,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"help":      "Check parameter types and values. Use tool descriptions for guidance.",
 	}
 	jsonResult, _ := json.MarshalIndent(errorResponse, "", "  ")
 	return string(jsonResult)
 }
 
+// errorChain returns the messages of an error and of every error it wraps, outermost first
+func errorChain(err error) []string {
+	var chain []string
+	for ; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, err.Error())
+	}
+	return chain
+}
+
 // Generate route URL from app name and namespace
-func generateRouteURL(appName, namespace string) string {
-	// This would be configurable based on your OpenShift cluster
-	clusterDomain := "apps.rosa.sgaikwad.15fi.p3.openshiftapps.com"
-	return fmt.Sprintf("https://%s-%s.%s", appName, namespace, clusterDomain)
+// generateRouteURL returns the URL the router assigns to a Route without an explicit host
+func (s *Server) generateRouteURL(ctx context.Context, appName, namespace string) string {
+	return fmt.Sprintf("https://%s-%s.%s", appName, namespace, s.clusterIngressDomain(ctx))
 }
 
 // Generic CI/CD tools that work with any repository and namespace
 func (s *Server) initCicdSimple() []server.ServerTool {
-	mcpLogger.Println("Initializing CI/CD tools for multi-repository automation")
+	klog.V(1).Info("Initializing CI/CD tools for multi-repository automation")
 
 	return []server.ServerTool{
 		{Tool: mcp.NewTool("repo_add",
 			mcp.WithDescription("Add a Git repository for CI/CD monitoring and automated deployment. Supports any Git repository with automatic detection of application type, port, and deployment configuration. This tool enables complete GitOps workflow from commit to live application."),
-			mcp.WithString("url", mcp.Description("Git repository URL (e.g., https://github.com/user/repo.git). Supports GitHub, GitLab, Bitbucket, and other Git hosting services. Must be a valid HTTPS Git URL."), mcp.Required()),
+			mcp.WithString("url", mcp.Description("Git repository URL (e.g., https://github.com/user/repo.git). Supports GitHub, GitLab, Bitbucket, and other Git hosting services. HTTPS URLs and SSH URLs (git@github.com:user/repo.git) are accepted, SSH URLs require an SSH key."), mcp.Required()),
 			mcp.WithString("name", mcp.Description("Friendly name for the repository. If not provided, will be extracted from the repository URL. Used for Kubernetes resource names (must be DNS-compliant). Example: 'my-web-app', 'sample-gaming-app'.")),
 			mcp.WithString("branch", mcp.Description("Git branch to monitor for changes. Defaults to 'main'. Common values: main, master, develop, staging. Commits to this branch will trigger automated builds and deployments.")),
 			mcp.WithString("dockerfile", mcp.Description("Path to Dockerfile relative to repository root. Defaults to './Dockerfile'. Can be in subdirectories like './docker/Dockerfile' or './build/Dockerfile'.")),
 			mcp.WithString("build_context", mcp.Description("Build context path for Docker build. Defaults to repository root ('.'). Useful when Dockerfile is in a subdirectory but needs access to parent directories.")),
 			mcp.WithString("image_name", mcp.Description("Container image name including registry. If not provided, auto-generated as '{registry}/default/{repo-name}'. Example: 'quay.io/myuser/myapp', 'docker.io/company/product'.")),
-			mcp.WithString("registry", mcp.Description("Container registry URL. Defaults to 'quay.io'. Supports Docker Hub (docker.io), Quay.io, AWS ECR, Azure ACR, Google GCR. Must be accessible for push operations.")),
-			mcp.WithString("namespace", mcp.Description("Kubernetes/OpenShift namespace for deployment. Required. Will be created if it doesn't exist. Must be a valid DNS subdomain. Examples: 'my-app-prod', 'gaming-dev', 'team-staging'."), mcp.Required()),
+			mcp.WithString("registry", mcp.Description("Container registry URL, or the name of a registry configured with 'registry_configure' whose credentials are used for pushes. Defaults to the server's default registry, 'quay.io' unless configured. Supports Docker Hub (docker.io), Quay.io, AWS ECR, Azure ACR, Google GCR. Must be accessible for push operations.")),
+			mcp.WithString("namespace", mcp.Description("Kubernetes/OpenShift namespace for deployment. Required unless the server has a default namespace. Will be created if it doesn't exist. Must be a valid DNS subdomain. Examples: 'my-app-prod', 'gaming-dev', 'team-staging'.")),
+			mcp.WithString("notify_url", mcp.Description("Slack incoming webhook or HTTP endpoint receiving a JSON notification with the repository, commit, status, last stage and duration when a pipeline execution completes (Optional)")),
+			mcp.WithString("ssh_key_path", mcp.Description("Path to a private SSH key on the server used to clone the repository over SSH. Example: '~/.ssh/id_ed25519'.")),
+			mcp.WithString("ssh_key", mcp.Description("Private SSH key content in PEM format, as an alternative to ssh_key_path. Stored on the server readable only by its user.")),
+			mcp.WithString("ssh_key_passphrase", mcp.Description("Passphrase of an encrypted SSH key. Never returned in tool results.")),
 			// Enhanced tool annotations for better discoverability
 			mcp.WithTitleAnnotation("CI/CD: Add Repository for Monitoring"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -274,6 +939,23 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 			mcp.WithOpenWorldHintAnnotation(true),
 		), Handler: s.repoAdd},
 
+		{Tool: mcp.NewTool("repo_update",
+			mcp.WithDescription("Update the configuration of a monitored repository in place. Only the provided fields are changed; when the registry changes and the image name was generated from it, the image name is regenerated. Returns the changed fields with their previous and new values"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("branch", mcp.Description("Git branch to monitor and build (Optional)")),
+			mcp.WithString("dockerfile", mcp.Description("Path to the Dockerfile relative to the build context (Optional)")),
+			mcp.WithString("build_context", mcp.Description("Build context path relative to the repository root (Optional)")),
+			mcp.WithString("image_name", mcp.Description("Container image name including registry (Optional)")),
+			mcp.WithString("registry", mcp.Description("Container registry URL, or the name of a registry configured with 'registry_configure' (Optional)")),
+			mcp.WithString("namespace", mcp.Description("Namespace the repository is deployed to (Optional)")),
+			mcp.WithString("notify_url", mcp.Description("Slack incoming webhook or HTTP endpoint notified when a pipeline execution completes (Optional)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Update Repository"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.repoUpdate},
+
 		{Tool: mcp.NewTool("repo_list",
 			mcp.WithDescription("List all monitored Git repositories with their CI/CD configurations"),
 			// Tool annotations
@@ -284,8 +966,9 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 		), Handler: s.repoList},
 
 		{Tool: mcp.NewTool("repo_status",
-			mcp.WithDescription("Get detailed status of a specific repository's CI/CD pipeline"),
+			mcp.WithDescription("Get detailed status of a specific repository's CI/CD pipeline, including its most recent executions with the commit, per-stage status and error of each run"),
 			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithNumber("limit", mcp.Description("Number of recent executions to return (Optional, defaults to 5, at most 20)")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("CI/CD: Repository Status"),
 			mcp.WithReadOnlyHintAnnotation(true),
@@ -294,10 +977,13 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 		), Handler: s.repoStatus},
 
 		{Tool: mcp.NewTool("repo_build",
-			mcp.WithDescription("Trigger a manual build for a specific repository"),
+			mcp.WithDescription("Build a repository's image from a fresh checkout using its configured Dockerfile or Source-to-Image, optionally pushing it to the configured registry (credentials from 'registry_configure', REGISTRY_USERNAME/REGISTRY_PASSWORD or 'registry_login')"),
 			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
 			mcp.WithString("commit", mcp.Description("Specific commit hash to build (Optional, defaults to latest)")),
-			mcp.WithBoolean("push", mcp.Description("Push built image to registry (Optional, defaults to true)")),
+			mcp.WithBoolean("push", mcp.Description("Push built image to registry (Optional, defaults to true, s2i builds always push)")),
+			mcp.WithString("strategy", mcp.Description("Build strategy: 'docker' builds the Dockerfile with the local Docker daemon, 's2i' runs an OpenShift Source-to-Image build of the build context, which needs no Dockerfile (Optional, defaults to 'docker')")),
+			mcp.WithString("builder_image", mcp.Description("s2i builder image, an image stream tag of the openshift namespace (e.g. 'nodejs:latest') or an image reference (Optional, selected from the detected language)")),
+			mcp.WithBoolean("persist_logs", mcp.Description("Store the full build log in the configured build log sink (a PVC directory or S3-compatible bucket) and return a reference to it, also when the build fails (Optional, defaults to false)")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("CI/CD: Build Repository"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -310,6 +996,42 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
 			mcp.WithString("image_tag", mcp.Description("Specific image tag to deploy (Optional, defaults to latest)")),
 			mcp.WithString("namespace", mcp.Description("Override target namespace (Optional, uses repo config)")),
+			mcp.WithBoolean("require_verification", mcp.Description("Refuse to deploy unless the image signature verifies against the configured trust policy (Optional, defaults to false)")),
+			mcp.WithBoolean("check_platforms", mcp.Description("Refuse to deploy an image whose architectures match no schedulable node, and warn when only some nodes match (Optional, defaults to true)")),
+			mcp.WithString("environment", mcp.Description("Environment whose overrides (namespace, env vars, replicas, resources) should be applied, as configured with 'repo_env_set' (Optional)")),
+			mcp.WithString("image_pull_secret", mcp.Description("Existing kubernetes.io/dockerconfigjson secret used to pull the image from a private registry (Optional). With registry credentials, the name of the secret to create, defaulting to '{name}-pull-secret'")),
+			mcp.WithString("registry_username", mcp.Description("Username for the image's registry; creates an image pull secret linked to the namespace's default service account (Optional, requires registry_password)")),
+			mcp.WithString("registry_password", mcp.Description("Password or token for the image's registry (Optional, requires registry_username)")),
+			mcp.WithString("strategy", mcp.Description("Rollout strategy: 'rolling' (default), 'recreate' to replace every pod at once, or 'blue-green' to deploy the new version next to the running one as {name} and {name}-green and switch the service once it is ready. Kept for later deployments of the repository; autoscaling is not supported with 'blue-green' (Optional, defaults to the strategy of the last deployment)")),
+			mcp.WithString("route_type", mcp.Description("How the application is exposed: 'route' (OpenShift Route), 'ingress' (networking.k8s.io/v1 Ingress) or 'auto' to use a Route when the cluster serves the Route API (Optional, defaults to 'auto')")),
+			mcp.WithString("ingress_class", mcp.Description("Ingress class of the generated Ingress, e.g. 'nginx' (Optional, defaults to the cluster default class)")),
+			mcp.WithString("host", mcp.Description("Host to expose the application on (Optional, a Route defaults to the cluster-assigned host and an Ingress matches any host)")),
+			mcp.WithNumber("min_replicas", mcp.Description("Minimum replicas kept by the HorizontalPodAutoscaler (Optional, defaults to the replicas, requires max_replicas)")),
+			mcp.WithNumber("max_replicas", mcp.Description("Maximum replicas of the HorizontalPodAutoscaler; setting it generates an autoscaling/v2 HPA targeting the Deployment (Optional)")),
+			mcp.WithNumber("target_cpu_utilization", mcp.Description("Average CPU utilization, as a percentage of the CPU request, the HPA scales to (Optional, defaults to 80, requires max_replicas)")),
+			mcp.WithString("cpu_request", mcp.Description("CPU request of the application container, e.g. '100m' (Optional, defaults to '50m')")),
+			mcp.WithString("cpu_limit", mcp.Description("CPU limit of the application container, e.g. '500m' (Optional, defaults to '200m')")),
+			mcp.WithString("memory_request", mcp.Description("Memory request of the application container, e.g. '128Mi' (Optional, defaults to '64Mi')")),
+			mcp.WithString("memory_limit", mcp.Description("Memory limit of the application container, e.g. '512Mi' (Optional, defaults to '256Mi')")),
+			mcp.WithObject("env", mcp.Description("Environment variables for the application container as name/value pairs, merged over environment overrides (Optional)")),
+			mcp.WithString("liveness_path", mcp.Description("HTTP path checked by the liveness probe, e.g. '/healthz'; an empty string disables the probe for workloads that do not serve HTTP (Optional, defaults to '/')")),
+			mcp.WithString("readiness_path", mcp.Description("HTTP path checked by the readiness probe, e.g. '/ready'; an empty string disables the probe (Optional, defaults to '/')")),
+			mcp.WithString("startup_path", mcp.Description("HTTP path checked by a startup probe, which holds off the liveness probe of slow starting applications, e.g. '/healthz' (Optional, no startup probe by default)")),
+			mcp.WithObject("liveness_timing", mcp.Description("Liveness probe timing with initial_delay_seconds, period_seconds, timeout_seconds and failure_threshold fields (Optional, defaults to a 30s initial delay and a 10s period)")),
+			mcp.WithObject("readiness_timing", mcp.Description("Readiness probe timing, same fields as liveness_timing (Optional, defaults to a 5s initial delay and a 5s period)")),
+			mcp.WithObject("startup_timing", mcp.Description("Startup probe timing, same fields as liveness_timing (Optional, defaults to a 10s period and 30 failures)")),
+			mcp.WithObject("config_map_mounts", mcp.Description("ConfigMaps mounted read-only in the application container, as name/mount path pairs, e.g. {\"app-config\": \"/etc/app\"} (Optional)")),
+			mcp.WithObject("secret_mounts", mcp.Description("Secrets mounted read-only in the application container, as name/mount path pairs (Optional)")),
+			mcp.WithArray("secret_env_from", mcp.Description("Secrets whose keys are loaded as environment variables of the application container (Optional)"),
+				func(schema map[string]interface{}) {
+					schema["type"] = "array"
+					schema["items"] = map[string]interface{}{
+						"type": "string",
+					}
+				},
+			),
+			mcp.WithObject("config_maps", mcp.Description("ConfigMaps created or updated before deploying, as names to key/value data (Optional)")),
+			mcp.WithObject("secrets", mcp.Description("Opaque Secrets created or updated before deploying, as names to key/value data (Optional)")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("CI/CD: Deploy Repository"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -320,7 +1042,7 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 		{Tool: mcp.NewTool("repo_remove",
 			mcp.WithDescription("Remove a repository from CI/CD monitoring"),
 			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
-			mcp.WithBoolean("cleanup", mcp.Description("Also cleanup deployed resources (Optional, defaults to false)")),
+			mcp.WithBoolean("cleanup", mcp.Description("Also delete the Deployment, Service and Route named after the repository from its namespace (Optional, defaults to false)")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("CI/CD: Remove Repository"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -352,11 +1074,68 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 		{Tool: mcp.NewTool("repo_auto_deploy",
 			mcp.WithDescription("Fully automated deployment: create namespace, generate manifests, build, deploy, and provide URL"),
 			mcp.WithString("url", mcp.Description("Git repository URL (e.g., https://github.com/user/repo.git)"), mcp.Required()),
-			mcp.WithString("namespace", mcp.Description("OpenShift/Kubernetes namespace for deployment (Required)"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("OpenShift/Kubernetes namespace for deployment (Required unless the server has a default namespace)")),
 			mcp.WithString("name", mcp.Description("Application name (Optional, defaults to repo name)")),
 			mcp.WithString("branch", mcp.Description("Git branch to deploy (Optional, defaults to 'main')")),
-			mcp.WithNumber("port", mcp.Description("Application port (Optional, auto-detected from repo type)")),
-			mcp.WithString("image_registry", mcp.Description("Container registry (Optional, defaults to 'quay.io')")),
+			mcp.WithNumber("port", mcp.Description("Application port (Optional, auto-detected from repo type). Deprecated alias for 'container_port'")),
+			mcp.WithNumber("container_port", mcp.Description("Port the application container listens on (Optional, auto-detected from repo type)")),
+			mcp.WithNumber("service_port", mcp.Description("Port the Service exposes inside the cluster (Optional, defaults to 80)")),
+			mcp.WithString("target_port", mcp.Description("Container port the Service forwards to, as a number or port name (Optional, defaults to the container port). Deployment is refused if it does not match the container port")),
+			mcp.WithString("image_registry", mcp.Description("Container registry host, or the name of a registry configured with 'registry_configure' (Optional, defaults to the server's default registry, 'quay.io' unless configured)")),
+			mcp.WithString("environment", mcp.Description("Environment whose overrides (namespace, env vars, replicas, resources) should be applied, as configured with 'repo_env_set' (Optional)")),
+			mcp.WithString("route_host", mcp.Description("Host to expose the application on (Optional, defaults to the cluster-assigned '{name}-{namespace}' host). Deployment is refused if another Route or Ingress already claims the host")),
+			mcp.WithString("commit", mcp.Description("Commit SHA being deployed, recorded on the pipeline execution so 'cicd_await_next' can wait for it (Optional)")),
+			mcp.WithString("notify_url", mcp.Description("Slack incoming webhook or HTTP endpoint receiving a JSON notification when a pipeline execution completes (Optional, keeps the previously configured endpoint)")),
+			mcp.WithBoolean("persist_logs", mcp.Description("Store the log of the pipeline execution (its stages and the applied objects) in the configured build log sink (a PVC directory or S3-compatible bucket) and return a reference to it (Optional, defaults to false)")),
+			mcp.WithArray("command", mcp.Description(`Container command overriding the image entrypoint, one item per argument (Optional). Example: ["python", "worker.py"]`),
+				func(schema map[string]interface{}) {
+					schema["type"] = "array"
+					schema["items"] = map[string]interface{}{
+						"type": "string",
+					}
+				},
+			),
+			mcp.WithArray("args", mcp.Description(`Container arguments overriding the image CMD (Optional). Example: ["--queue", "emails"]`),
+				func(schema map[string]interface{}) {
+					schema["type"] = "array"
+					schema["items"] = map[string]interface{}{
+						"type": "string",
+					}
+				},
+			),
+			mcp.WithString("image_pull_secret", mcp.Description("Existing kubernetes.io/dockerconfigjson secret used to pull the image from a private registry (Optional). With registry credentials, the name of the secret to create, defaulting to '{name}-pull-secret'")),
+			mcp.WithString("registry_username", mcp.Description("Username for the image's registry; creates an image pull secret linked to the namespace's default service account (Optional, requires registry_password)")),
+			mcp.WithString("registry_password", mcp.Description("Password or token for the image's registry (Optional, requires registry_username)")),
+			mcp.WithString("route_type", mcp.Description("How the application is exposed: 'route' (OpenShift Route), 'ingress' (networking.k8s.io/v1 Ingress) or 'auto' to use a Route when the cluster serves the Route API (Optional, defaults to 'auto')")),
+			mcp.WithString("ingress_class", mcp.Description("Ingress class of the generated Ingress, e.g. 'nginx' (Optional, defaults to the cluster default class)")),
+			mcp.WithNumber("min_replicas", mcp.Description("Minimum replicas kept by the HorizontalPodAutoscaler (Optional, defaults to the replicas, requires max_replicas)")),
+			mcp.WithNumber("max_replicas", mcp.Description("Maximum replicas of the HorizontalPodAutoscaler; setting it generates an autoscaling/v2 HPA targeting the Deployment (Optional)")),
+			mcp.WithNumber("target_cpu_utilization", mcp.Description("Average CPU utilization, as a percentage of the CPU request, the HPA scales to (Optional, defaults to 80, requires max_replicas)")),
+			mcp.WithString("cpu_request", mcp.Description("CPU request of the application container, e.g. '100m' (Optional, defaults to '50m')")),
+			mcp.WithString("cpu_limit", mcp.Description("CPU limit of the application container, e.g. '500m' (Optional, defaults to '200m')")),
+			mcp.WithString("memory_request", mcp.Description("Memory request of the application container, e.g. '128Mi' (Optional, defaults to '64Mi')")),
+			mcp.WithString("memory_limit", mcp.Description("Memory limit of the application container, e.g. '512Mi' (Optional, defaults to '256Mi')")),
+			mcp.WithObject("env", mcp.Description("Environment variables for the application container as name/value pairs, merged over environment overrides (Optional)")),
+			mcp.WithString("liveness_path", mcp.Description("HTTP path checked by the liveness probe, e.g. '/healthz'; an empty string disables the probe for workloads that do not serve HTTP (Optional, defaults to '/')")),
+			mcp.WithString("readiness_path", mcp.Description("HTTP path checked by the readiness probe, e.g. '/ready'; an empty string disables the probe (Optional, defaults to '/')")),
+			mcp.WithString("startup_path", mcp.Description("HTTP path checked by a startup probe, which holds off the liveness probe of slow starting applications, e.g. '/healthz' (Optional, no startup probe by default)")),
+			mcp.WithObject("liveness_timing", mcp.Description("Liveness probe timing with initial_delay_seconds, period_seconds, timeout_seconds and failure_threshold fields (Optional, defaults to a 30s initial delay and a 10s period)")),
+			mcp.WithObject("readiness_timing", mcp.Description("Readiness probe timing, same fields as liveness_timing (Optional, defaults to a 5s initial delay and a 5s period)")),
+			mcp.WithObject("startup_timing", mcp.Description("Startup probe timing, same fields as liveness_timing (Optional, defaults to a 10s period and 30 failures)")),
+			mcp.WithObject("config_map_mounts", mcp.Description("ConfigMaps mounted read-only in the application container, as name/mount path pairs, e.g. {\"app-config\": \"/etc/app\"} (Optional)")),
+			mcp.WithObject("secret_mounts", mcp.Description("Secrets mounted read-only in the application container, as name/mount path pairs (Optional)")),
+			mcp.WithArray("secret_env_from", mcp.Description("Secrets whose keys are loaded as environment variables of the application container (Optional)"),
+				func(schema map[string]interface{}) {
+					schema["type"] = "array"
+					schema["items"] = map[string]interface{}{
+						"type": "string",
+					}
+				},
+			),
+			mcp.WithObject("config_maps", mcp.Description("ConfigMaps created or updated before deploying, as names to key/value data (Optional)")),
+			mcp.WithObject("secrets", mcp.Description("Opaque Secrets created or updated before deploying, as names to key/value data (Optional)")),
+			mcp.WithBoolean("dry_run", mcp.Description("Validate the generated manifests with a server-side dry-run apply and return the would-be objects without changing anything (Optional, defaults to false)")),
+			mcp.WithBoolean("diff", mcp.Description("Compare the generated manifests with the objects in the cluster and return the changed fields without applying anything (Optional, defaults to false)")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("CI/CD: Full Auto Deploy"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -368,6 +1147,24 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 			mcp.WithDescription("Generate Kubernetes/OpenShift manifests for a repository"),
 			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
 			mcp.WithString("image_tag", mcp.Description("Image tag to use in manifests (Optional, defaults to 'latest')")),
+			mcp.WithString("route_type", mcp.Description("How the application is exposed: 'route' (OpenShift Route), 'ingress' (networking.k8s.io/v1 Ingress) or 'auto' to use a Route when the cluster serves the Route API (Optional, defaults to 'auto')")),
+			mcp.WithString("ingress_class", mcp.Description("Ingress class of the generated Ingress, e.g. 'nginx' (Optional, defaults to the cluster default class)")),
+			mcp.WithString("host", mcp.Description("Host to expose the application on (Optional, a Route defaults to the cluster-assigned host and an Ingress matches any host)")),
+			mcp.WithNumber("min_replicas", mcp.Description("Minimum replicas kept by the HorizontalPodAutoscaler (Optional, defaults to the replicas, requires max_replicas)")),
+			mcp.WithNumber("max_replicas", mcp.Description("Maximum replicas of the HorizontalPodAutoscaler; setting it generates an autoscaling/v2 HPA targeting the Deployment (Optional)")),
+			mcp.WithNumber("target_cpu_utilization", mcp.Description("Average CPU utilization, as a percentage of the CPU request, the HPA scales to (Optional, defaults to 80, requires max_replicas)")),
+			mcp.WithString("cpu_request", mcp.Description("CPU request of the application container, e.g. '100m' (Optional, defaults to '50m')")),
+			mcp.WithString("cpu_limit", mcp.Description("CPU limit of the application container, e.g. '500m' (Optional, defaults to '200m')")),
+			mcp.WithString("memory_request", mcp.Description("Memory request of the application container, e.g. '128Mi' (Optional, defaults to '64Mi')")),
+			mcp.WithString("memory_limit", mcp.Description("Memory limit of the application container, e.g. '512Mi' (Optional, defaults to '256Mi')")),
+			mcp.WithObject("env", mcp.Description("Environment variables for the application container as name/value pairs, merged over environment overrides (Optional)")),
+			mcp.WithString("liveness_path", mcp.Description("HTTP path checked by the liveness probe, e.g. '/healthz'; an empty string disables the probe for workloads that do not serve HTTP (Optional, defaults to '/')")),
+			mcp.WithString("readiness_path", mcp.Description("HTTP path checked by the readiness probe, e.g. '/ready'; an empty string disables the probe (Optional, defaults to '/')")),
+			mcp.WithString("startup_path", mcp.Description("HTTP path checked by a startup probe, which holds off the liveness probe of slow starting applications, e.g. '/healthz' (Optional, no startup probe by default)")),
+			mcp.WithObject("liveness_timing", mcp.Description("Liveness probe timing with initial_delay_seconds, period_seconds, timeout_seconds and failure_threshold fields (Optional, defaults to a 30s initial delay and a 10s period)")),
+			mcp.WithObject("readiness_timing", mcp.Description("Readiness probe timing, same fields as liveness_timing (Optional, defaults to a 5s initial delay and a 5s period)")),
+			mcp.WithObject("startup_timing", mcp.Description("Startup probe timing, same fields as liveness_timing (Optional, defaults to a 10s period and 30 failures)")),
+			mcp.WithString("format", mcp.Description("Output format: 'json' (the result with the manifests as strings), 'yaml' (the same result as YAML) or 'raw' (only the manifests as multi-document YAML, ready for 'kubectl apply -f -') (Optional, defaults to 'json')")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("CI/CD: Generate Manifests"),
 			mcp.WithReadOnlyHintAnnotation(true),
@@ -384,73 +1181,406 @@ func (s *Server) initCicdSimple() []server.ServerTool {
 			mcp.WithDestructiveHintAnnotation(false),
 			mcp.WithOpenWorldHintAnnotation(true),
 		), Handler: s.repoGetURL},
-	}
-}
 
-// Helper function to extract repository name from URL
-func extractRepoName(url string) string {
-	parts := strings.Split(strings.TrimSuffix(url, ".git"), "/")
-	if len(parts) > 0 {
-		return parts[len(parts)-1]
-	}
-	return "unknown"
-}
+		{Tool: mcp.NewTool("list_applications",
+			mcp.WithDescription("List applications deployed by the CI/CD tools with their image and replica readiness. Serves last-known-good data marked as stale if the cluster is briefly unreachable"),
+			mcp.WithString("namespace", mcp.Description("Namespace to list applications from (Optional, defaults to all namespaces)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: List Applications"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.listApplications},
 
-// Helper function to generate image name from repo name and registry
-func generateImageName(repoName, registry string) string {
-	if registry == "" {
-		registry = "quay.io"
-	}
-	// Use a default username/org if not specified
-	return fmt.Sprintf("%s/default/%s", registry, strings.ToLower(repoName))
-}
+		{Tool: mcp.NewTool("repo_env_set",
+			mcp.WithDescription("Set the deployment overrides used when a repository is deployed to a specific environment (e.g. dev, staging, prod). Only the provided fields are changed"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("environment", mcp.Description("Environment name (e.g. 'dev', 'staging', 'prod')"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace to deploy to in this environment (Optional)")),
+			mcp.WithString("image_tag", mcp.Description("Image tag to deploy in this environment (Optional)")),
+			mcp.WithNumber("replicas", mcp.Description("Number of replicas in this environment (Optional)")),
+			mcp.WithObject("env", mcp.Description("Environment variables for the application container in this environment, as name/value pairs (Optional)")),
+			mcp.WithString("cpu_request", mcp.Description("CPU request, e.g. '100m' (Optional)")),
+			mcp.WithString("cpu_limit", mcp.Description("CPU limit, e.g. '500m' (Optional)")),
+			mcp.WithString("memory_request", mcp.Description("Memory request, e.g. '128Mi' (Optional)")),
+			mcp.WithString("memory_limit", mcp.Description("Memory limit, e.g. '512Mi' (Optional)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Set Environment Overrides"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.repoEnvSet},
 
-func (s *Server) repoAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	mcpLogger.Printf("Processing repo_add request from context: %v", ctx.Value("request_id"))
+		{Tool: mcp.NewTool("repo_env_get",
+			mcp.WithDescription("Show the environment-specific deployment overrides configured for a repository"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("environment", mcp.Description("Environment name (Optional, defaults to all environments)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Get Environment Overrides"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.repoEnvGet},
 
-	args, ok := request.Params.Arguments.(map[string]interface{})
-	if !ok {
-		mcpLogger.Printf("Invalid arguments format in repo_add request")
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				mcp.NewTextContent(formatMCPError("INVALID_PARAMS", "Arguments must be a JSON object with required 'url' and 'namespace' fields")),
-			},
-		}, nil
-	}
+		{Tool: mcp.NewTool("repo_image_diff",
+			mcp.WithDescription("Compare the image a repository is configured to deploy with the image actually running in its live Deployment, including resolved digests, and report match or mismatch. Detects drift left by manual changes or failed deploys"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("image_tag", mcp.Description("Intended image tag (Optional, defaults to 'latest')")),
+			mcp.WithString("environment", mcp.Description("Environment whose namespace override should be checked (Optional)")),
+			mcp.WithBoolean("no_cache", mcp.Description("Bypass the registry lookup cache when resolving the configured image digest (Optional, defaults to false)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Diff Configured and Live Image"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.repoImageDiff},
 
-	url, ok := args["url"].(string)
-	if !ok || url == "" {
-		mcpLogger.Printf("Missing or invalid 'url' parameter in repo_add request")
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				mcp.NewTextContent(formatMCPError("MISSING_PARAMETER", "The 'url' parameter is required and must be a valid Git repository URL (e.g., https://github.com/user/repo.git)")),
-			},
-		}, nil
-	}
+		{Tool: mcp.NewTool("route_check_host",
+			mcp.WithDescription("Check whether a Route/Ingress host is already claimed by another Route or Ingress in any namespace, and suggest an unused alternative. Use before deploying to avoid routes that exist but never serve traffic"),
+			mcp.WithString("host", mcp.Description("Host to check (Optional, defaults to the host generated for 'name' in 'namespace')")),
+			mcp.WithString("name", mcp.Description("Application name the host is intended for (Optional, required if host is not set)")),
+			mcp.WithString("namespace", mcp.Description("Namespace the application is deployed to (Optional, required if host is not set)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Check Route Host Availability"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.routeCheckHost},
+
+		{Tool: mcp.NewTool("application_verify",
+			mcp.WithDescription("Verify a deployed application end to end: Deployment pods ready, Service endpoints populated, Route admitted, and an HTTP probe through the route host succeeding. Returns each check's result so a failure is pinpointed to a layer"),
+			mcp.WithString("name", mcp.Description("Application or repository name"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace of the application (Optional, defaults to the repository's configured namespace)")),
+			mcp.WithString("path", mcp.Description("HTTP path to probe (Optional, defaults to '/')")),
+			mcp.WithNumber("timeout", mcp.Description("HTTP probe timeout in seconds (Optional, defaults to 10)")),
+			mcp.WithBoolean("skip_tls_verify", mcp.Description("Skip TLS certificate verification for the HTTP probe (Optional, defaults to false)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Verify Application End to End"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.applicationVerify},
+
+		{Tool: mcp.NewTool("scale_application",
+			mcp.WithDescription("Change the replica count of a deployed application without redeploying it, then wait for the new number of replicas to be ready. Returns the replica count before and after and whether every replica became ready"),
+			mcp.WithString("name", mcp.Description("Application or repository name, the name of its Deployment"), mcp.Required()),
+			mcp.WithNumber("replicas", mcp.Description("Desired number of replicas, 0 stops the application"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace of the application (Optional, defaults to the repository's configured namespace)")),
+			mcp.WithNumber("timeout", mcp.Description("Maximum time to wait for the replicas to be ready in seconds, 0 returns without waiting (Optional, defaults to 120, at most 600)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Scale Application"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.scaleApplication},
+
+		{Tool: mcp.NewTool("rollback_application",
+			mcp.WithDescription("Roll a deployed application back to an earlier revision of its Deployment, restoring the pod template (image, env, resources) recorded by that revision's ReplicaSet, then wait for the rollout to be ready. Rolls back to the previous revision by default"),
+			mcp.WithString("name", mcp.Description("Application or repository name, the name of its Deployment"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace of the application (Optional, defaults to the repository's configured namespace)")),
+			mcp.WithNumber("revision", mcp.Description("Revision to roll back to, as recorded in the deployment.kubernetes.io/revision annotation (Optional, defaults to the previous revision)")),
+			mcp.WithNumber("timeout", mcp.Description("Maximum time to wait for the rollout in seconds, 0 returns without waiting (Optional, defaults to 300, at most 600)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Roll Back Application"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.rollbackApplication},
+
+		{Tool: mcp.NewTool("deployment_logs",
+			mcp.WithDescription("Read the logs of a deployed application. Selects the pods labelled app=<name> in the namespace and returns the logs of the newest one, or of every pod with all_pods. Reports the phase of each pod when none has started yet"),
+			mcp.WithString("name", mcp.Description("Application or repository name, matched against the pods' app label"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace of the application (Optional, defaults to the repository's configured namespace)")),
+			mcp.WithString("container", mcp.Description("Container to read logs from (Optional, defaults to the pod's only or first container)")),
+			mcp.WithNumber("tail_lines", mcp.Description("Number of lines from the end of the log to return (Optional, defaults to 100)")),
+			mcp.WithString("since", mcp.Description("Only return logs newer than this duration, e.g. '10m', '1h' (Optional)")),
+			mcp.WithBoolean("previous", mcp.Description("Return the logs of the previous, crashed instance of the container (Optional, defaults to false)")),
+			mcp.WithBoolean("all_pods", mcp.Description("Return the logs of every pod of the application, each prefixed with the pod name (Optional, defaults to false)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Application Logs"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.deploymentLogs},
 
-	namespace, ok := args["namespace"].(string)
-	if !ok || namespace == "" {
-		mcpLogger.Printf("Missing or invalid 'namespace' parameter in repo_add request")
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				mcp.NewTextContent(formatMCPError("MISSING_PARAMETER", "The 'namespace' parameter is required and must be a valid Kubernetes namespace name")),
-			},
-		}, nil
-	}
+		{Tool: mcp.NewTool("deployment_status",
+			mcp.WithDescription("Report whether a deployed application is healthy and, if not, why: rollout progress of the Deployment, its ReplicaSets, pod readiness and restarts, crash loops, image pull errors and the most recent events about them"),
+			mcp.WithString("name", mcp.Description("Application or repository name, the name of its Deployment"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace of the application (Optional, defaults to the repository's configured namespace)")),
+			mcp.WithNumber("events", mcp.Description("Number of recent events to include (Optional, defaults to 10)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Deployment Status"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.deploymentStatus},
 
-	// Extract optional parameters with defaults
-	repoName := extractRepoName(url)
-	if name, exists := args["name"].(string); exists && name != "" {
-		repoName = name
-	}
+		{Tool: mcp.NewTool("build_status",
+			mcp.WithDescription("Report the phase (New, Pending, Running, Complete, Failed, Error or Cancelled) and duration of an OpenShift build without re-triggering it"),
+			mcp.WithString("name", mcp.Description("Build name, '<build config>-<number>' (e.g. 'my-app-3')"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace of the build (Optional, defaults to the namespace of the repository the build config is named after, or the current namespace)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Build Status"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.buildStatus},
 
-	branch := "main"
-	if b, exists := args["branch"].(string); exists && b != "" {
-		branch = b
-	}
+		{Tool: mcp.NewTool("build_cancel",
+			mcp.WithDescription("Cancel a running or pending OpenShift build. Builds that already finished are left unchanged and their status is returned"),
+			mcp.WithString("name", mcp.Description("Build name, '<build config>-<number>' (e.g. 'my-app-3')"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("Namespace of the build (Optional, defaults to the namespace of the repository the build config is named after, or the current namespace)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Cancel Build"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.buildCancel},
+
+		{Tool: mcp.NewTool("cicd_await_next",
+			mcp.WithDescription("Block until the next pipeline execution of a repository completes (or the most recent execution of a given commit), then return its status, stages and application URL. Useful for scripting releases: push a commit, then await its deployment"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("commit", mcp.Description("Commit SHA or SHA prefix to wait for (Optional, defaults to the next execution started after this call)")),
+			mcp.WithNumber("timeout", mcp.Description("Maximum time to wait in seconds (Optional, defaults to 300, at most 1800)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Await Next Execution"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.cicdAwaitNext},
+
+		{Tool: mcp.NewTool("cicd_pipeline_status",
+			mcp.WithDescription("Get the status of one pipeline execution of a repository, by default the most recent: its commit, start and finish time, per-stage status, the stage it failed at and its error, along with the repository's recent executions"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("run_id", mcp.Description("ID of the execution to return, as listed in 'recent_executions' (Optional, defaults to the most recent execution)")),
+			mcp.WithNumber("limit", mcp.Description("Number of recent executions to include (Optional, defaults to 5, at most 20)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Pipeline Status"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.cicdPipelineStatus},
+
+		{Tool: mcp.NewTool("repo_generate_overlays",
+			mcp.WithDescription("Generate a kustomize base plus one overlay per environment (dev, staging, prod and any environment configured with 'repo_env_set') for a repository. Overlays set namespace, replicas, image tag, env vars and resources from the per-environment overrides, giving a ready-to-commit GitOps directory structure"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("image_tag", mcp.Description("Image tag for environments without an image_tag override (Optional, defaults to 'latest')")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Generate Kustomize Overlays"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.repoGenerateOverlays},
+
+		{Tool: mcp.NewTool("repo_generate_helm",
+			mcp.WithDescription("Scaffold a minimal Helm chart for a repository: Chart.yaml, values.yaml with the image, port, replicas, resources, env vars and probe paths, and templates for the Deployment, Service and OpenShift Route. Returns the chart files keyed by path relative to the chart directory"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("image_tag", mcp.Description("Image tag set in values.yaml (Optional, defaults to 'latest')")),
+			mcp.WithNumber("replicas", mcp.Description("Replica count set in values.yaml (Optional, defaults to 1)")),
+			mcp.WithString("route_type", mcp.Description("'route' enables the Route in values.yaml, 'ingress' disables it and 'auto' enables it when the cluster serves the Route API (Optional, defaults to 'auto')")),
+			mcp.WithString("host", mcp.Description("Host of the Route (Optional, defaults to the cluster-assigned host)")),
+			mcp.WithString("cpu_request", mcp.Description("CPU request of the application container, e.g. '100m' (Optional, defaults to '50m')")),
+			mcp.WithString("cpu_limit", mcp.Description("CPU limit of the application container, e.g. '500m' (Optional, defaults to '200m')")),
+			mcp.WithString("memory_request", mcp.Description("Memory request of the application container, e.g. '128Mi' (Optional, defaults to '64Mi')")),
+			mcp.WithString("memory_limit", mcp.Description("Memory limit of the application container, e.g. '512Mi' (Optional, defaults to '256Mi')")),
+			mcp.WithObject("env", mcp.Description("Environment variables for the application container as name/value pairs (Optional)")),
+			mcp.WithString("liveness_path", mcp.Description("HTTP path checked by the liveness probe, e.g. '/healthz' (Optional, defaults to '/')")),
+			mcp.WithString("readiness_path", mcp.Description("HTTP path checked by the readiness probe, e.g. '/ready' (Optional, defaults to '/')")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Generate Helm Chart"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.repoGenerateHelm},
+
+		{Tool: mcp.NewTool("pipeline_diagnose",
+			mcp.WithDescription("Diagnose why a repository's pipeline is not triggering. Checks that the configured branch exists on the Git remote and reports the remote's default branch, catching pipelines that watch 'main' on a repository whose default branch is 'master'"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Diagnose Pipeline"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.pipelineDiagnose},
+
+		{Tool: mcp.NewTool("ship",
+			mcp.WithDescription("Ship a repository end to end as one operation: clone, build with UBI and security validation, push, deploy and wait for the Deployment to become ready. If the rollout does not become ready the previous Deployment is restored (or the new resources removed) and the pushed tag can optionally be deleted. Returns every stage's outcome"),
+			mcp.WithString("url", mcp.Description("Git repository URL (e.g., https://github.com/user/repo.git)"), mcp.Required()),
+			mcp.WithString("namespace", mcp.Description("OpenShift/Kubernetes namespace for deployment (Required unless the server has a default namespace)")),
+			mcp.WithString("name", mcp.Description("Application name (Optional, defaults to repo name)")),
+			mcp.WithString("branch", mcp.Description("Git branch to build (Optional, defaults to 'main')")),
+			mcp.WithString("commit", mcp.Description("Commit SHA to build, also recorded on the pipeline execution (Optional, defaults to the branch head)")),
+			mcp.WithString("notify_url", mcp.Description("Slack incoming webhook or HTTP endpoint receiving a JSON notification when the execution completes (Optional, keeps the previously configured endpoint)")),
+			mcp.WithString("dockerfile", mcp.Description("Path to Dockerfile relative to the build context (Optional, defaults to 'Dockerfile')")),
+			mcp.WithString("build_context", mcp.Description("Build context path (Optional, defaults to repository root '.')")),
+			mcp.WithString("image_registry", mcp.Description("Container registry host, or the name of a registry configured with 'registry_configure' (Optional, defaults to the server's default registry, 'quay.io' unless configured)")),
+			mcp.WithString("image_name", mcp.Description("Image name without tag (Optional, defaults to '{registry}/default/{name}')")),
+			mcp.WithString("image_tag", mcp.Description("Image tag to build and deploy (Optional, defaults to a unique 'ship-{timestamp}' tag)")),
+			mcp.WithNumber("container_port", mcp.Description("Port the application container listens on (Optional, auto-detected from repo type)")),
+			mcp.WithNumber("ready_timeout", mcp.Description("Seconds to wait for the Deployment to become ready before rolling back (Optional, defaults to 300, at most 1800)")),
+			mcp.WithBoolean("cleanup_tag_on_failure", mcp.Description("Delete the pushed image tag from the registry when the deploy is rolled back (Optional, defaults to false)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Ship Repository"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.ship},
+
+		{Tool: mcp.NewTool("git_add_webhook",
+			mcp.WithDescription("Register a GitHub or GitLab push webhook for a repository. Pushes received at the server's /webhooks/git endpoint are authenticated with the secret (GitHub HMAC signature or GitLab secret token) and trigger the pipeline immediately; the repository is no longer polled. Returns the endpoint and secret to configure on the Git provider"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithString("secret", mcp.Description("Webhook secret (Optional, a random secret is generated and returned if not provided)")),
+			mcp.WithArray("events", mcp.Description(`Events that trigger the pipeline (Optional, defaults to ["push"])`),
+				func(schema map[string]interface{}) {
+					schema["type"] = "array"
+					schema["items"] = map[string]interface{}{
+						"type": "string",
+					}
+				},
+			),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Add Git Webhook"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.gitAddWebhook},
+
+		{Tool: mcp.NewTool("cicd_set_pipeline_active",
+			mcp.WithDescription("Enable or disable the pipeline of a repository. A disabled repository keeps its configuration but its pushes and new commits no longer trigger builds or deployments, and it is no longer polled"),
+			mcp.WithString("name", mcp.Description("Repository name or URL"), mcp.Required()),
+			mcp.WithBoolean("active", mcp.Description("true to enable the pipeline, false to disable it"), mcp.Required()),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Enable or Disable Pipeline"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.cicdSetPipelineActive},
+
+		{Tool: mcp.NewTool("image_platform_check",
+			mcp.WithDescription("Check which platforms (os/architecture) an image supports, from its registry manifest or the local image, and compare them with the architectures of the cluster's schedulable nodes. Reports a mismatch before deploy instead of an 'exec format error' crashloop"),
+			mcp.WithString("image_name", mcp.Description("Image reference to check. Examples: 'quay.io/user/app:v1.0', 'docker.io/library/nginx:latest'"), mcp.Required()),
+			mcp.WithBoolean("local", mcp.Description("Inspect the image in local container storage instead of the registry (Optional, defaults to false)")),
+			mcp.WithBoolean("skip_tls_verify", mcp.Description("Skip TLS verification when reading the registry manifest (Optional, defaults to false)")),
+			mcp.WithBoolean("no_cache", mcp.Description("Bypass the registry lookup cache (Optional, defaults to false)")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("CI/CD: Check Image Platforms Against Nodes"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.imagePlatformCheck},
+	}
+}
+
+// Helper function to extract repository name from URL
+func extractRepoName(url string) string {
+	parts := strings.Split(strings.TrimSuffix(url, ".git"), "/")
+	if len(parts) > 0 {
+		return parts[len(parts)-1]
+	}
+	return "unknown"
+}
+
+// Helper function to generate image name from repo name and registry
+func generateImageName(repoName, registry string) string {
+	if registry == "" {
+		registry = "quay.io"
+	}
+	// Use a default username/org if not specified
+	return fmt.Sprintf("%s/default/%s", registry, strings.ToLower(repoName))
+}
+
+// defaultRegistry returns the configured registry images are pushed to, quay.io when unset
+func (s *Server) defaultRegistry() string {
+	if s.configuration != nil && s.configuration.StaticConfig != nil && s.configuration.StaticConfig.DefaultRegistry != "" {
+		return s.configuration.StaticConfig.DefaultRegistry
+	}
+	return "quay.io"
+}
+
+// defaultNamespace returns the configured namespace applications are deployed to, empty when unset
+func (s *Server) defaultNamespace() string {
+	if s.configuration != nil && s.configuration.StaticConfig != nil {
+		return s.configuration.StaticConfig.DefaultNamespace
+	}
+	return ""
+}
+
+// resolveRepoRegistry accepts a registry host or URL, or the name of a registry configured with
+// registry_configure, and returns the registry host images are pushed to
+func resolveRepoRegistry(registry string) (string, error) {
+	host := registryHost(registry)
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return registry, nil
+	}
+	configuredRegistriesMu.RLock()
+	configured, exists := configuredRegistries[registry]
+	configuredRegistriesMu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("registry '%s' is not configured, add it with registry_configure or pass a registry host such as quay.io", registry)
+	}
+	return registryHost(configured.info.URL), nil
+}
+
+// repoRegistryAuth returns the credentials to push to a repository's registry: those configured with
+// registry_configure, else REGISTRY_USERNAME/REGISTRY_PASSWORD, else those stored by registry_login
+func repoRegistryAuth(registry string) *cicd.RegistryConfig {
+	auth := &cicd.RegistryConfig{URL: registry}
+	if configured := lookupConfiguredRegistry(registry); configured != nil {
+		auth.Username, auth.Password = registryCredentials(registryHost(configured.info.URL), configured.info.Metadata["username"], configured.password)
+		return auth
+	}
+	auth.Username, auth.Password = registryCredentials(registryHost(registry), os.Getenv("REGISTRY_USERNAME"), os.Getenv("REGISTRY_PASSWORD"))
+	return auth
+}
+
+func (s *Server) repoAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	klog.V(2).Infof("Processing repo_add request from context: %v", ctx.Value("request_id"))
+
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		klog.V(1).Info("Invalid arguments format in repo_add request")
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.NewTextContent(formatMCPError("INVALID_PARAMS", "Arguments must be a JSON object with required 'url' and 'namespace' fields")),
+			},
+		}, nil
+	}
+
+	url, ok := args["url"].(string)
+	if !ok || url == "" {
+		klog.V(1).Info("Missing or invalid 'url' parameter in repo_add request")
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.NewTextContent(formatMCPError("MISSING_PARAMETER", "The 'url' parameter is required and must be a valid Git repository URL (e.g., https://github.com/user/repo.git)")),
+			},
+		}, nil
+	}
+
+	namespace := getStringArg(args, "namespace", s.defaultNamespace())
+	if namespace == "" {
+		klog.V(1).Info("Missing or invalid 'namespace' parameter in repo_add request")
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.NewTextContent(formatMCPError("MISSING_PARAMETER", "The 'namespace' parameter is required and must be a valid Kubernetes namespace name")),
+			},
+		}, nil
+	}
+
+	// Extract optional parameters with defaults
+	repoName := extractRepoName(url)
+	if name, exists := args["name"].(string); exists && name != "" {
+		repoName = name
+	}
+
+	branch := "main"
+	if b, exists := args["branch"].(string); exists && b != "" {
+		branch = b
+	}
 
 	dockerfile := "./Dockerfile"
 	if df, exists := args["dockerfile"].(string); exists && df != "" {
@@ -462,9 +1592,9 @@ func (s *Server) repoAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		buildContext = bc
 	}
 
-	registry := "quay.io"
-	if reg, exists := args["registry"].(string); exists && reg != "" {
-		registry = reg
+	registry, err := resolveRepoRegistry(getStringArg(args, "registry", s.defaultRegistry()))
+	if err != nil {
+		return NewTextResult("", err), nil
 	}
 
 	imageName := generateImageName(repoName, registry)
@@ -483,15 +1613,52 @@ func (s *Server) repoAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		Registry:     registry,
 		Namespace:    namespace,
 		Status:       "configured",
+		Active:       true,
+	}
+	if notifyURL := getStringArg(args, "notify_url", ""); notifyURL != "" {
+		if err := validateNotifyURL(notifyURL); err != nil {
+			return NewTextResult("", err), nil
+		}
+		config.NotifyURL = notifyURL
+	}
+
+	// An SSH key is loaded before the repository is stored so a wrong path or passphrase fails early
+	sshKeyPath := getStringArg(args, "ssh_key_path", "")
+	sshKeyPEM := getStringArg(args, "ssh_key", "")
+	if sshKeyPath != "" && sshKeyPEM != "" {
+		return NewTextResult("", fmt.Errorf("ssh_key_path and ssh_key are mutually exclusive")), nil
+	}
+	if sshKeyPath != "" || sshKeyPEM != "" {
+		if strings.HasPrefix(sshKeyPath, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				sshKeyPath = filepath.Join(home, sshKeyPath[2:])
+			}
+		}
+		key := &cicd.SSHKey{Path: sshKeyPath, PEM: sshKeyPEM, Passphrase: getStringArg(args, "ssh_key_passphrase", "")}
+		if _, err := key.AuthMethod(); err != nil {
+			return NewTextResult("", err), nil
+		}
+		if sshKeyPEM != "" {
+			path, err := storeRepoSSHKey(repoName, sshKeyPEM)
+			if err != nil {
+				return NewTextResult("", err), nil
+			}
+			sshKeyPath = path
+		}
+		config.SSHKeyPath = sshKeyPath
+		config.SSHKeyPassphrase = key.Passphrase
+	} else if cicd.IsSSHURL(url) {
+		klog.Warningf("Repository %s uses SSH without an SSH key, the default SSH identity is used", url)
 	}
 
-	// Store configuration
-	repositoryStore[repoName] = config
+	// Store configuration and watch the branch for new commits
+	putRepo(repoName, config)
+	s.watchRepo(config)
 
 	result := map[string]interface{}{
 		"status":     "success",
 		"message":    fmt.Sprintf("Repository '%s' added successfully", repoName),
-		"repository": config,
+		"repository": config.redacted(),
 		"next_steps": []string{
 			fmt.Sprintf("Repository will be monitored for commits on branch '%s'", branch),
 			fmt.Sprintf("Built images will be pushed to '%s'", imageName),
@@ -501,22 +1668,29 @@ func (s *Server) repoAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		},
 	}
 
+	// A branch missing on the remote is a warning, the repository may be created later
+	branchCheck := checkPipelineBranch(ctx, config)
+	result["branch_check"] = branchCheck
+	if branchCheck.Warning != "" {
+		result["warnings"] = []string{branchCheck.Warning}
+	}
+
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
 }
 
 func (s *Server) repoList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	repos := make([]interface{}, 0)
-	for _, config := range repositoryStore {
-		repos = append(repos, config)
+	for _, config := range listRepos() {
+		repos = append(repos, config.redacted())
 	}
 
 	result := map[string]interface{}{
-		"total_repositories": len(repositoryStore),
+		"total_repositories": len(repos),
 		"repositories":       repos,
 	}
 
-	if len(repositoryStore) == 0 {
+	if len(repos) == 0 {
 		result["message"] = "No repositories configured. Use 'repo_add' to add a repository for monitoring."
 	}
 
@@ -536,8 +1710,8 @@ func (s *Server) repoAutoDeploy(ctx context.Context, request mcp.CallToolRequest
 		return NewTextResult("", fmt.Errorf("url parameter is required")), nil
 	}
 
-	namespace, ok := args["namespace"].(string)
-	if !ok || namespace == "" {
+	namespace := getStringArg(args, "namespace", s.defaultNamespace())
+	if namespace == "" {
 		return NewTextResult("", fmt.Errorf("namespace parameter is required")), nil
 	}
 
@@ -551,73 +1725,252 @@ func (s *Server) repoAutoDeploy(ctx context.Context, request mcp.CallToolRequest
 		branch = b
 	}
 
-	registry := "quay.io"
-	if reg, exists := args["image_registry"].(string); exists && reg != "" {
-		registry = reg
+	registry, err := resolveRepoRegistry(getStringArg(args, "image_registry", s.defaultRegistry()))
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	// Detect port, container_port takes precedence over the older port argument
+	detection := detectApp(ctx, url, branch, ".", "./Dockerfile", repoName)
+	appType := detection.Type
+	port := getIntArg(args, "container_port", getIntArg(args, "port", detection.Port))
+	servicePort := getIntArg(args, "service_port", 80)
+	targetPort := "http"
+	switch v := args["target_port"].(type) {
+	case string:
+		if v != "" {
+			targetPort = v
+		}
+	case float64:
+		targetPort = fmt.Sprintf("%d", int(v))
+	case int:
+		targetPort = fmt.Sprintf("%d", v)
 	}
 
-	// Detect port
-	defaultPort, appType := detectAppDetails(repoName)
-	port := defaultPort
-	if p, exists := args["port"]; exists {
-		switch v := p.(type) {
-		case float64:
-			port = int(v)
-		case int:
-			port = v
-		}
+	command, err := getStringSliceArg(args, "command")
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	containerArgs, err := getStringSliceArg(args, "args")
+	if err != nil {
+		return NewTextResult("", err), nil
 	}
 
 	imageName := generateImageName(repoName, registry)
 	imageTag := "latest"
 
-	// Save repo config
-	repositoryStore[repoName] = &RepoConfig{
-		URL:          url,
-		Name:         repoName,
-		Branch:       branch,
-		BuildContext: ".",
-		DockerFile:   "./Dockerfile",
-		ImageName:    imageName,
-		Registry:     registry,
-		Namespace:    namespace,
-		Status:       "deploying",
+	// Save repo config, keeping the settings of a previous configuration this tool does not set
+	config := copyRepo(repoName)
+	added := config.URL == ""
+	config.URL = url
+	config.Name = repoName
+	config.Branch = branch
+	if config.BuildContext == "" {
+		config.BuildContext = "."
+	}
+	if config.DockerFile == "" {
+		config.DockerFile = "./Dockerfile"
+	}
+	config.ImageName = imageName
+	config.Registry = registry
+	config.Namespace = namespace
+	config.LastCommit = getStringArg(args, "commit", "")
+	config.Status = "deploying"
+	if notifyURL := getStringArg(args, "notify_url", ""); notifyURL != "" {
+		if err := validateNotifyURL(notifyURL); err != nil {
+			return NewTextResult("", err), nil
+		}
+		config.NotifyURL = notifyURL
+	}
+
+	environment, _ := args["environment"].(string)
+	override, err := lookupEnvironment(config, environment)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	// A preview neither saves the configuration nor records an execution
+	dryRun, diff := getBoolArg(args, "dry_run", false), getBoolArg(args, "diff", false)
+	preview := dryRun || diff
+	if preview && s.k == nil {
+		return NewTextResult("", fmt.Errorf("dry_run and diff need a cluster connection")), nil
+	}
+	execution := &PipelineExecution{}
+	if !preview {
+		putRepo(repoName, config)
+		if added {
+			s.watchRepo(config)
+		}
+		execution = pipelineExecutions.start(repoName, config.LastCommit, environment)
 	}
 
 	// Generate manifests
 	manifestData := ManifestData{
-		AppName:   repoName,
-		Namespace: namespace,
-		ImageName: imageName,
-		ImageTag:  imageTag,
-		Port:      port,
-		Replicas:  1,
-		Version:   "1.0.0",
+		AppName:     repoName,
+		Namespace:   namespace,
+		ImageName:   imageName,
+		ImageTag:    imageTag,
+		Port:        port,
+		Replicas:    1,
+		Version:     "1.0.0",
+		ServicePort: servicePort,
+		TargetPort:  targetPort,
+		Command:     command,
+		Args:        containerArgs,
+	}
+	applyEnvironmentOverride(&manifestData, override)
+	namespace = manifestData.Namespace
+	manifestData.RouteHost = getStringArg(args, "route_host", "")
+	manifestData.IngressClass = getStringArg(args, "ingress_class", "")
+	err = containerSpecArgs(args, &manifestData)
+	if err == nil {
+		err = autoscalingArgs(args, &manifestData)
+	}
+	if err == nil {
+		manifestData.RouteType, err = s.resolveRouteType(ctx, getStringArg(args, "route_type", routeTypeAuto))
+	}
+	var pullSecret *cicd.ImagePullSecret
+	if err == nil {
+		pullSecret, err = pullSecretArgs(args, imageName)
+	}
+	if pullSecret != nil {
+		manifestData.ImagePullSecret = pullSecret.SecretName(repoName)
+	}
+	var configData *cicd.DeploymentConfig
+	if err == nil {
+		configData, err = configDataArgs(args, &manifestData)
+	}
+	if err != nil {
+		pipelineExecutions.stage(execution, "generate_manifests", "failed", err.Error())
+		pipelineExecutions.finish(execution, "", err.Error())
+		return NewTextResult("", err), nil
+	}
+	// An Ingress without a host matches any host, the URL is known once the controller assigns an address
+	routeHost := manifestData.RouteHost
+	if routeHost == "" && manifestData.RouteType == routeTypeRoute {
+		routeHost = strings.TrimPrefix(s.generateRouteURL(ctx, repoName, namespace), "https://")
 	}
 	manifests, err := generateManifests(manifestData)
 	if err != nil {
+		pipelineExecutions.stage(execution, "generate_manifests", "failed", err.Error())
+		pipelineExecutions.finish(execution, "", fmt.Sprintf("failed to generate manifests: %v", err))
 		return NewTextResult("", fmt.Errorf("failed to generate manifests: %v", err)), nil
 	}
+	pipelineExecutions.stage(execution, "generate_manifests", "succeeded", "")
+
+	// URL, an Ingress is plain HTTP unless TLS is configured on it
+	appURL := "https://" + routeHost
+	if manifestData.RouteType == routeTypeIngress {
+		appURL = "http://" + routeHost
+	}
 
 	// Build YAML strings
 	nsYAML := fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n  labels:\n    app.kubernetes.io/managed-by: ai-mcp-openshift-server\n", namespace)
-	combinedYAML := manifests["deployment.yaml"] + "\n---\n" + manifests["service.yaml"] + "\n---\n" + manifests["route.yaml"]
+	combinedYAML := combineManifests(nsYAML, manifests)
+
+	if preview {
+		k8s, err := s.k.Derived(ctx)
+		if err != nil {
+			return NewTextResult("", fmt.Errorf("failed to connect to the cluster: %v", err)), nil
+		}
+		objects := previewManifestObjects(ctx, k8s, combinedYAML, dryRun, diff)
+		valid := true
+		for _, object := range objects {
+			valid = valid && object.Action != "failed"
+		}
+		result := map[string]interface{}{
+			"status":              "preview",
+			"message":             fmt.Sprintf("Nothing was applied for '%s'", repoName),
+			"dry_run":             dryRun,
+			"diff":                diff,
+			"valid":               valid,
+			"objects":             objects,
+			"generated_manifests": combinedYAML,
+		}
+		jsonResult, _ := json.MarshalIndent(result, "", "  ")
+		return NewTextResult(string(jsonResult), nil), nil
+	}
 
-	// Apply to cluster
+	// Apply to cluster, one object at a time
 	applied := false
+	appliedObjects := make([]ManifestApplyResult, 0)
+	warnings := make([]string, 0)
 	if s.k != nil {
 		if k8s, derr := s.k.Derived(ctx); derr == nil && k8s != nil {
-			if _, nerr := k8s.ResourcesCreateOrUpdate(ctx, nsYAML); nerr == nil {
-				if _, aerr := k8s.ResourcesCreateOrUpdate(ctx, combinedYAML); aerr == nil {
-					applied = true
-					repositoryStore[repoName].Status = "deployed"
+			// Refuse to create a Route whose host is already served by another object
+			conflicts, claimed, cerr := findHostConflicts(ctx, k8s, routeHost, namespace, repoName)
+			if cerr != nil {
+				warnings = append(warnings, fmt.Sprintf("route host check skipped: %v", cerr))
+				pipelineExecutions.stage(execution, "route_host_check", "skipped", cerr.Error())
+			} else if len(conflicts) > 0 {
+				setRepoStatus(config, "failed")
+				conflictErr := fmt.Errorf("route host '%s' is already claimed by %s %s/%s; retry with route_host '%s'",
+					routeHost, conflicts[0].Kind, conflicts[0].Namespace, conflicts[0].Name, suggestAlternativeHost(routeHost, claimed))
+				pipelineExecutions.stage(execution, "route_host_check", "failed", conflictErr.Error())
+				pipelineExecutions.finish(execution, "", conflictErr.Error())
+				return NewTextResult("", conflictErr), nil
+			} else {
+				pipelineExecutions.stage(execution, "route_host_check", "succeeded", "")
+			}
+			// The pull secret and configuration live in the target namespace, which is created first
+			if pullSecret != nil || configData != nil {
+				applyManifestObjects(ctx, k8s, nsYAML)
+			}
+			if pullSecret != nil {
+				_, secretWarnings, err := applyPullSecret(ctx, k8s, namespace, repoName, pullSecret)
+				if err != nil {
+					setRepoStatus(config, "failed")
+					pipelineExecutions.stage(execution, "pull_secret", "failed", err.Error())
+					pipelineExecutions.finish(execution, "", err.Error())
+					return NewTextResult("", fmt.Errorf("failed to prepare image pull secret: %v", err)), nil
+				}
+				pipelineExecutions.stage(execution, "pull_secret", "succeeded", manifestData.ImagePullSecret)
+				warnings = append(warnings, secretWarnings...)
+			}
+			if configData != nil {
+				configLogs, err := applyConfigData(ctx, k8s, configData)
+				if err != nil {
+					setRepoStatus(config, "failed")
+					pipelineExecutions.stage(execution, "config_data", "failed", err.Error())
+					pipelineExecutions.finish(execution, "", err.Error())
+					return NewTextResult("", fmt.Errorf("failed to prepare configuration: %v", err)), nil
+				}
+				pipelineExecutions.stage(execution, "config_data", "succeeded", strings.Join(configLogs, "; "))
+			}
+			appliedObjects = deployManifestObjects(ctx, k8s, combinedYAML)
+			applied = len(appliedObjects) > 0
+			for _, object := range appliedObjects {
+				if object.Action != "failed" {
+					continue
+				}
+				if object.Critical {
+					applied = false
+				} else {
+					warnings = append(warnings, fmt.Sprintf("%s %s was not applied: %s", object.Kind, object.Name, object.Error))
+				}
+			}
+			if applied {
+				setRepoStatus(config, "deployed")
+				if manifestData.RouteType == routeTypeIngress {
+					if url, err := fetchIngressURL(ctx, k8s, namespace, repoName); err == nil {
+						appURL = url
+					} else {
+						warnings = append(warnings, fmt.Sprintf("external URL not known yet: %v", err))
+					}
 				}
 			}
 		}
 	}
 
-	// URL
-	appURL := generateRouteURL(repoName, namespace)
+	if applied {
+		pipelineExecutions.stage(execution, "apply", "succeeded", fmt.Sprintf("%d objects applied", len(appliedObjects)))
+		pipelineExecutions.finish(execution, appURL, "")
+	} else if len(appliedObjects) > 0 {
+		pipelineExecutions.stage(execution, "apply", "failed", "one or more critical objects failed to apply")
+		pipelineExecutions.finish(execution, "", "manifests were not applied")
+	} else {
+		pipelineExecutions.stage(execution, "apply", "skipped", "cluster not available")
+		pipelineExecutions.finish(execution, "", "manifests were not applied: cluster not available")
+	}
 
 	result := map[string]interface{}{
 		"status":  "success",
@@ -629,14 +1982,26 @@ func (s *Server) repoAutoDeploy(ctx context.Context, request mcp.CallToolRequest
 			"registry": registry,
 		},
 		"application": map[string]interface{}{
-			"name":      repoName,
-			"type":      appType,
-			"port":      port,
-			"namespace": namespace,
-			"url":       appURL,
+			"name":       repoName,
+			"type":       appType,
+			"detection":  detection,
+			"port":       port,
+			"namespace":  namespace,
+			"url":        appURL,
+			"route_type": manifestData.RouteType,
+			"replicas":   manifestData.Replicas,
+			"ports": map[string]interface{}{
+				"container_port": port,
+				"service_port":   servicePort,
+				"target_port":    targetPort,
+			},
+			"command": command,
+			"args":    containerArgs,
 		},
 		"generated_manifests": manifests,
+		"execution_id":        execution.ID,
 		"applied":             applied,
+		"applied_objects":     appliedObjects,
 		"next_steps": []string{
 			"Create namespace if not exists",
 			"Build image and push to registry",
@@ -644,6 +2009,24 @@ func (s *Server) repoAutoDeploy(ctx context.Context, request mcp.CallToolRequest
 			"Expose application via Route",
 		},
 	}
+	if getBoolArg(args, "persist_logs", false) && !preview {
+		ref, err := s.persistBuildArtifact(ctx, imageName, ".pipeline.log", []byte(executionLog(execution, appliedObjects)))
+		if err != nil {
+			klog.V(1).Infof("Warning: failed to persist pipeline log: %v", err)
+			result["pipeline_log_error"] = err.Error()
+		} else {
+			result["pipeline_log"] = ref
+		}
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	if environment != "" {
+		result["environment"] = environment
+	}
+	if manifestData.ImagePullSecret != "" {
+		result["application"].(map[string]interface{})["pull_secret"] = manifestData.ImagePullSecret
+	}
 
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
@@ -662,13 +2045,7 @@ func (s *Server) repoGenerateManifests(ctx context.Context, request mcp.CallTool
 	}
 
 	// Lookup repo
-	var config *RepoConfig
-	for key, repo := range repositoryStore {
-		if key == name || repo.URL == name || repo.Name == name {
-			config = repo
-			break
-		}
-	}
+	config := findRepo(name)
 	if config == nil {
 		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
 	}
@@ -677,8 +2054,13 @@ func (s *Server) repoGenerateManifests(ctx context.Context, request mcp.CallTool
 	if tag, exists := args["image_tag"].(string); exists && tag != "" {
 		imageTag = tag
 	}
+	format := getStringArg(args, "format", "json")
+	if format != "json" && format != "yaml" && format != "raw" {
+		return NewTextResult("", fmt.Errorf("format must be 'json', 'yaml' or 'raw', got '%s'", format)), nil
+	}
 
-	port, appType := detectAppDetails(config.Name)
+	detection := detectRepoApp(ctx, config)
+	port, appType := detection.Port, detection.Type
 	data := ManifestData{
 		AppName:   config.Name,
 		Namespace: config.Namespace,
@@ -688,6 +2070,19 @@ func (s *Server) repoGenerateManifests(ctx context.Context, request mcp.CallTool
 		Replicas:  1,
 		Version:   "1.0.0",
 	}
+	if err := containerSpecArgs(args, &data); err != nil {
+		return NewTextResult("", err), nil
+	}
+	if err := autoscalingArgs(args, &data); err != nil {
+		return NewTextResult("", err), nil
+	}
+	routeType, err := s.resolveRouteType(ctx, getStringArg(args, "route_type", routeTypeAuto))
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	data.RouteType = routeType
+	data.IngressClass = getStringArg(args, "ingress_class", "")
+	data.RouteHost = getStringArg(args, "host", "")
 	manifests, err := generateManifests(data)
 	if err != nil {
 		return NewTextResult("", fmt.Errorf("failed to generate manifests: %v", err)), nil
@@ -697,10 +2092,14 @@ func (s *Server) repoGenerateManifests(ctx context.Context, request mcp.CallTool
 		"status":     "success",
 		"repository": config.Name,
 		"app_type":   appType,
+		"detection":  detection,
 		"manifests":  manifests,
 	}
-	jsonResult, _ := json.MarshalIndent(result, "", "  ")
-	return NewTextResult(string(jsonResult), nil), nil
+	output, err := manifestsOutput(format, result, manifests)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	return NewTextResult(output, nil), nil
 }
 
 // Return live URLs for a repo
@@ -716,19 +2115,23 @@ func (s *Server) repoGetURL(ctx context.Context, request mcp.CallToolRequest) (*
 	}
 
 	// Lookup repo
-	var config *RepoConfig
-	for key, repo := range repositoryStore {
-		if key == name || repo.URL == name || repo.Name == name {
-			config = repo
-			break
-		}
-	}
+	config := findRepo(name)
 	if config == nil {
 		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
 	}
 
-	port, _ := detectAppDetails(config.Name)
-	appURL := generateRouteURL(config.Name, config.Namespace)
+	port := detectRepoApp(ctx, config).Port
+	appURL := s.generateRouteURL(ctx, config.Name, config.Namespace)
+	url, liveStatus, err := s.cachedClusterRead(ctx, "route/"+config.Namespace+"/"+config.Name, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		return fetchAppURL(ctx, k, config.Namespace, config.Name)
+	})
+	// Without a Route or Ingress the URL the cluster would assign is returned
+	if err != nil && !apierrors.IsNotFound(err) {
+		return NewTextResult("", fmt.Errorf("failed to read the URL of '%s': %v", config.Name, err)), nil
+	}
+	if u, ok := url.(string); ok && u != "" {
+		appURL = u
+	}
 	result := map[string]interface{}{
 		"status":     "success",
 		"repository": config.Name,
@@ -738,6 +2141,7 @@ func (s *Server) repoGetURL(ctx context.Context, request mcp.CallToolRequest) (*
 			"internal_service": fmt.Sprintf("%s.%s.svc.cluster.local:%d", config.Name, config.Namespace, port),
 			"health_check":     fmt.Sprintf("%s/health", appURL),
 		},
+		"data_status": liveStatus,
 	}
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
@@ -755,29 +2159,45 @@ func (s *Server) repoStatus(ctx context.Context, request mcp.CallToolRequest) (*
 	}
 
 	// Find repository by name or URL
-	var config *RepoConfig
-	for key, repo := range repositoryStore {
-		if key == name || repo.URL == name || repo.Name == name {
-			config = repo
-			break
-		}
-	}
+	config := findRepo(name)
 
 	if config == nil {
 		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
 	}
 
+	limit := getIntArg(args, "limit", 5)
+	if limit <= 0 || limit > executionHistoryLimit {
+		limit = executionHistoryLimit
+	}
+	executions := pipelineExecutions.recent(config.Name, limit)
+	pipelineStatus := map[string]interface{}{
+		"status":      config.Status,
+		"last_commit": config.LastCommit,
+	}
+	if len(executions) > 0 {
+		pipelineStatus["last_execution"] = executions[0]
+	}
+	health, err := s.liveClusterRead(ctx, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		return fetchDeploymentHealth(ctx, k, config.Namespace, config.Name, 5)
+	})
+	switch {
+	case apierrors.IsNotFound(err):
+		pipelineStatus["deployment"] = "not deployed"
+	case err != nil:
+		pipelineStatus["deployment"] = fmt.Sprintf("unavailable: %v", err)
+	default:
+		pipelineStatus["deployment"] = health
+	}
+
 	result := map[string]interface{}{
-		"repository": config,
-		"pipeline_status": map[string]interface{}{
-			"monitoring":   "active",
-			"last_build":   "not available",
-			"last_deploy":  "not available",
-			"health_check": "pending",
-		},
+		"repository":        config.redacted(),
+		"pipeline_status":   pipelineStatus,
+		"recent_executions": executions,
+		"branch_check":      checkPipelineBranch(ctx, config),
 		"available_actions": []string{
 			"repo_build - Trigger a manual build",
 			"repo_deploy - Deploy to OpenShift",
+			"deployment_status - Diagnose the deployed application",
 			"repo_remove - Remove from monitoring",
 		},
 	}
@@ -786,6 +2206,46 @@ func (s *Server) repoStatus(ctx context.Context, request mcp.CallToolRequest) (*
 	return NewTextResult(string(jsonResult), nil), nil
 }
 
+// envVarNamePattern matches the environment variable names accepted in generated manifests
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateEnvNames rejects environment variable names that are not valid shell identifiers
+func validateEnvNames(env map[string]string) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !envVarNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name '%s', names must match %s", name, envVarNamePattern)
+		}
+	}
+	return nil
+}
+
+// invalidImageTagChars matches the characters not allowed in an image tag
+var invalidImageTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// commitHashPattern matches a full or abbreviated commit SHA
+var commitHashPattern = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// commitImageTag derives an image tag from a commit reference: commit SHAs are shortened to their
+// first 8 characters (kept whole when shorter) and other references are sanitized to a valid tag.
+func commitImageTag(commit string) string {
+	if commitHashPattern.MatchString(commit) {
+		return commit[:min(len(commit), 8)]
+	}
+	tag := strings.TrimLeft(invalidImageTagChars.ReplaceAllString(commit, "-"), ".-")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	if tag == "" {
+		return "latest"
+	}
+	return tag
+}
+
 func (s *Server) repoBuild(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args, ok := request.Params.Arguments.(map[string]interface{})
 	if !ok {
@@ -798,13 +2258,7 @@ func (s *Server) repoBuild(ctx context.Context, request mcp.CallToolRequest) (*m
 	}
 
 	// Find repository
-	var config *RepoConfig
-	for key, repo := range repositoryStore {
-		if key == name || repo.URL == name || repo.Name == name {
-			config = repo
-			break
-		}
-	}
+	config := findRepo(name)
 
 	if config == nil {
 		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
@@ -820,37 +2274,186 @@ func (s *Server) repoBuild(ctx context.Context, request mcp.CallToolRequest) (*m
 		commit = c
 	}
 
-	// Simulate build process (in real implementation, this would create Kubernetes Jobs)
+	// s2i builds run on the cluster, which pushes the image itself
+	strategy := getStringArg(args, "strategy", "docker")
+	if strategy != "docker" && strategy != "s2i" {
+		return NewTextResult("", fmt.Errorf("strategy must be 'docker' or 's2i', got '%s'", strategy)), nil
+	}
+	builderImage := getStringArg(args, "builder_image", "")
+	if builderImage != "" && strategy != "s2i" {
+		return NewTextResult("", fmt.Errorf("builder_image is only used by the s2i strategy")), nil
+	}
+	var restConfig *rest.Config
+	if strategy == "s2i" {
+		if s.k == nil {
+			return NewTextResult("", fmt.Errorf("s2i builds need a cluster connection")), nil
+		}
+		k8s, err := s.k.Derived(ctx)
+		if err == nil {
+			restConfig, err = k8s.ToRESTConfig()
+		}
+		if err != nil {
+			return NewTextResult("", fmt.Errorf("failed to access cluster: %v", err)), nil
+		}
+	}
+
+	// Build from a fresh checkout of the configured branch, or of the requested commit
+	checkout := ""
+	if commit != "latest" {
+		checkout = commit
+	}
+	persistLogs := getBoolArg(args, "persist_logs", false)
+	setRepoStatus(config, "building")
+	buildFailed := func(err error) (*mcp.CallToolResult, error) {
+		updateRepo(config, func(config *RepoConfig) {
+			config.Status = "error"
+			config.LastError = err.Error()
+		})
+		return NewTextResult("", fmt.Errorf("build failed for repository '%s': %v", config.Name, err)), nil
+	}
+
+	sourceDir, err := s.cloneGitRepository(ctx, config.URL, config.Branch, checkout)
+	if err != nil {
+		return buildFailed(err)
+	}
+	defer func() { _ = os.RemoveAll(sourceDir) }()
+
+	// Tag the image with the commit actually built, the branch head when no commit was requested
+	if head, err := resolveHeadCommit(ctx, sourceDir); err == nil {
+		checkout = head
+		commit = head
+	}
+
+	builder, err := cicd.NewImageBuilder(restConfig, config.Namespace)
+	if err != nil {
+		return buildFailed(err)
+	}
+	buildResult, err := builder.BuildImage(ctx, cicd.BuildConfig{
+		Name:          config.Name,
+		Namespace:     config.Namespace,
+		SourceRepo:    config.URL,
+		SourceBranch:  config.Branch,
+		SourceCommit:  checkout,
+		Dockerfile:    filepath.ToSlash(filepath.Clean(config.DockerFile)),
+		ContextPath:   filepath.Join(sourceDir, config.BuildContext),
+		ContextDir:    filepath.ToSlash(config.BuildContext),
+		ImageName:     config.ImageName,
+		ImageTag:      commitImageTag(commit),
+		Labels:        map[string]string{"app.kubernetes.io/managed-by": "ai-mcp-openshift-server"},
+		BuildStrategy: strategy,
+		BuilderImage:  builderImage,
+		LogWriter:     buildLogWriter{name: config.Name},
+	})
+	if err != nil {
+		return buildFailed(err)
+	}
+	// The Docker API reports build failures inside the log stream
+	if buildResult.Error == nil && strategy == "docker" {
+		buildResult.Error = dockerStreamError(buildResult.BuildLogs)
+	}
+	buildResult.Success = buildResult.Error == nil
+	var buildLogRef string
+	var buildLogErr error
+	if persistLogs {
+		if buildLogRef, buildLogErr = s.persistBuildLog(ctx, config.ImageName, buildResult.BuildLogs); buildLogErr != nil {
+			klog.V(1).Infof("Warning: failed to persist build log: %v", buildLogErr)
+		}
+	}
+	if !buildResult.Success {
+		if buildLogRef != "" {
+			return buildFailed(fmt.Errorf("%v\nFull log: %s", buildResult.Error, buildLogRef))
+		}
+		return buildFailed(buildResult.Error)
+	}
+
+	logs, truncated := tailLines(strings.TrimSpace(buildResult.BuildLogs), buildLogResponseLines)
 	result := map[string]interface{}{
 		"status":  "success",
-		"message": fmt.Sprintf("Build triggered for repository '%s'", config.Name),
+		"message": fmt.Sprintf("Built image %s for repository '%s'", buildResult.FullImageName, config.Name),
 		"build_info": map[string]interface{}{
 			"repository":    config.URL,
 			"branch":        config.Branch,
 			"commit":        commit,
 			"dockerfile":    config.DockerFile,
 			"build_context": config.BuildContext,
-			"target_image":  config.ImageName,
+			"strategy":      strategy,
+			"target_image":  buildResult.FullImageName,
 			"push_enabled":  push,
 		},
-		"next_steps": []string{
-			"Build job will be created in OpenShift",
-			"Image will be built using Docker-in-Docker",
+		"build": map[string]interface{}{
+			"image":          buildResult.FullImageName,
+			"build_time":     buildResult.BuildTime.Round(time.Millisecond).String(),
+			"success":        buildResult.Success,
+			"logs":           logs,
+			"logs_truncated": truncated,
 		},
 	}
 
-	if push {
-		result["next_steps"] = append(result["next_steps"].([]string),
-			fmt.Sprintf("Image will be pushed to %s", config.Registry))
+	if buildLogRef != "" {
+		result["build"].(map[string]interface{})["build_log"] = buildLogRef
+	} else if buildLogErr != nil {
+		result["build"].(map[string]interface{})["build_log_error"] = buildLogErr.Error()
+	}
+
+	if strategy == "s2i" {
+		result["build"].(map[string]interface{})["builder_image"] = buildResult.BuilderImage
+		result["push"] = map[string]interface{}{
+			"image":     buildResult.FullImageName,
+			"pushed_by": "openshift build",
+			"success":   true,
+		}
+	} else if push {
+		pusher, err := cicd.NewRegistryPusher(nil)
+		if err != nil {
+			return buildFailed(err)
+		}
+		pushResult, err := pusher.PushImage(ctx, cicd.PushConfig{
+			SourceImage:  buildResult.FullImageName,
+			TargetImage:  config.ImageName,
+			TargetTag:    commitImageTag(commit),
+			RegistryAuth: repoRegistryAuth(config.Registry),
+		})
+		if err == nil {
+			err = pushResult.Error
+		}
+		if err == nil {
+			err = dockerStreamError(pushResult.PushLogs)
+		}
+		if err != nil {
+			return buildFailed(fmt.Errorf("image built but push to %s failed: %v", config.Registry, err))
+		}
+		result["push"] = map[string]interface{}{
+			"image":     pushResult.FullImageName,
+			"push_time": pushResult.PushTime.Round(time.Millisecond).String(),
+			"success":   true,
+		}
 	}
 
-	// Update repository status
-	config.Status = "building"
+	updateRepo(config, func(config *RepoConfig) {
+		config.Status = "built"
+		config.LastError = ""
+		if checkout != "" {
+			config.LastCommit = checkout
+		}
+	})
 
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
 }
 
+// dockerStreamError returns the first error reported in a Docker API JSON message stream
+func dockerStreamError(stream string) error {
+	for _, line := range strings.Split(stream, "\n") {
+		var message struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal([]byte(line), &message) == nil && message.Error != "" {
+			return fmt.Errorf("%s", strings.TrimSpace(message.Error))
+		}
+	}
+	return nil
+}
+
 func (s *Server) repoDeploy(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args, ok := request.Params.Arguments.(map[string]interface{})
 	if !ok {
@@ -863,55 +2466,245 @@ func (s *Server) repoDeploy(ctx context.Context, request mcp.CallToolRequest) (*
 	}
 
 	// Find repository
-	var config *RepoConfig
-	for key, repo := range repositoryStore {
-		if key == name || repo.URL == name || repo.Name == name {
-			config = repo
-			break
-		}
-	}
+	config := findRepo(name)
 
 	if config == nil {
 		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
 	}
 
+	environment, _ := args["environment"].(string)
+	override, err := lookupEnvironment(config, environment)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+
 	targetNamespace := config.Namespace
+	if override != nil && override.Namespace != "" {
+		targetNamespace = override.Namespace
+	}
 	if ns, exists := args["namespace"].(string); exists && ns != "" {
 		targetNamespace = ns
 	}
 
 	imageTag := "latest"
+	if override != nil && override.ImageTag != "" {
+		imageTag = override.ImageTag
+	}
 	if tag, exists := args["image_tag"].(string); exists && tag != "" {
 		imageTag = tag
 	}
 
 	deploymentImage := fmt.Sprintf("%s:%s", config.ImageName, imageTag)
 
+	var verification *ImageVerificationResult
+	if requireVerification, _ := args["require_verification"].(bool); requireVerification {
+		var err error
+		verification, err = s.performImageVerification(ctx, deploymentImage, s.imageVerificationPolicy(), false)
+		if err != nil {
+			return NewTextResult("", fmt.Errorf("image verification failed for '%s': %v", deploymentImage, err)), nil
+		}
+		if !verification.Verified {
+			return NewTextResult("", fmt.Errorf("refusing to deploy unverified image '%s': %s", deploymentImage, verification.SignatureError)), nil
+		}
+	}
+
+	// Refuse images that cannot run on any node; a check that cannot run only warns
+	var platformCheck *PlatformCheck
+	var platformWarning string
+	if checkPlatforms := getBoolArg(args, "check_platforms", true); checkPlatforms && s.k != nil {
+		if k8s, derr := s.k.Derived(ctx); derr == nil {
+			platformCheck, err = s.checkImagePlatforms(ctx, k8s, deploymentImage, false, false, false)
+			if err != nil {
+				platformWarning = fmt.Sprintf("platform check skipped: %v", err)
+			} else if platformCheck.Error != "" {
+				return NewTextResult("", fmt.Errorf("refusing to deploy '%s': %s", deploymentImage, platformCheck.Error)), nil
+			} else {
+				platformWarning = platformCheck.Warning
+			}
+		}
+	}
+
+	if s.k == nil {
+		return NewTextResult("", fmt.Errorf("kubernetes manager is not initialized")), nil
+	}
+	k8s, err := s.k.Derived(ctx)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to access cluster: %v", err)), nil
+	}
+
+	// Generate manifests from the stored configuration; explicit arguments win over environment overrides
+	port := detectRepoApp(ctx, config).Port
+	manifestData := ManifestData{
+		AppName:   config.Name,
+		Namespace: config.Namespace,
+		ImageName: config.ImageName,
+		Port:      port,
+		Replicas:  1,
+	}
+	applyEnvironmentOverride(&manifestData, override)
+	manifestData.Namespace = targetNamespace
+	manifestData.ImageTag = imageTag
+	manifestData.Version = imageTag
+	if err := containerSpecArgs(args, &manifestData); err != nil {
+		return NewTextResult("", err), nil
+	}
+	if err := autoscalingArgs(args, &manifestData); err != nil {
+		return NewTextResult("", err), nil
+	}
+	strategy, err := deployStrategyArg(args, config)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	if strategy == cicd.StrategyBlueGreen && manifestData.MaxReplicas > 0 {
+		return NewTextResult("", fmt.Errorf("autoscaling is not supported with the %s strategy", cicd.StrategyBlueGreen)), nil
+	}
+	manifestData.Strategy = strategy
+	if manifestData.RouteType, err = s.resolveRouteType(ctx, getStringArg(args, "route_type", routeTypeAuto)); err != nil {
+		return NewTextResult("", err), nil
+	}
+	manifestData.IngressClass = getStringArg(args, "ingress_class", "")
+	manifestData.RouteHost = getStringArg(args, "host", "")
+	pullSecret, err := pullSecretArgs(args, deploymentImage)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	if pullSecret != nil {
+		manifestData.ImagePullSecret = pullSecret.SecretName(config.Name)
+	}
+	configData, err := configDataArgs(args, &manifestData)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	manifests, err := generateManifests(manifestData)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to generate manifests: %v", err)), nil
+	}
+
+	// The pull secret and configuration live in the target namespace, which is created first
+	nsYAML := fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n  labels:\n    app.kubernetes.io/managed-by: ai-mcp-openshift-server\n", targetNamespace)
+	warnings := make([]string, 0)
+	if pullSecret != nil || configData != nil {
+		applyManifestObjects(ctx, k8s, nsYAML)
+	}
+	if pullSecret != nil {
+		_, secretWarnings, err := applyPullSecret(ctx, k8s, targetNamespace, config.Name, pullSecret)
+		if err != nil {
+			return NewTextResult("", fmt.Errorf("failed to prepare image pull secret: %v", err)), nil
+		}
+		warnings = append(warnings, secretWarnings...)
+	}
+	var configLogs []string
+	if configData != nil {
+		if configLogs, err = applyConfigData(ctx, k8s, configData); err != nil {
+			return NewTextResult("", fmt.Errorf("failed to prepare configuration: %v", err)), nil
+		}
+	}
+
+	setRepoStatus(config, "deploying")
+	execution := pipelineExecutions.start(config.Name, config.LastCommit, environment)
+	pipelineExecutions.stage(execution, "generate_manifests", "succeeded", "")
+
+	// Apply to cluster, one object at a time. Blue-green slots are rolled out by the deployment
+	// automation, which also takes over applications already deployed blue-green.
+	var appliedObjects []ManifestApplyResult
+	var rollout *cicd.DeploymentResult
+	if strategy == cicd.StrategyBlueGreen || deployedBlueGreen(ctx, k8s, targetNamespace, config.Name) {
+		rollout, appliedObjects, err = rolloutApplication(ctx, k8s, manifestData, configData, strategy, manifests)
+		if err != nil {
+			appliedObjects = []ManifestApplyResult{{Kind: "Deployment", Name: config.Name, Namespace: targetNamespace, Action: "failed", Critical: true, Error: err.Error()}}
+		}
+	} else {
+		appliedObjects = deployManifestObjects(ctx, k8s, combineManifests(nsYAML, manifests))
+	}
+	applied := len(appliedObjects) > 0
+	if platformWarning != "" {
+		warnings = append(warnings, platformWarning)
+	}
+	for _, object := range appliedObjects {
+		if object.Action != "failed" {
+			continue
+		}
+		if object.Critical {
+			applied = false
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s %s was not applied: %s", object.Kind, object.Name, object.Error))
+		}
+	}
+
+	// Report the host the router or ingress controller assigned, falling back to the generated one
+	appURL := s.generateRouteURL(ctx, config.Name, targetNamespace)
+	if applied {
+		if url, err := fetchAppURL(ctx, k8s, targetNamespace, config.Name); err == nil {
+			appURL = url
+		}
+		updateRepo(config, func(config *RepoConfig) {
+			config.Status = "deployed"
+			config.Strategy = strategy
+		})
+		pipelineExecutions.stage(execution, "apply", "succeeded", fmt.Sprintf("%d objects applied", len(appliedObjects)))
+		pipelineExecutions.finish(execution, appURL, "")
+	} else {
+		setRepoStatus(config, "error")
+		pipelineExecutions.stage(execution, "apply", "failed", "one or more critical objects failed to apply")
+		pipelineExecutions.finish(execution, "", "manifests were not applied")
+	}
+
+	status := "success"
+	message := fmt.Sprintf("Repository '%s' deployed to namespace '%s'", config.Name, targetNamespace)
+	if !applied {
+		status = "failed"
+		message = fmt.Sprintf("Deployment of repository '%s' to namespace '%s' failed", config.Name, targetNamespace)
+	}
 	result := map[string]interface{}{
-		"status":  "success",
-		"message": fmt.Sprintf("Deployment triggered for repository '%s'", config.Name),
+		"status":  status,
+		"message": message,
 		"deployment_info": map[string]interface{}{
 			"repository":       config.URL,
 			"image":            deploymentImage,
 			"target_namespace": targetNamespace,
 			"deployment_name":  config.Name,
+			"replicas":         manifestData.Replicas,
+			"url":              appURL,
+			"route_type":       manifestData.RouteType,
+			"pull_secret":      manifestData.ImagePullSecret,
+			"strategy":         strategy,
 		},
-		"kubernetes_resources": []string{
-			"Deployment",
-			"Service",
-			"ConfigMap (if needed)",
-			"Route/Ingress (for external access)",
-		},
+		"execution_id":    execution.ID,
+		"applied":         applied,
+		"applied_objects": appliedObjects,
 		"next_steps": []string{
-			fmt.Sprintf("Deployment will be created in namespace '%s'", targetNamespace),
-			"Service will expose the application internally",
-			"Route will provide external access",
-			"Use 'pods_list_in_namespace' to check pod status",
+			fmt.Sprintf("Use 'pods_list_in_namespace' with namespace '%s' to check pod status", targetNamespace),
+			fmt.Sprintf("Use 'repo_status' with name '%s' to review the deployment history", config.Name),
 		},
 	}
-
-	// Update repository status
-	config.Status = "deploying"
+	if verification != nil {
+		result["image_verification"] = verification
+	}
+	if len(configLogs) > 0 {
+		result["config_data"] = configLogs
+	}
+	if rollout != nil {
+		rolloutResult := map[string]interface{}{
+			"strategy":          rollout.Strategy,
+			"active_deployment": rollout.ActiveDeployment,
+			"replicas":          rollout.Replicas,
+			"logs":              rollout.Logs,
+		}
+		if rollout.Error != nil {
+			rolloutResult["error"] = rollout.Error.Error()
+		}
+		result["rollout"] = rolloutResult
+	}
+	if platformCheck != nil {
+		result["platform_check"] = platformCheck
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	if override != nil {
+		result["environment"] = environment
+		result["environment_overrides"] = override
+	}
 
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
@@ -934,35 +2727,44 @@ func (s *Server) repoRemove(ctx context.Context, request mcp.CallToolRequest) (*
 	}
 
 	// Find and remove repository
-	var config *RepoConfig
-	var key string
-	for k, repo := range repositoryStore {
-		if k == name || repo.URL == name || repo.Name == name {
-			config = repo
-			key = k
-			break
-		}
-	}
-
+	key, config := lookupRepo(name)
 	if config == nil {
 		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
 	}
 
-	// Remove from store
-	delete(repositoryStore, key)
+	// Remove from store, even when some deployed resources cannot be deleted
+	removeRepo(key)
+	unwatched := s.unwatchRepo(config)
 
 	result := map[string]interface{}{
-		"status":  "success",
-		"message": fmt.Sprintf("Repository '%s' removed from monitoring", config.Name),
-		"removed": config,
-		"cleanup": cleanup,
+		"status":                 "success",
+		"message":                fmt.Sprintf("Repository '%s' removed from monitoring", config.Name),
+		"removed":                config.redacted(),
+		"cleanup":                cleanup,
+		"remaining_repositories": len(listRepos()),
+		"git_watcher_updated":    unwatched,
 	}
 
 	if cleanup {
-		result["cleanup_actions"] = []string{
-			fmt.Sprintf("Deployment '%s' will be deleted from namespace '%s'", config.Name, config.Namespace),
-			"Associated services and routes will be removed",
-			"ConfigMaps and secrets will be cleaned up",
+		var cleanupResults []ResourceCleanup
+		if s.k == nil {
+			cleanupResults = []ResourceCleanup{{Kind: "*", Name: config.Name, Namespace: config.Namespace, Status: "failed", Error: "kubernetes manager is not initialized"}}
+		} else if k8s, err := s.k.Derived(ctx); err != nil {
+			cleanupResults = []ResourceCleanup{{Kind: "*", Name: config.Name, Namespace: config.Namespace, Status: "failed", Error: fmt.Sprintf("failed to access cluster: %v", err)}}
+		} else {
+			cleanupResults = deleteAppResources(ctx, k8s, config.Namespace, config.Name)
+		}
+		result["cleanup_results"] = cleanupResults
+		failed := make([]string, 0)
+		for _, cleanupResult := range cleanupResults {
+			if cleanupResult.Status == "failed" {
+				failed = append(failed, fmt.Sprintf("%s %s: %s", cleanupResult.Kind, cleanupResult.Name, cleanupResult.Error))
+			}
+		}
+		if len(failed) > 0 {
+			result["status"] = "partial"
+			result["message"] = fmt.Sprintf("Repository '%s' removed from monitoring, but %d resource(s) could not be deleted from namespace '%s'", config.Name, len(failed), config.Namespace)
+			result["cleanup_failures"] = failed
 		}
 	} else {
 		result["note"] = "Deployed resources were not cleaned up. Use cleanup=true to remove deployed applications."
@@ -1013,23 +2815,57 @@ func (s *Server) namespaceCreate(ctx context.Context, request mcp.CallToolReques
 	return NewTextResult(string(jsonResult), nil), nil
 }
 
+// ResourceCleanup is the outcome of deleting one deployed resource of an application
+type ResourceCleanup struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Status    string `json:"status"` // deleted, not_found or failed
+	Error     string `json:"error,omitempty"`
+}
+
+// deleteAppResources deletes the Route, Service and Deployment named after an application.
+// Resources that are already gone, or kinds the cluster does not serve, count as cleaned up.
+func deleteAppResources(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) []ResourceCleanup {
+	results := make([]ResourceCleanup, 0, 3)
+	for _, gvk := range []schema.GroupVersionKind{routeGVK, serviceGVK, deploymentGVK} {
+		result := ResourceCleanup{Kind: gvk.Kind, Name: name, Namespace: namespace, Status: "deleted"}
+		if err := k.ResourcesDelete(ctx, &gvk, namespace, name); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				result.Status = "not_found"
+			} else {
+				result.Status = "failed"
+				result.Error = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 func (s *Server) cicdStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	totalRepos := len(repositoryStore)
+	repos := listRepos()
+	totalRepos := len(repos)
 
 	statusCounts := map[string]int{
 		"configured": 0,
 		"building":   0,
+		"built":      0,
 		"deploying":  0,
 		"deployed":   0,
 		"error":      0,
 	}
 
-	for _, repo := range repositoryStore {
+	for _, repo := range repos {
 		if count, exists := statusCounts[repo.Status]; exists {
 			statusCounts[repo.Status] = count + 1
 		}
 	}
 
+	deployedApps, liveStatus, _ := s.cachedClusterRead(ctx, "applications/", func(k *internalk8s.Kubernetes) (interface{}, error) {
+		return fetchManagedApplications(ctx, k, "")
+	})
+
 	result := map[string]interface{}{
 		"status":  "operational",
 		"message": "CI/CD system is ready for multi-repository automation",
@@ -1049,6 +2885,8 @@ func (s *Server) cicdStatus(ctx context.Context, request mcp.CallToolRequest) (*
 			"total_repositories": totalRepos,
 			"status_breakdown":   statusCounts,
 		},
+		"deployed_applications": deployedApps,
+		"data_status":           liveStatus,
 		"available_tools": []string{
 			"repo_add - Add repository for monitoring",
 			"repo_list - List all monitored repositories",
@@ -1057,6 +2895,15 @@ func (s *Server) cicdStatus(ctx context.Context, request mcp.CallToolRequest) (*
 			"repo_deploy - Deploy to OpenShift",
 			"repo_remove - Remove repository",
 			"namespace_create - Create new namespace",
+			"list_applications - List deployed applications",
+			"repo_env_set - Set per-environment deployment overrides",
+			"repo_env_get - View per-environment deployment overrides",
+			"repo_image_diff - Compare configured and live images",
+			"route_check_host - Check a route host is not already claimed",
+			"application_verify - Verify a deployed application end to end",
+			"repo_generate_overlays - Generate kustomize overlays per environment",
+			"cicd_await_next - Wait for a repository's next pipeline execution",
+			"cicd_pipeline_status - Get the status of a pipeline execution",
 		},
 	}
 
@@ -1071,3 +2918,430 @@ func (s *Server) cicdStatus(ctx context.Context, request mcp.CallToolRequest) (*
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
 }
+
+func (s *Server) listApplications(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		args = make(map[string]interface{})
+	}
+	namespace := getStringArg(args, "namespace", "")
+
+	apps, liveStatus, err := s.cachedClusterRead(ctx, "applications/"+namespace, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		return fetchManagedApplications(ctx, k, namespace)
+	})
+	if err != nil || liveStatus.Source == "unavailable" {
+		return NewTextResult("", fmt.Errorf("failed to list applications: %s", liveStatus.Error)), nil
+	}
+
+	result := map[string]interface{}{
+		"namespace":    namespace,
+		"applications": apps,
+		"data_status":  liveStatus,
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// findRepo looks up a repository by key, URL or name and returns a copy of its configuration,
+// which is changed through updateRepo
+func findRepo(name string) *RepoConfig {
+	_, repo := lookupRepo(name)
+	return repo
+}
+
+// lookupRepo looks up a repository by key, URL or name and returns its store key and a copy of its
+// configuration
+func lookupRepo(name string) (string, *RepoConfig) {
+	repositoryStoreMu.RLock()
+	defer repositoryStoreMu.RUnlock()
+	for key, repo := range repositoryStore {
+		if key == name || repo.URL == name || repo.Name == name {
+			config := *repo
+			return key, &config
+		}
+	}
+	return "", nil
+}
+
+// Update the configuration of a monitored repo, leaving the fields not provided intact
+func (s *Server) repoUpdate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	previous, updated := *config, *config
+	updated.Branch = getStringArg(args, "branch", config.Branch)
+	updated.DockerFile = getStringArg(args, "dockerfile", config.DockerFile)
+	updated.BuildContext = getStringArg(args, "build_context", config.BuildContext)
+	updated.Namespace = getStringArg(args, "namespace", config.Namespace)
+	if registry := getStringArg(args, "registry", ""); registry != "" {
+		resolved, err := resolveRepoRegistry(registry)
+		if err != nil {
+			return NewTextResult("", err), nil
+		}
+		updated.Registry = resolved
+		// Only an image name generated from the previous registry follows the registry
+		if updated.Registry != config.Registry && config.ImageName == generateImageName(config.Name, config.Registry) {
+			updated.ImageName = generateImageName(config.Name, updated.Registry)
+		}
+	}
+	updated.ImageName = getStringArg(args, "image_name", updated.ImageName)
+	if notifyURL := getStringArg(args, "notify_url", ""); notifyURL != "" {
+		if err := validateNotifyURL(notifyURL); err != nil {
+			return NewTextResult("", err), nil
+		}
+		updated.NotifyURL = notifyURL
+	}
+
+	changes := make(map[string]interface{})
+	diff := func(field, previous, current string) {
+		if previous != current {
+			changes[field] = map[string]string{"before": previous, "after": current}
+		}
+	}
+	diff("branch", config.Branch, updated.Branch)
+	diff("dockerfile", config.DockerFile, updated.DockerFile)
+	diff("build_context", config.BuildContext, updated.BuildContext)
+	diff("image_name", config.ImageName, updated.ImageName)
+	diff("registry", config.Registry, updated.Registry)
+	diff("namespace", config.Namespace, updated.Namespace)
+	// Notification URLs embed credentials, only the change is reported
+	if updated.NotifyURL != config.NotifyURL {
+		changes["notify_url"] = map[string]string{"before": config.redacted().NotifyURL, "after": "***"}
+	}
+
+	updateRepo(config, func(config *RepoConfig) {
+		config.Branch = updated.Branch
+		config.DockerFile = updated.DockerFile
+		config.BuildContext = updated.BuildContext
+		config.ImageName = updated.ImageName
+		config.Registry = updated.Registry
+		config.Namespace = updated.Namespace
+		config.NotifyURL = updated.NotifyURL
+	})
+	// A polled repository is polled on its new branch
+	if updated.Branch != previous.Branch {
+		s.unwatchRepo(&previous)
+		s.watchRepo(&updated)
+	}
+
+	result := map[string]interface{}{
+		"status":     "success",
+		"message":    fmt.Sprintf("Repository '%s' updated, %d field(s) changed", config.Name, len(changes)),
+		"repository": updated.redacted(),
+		"changes":    changes,
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// Set environment-specific deployment overrides for a repo
+func (s *Server) repoEnvSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	environment, ok := args["environment"].(string)
+	if !ok || environment == "" {
+		return NewTextResult("", fmt.Errorf("environment parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	replicas := getIntArg(args, "replicas", 0)
+	if replicas < 0 {
+		return NewTextResult("", fmt.Errorf("replicas must not be negative")), nil
+	}
+
+	override, exists := config.Environments[environment]
+	if !exists {
+		override = &EnvironmentOverride{}
+	}
+	if replicas > 0 {
+		override.Replicas = replicas
+	}
+	override.Namespace = getStringArg(args, "namespace", override.Namespace)
+	override.ImageTag = getStringArg(args, "image_tag", override.ImageTag)
+	override.CPURequest = getStringArg(args, "cpu_request", override.CPURequest)
+	override.CPULimit = getStringArg(args, "cpu_limit", override.CPULimit)
+	override.MemoryRequest = getStringArg(args, "memory_request", override.MemoryRequest)
+	override.MemoryLimit = getStringArg(args, "memory_limit", override.MemoryLimit)
+	if env, exists := args["env"].(map[string]interface{}); exists {
+		override.Env = make(map[string]string, len(env))
+		for key, value := range env {
+			override.Env[key] = fmt.Sprintf("%v", value)
+		}
+		if err := validateEnvNames(override.Env); err != nil {
+			return NewTextResult("", err), nil
+		}
+	}
+
+	updateRepo(config, func(config *RepoConfig) {
+		if config.Environments == nil {
+			config.Environments = make(map[string]*EnvironmentOverride)
+		}
+		config.Environments[environment] = override
+	})
+
+	result := map[string]interface{}{
+		"status":      "success",
+		"message":     fmt.Sprintf("Overrides for environment '%s' saved for repository '%s'", environment, config.Name),
+		"repository":  config.Name,
+		"environment": environment,
+		"overrides":   override,
+		"next_steps": []string{
+			fmt.Sprintf("Use 'repo_deploy' with environment '%s' to deploy with these overrides", environment),
+		},
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// View environment-specific deployment overrides for a repo
+func (s *Server) repoEnvGet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	result := map[string]interface{}{
+		"repository": config.Name,
+	}
+	if environment := getStringArg(args, "environment", ""); environment != "" {
+		override, err := lookupEnvironment(config, environment)
+		if err != nil {
+			return NewTextResult("", err), nil
+		}
+		result["environment"] = environment
+		result["overrides"] = override
+	} else {
+		environments := config.Environments
+		if environments == nil {
+			environments = make(map[string]*EnvironmentOverride)
+		}
+		result["environments"] = environments
+	}
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// Compare the configured image of a repo with its live deployment
+func (s *Server) repoImageDiff(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	environment := getStringArg(args, "environment", "")
+	override, err := lookupEnvironment(config, environment)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	namespace := config.Namespace
+	if override != nil && override.Namespace != "" {
+		namespace = override.Namespace
+	}
+
+	configuredImage := fmt.Sprintf("%s:%s", config.ImageName, getStringArg(args, "image_tag", "latest"))
+
+	live, err := s.liveClusterRead(ctx, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		return fetchLiveImage(ctx, k, namespace, config.Name)
+	})
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to read live deployment '%s/%s': %v", namespace, config.Name, err)), nil
+	}
+	liveImage := live.(*LiveImage)
+
+	result := map[string]interface{}{
+		"repository": config.Name,
+		"namespace":  namespace,
+		"configured": map[string]interface{}{
+			"image": configuredImage,
+		},
+		"live": liveImage,
+	}
+	if environment != "" {
+		result["environment"] = environment
+	}
+
+	if !liveImage.Deployed {
+		result["status"] = "not_deployed"
+		result["differences"] = []string{fmt.Sprintf("no Deployment '%s' found in namespace '%s'", config.Name, namespace)}
+		jsonResult, _ := json.MarshalIndent(result, "", "  ")
+		return NewTextResult(string(jsonResult), nil), nil
+	}
+
+	differences := make([]string, 0)
+	if liveImage.Image != configuredImage {
+		differences = append(differences, fmt.Sprintf("deployment image is '%s', configured image is '%s'", liveImage.Image, configuredImage))
+	}
+	configuredDigest, digestErr := s.resolveImageDigest(ctx, configuredImage, getBoolArg(args, "no_cache", false))
+	if digestErr != nil {
+		result["configured"].(map[string]interface{})["digest_error"] = digestErr.Error()
+	} else {
+		result["configured"].(map[string]interface{})["digest"] = configuredDigest
+		for _, digest := range liveImage.RunningDigests {
+			if digest != configuredDigest {
+				differences = append(differences, fmt.Sprintf("pods are running digest %s, configured image resolves to %s", digest, configuredDigest))
+			}
+		}
+	}
+
+	result["status"] = "match"
+	if len(differences) > 0 {
+		result["status"] = "mismatch"
+	}
+	result["differences"] = differences
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// Check that a route host is not already claimed by another Route or Ingress
+func (s *Server) routeCheckHost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name := getStringArg(args, "name", "")
+	namespace := getStringArg(args, "namespace", "")
+	host := getStringArg(args, "host", "")
+	if host == "" {
+		if name == "" || namespace == "" {
+			return NewTextResult("", fmt.Errorf("either host or both name and namespace parameters are required")), nil
+		}
+		host = strings.TrimPrefix(s.generateRouteURL(ctx, name, namespace), "https://")
+	}
+
+	var conflicts []HostConflict
+	var claimed map[string]bool
+	_, err := s.liveClusterRead(ctx, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		var ferr error
+		conflicts, claimed, ferr = findHostConflicts(ctx, k, host, namespace, name)
+		return nil, ferr
+	})
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to check route host '%s': %v", host, err)), nil
+	}
+
+	result := map[string]interface{}{
+		"host":      host,
+		"available": len(conflicts) == 0,
+		"conflicts": conflicts,
+	}
+	if len(conflicts) > 0 {
+		result["suggested_host"] = suggestAlternativeHost(host, claimed)
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// Verify a deployed application layer by layer
+func (s *Server) applicationVerify(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	namespace := getStringArg(args, "namespace", "")
+	if config := findRepo(name); config != nil {
+		name = config.Name
+		if namespace == "" {
+			namespace = config.Namespace
+		}
+	}
+	if namespace == "" {
+		return NewTextResult("", fmt.Errorf("namespace parameter is required for applications not added with 'repo_add'")), nil
+	}
+	path := getStringArg(args, "path", "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	timeout := time.Duration(getIntArg(args, "timeout", 10)) * time.Second
+	skipTLSVerify := getBoolArg(args, "skip_tls_verify", false)
+
+	var checks []VerificationCheck
+	_, err := s.liveClusterRead(ctx, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		checks = append(checks,
+			verifyDeploymentReady(ctx, k, namespace, name),
+			verifyServiceEndpoints(ctx, k, namespace, name))
+		routeCheck, host := verifyRouteAdmitted(ctx, k, namespace, name)
+		checks = append(checks, routeCheck)
+		if host == "" {
+			checks = append(checks, VerificationCheck{Name: "http", Status: "skipped", Message: "no route host to probe"})
+		} else {
+			checks = append(checks, verifyHTTP(ctx, "https://"+host+path, timeout, skipTLSVerify))
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to verify application '%s': %v", name, err)), nil
+	}
+
+	status := "healthy"
+	failedLayer := ""
+	for _, check := range checks {
+		if check.Status == "fail" {
+			status = "unhealthy"
+			failedLayer = check.Name
+			break
+		}
+	}
+
+	result := map[string]interface{}{
+		"application": name,
+		"namespace":   namespace,
+		"status":      status,
+		"checks":      checks,
+	}
+	if failedLayer != "" {
+		result["failed_layer"] = failedLayer
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
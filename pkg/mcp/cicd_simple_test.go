@@ -0,0 +1,550 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+func TestCommitImageTag(t *testing.T) {
+	cases := map[string]string{
+		"":                 "latest",
+		"latest":           "latest",
+		"a1b2c":            "a1b2c",
+		"a1b2c3d4e5f6a7b8": "a1b2c3d4",
+		"origin/feature":   "origin-feature",
+		"-v1.0/rc":         "v1.0-rc",
+	}
+	for commit, expected := range cases {
+		if tag := commitImageTag(commit); tag != expected {
+			t.Errorf("commitImageTag(%q) = %q, expected %q", commit, tag, expected)
+		}
+	}
+	long := make([]byte, 200)
+	for i := range long {
+		long[i] = 'x'
+	}
+	if tag := commitImageTag(string(long)); len(tag) != 128 {
+		t.Errorf("expected the tag to be truncated to 128 characters, got %d", len(tag))
+	}
+}
+
+func TestResolveRepoRegistry(t *testing.T) {
+	configuredRegistriesMu.Lock()
+	configuredRegistries["internal"] = &configuredRegistry{
+		info:     &RegistryInfo{Name: "internal", URL: "https://registry.example.com", Metadata: map[string]string{"username": "ci"}},
+		password: "secret",
+	}
+	configuredRegistriesMu.Unlock()
+	t.Cleanup(func() {
+		configuredRegistriesMu.Lock()
+		delete(configuredRegistries, "internal")
+		configuredRegistriesMu.Unlock()
+	})
+
+	for registry, expected := range map[string]string{
+		"quay.io":        "quay.io",
+		"localhost:5000": "localhost:5000",
+		"internal":       "registry.example.com",
+	} {
+		if resolved, err := resolveRepoRegistry(registry); err != nil || resolved != expected {
+			t.Errorf("resolveRepoRegistry(%q) = %q, %v, expected %q", registry, resolved, err, expected)
+		}
+	}
+	if _, err := resolveRepoRegistry("unknown"); err == nil {
+		t.Error("expected an error for a registry name that is not configured")
+	}
+	if auth := repoRegistryAuth("internal"); auth.Username != "ci" || auth.Password != "secret" {
+		t.Errorf("expected the configured credentials, got %s/%s", auth.Username, auth.Password)
+	}
+}
+
+func TestFormatMCPError(t *testing.T) {
+	cause := fmt.Errorf("clone failed: %w", errors.New("repository not found"))
+	var response struct {
+		Error struct {
+			Code    string   `json:"code"`
+			Message string   `json:"message"`
+			Type    string   `json:"type"`
+			Details []string `json:"details"`
+		} `json:"error"`
+		Timestamp string `json:"timestamp"`
+		Help      string `json:"help"`
+	}
+	if err := json.Unmarshal([]byte(formatMCPError("INVALID_PARAMS", "bad url", cause)), &response); err != nil {
+		t.Fatalf("invalid error response: %v", err)
+	}
+	if _, err := time.Parse(time.RFC3339, response.Timestamp); err != nil {
+		t.Errorf("expected an RFC3339 timestamp, got %q: %v", response.Timestamp, err)
+	}
+	if response.Error.Code != "INVALID_PARAMS" || response.Error.Message != "bad url" || response.Error.Type != "tool_execution_error" || response.Help == "" {
+		t.Errorf("unexpected error fields %+v", response)
+	}
+	if len(response.Error.Details) != 2 || response.Error.Details[1] != "repository not found" {
+		t.Errorf("expected the error chain as details, got %v", response.Error.Details)
+	}
+	if strings.Contains(formatMCPError("MISSING_PARAMETER", "url is required"), "details") {
+		t.Error("expected no details without a cause")
+	}
+}
+
+func TestRepoUpdate(t *testing.T) {
+	putRepo("update-test", &RepoConfig{
+		URL:        "https://github.com/example/update-test.git",
+		Name:       "update-test",
+		Branch:     "main",
+		DockerFile: "./Dockerfile",
+		ImageName:  generateImageName("update-test", "quay.io"),
+		Registry:   "quay.io",
+		Namespace:  "dev",
+	})
+	t.Cleanup(func() { removeRepo("update-test") })
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"name": "update-test", "branch": "develop", "registry": "ghcr.io"}
+	result, err := (&Server{}).repoUpdate(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("repo_update failed: %v %v", err, result)
+	}
+	var response struct {
+		Changes map[string]map[string]string `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if change := response.Changes["branch"]; change["before"] != "main" || change["after"] != "develop" {
+		t.Errorf("expected the branch change, got %v", response.Changes)
+	}
+	if _, changed := response.Changes["dockerfile"]; changed {
+		t.Errorf("expected fields not provided to be left intact, got %v", response.Changes)
+	}
+	config := findRepo("update-test")
+	if config.Branch != "develop" || config.Namespace != "dev" {
+		t.Errorf("unexpected stored config %+v", config)
+	}
+	if config.ImageName != "ghcr.io/default/update-test" {
+		t.Errorf("expected the generated image name to follow the registry, got %s", config.ImageName)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"name": "update-test", "registry": "quay.io", "image_name": "quay.io/team/app"}
+	if result, _ := (&Server{}).repoUpdate(context.Background(), request); result.IsError {
+		t.Fatalf("repo_update failed: %v", result)
+	}
+	if config := findRepo("update-test"); config.ImageName != "quay.io/team/app" {
+		t.Errorf("expected the explicit image name to win, got %s", config.ImageName)
+	}
+}
+
+func TestManifestsOutput(t *testing.T) {
+	manifests, err := generateManifests(ManifestData{AppName: "app", Namespace: "dev", ImageName: "quay.io/team/app", ImageTag: "v1", Port: 8080, Replicas: 1, RouteType: routeTypeRoute})
+	if err != nil {
+		t.Fatalf("failed to generate manifests: %v", err)
+	}
+	result := map[string]interface{}{"status": "success", "manifests": manifests}
+
+	raw, err := manifestsOutput("raw", result, manifests)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var kinds []string
+	for _, document := range strings.Split(strings.TrimPrefix(raw, "---\n"), "\n---\n") {
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			t.Fatalf("invalid YAML document %q: %v", document, err)
+		}
+		kinds = append(kinds, fmt.Sprintf("%v", object["kind"]))
+	}
+	if strings.Join(kinds, ",") != "Deployment,Service,Route" {
+		t.Errorf("expected the manifests in apply order, got %v", kinds)
+	}
+
+	yamlOutput, err := manifestsOutput("yaml", result, manifests)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := yaml.Unmarshal([]byte(yamlOutput), &decoded); err != nil || decoded["status"] != "success" {
+		t.Errorf("expected the result as YAML, got %q: %v", yamlOutput, err)
+	}
+	if _, err := manifestsOutput("xml", result, manifests); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
+
+func TestContainerSpecArgs(t *testing.T) {
+	data := ManifestData{AppName: "app", Namespace: "dev", ImageName: "quay.io/team/app", ImageTag: "v1", Port: 8080, Replicas: 1, Env: map[string]string{"LOG_LEVEL": "info", "MODE": "dev"}}
+	args := map[string]interface{}{
+		"memory_limit":   "1Gi",
+		"liveness_path":  "/healthz",
+		"readiness_path": "/ready",
+		"env":            map[string]interface{}{"MODE": "prod"},
+	}
+	if err := containerSpecArgs(args, &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Env["MODE"] != "prod" || data.Env["LOG_LEVEL"] != "info" {
+		t.Errorf("expected env vars merged over the existing ones, got %v", data.Env)
+	}
+	manifests, err := generateManifests(data)
+	if err != nil {
+		t.Fatalf("failed to generate manifests: %v", err)
+	}
+	var deployment struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []map[string]interface{} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(manifests["deployment.yaml"]), &deployment); err != nil {
+		t.Fatalf("invalid deployment: %v", err)
+	}
+	container, _ := json.Marshal(deployment.Spec.Template.Spec.Containers[0])
+	for _, expected := range []string{`"path":"/healthz"`, `"path":"/ready"`, `"memory":"1Gi"`, `"memory":"64Mi"`} {
+		if !strings.Contains(string(container), expected) {
+			t.Errorf("expected %s in the container, got %s", expected, container)
+		}
+	}
+
+	for _, invalid := range []map[string]interface{}{
+		{"cpu_limit": "lots"},
+		{"liveness_path": "healthz"},
+		{"env": map[string]interface{}{"LOG-LEVEL": "info"}},
+		{"env": map[string]interface{}{"1MODE": "dev"}},
+	} {
+		if err := containerSpecArgs(invalid, &ManifestData{}); err == nil {
+			t.Errorf("expected %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestGenerateManifestsRejectsInvalidEnvNames(t *testing.T) {
+	data := ManifestData{AppName: "app", Namespace: "dev", ImageName: "quay.io/team/app", ImageTag: "v1", Port: 8080, Replicas: 1}
+	for _, name := range []string{"LOG_LEVEL", "_private", "mode2"} {
+		data.Env = map[string]string{name: "value"}
+		if _, err := generateManifests(data); err != nil {
+			t.Errorf("expected %q to be accepted, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "2FA", "LOG-LEVEL", "a.b", "NAME: x\n        - name: injected"} {
+		data.Env = map[string]string{name: "value"}
+		if _, err := generateManifests(data); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestContainerSpecProbeArgs(t *testing.T) {
+	data := ManifestData{AppName: "app", Namespace: "dev", ImageName: "quay.io/team/app", ImageTag: "v1", Port: 8080, Replicas: 1}
+	args := map[string]interface{}{
+		"readiness_path":  "",
+		"startup_path":    "/healthz",
+		"liveness_timing": map[string]interface{}{"period_seconds": float64(20), "failure_threshold": float64(5)},
+	}
+	if err := containerSpecArgs(args, &data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	manifests, err := generateManifests(data)
+	if err != nil {
+		t.Fatalf("failed to generate manifests: %v", err)
+	}
+	var deployment struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []map[string]interface{} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(manifests["deployment.yaml"]), &deployment); err != nil {
+		t.Fatalf("invalid deployment: %v", err)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if _, exists := container["readinessProbe"]; exists {
+		t.Errorf("expected the readiness probe to be disabled, got %v", container["readinessProbe"])
+	}
+	liveness, _ := json.Marshal(container["livenessProbe"])
+	for _, expected := range []string{`"path":"/"`, `"initialDelaySeconds":30`, `"periodSeconds":20`, `"failureThreshold":5`} {
+		if !strings.Contains(string(liveness), expected) {
+			t.Errorf("expected %s in the liveness probe, got %s", expected, liveness)
+		}
+	}
+	startup, _ := json.Marshal(container["startupProbe"])
+	for _, expected := range []string{`"path":"/healthz"`, `"periodSeconds":10`, `"failureThreshold":30`} {
+		if !strings.Contains(string(startup), expected) {
+			t.Errorf("expected %s in the startup probe, got %s", expected, startup)
+		}
+	}
+
+	invalid := map[string]interface{}{"startup_timing": map[string]interface{}{"timeout_seconds": float64(-1)}}
+	if err := containerSpecArgs(invalid, &ManifestData{}); err == nil {
+		t.Error("expected a negative timing to be rejected")
+	}
+}
+
+func TestConfigDataArgs(t *testing.T) {
+	data := ManifestData{AppName: "app", Namespace: "dev", ImageName: "quay.io/team/app", ImageTag: "v1", Port: 8080, Replicas: 1}
+	if config, err := configDataArgs(map[string]interface{}{}, &data); err != nil || config != nil {
+		t.Fatalf("expected no configuration without references, got %v, %v", config, err)
+	}
+	args := map[string]interface{}{
+		"config_map_mounts": map[string]interface{}{"app-config": "/etc/app"},
+		"secret_mounts":     map[string]interface{}{"app-tls": "/etc/tls"},
+		"secret_env_from":   []interface{}{"app-env"},
+		"config_maps":       map[string]interface{}{"app-config": map[string]interface{}{"app.yaml": "debug: true"}},
+	}
+	config, err := configDataArgs(args, &data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Namespace != "dev" || config.ConfigMaps["app-config"]["app.yaml"] != "debug: true" || config.SecretEnvFrom[0] != "app-env" {
+		t.Errorf("unexpected configuration %+v", config)
+	}
+	manifests, err := generateManifests(data)
+	if err != nil {
+		t.Fatalf("failed to generate manifests: %v", err)
+	}
+	var deployment struct {
+		Spec struct {
+			Template struct {
+				Spec map[string]interface{} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(manifests["deployment.yaml"]), &deployment); err != nil {
+		t.Fatalf("invalid deployment: %v", err)
+	}
+	spec, _ := json.Marshal(deployment.Spec.Template.Spec)
+	for _, expected := range []string{
+		`{"configMap":{"name":"app-config"},"name":"cm-app-config"}`,
+		`{"name":"secret-app-tls","secret":{"secretName":"app-tls"}}`,
+		`{"mountPath":"/etc/app","name":"cm-app-config","readOnly":true}`,
+		`"envFrom":[{"secretRef":{"name":"app-env"}}]`,
+	} {
+		if !strings.Contains(string(spec), expected) {
+			t.Errorf("expected %s in the pod spec, got %s", expected, spec)
+		}
+	}
+
+	for _, invalid := range []map[string]interface{}{
+		{"config_map_mounts": map[string]interface{}{"app-config": "etc/app"}},
+		{"secrets": map[string]interface{}{"app-env": "TOKEN=abc"}},
+	} {
+		if _, err := configDataArgs(invalid, &ManifestData{}); err == nil {
+			t.Errorf("expected %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestRunCommitPipelineRecordsFailedBuild(t *testing.T) {
+	(&Server{}).runCommitPipeline(context.Background(), "missing-pipeline-repo", "abc1234")
+	executions := pipelineExecutions.recent("missing-pipeline-repo", 1)
+	if len(executions) != 1 {
+		t.Fatalf("expected the failed build to be recorded, got %d executions", len(executions))
+	}
+	execution := executions[0]
+	if execution.Status != "failed" || execution.Commit != "abc1234" || len(execution.Stages) != 1 || execution.Stages[0].Name != "build" {
+		t.Errorf("unexpected execution %+v", execution)
+	}
+	if !strings.Contains(execution.Error, "not found") {
+		t.Errorf("expected the build error, got %q", execution.Error)
+	}
+}
+
+func TestExecutionHistoryFind(t *testing.T) {
+	history := newExecutionHistory()
+	if _, found := history.find("app", ""); found {
+		t.Error("expected no execution before the first run")
+	}
+	first := history.start("app", "abc1234", "")
+	history.stage(first, "push", "failed", "denied")
+	history.finish(first, "", "push failed")
+	history.finish(history.start("app", "def5678", ""), "https://app.example.com", "")
+
+	if latest, found := history.find("app", ""); !found || latest.Commit != "def5678" {
+		t.Errorf("expected the most recent execution, got %+v", latest)
+	}
+	execution, found := history.find("app", first.ID)
+	if !found || execution.Status != "failed" || execution.Stages[0].Name != "push" {
+		t.Errorf("expected the failed execution, got %+v", execution)
+	}
+	if _, found := history.find("app", "app-99"); found {
+		t.Error("expected an unknown execution ID not to be found")
+	}
+}
+
+func TestExecutionLog(t *testing.T) {
+	history := newExecutionHistory()
+	execution := history.start("app", "abc1234", "prod")
+	history.stage(execution, "generate_manifests", "succeeded", "")
+	history.stage(execution, "apply", "failed", "one or more critical objects failed to apply")
+	history.finish(execution, "", "manifests were not applied")
+
+	log := executionLog(execution, []ManifestApplyResult{{Kind: "Deployment", Name: "app", Namespace: "prod", Action: "failed", Error: "forbidden"}})
+	for _, expected := range []string{"commit: abc1234", "environment: prod", "stage apply: failed (one or more critical objects failed to apply)", "Deployment prod/app: failed (forbidden)", "error: manifests were not applied"} {
+		if !strings.Contains(log, expected) {
+			t.Errorf("expected %q in the log, got:\n%s", expected, log)
+		}
+	}
+}
+
+func TestIsClusterUnavailable(t *testing.T) {
+	routes := schema.GroupResource{Group: "route.openshift.io", Resource: "routes"}
+	unavailable := []error{
+		errClusterNotConfigured,
+		&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+		apierrors.NewServiceUnavailable("etcd leader changed"),
+		apierrors.NewTimeoutError("request timed out", 1),
+		fmt.Errorf("no Route (%w) or Ingress (%w) exposes dev/app", apierrors.NewNotFound(routes, "app"), context.DeadlineExceeded),
+	}
+	for _, err := range unavailable {
+		if !isClusterUnavailable(err) {
+			t.Errorf("expected %v to fall back to the cache", err)
+		}
+	}
+	answered := []error{
+		apierrors.NewNotFound(routes, "app"),
+		apierrors.NewForbidden(routes, "app", errors.New("denied")),
+		fmt.Errorf("route dev/app has no host assigned"),
+	}
+	for _, err := range answered {
+		if isClusterUnavailable(err) {
+			t.Errorf("expected %v to be returned", err)
+		}
+	}
+}
+
+func TestLiveDataCacheEvict(t *testing.T) {
+	cache := newLiveDataCache(time.Minute)
+	cache.put("route/dev/app", "https://app.example.com")
+	cache.evict("route/dev/app")
+	if _, ok := cache.get("route/dev/app"); ok {
+		t.Error("expected the evicted entry to be gone")
+	}
+}
+
+func TestLiveDataCacheTTL(t *testing.T) {
+	if ttl := liveDataTTL(""); ttl != defaultLiveDataTTL || ttl > time.Minute {
+		t.Errorf("expected a short default TTL, got %s", ttl)
+	}
+	if ttl := liveDataTTL("5s"); ttl != 5*time.Second {
+		t.Errorf("expected the configured TTL, got %s", ttl)
+	}
+	if ttl := liveDataTTL("soon"); ttl != defaultLiveDataTTL {
+		t.Errorf("expected the default TTL for an invalid value, got %s", ttl)
+	}
+	cache := newLiveDataCache(time.Nanosecond)
+	cache.put("route/dev/app", "https://app.example.com")
+	time.Sleep(time.Millisecond)
+	if _, ok := cache.get("route/dev/app"); ok {
+		t.Error("expected the expired entry not to be served")
+	}
+	disabled := newLiveDataCache(0)
+	disabled.put("route/dev/app", "https://app.example.com")
+	if _, ok := disabled.get("route/dev/app"); ok {
+		t.Error("expected a zero TTL to disable the cache")
+	}
+}
+
+func TestRepoAutoDeployKeepsStoredSettings(t *testing.T) {
+	putRepo("keep-settings", &RepoConfig{
+		URL:              "file:///nonexistent/keep-settings.git",
+		Name:             "keep-settings",
+		Branch:           "main",
+		BuildContext:     "app",
+		DockerFile:       "Containerfile",
+		Namespace:        "dev",
+		NotifyURL:        "https://hooks.example.com/notify",
+		WebhookSecret:    "s3cret",
+		WebhookEvents:    []string{"push", "tag_push"},
+		SSHKeyPath:       "/keys/keep-settings",
+		SSHKeyPassphrase: "passphrase",
+	})
+	t.Cleanup(func() { removeRepo("keep-settings") })
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"url": "file:///nonexistent/keep-settings.git", "name": "keep-settings", "namespace": "prod", "container_port": 8080}
+	_, _ = (&Server{}).repoAutoDeploy(context.Background(), request)
+
+	config := findRepo("keep-settings")
+	if config.Namespace != "prod" {
+		t.Errorf("expected the namespace argument to be stored, got %s", config.Namespace)
+	}
+	if config.SSHKeyPath != "/keys/keep-settings" || config.SSHKeyPassphrase != "passphrase" {
+		t.Errorf("expected the SSH key to be kept, got %q %q", config.SSHKeyPath, config.SSHKeyPassphrase)
+	}
+	if config.WebhookSecret != "s3cret" || strings.Join(config.WebhookEvents, ",") != "push,tag_push" {
+		t.Errorf("expected the webhook to be kept, got %q %v", config.WebhookSecret, config.WebhookEvents)
+	}
+	if config.NotifyURL != "https://hooks.example.com/notify" || config.BuildContext != "app" || config.DockerFile != "Containerfile" {
+		t.Errorf("unexpected stored config %+v", config)
+	}
+}
+
+func TestCicdSetPipelineActive(t *testing.T) {
+	var stored RepoConfig
+	if err := json.Unmarshal([]byte(`{"url": "https://github.com/example/toggle.git", "name": "toggle", "branch": "main"}`), &stored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stored.Active {
+		t.Error("expected repositories stored without the flag to be active")
+	}
+	putRepo("toggle", &stored)
+	t.Cleanup(func() { removeRepo("toggle") })
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"name": "toggle", "active": false}
+	result, err := (&Server{}).cicdSetPipelineActive(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("cicd_set_pipeline_active failed: %v %v", err, result)
+	}
+	if findRepo("toggle").Active {
+		t.Error("expected the pipeline to be disabled")
+	}
+	if err := (&Server{}).recordCommit(cicd.CommitEvent{RepoURL: "https://github.com/example/toggle", Branch: "main", CommitHash: "abc1234"}); err == nil {
+		t.Error("expected pushes to a disabled repository to be ignored")
+	}
+}
+
+func TestRepoBuildStrategyArguments(t *testing.T) {
+	putRepo("strategy-test", &RepoConfig{URL: "file:///nonexistent/strategy-test.git", Name: "strategy-test", Branch: "main"})
+	t.Cleanup(func() { removeRepo("strategy-test") })
+
+	cases := map[string]struct {
+		args     map[string]interface{}
+		expected string
+	}{
+		"unknown strategy":         {map[string]interface{}{"strategy": "buildah"}, "strategy must be 'docker' or 's2i'"},
+		"builder image for docker": {map[string]interface{}{"builder_image": "nodejs:latest"}, "only used by the s2i strategy"},
+		"s2i without cluster":      {map[string]interface{}{"strategy": "s2i"}, "s2i builds need a cluster connection"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.args["name"] = "strategy-test"
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.args
+			result, err := (&Server{}).repoBuild(context.Background(), request)
+			if err != nil || !result.IsError {
+				t.Fatalf("expected a failed build, got %v %v", err, result)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tc.expected) {
+				t.Errorf("expected %q in %q", tc.expected, text)
+			}
+		})
+	}
+}
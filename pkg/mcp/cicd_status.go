@@ -0,0 +1,337 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+// defaultStatusEvents is the number of recent events deployment_status reports by default
+const defaultStatusEvents = 10
+
+var eventGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Event"}
+
+// Container waiting reasons reported as crash loops and image pull errors
+var (
+	crashLoopReasons = map[string]bool{"CrashLoopBackOff": true}
+	imagePullReasons = map[string]bool{"ErrImagePull": true, "ImagePullBackOff": true, "InvalidImageName": true, "ErrImageNeverPull": true}
+)
+
+// DeploymentHealth answers whether a Deployment is healthy and, if not, why
+type DeploymentHealth struct {
+	Name            string              `json:"name"`
+	Namespace       string              `json:"namespace"`
+	Healthy         bool                `json:"healthy"`
+	Summary         string              `json:"summary"`
+	Image           string              `json:"image"`
+	Rollout         RolloutProgress     `json:"rollout"`
+	Conditions      []ConditionSummary  `json:"conditions,omitempty"`
+	ReplicaSets     []ReplicaSetSummary `json:"replica_sets,omitempty"`
+	Pods            []PodHealth         `json:"pods"`
+	CrashLooping    int                 `json:"crash_looping_pods"`
+	ImagePullErrors int                 `json:"image_pull_errors"`
+	Issues          []string            `json:"issues,omitempty"`
+	Events          []EventSummary      `json:"recent_events"`
+}
+
+// RolloutProgress compares the replicas of the current revision to the desired count
+type RolloutProgress struct {
+	Revision  int64 `json:"revision"`
+	Desired   int32 `json:"desired"`
+	Updated   int32 `json:"updated"`
+	Ready     int32 `json:"ready"`
+	Available int32 `json:"available"`
+	Complete  bool  `json:"complete"`
+	// Stalled is set once the rollout exceeded its progress deadline
+	Stalled bool `json:"stalled"`
+}
+
+type ConditionSummary struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ReplicaSetSummary struct {
+	Name     string `json:"name"`
+	Revision int64  `json:"revision"`
+	Image    string `json:"image"`
+	Replicas string `json:"replicas"`
+}
+
+type PodHealth struct {
+	Name     string   `json:"name"`
+	Phase    string   `json:"phase"`
+	Ready    bool     `json:"ready"`
+	Restarts int32    `json:"restarts"`
+	Node     string   `json:"node,omitempty"`
+	Issues   []string `json:"issues,omitempty"`
+}
+
+type EventSummary struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	Object   string `json:"object"`
+	Message  string `json:"message"`
+	Count    int32  `json:"count,omitempty"`
+	LastSeen string `json:"last_seen,omitempty"`
+}
+
+// fetchDeploymentHealth gathers the Deployment, its ReplicaSets, pods and recent events and
+// summarizes rollout progress, crash loops and image pull errors
+func fetchDeploymentHealth(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string, eventLimit int) (*DeploymentHealth, error) {
+	deployments, err := k.Deployments(namespace)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	health := &DeploymentHealth{Name: name, Namespace: namespace, Pods: []PodHealth{}, Events: []EventSummary{}}
+	if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+		health.Image = containers[0].Image
+	}
+	health.Rollout = rolloutProgress(deployment)
+	for _, condition := range deployment.Status.Conditions {
+		health.Conditions = append(health.Conditions, ConditionSummary{
+			Type: string(condition.Type), Status: string(condition.Status), Reason: condition.Reason, Message: condition.Message,
+		})
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			health.Rollout.Stalled = true
+		}
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %s: %w", name, err)
+	}
+	involved := []string{"Deployment/" + name}
+	if replicaSets, err := k.ReplicaSets(namespace); err == nil {
+		if list, err := replicaSets.List(ctx, metav1.ListOptions{LabelSelector: selector.String()}); err == nil {
+			for i := range list.Items {
+				replicaSet := &list.Items[i]
+				if owner := metav1.GetControllerOf(replicaSet); owner == nil || owner.UID != deployment.UID {
+					continue
+				}
+				// ReplicaSets of older revisions scaled to zero are history, not live state
+				if replicaSet.Status.Replicas == 0 && (replicaSet.Spec.Replicas == nil || *replicaSet.Spec.Replicas == 0) {
+					continue
+				}
+				summary := ReplicaSetSummary{Name: replicaSet.Name, Replicas: fmt.Sprintf("%d/%d", replicaSet.Status.ReadyReplicas, replicaSet.Status.Replicas)}
+				summary.Revision, _ = strconv.ParseInt(replicaSet.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+				if containers := replicaSet.Spec.Template.Spec.Containers; len(containers) > 0 {
+					summary.Image = containers[0].Image
+				}
+				health.ReplicaSets = append(health.ReplicaSets, summary)
+				involved = append(involved, "ReplicaSet/"+replicaSet.Name)
+			}
+			sort.Slice(health.ReplicaSets, func(i, j int) bool { return health.ReplicaSets[i].Revision > health.ReplicaSets[j].Revision })
+		}
+	}
+
+	pods, err := k.Pods(namespace)
+	if err != nil {
+		return nil, err
+	}
+	podList, err := pods.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of deployment %s: %w", name, err)
+	}
+	for i := range podList.Items {
+		pod := podHealth(&podList.Items[i])
+		for _, issue := range pod.Issues {
+			reason, _, _ := strings.Cut(issue, ":")
+			switch {
+			case crashLoopReasons[reason]:
+				health.CrashLooping++
+			case imagePullReasons[reason]:
+				health.ImagePullErrors++
+			}
+			health.Issues = append(health.Issues, fmt.Sprintf("pod %s: %s", pod.Name, issue))
+		}
+		health.Pods = append(health.Pods, pod)
+		involved = append(involved, "Pod/"+pod.Name)
+	}
+
+	health.Events = recentEvents(ctx, k, namespace, involved, eventLimit)
+	health.Healthy, health.Summary = summarizeHealth(health)
+	return health, nil
+}
+
+func rolloutProgress(deployment *appsv1.Deployment) RolloutProgress {
+	progress := RolloutProgress{
+		Desired:   1,
+		Updated:   deployment.Status.UpdatedReplicas,
+		Ready:     deployment.Status.ReadyReplicas,
+		Available: deployment.Status.AvailableReplicas,
+	}
+	if deployment.Spec.Replicas != nil {
+		progress.Desired = *deployment.Spec.Replicas
+	}
+	progress.Revision, _ = strconv.ParseInt(deployment.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+	progress.Complete = deployment.Status.ObservedGeneration >= deployment.Generation &&
+		progress.Updated == progress.Desired && progress.Ready == progress.Desired &&
+		progress.Available == progress.Desired && deployment.Status.Replicas == progress.Desired
+	return progress
+}
+
+// podHealth reports a pod's readiness, restarts and the reasons its containers are not running,
+// as "<reason>: <message>"
+func podHealth(pod *corev1.Pod) PodHealth {
+	health := PodHealth{Name: pod.Name, Phase: string(pod.Status.Phase), Node: pod.Spec.NodeName}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			health.Ready = condition.Status == corev1.ConditionTrue
+		}
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			health.Issues = append(health.Issues, fmt.Sprintf("%s: %s", condition.Reason, condition.Message))
+		}
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		health.Restarts += status.RestartCount
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" && waiting.Reason != "ContainerCreating" && waiting.Reason != "PodInitializing" {
+			issue := waiting.Reason + ": container " + status.Name
+			if waiting.Message != "" {
+				issue += " - " + waiting.Message
+			}
+			if terminated := status.LastTerminationState.Terminated; terminated != nil && crashLoopReasons[waiting.Reason] {
+				issue += fmt.Sprintf(" (last exit code %d, %s)", terminated.ExitCode, terminated.Reason)
+			}
+			health.Issues = append(health.Issues, issue)
+		}
+	}
+	return health
+}
+
+// recentEvents returns the latest events about the given Kind/name objects, newest first
+func recentEvents(ctx context.Context, k *internalk8s.Kubernetes, namespace string, involved []string, limit int) []EventSummary {
+	var events []corev1.Event
+	for _, object := range involved {
+		kind, name, _ := strings.Cut(object, "/")
+		list, err := k.ResourcesList(ctx, &eventGVK, namespace, internalk8s.ResourceListOptions{ListOptions: metav1.ListOptions{
+			FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+		}})
+		if err != nil {
+			continue
+		}
+		unstructuredList, ok := list.(*unstructured.UnstructuredList)
+		if !ok {
+			continue
+		}
+		for _, item := range unstructuredList.Items {
+			var event corev1.Event
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &event); err == nil {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return eventTime(&events[j]).Before(eventTime(&events[i])) })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	summaries := make([]EventSummary, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		summary := EventSummary{
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Object:  event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			Message: event.Message,
+			Count:   event.Count,
+		}
+		if seen := eventTime(event); !seen.IsZero() {
+			summary.LastSeen = seen.Format(time.RFC3339)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
+
+// summarizeHealth reduces the gathered state to a verdict and a one line explanation
+func summarizeHealth(health *DeploymentHealth) (bool, string) {
+	rollout := health.Rollout
+	switch {
+	case health.ImagePullErrors > 0:
+		return false, fmt.Sprintf("%d pod(s) cannot pull image %s", health.ImagePullErrors, health.Image)
+	case health.CrashLooping > 0:
+		return false, fmt.Sprintf("%d pod(s) are crash looping, check deployment_logs with previous=true", health.CrashLooping)
+	case rollout.Stalled:
+		return false, fmt.Sprintf("rollout of revision %d exceeded its progress deadline with %d/%d replicas updated", rollout.Revision, rollout.Updated, rollout.Desired)
+	case rollout.Desired == 0:
+		return true, "scaled to zero replicas"
+	case rollout.Complete:
+		return true, fmt.Sprintf("revision %d rolled out, %d/%d replicas ready", rollout.Revision, rollout.Ready, rollout.Desired)
+	case len(health.Issues) > 0:
+		return false, health.Issues[0]
+	default:
+		return false, fmt.Sprintf("rollout of revision %d in progress, %d/%d updated and %d/%d ready", rollout.Revision, rollout.Updated, rollout.Desired, rollout.Ready, rollout.Desired)
+	}
+}
+
+// deploymentStatus reports whether an application's Deployment is healthy and why not
+func (s *Server) deploymentStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	namespace := getStringArg(args, "namespace", "")
+	if config := findRepo(name); config != nil {
+		name = config.Name
+		if namespace == "" {
+			namespace = config.Namespace
+		}
+	}
+	if namespace == "" {
+		return NewTextResult("", fmt.Errorf("namespace parameter is required for applications not added with 'repo_add'")), nil
+	}
+	eventLimit := getIntArg(args, "events", defaultStatusEvents)
+	if eventLimit < 0 {
+		eventLimit = 0
+	}
+
+	health, err := s.liveClusterRead(ctx, func(k *internalk8s.Kubernetes) (interface{}, error) {
+		return fetchDeploymentHealth(ctx, k, namespace, name, eventLimit)
+	})
+	if apierrors.IsNotFound(err) {
+		return NewTextResult("", fmt.Errorf("deployment '%s' not found in namespace '%s'", name, namespace)), nil
+	}
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to get status of deployment '%s': %v", name, err)), nil
+	}
+	jsonResult, _ := json.MarshalIndent(health, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+var endpointsGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Endpoints"}
+
+// VerificationCheck is the result of verifying one layer of a deployed application
+type VerificationCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // "pass", "fail" or "skipped"
+	Message string `json:"message"`
+}
+
+func passCheck(name, format string, args ...interface{}) VerificationCheck {
+	return VerificationCheck{Name: name, Status: "pass", Message: fmt.Sprintf(format, args...)}
+}
+
+func failCheck(name, format string, args ...interface{}) VerificationCheck {
+	return VerificationCheck{Name: name, Status: "fail", Message: fmt.Sprintf(format, args...)}
+}
+
+// verifyDeploymentReady checks that every desired replica of the Deployment is ready
+func verifyDeploymentReady(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) VerificationCheck {
+	deployment, err := k.ResourcesGet(ctx, &deploymentGVK, namespace, name)
+	if err != nil {
+		return failCheck("deployment", "failed to get Deployment %s/%s: %v", namespace, name, err)
+	}
+	summary := summarizeDeployment(deployment)
+	if ready, _ := summary["ready"].(bool); !ready {
+		return failCheck("deployment", "pods not ready: %s replicas ready", summary["replicas"])
+	}
+	return passCheck("deployment", "%s replicas ready", summary["replicas"])
+}
+
+// verifyServiceEndpoints checks that the Service has at least one ready endpoint
+func verifyServiceEndpoints(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) VerificationCheck {
+	endpoints, err := k.ResourcesGet(ctx, &endpointsGVK, namespace, name)
+	if err != nil {
+		return failCheck("service", "failed to get endpoints for Service %s/%s: %v", namespace, name, err)
+	}
+	ready, notReady := 0, 0
+	subsets, _, _ := unstructured.NestedSlice(endpoints.Object, "subsets")
+	for _, subset := range subsets {
+		if s, ok := subset.(map[string]interface{}); ok {
+			if addresses, ok := s["addresses"].([]interface{}); ok {
+				ready += len(addresses)
+			}
+			if addresses, ok := s["notReadyAddresses"].([]interface{}); ok {
+				notReady += len(addresses)
+			}
+		}
+	}
+	if ready == 0 {
+		return failCheck("service", "Service has no ready endpoints (%d not ready), check the selector and pod readiness", notReady)
+	}
+	return passCheck("service", "%d ready endpoints", ready)
+}
+
+// verifyRouteAdmitted checks that the Route has been admitted by a router and returns its host
+func verifyRouteAdmitted(ctx context.Context, k *internalk8s.Kubernetes, namespace, name string) (VerificationCheck, string) {
+	route, err := k.ResourcesGet(ctx, &routeGVK, namespace, name)
+	if err != nil {
+		return failCheck("route", "failed to get Route %s/%s: %v", namespace, name, err), ""
+	}
+	host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+	ingresses, _, _ := unstructured.NestedSlice(route.Object, "status", "ingress")
+	for _, ingress := range ingresses {
+		i, ok := ingress.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions, _ := i["conditions"].([]interface{})
+		for _, condition := range conditions {
+			c, ok := condition.(map[string]interface{})
+			if !ok || c["type"] != "Admitted" {
+				continue
+			}
+			if c["status"] == "True" {
+				return passCheck("route", "admitted by router %v with host %s", i["routerName"], host), host
+			}
+			return failCheck("route", "not admitted by router %v: %v %v", i["routerName"], c["reason"], c["message"]), host
+		}
+	}
+	return failCheck("route", "Route has not been admitted by any router yet"), host
+}
+
+// verifyHTTP probes the application through its route host
+func verifyHTTP(ctx context.Context, url string, timeout time.Duration, skipTLSVerify bool) VerificationCheck {
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipTLSVerify},
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return failCheck("http", "invalid URL %s: %v", url, err)
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return failCheck("http", "request to %s failed: %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 400 {
+		return failCheck("http", "%s returned %s", url, resp.Status)
+	}
+	return passCheck("http", "%s returned %s in %s", url, resp.Status, time.Since(start).Round(time.Millisecond))
+}
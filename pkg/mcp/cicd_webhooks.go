@@ -0,0 +1,294 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+// defaultGitPollInterval is how often repositories without a webhook are polled for new commits
+const defaultGitPollInterval = 30 * time.Second
+
+// GitWatcher returns the watcher receiving push webhooks, mounted at cicd.WebhookPath by the
+// integrated server
+func (s *Server) GitWatcher() *cicd.GitWatcher {
+	return s.gitWatcher
+}
+
+// initGitWatcher creates the watcher, restores the persisted webhooks and polled repositories, and
+// runs the pipeline of the repositories the pushes and new commits it detects belong to. Without
+// poll the watcher only receives webhooks.
+func (s *Server) initGitWatcher(poll bool) {
+	s.gitWatcher = cicd.NewGitWatcher(defaultGitPollInterval)
+	s.gitWatcher.AddCommitCallback(s.recordCommit)
+	var ctx context.Context
+	if poll {
+		ctx, s.stopGitPolling = context.WithCancel(context.Background())
+	}
+	for _, config := range listRepos() {
+		s.watchRepo(config)
+	}
+	if poll {
+		go s.gitWatcher.StartPolling(ctx)
+	}
+}
+
+// watchRepo registers an active repository with the git watcher: through its webhook when one is
+// configured, otherwise polled for new commits on its branch when the watcher polls. The initial
+// clone of a polled repository runs in the background.
+func (s *Server) watchRepo(config *RepoConfig) {
+	if s.gitWatcher == nil || !config.Active {
+		return
+	}
+	if config.WebhookSecret != "" {
+		s.gitWatcher.AddWebhook(config.URL, &cicd.WebhookConfig{Secret: config.WebhookSecret, Events: config.WebhookEvents, Endpoint: cicd.WebhookPath})
+		return
+	}
+	if s.stopGitPolling == nil {
+		return
+	}
+	go func() {
+		auth, err := gitAuthForURL(config.URL)
+		if err == nil {
+			err = s.gitWatcher.AddRepository(config.URL, config.Branch, auth)
+		}
+		if err != nil {
+			klog.Errorf("Failed to watch repository %s for new commits: %v", config.URL, err)
+		}
+	}()
+}
+
+// recordCommit stores the commit of a push on every active repository configured for its URL and
+// branch, then builds and deploys each of them in the background
+func (s *Server) recordCommit(event cicd.CommitEvent) error {
+	matched := false
+	for _, config := range listRepos() {
+		if config.Active && config.Branch == event.Branch && cicd.NormalizeRepoURL(config.URL) == cicd.NormalizeRepoURL(event.RepoURL) {
+			updateRepo(config, func(config *RepoConfig) { config.LastCommit = event.CommitHash })
+			matched = true
+			go s.runCommitPipeline(context.Background(), config.Name, event.CommitHash)
+		}
+	}
+	if !matched {
+		return fmt.Errorf("no active repository configured for %s branch %s", event.RepoURL, event.Branch)
+	}
+	klog.V(1).Infof("Recorded commit %s on %s:%s", event.CommitHash, event.RepoURL, event.Branch)
+	return nil
+}
+
+// runCommitPipeline builds and pushes the image of a commit with repo_build, then deploys it with
+// repo_deploy, which records the execution. A failed build is recorded as a failed execution so
+// its notifications fire as well.
+func (s *Server) runCommitPipeline(ctx context.Context, name, commit string) {
+	build, _ := s.repoBuild(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "repo_build",
+		Arguments: map[string]interface{}{"name": name, "commit": commit},
+	}})
+	if build.IsError {
+		message := toolResultText(build)
+		execution := pipelineExecutions.start(name, commit, "")
+		pipelineExecutions.stage(execution, "build", "failed", message)
+		pipelineExecutions.finish(execution, "", message)
+		klog.Errorf("Pipeline of %s failed for commit %s: %s", name, commit, message)
+		return
+	}
+	deploy, _ := s.repoDeploy(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "repo_deploy",
+		Arguments: map[string]interface{}{"name": name, "image_tag": commitImageTag(commit)},
+	}})
+	if deploy.IsError {
+		klog.Errorf("Pipeline of %s failed to deploy commit %s: %s", name, commit, toolResultText(deploy))
+		return
+	}
+	// A deployment whose critical objects failed to apply is reported in the result, not as an error
+	if status := deployResultStatus(deploy); status != "success" {
+		klog.Errorf("Pipeline of %s failed to deploy commit %s: repo_deploy reported status %q", name, commit, status)
+		return
+	}
+	klog.V(1).Infof("Pipeline of %s deployed commit %s", name, commit)
+}
+
+// deployResultStatus returns the status field of a repo_deploy result
+func deployResultStatus(result *mcp.CallToolResult) string {
+	var deploy struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(toolResultText(result)), &deploy); err != nil {
+		return ""
+	}
+	return deploy.Status
+}
+
+// toolResultText returns the text of a tool result, the error message of a failed one
+func toolResultText(result *mcp.CallToolResult) string {
+	if len(result.Content) > 0 {
+		if text, ok := result.Content[0].(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
+
+// unwatchRepo unregisters a removed or deactivated repository from the git watcher, unless another
+// active repository receives the pushes or polls the branch of the same URL. It reports whether the
+// watcher changed.
+func (s *Server) unwatchRepo(config *RepoConfig) bool {
+	if s.gitWatcher == nil {
+		return false
+	}
+	for _, other := range listRepos() {
+		if !other.Active || cicd.NormalizeRepoURL(other.URL) != cicd.NormalizeRepoURL(config.URL) {
+			continue
+		}
+		if config.WebhookSecret != "" && other.WebhookSecret != "" {
+			return false
+		}
+		if config.WebhookSecret == "" && other.WebhookSecret == "" && other.Branch == config.Branch {
+			return false
+		}
+	}
+	if config.WebhookSecret != "" {
+		s.gitWatcher.RemoveWebhook(config.URL)
+	} else {
+		s.gitWatcher.RemoveRepository(config.URL, config.Branch)
+	}
+	return true
+}
+
+// redacted returns a copy of the configuration with the webhook secret and SSH key passphrase
+// masked, for tool results
+func (c *RepoConfig) redacted() *RepoConfig {
+	redacted := *c
+	if redacted.WebhookSecret != "" {
+		redacted.WebhookSecret = "***"
+	}
+	if redacted.SSHKeyPassphrase != "" {
+		redacted.SSHKeyPassphrase = "***"
+	}
+	// Slack and most webhook URLs embed their credentials
+	if redacted.NotifyURL != "" {
+		redacted.NotifyURL = "***"
+	}
+	return &redacted
+}
+
+// gitAddWebhook registers a push webhook for a configured repository
+func (s *Server) gitAddWebhook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+	events, err := getStringSliceArg(args, "events")
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	if len(events) == 0 {
+		events = []string{"push"}
+	}
+	secret := getStringArg(args, "secret", "")
+	generated := secret == ""
+	if generated {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return NewTextResult("", fmt.Errorf("failed to generate webhook secret: %v", err)), nil
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	updateRepo(config, func(config *RepoConfig) {
+		config.Webhook = cicd.WebhookPath
+		config.WebhookSecret = secret
+		config.WebhookEvents = events
+	})
+	s.watchRepo(config)
+
+	result := map[string]interface{}{
+		"status":   "registered",
+		"repo":     config.Name,
+		"url":      config.URL,
+		"endpoint": cicd.WebhookPath,
+		"events":   events,
+		"setup": map[string]string{
+			"github": fmt.Sprintf("Settings > Webhooks > Add webhook: payload URL https://<server>%s, content type application/json, secret as below", cicd.WebhookPath),
+			"gitlab": fmt.Sprintf("Settings > Webhooks: URL https://<server>%s, secret token as below, trigger 'Push events'", cicd.WebhookPath),
+		},
+		"message": fmt.Sprintf("Pushes to %s now trigger the pipeline through the webhook and the repository is no longer polled", config.Branch),
+	}
+	// A secret chosen by the caller is not echoed back
+	if generated {
+		result["secret"] = secret
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// cicdSetPipelineActive enables or disables the pipeline of a repository, watching or unwatching it
+func (s *Server) cicdSetPipelineActive(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	active, ok := args["active"].(bool)
+	if !ok {
+		return NewTextResult("", fmt.Errorf("active parameter is required")), nil
+	}
+	config := findRepo(name)
+	if config == nil {
+		return NewTextResult("", fmt.Errorf("repository '%s' not found", name)), nil
+	}
+
+	watcherUpdated := false
+	if config.Active != active {
+		updateRepo(config, func(config *RepoConfig) { config.Active = active })
+		if active {
+			s.watchRepo(config)
+			watcherUpdated = s.gitWatcher != nil
+		} else {
+			watcherUpdated = s.unwatchRepo(config)
+		}
+	}
+
+	state := "disabled"
+	if active {
+		state = "enabled"
+	}
+	activeCount := 0
+	repos := listRepos()
+	for _, repo := range repos {
+		if repo.Active {
+			activeCount++
+		}
+	}
+	result := map[string]interface{}{
+		"status":              "success",
+		"message":             fmt.Sprintf("Pipeline of repository '%s' %s", config.Name, state),
+		"repository":          config.redacted(),
+		"pipelines":           len(repos),
+		"active_pipelines":    activeCount,
+		"git_watcher_updated": watcherUpdated,
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
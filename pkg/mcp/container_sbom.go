@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// sbomFormat is an SBOM document format and how each tool names it
+type sbomFormat struct {
+	Name         string // syft and docker sbom output format
+	CosignType   string // cosign attach sbom --type
+	ArtifactType string // OCI artifact type used by oras attach
+	Extension    string
+}
+
+// sbomFormats lists the supported SBOM formats by name, 'spdx' and 'cyclonedx' are accepted as short names
+var sbomFormats = map[string]sbomFormat{
+	"spdx-json":      {Name: "spdx-json", CosignType: "spdx", ArtifactType: "application/spdx+json", Extension: ".spdx.json"},
+	"cyclonedx-json": {Name: "cyclonedx-json", CosignType: "cyclonedx", ArtifactType: "application/vnd.cyclonedx+json", Extension: ".cdx.json"},
+}
+
+// lookupSBOMFormat resolves an SBOM format name, defaulting to SPDX
+func lookupSBOMFormat(name string) (sbomFormat, error) {
+	switch name = strings.ToLower(name); name {
+	case "":
+		name = "spdx-json"
+	case "spdx", "cyclonedx":
+		name += "-json"
+	}
+	format, ok := sbomFormats[name]
+	if !ok {
+		return sbomFormat{}, fmt.Errorf("sbom format must be 'spdx-json' or 'cyclonedx-json', got '%s'", name)
+	}
+	return format, nil
+}
+
+// sbomPackageCount counts the packages of an SPDX document or the components of a CycloneDX one
+func sbomPackageCount(document []byte) int {
+	var parsed struct {
+		Packages   []json.RawMessage `json:"packages"`
+		Components []json.RawMessage `json:"components"`
+	}
+	if err := json.Unmarshal(document, &parsed); err != nil {
+		return 0
+	}
+	return len(parsed.Packages) + len(parsed.Components)
+}
+
+// generateSBOM produces the SBOM of an image with syft, or with the docker sbom plugin when syft is
+// not installed and the runtime is docker. Source is 'auto', 'local' or 'remote'.
+func generateSBOM(ctx context.Context, imageName string, format sbomFormat, source string) ([]byte, string, error) {
+	syft := os.Getenv("SYFT_PATH")
+	if syft == "" {
+		syft = "syft"
+	}
+	if _, err := exec.LookPath(syft); err == nil {
+		ref := imageName
+		switch source {
+		case "local":
+			containerRuntime, err := detectContainerRuntime()
+			if err != nil {
+				return nil, "", err
+			}
+			ref = containerRuntime + ":" + imageName
+		case "remote":
+			ref = "registry:" + imageName
+		}
+		cmd := exec.CommandContext(ctx, syft, ref, "-o", format.Name, "-q")
+		cmd.Env = os.Environ()
+		host, _ := splitRepository(imageRepository(imageName))
+		if username, password := registryCredentials(host, os.Getenv("REGISTRY_USERNAME"), os.Getenv("REGISTRY_PASSWORD")); username != "" {
+			cmd.Env = append(cmd.Env, "SYFT_REGISTRY_AUTH_AUTHORITY="+host, "SYFT_REGISTRY_AUTH_USERNAME="+username, "SYFT_REGISTRY_AUTH_PASSWORD="+password)
+		}
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, "", fmt.Errorf("syft failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.Bytes(), "syft", nil
+	}
+
+	// Podman has no SBOM command, docker has one when the sbom plugin is installed
+	if containerRuntime, err := detectContainerRuntime(); err == nil && containerRuntime == "docker" && source != "remote" {
+		output, err := exec.CommandContext(ctx, "docker", "sbom", "--format", format.Name, imageName).Output()
+		if err == nil {
+			return output, "docker sbom", nil
+		}
+		klog.V(1).Infof("docker sbom is not available: %v", err)
+	}
+	return nil, "", fmt.Errorf("SBOM generation is not available: install syft (https://github.com/anchore/syft) or the docker sbom plugin")
+}
+
+// attachSBOM attaches an SBOM file to a pushed image with cosign or oras
+func attachSBOM(ctx context.Context, imageName, path string, format sbomFormat, method string) error {
+	host, _ := splitRepository(imageRepository(imageName))
+	var cmd *exec.Cmd
+	switch method {
+	case "cosign":
+		args := []string{"attach", "sbom", "--sbom", path, "--type", format.CosignType, "--input-format", "json", imageName}
+		var cleanup func()
+		var err error
+		if cmd, cleanup, err = cosignCommand(ctx, host, args...); err != nil {
+			return err
+		}
+		defer cleanup()
+	case "oras":
+		if _, err := exec.LookPath("oras"); err != nil {
+			return fmt.Errorf("oras not found in PATH, install it from https://oras.land to attach SBOMs")
+		}
+		args := []string{"attach", "--artifact-type", format.ArtifactType}
+		if username, password := registryCredentials(host, os.Getenv("REGISTRY_USERNAME"), os.Getenv("REGISTRY_PASSWORD")); username != "" {
+			args = append(args, "--username", username, "--password", password)
+		}
+		cmd = exec.CommandContext(ctx, "oras", append(args, imageName, path+":"+format.ArtifactType)...)
+	default:
+		return fmt.Errorf("sbom attach must be 'cosign' or 'oras', got '%s'", method)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s attach failed: %v: %s", method, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// imageSBOM generates the SBOM of an image, stores it in the build log sink when one is configured
+// and attaches it to the image when attach names a method. Storage and attach failures are reported
+// in the result, only a failed generation is an error.
+func (s *Server) imageSBOM(ctx context.Context, imageName string, format sbomFormat, source, attach string) (map[string]interface{}, error) {
+	document, generator, err := generateSBOM(ctx, imageName, format, source)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"format":    format.Name,
+		"generator": generator,
+		"packages":  sbomPackageCount(document),
+		"document":  json.RawMessage(document),
+	}
+	if !json.Valid(document) {
+		result["document"] = string(document)
+	}
+	if s.buildLogSink() != "" {
+		if ref, err := s.persistBuildArtifact(ctx, imageName, format.Extension, document); err != nil {
+			result["storage_error"] = err.Error()
+		} else {
+			result["stored_at"] = ref
+		}
+	}
+	if attach != "" {
+		file, err := os.CreateTemp("", "sbom-*"+format.Extension)
+		if err == nil {
+			_, err = file.Write(document)
+			_ = file.Close()
+			defer func() { _ = os.Remove(file.Name()) }()
+		}
+		if err == nil {
+			err = attachSBOM(ctx, imageName, file.Name(), format, attach)
+		}
+		if err != nil {
+			result["attach_error"] = err.Error()
+		} else {
+			result["attached_with"] = attach
+		}
+	}
+	return result, nil
+}
+
+// containerSBOM handles generating the SBOM of an image
+func (s *Server) containerSBOM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	imageName, ok := args["image_name"].(string)
+	if !ok || imageName == "" {
+		return NewTextResult("", fmt.Errorf("image_name parameter is required")), nil
+	}
+	format, err := lookupSBOMFormat(getStringArg(args, "format", ""))
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	source := getStringArg(args, "source", "auto")
+	if source != "auto" && source != "local" && source != "remote" {
+		return NewTextResult("", fmt.Errorf("source must be 'auto', 'local' or 'remote', got '%s'", source)), nil
+	}
+	attach := getStringArg(args, "attach", "")
+	if attach != "" && attach != "cosign" && attach != "oras" {
+		return NewTextResult("", fmt.Errorf("attach must be 'cosign' or 'oras', got '%s'", attach)), nil
+	}
+
+	klog.V(2).Infof("Generating %s SBOM of image %s", format.Name, imageName)
+	sbom, err := s.imageSBOM(ctx, imageName, format, source, attach)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to generate the SBOM of %s: %v", imageName, err)), nil
+	}
+	sbom["image"] = imageName
+	jsonResult, _ := json.MarshalIndent(sbom, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
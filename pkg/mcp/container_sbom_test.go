@@ -0,0 +1,36 @@
+package mcp
+
+import "testing"
+
+func TestLookupSBOMFormat(t *testing.T) {
+	cases := map[string]string{
+		"":               "spdx-json",
+		"spdx":           "spdx-json",
+		"SPDX-JSON":      "spdx-json",
+		"cyclonedx":      "cyclonedx-json",
+		"cyclonedx-json": "cyclonedx-json",
+	}
+	for name, expected := range cases {
+		format, err := lookupSBOMFormat(name)
+		if err != nil || format.Name != expected {
+			t.Errorf("lookupSBOMFormat(%q) = %q, %v, expected %q", name, format.Name, err, expected)
+		}
+	}
+	if _, err := lookupSBOMFormat("syft-json"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestSBOMPackageCount(t *testing.T) {
+	cases := map[string]int{
+		`{"spdxVersion": "SPDX-2.3", "packages": [{"name": "openssl"}, {"name": "glibc"}]}`: 2,
+		`{"bomFormat": "CycloneDX", "components": [{"name": "lodash"}]}`:                    1,
+		`{"bomFormat": "CycloneDX"}`: 0,
+		`not json`:                   0,
+	}
+	for document, expected := range cases {
+		if count := sbomPackageCount([]byte(document)); count != expected {
+			t.Errorf("sbomPackageCount(%s) = %d, expected %d", document, count, expected)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// scanSeverities lists the severities reported by container_scan, most severe first
+var scanSeverities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+
+// ScanFinding is a vulnerability found in an image
+type ScanFinding struct {
+	ID               string `json:"id"`
+	Package          string `json:"package"`
+	InstalledVersion string `json:"installed_version"`
+	FixedVersion     string `json:"fixed_version,omitempty"` // empty when no fix is available
+	Title            string `json:"title,omitempty"`
+	Target           string `json:"target"` // OS packages or the language dependency file of the package
+}
+
+// ScanResult is the outcome of a container_scan
+type ScanResult struct {
+	Image             string                   `json:"image"`
+	Scanner           string                   `json:"scanner"`
+	Summary           map[string]int           `json:"summary"`
+	Findings          map[string][]ScanFinding `json:"findings"`
+	SeverityThreshold string                   `json:"severity_threshold,omitempty"`
+	Passed            bool                     `json:"passed"`
+	Duration          string                   `json:"duration"`
+}
+
+// trivyReport is the part of the Trivy JSON report container_scan reads
+type trivyReport struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// detectTrivy finds the Trivy binary, TRIVY_PATH takes precedence over the PATH
+func detectTrivy() (string, error) {
+	if path := os.Getenv("TRIVY_PATH"); path != "" {
+		if _, err := exec.LookPath(path); err == nil {
+			return path, nil
+		}
+		klog.V(1).Infof("Requested Trivy binary %s not found, falling back to the PATH", path)
+	}
+	if path, err := exec.LookPath("trivy"); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("trivy not found in PATH, install it from https://trivy.dev to scan images")
+}
+
+// severityIndex returns the position of a severity in scanSeverities, or -1 when unknown
+func severityIndex(severity string) int {
+	for i, s := range scanSeverities {
+		if s == strings.ToUpper(severity) {
+			return i
+		}
+	}
+	return -1
+}
+
+// summarizeTrivyReport groups the vulnerabilities of a report by severity, sorted by ID. A
+// vulnerability reported for several targets is kept once per target.
+func summarizeTrivyReport(report trivyReport) (map[string][]ScanFinding, map[string]int) {
+	findings := make(map[string][]ScanFinding, len(scanSeverities))
+	summary := make(map[string]int, len(scanSeverities))
+	for _, severity := range scanSeverities {
+		findings[severity] = make([]ScanFinding, 0)
+		summary[severity] = 0
+	}
+	for _, result := range report.Results {
+		for _, vuln := range result.Vulnerabilities {
+			severity := strings.ToUpper(vuln.Severity)
+			if severityIndex(severity) < 0 {
+				continue // UNKNOWN
+			}
+			findings[severity] = append(findings[severity], ScanFinding{
+				ID:               vuln.VulnerabilityID,
+				Package:          vuln.PkgName,
+				InstalledVersion: vuln.InstalledVersion,
+				FixedVersion:     vuln.FixedVersion,
+				Title:            vuln.Title,
+				Target:           result.Target,
+			})
+			summary[severity]++
+		}
+	}
+	for _, severity := range scanSeverities {
+		sort.SliceStable(findings[severity], func(i, j int) bool {
+			return findings[severity][i].ID < findings[severity][j].ID
+		})
+	}
+	return findings, summary
+}
+
+// findingsAtOrAbove counts the findings at or above a severity threshold
+func findingsAtOrAbove(summary map[string]int, threshold string) int {
+	count := 0
+	for i := 0; i <= severityIndex(threshold); i++ {
+		count += summary[scanSeverities[i]]
+	}
+	return count
+}
+
+// containerScan handles scanning an image for vulnerabilities with Trivy
+func (s *Server) containerScan(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	imageName, ok := args["image_name"].(string)
+	if !ok || imageName == "" {
+		return NewTextResult("", fmt.Errorf("image_name parameter is required")), nil
+	}
+	threshold := strings.ToUpper(getStringArg(args, "severity_threshold", ""))
+	if threshold != "" && severityIndex(threshold) < 0 {
+		return NewTextResult("", fmt.Errorf("severity_threshold must be one of %s, got '%s'", strings.Join(scanSeverities, ", "), threshold)), nil
+	}
+	source := getStringArg(args, "source", "auto")
+	timeout, err := time.ParseDuration(getStringArg(args, "timeout", "10m"))
+	if err != nil || timeout <= 0 {
+		return NewTextResult("", fmt.Errorf("invalid timeout '%s'", getStringArg(args, "timeout", ""))), nil
+	}
+
+	trivy, err := detectTrivy()
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	scanArgs := []string{"image", "--format", "json", "--quiet", "--scanners", "vuln", "--timeout", timeout.String()}
+	scanArgs = append(scanArgs, "--severity", strings.Join(scanSeverities, ","))
+	if getBoolArg(args, "ignore_unfixed", false) {
+		scanArgs = append(scanArgs, "--ignore-unfixed")
+	}
+	switch source {
+	case "auto":
+	case "local":
+		containerRuntime, err := detectContainerRuntime()
+		if err != nil {
+			return NewTextResult("", err), nil
+		}
+		scanArgs = append(scanArgs, "--image-src", containerRuntime)
+	case "remote":
+		scanArgs = append(scanArgs, "--image-src", "remote")
+	default:
+		return NewTextResult("", fmt.Errorf("source must be 'auto', 'local' or 'remote', got '%s'", source)), nil
+	}
+	scanArgs = append(scanArgs, imageName)
+
+	// Remote images are pulled by Trivy with the stored registry credentials
+	cmd := exec.CommandContext(ctx, trivy, scanArgs...)
+	cmd.Env = os.Environ()
+	host, _ := splitRepository(imageName)
+	if username, password := registryCredentials(host, os.Getenv("REGISTRY_USERNAME"), os.Getenv("REGISTRY_PASSWORD")); username != "" {
+		cmd.Env = append(cmd.Env, "TRIVY_USERNAME="+username, "TRIVY_PASSWORD="+password)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	klog.V(2).Infof("Scanning image %s with %s", imageName, trivy)
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		return NewTextResult("", fmt.Errorf("trivy scan of %s failed: %v: %s", imageName, err, strings.TrimSpace(stderr.String()))), nil
+	}
+	var report trivyReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return NewTextResult("", fmt.Errorf("failed to parse trivy report: %v", err)), nil
+	}
+
+	findings, summary := summarizeTrivyReport(report)
+	result := ScanResult{
+		Image:             imageName,
+		Scanner:           "trivy",
+		Summary:           summary,
+		Findings:          findings,
+		SeverityThreshold: threshold,
+		Passed:            threshold == "" || findingsAtOrAbove(summary, threshold) == 0,
+		Duration:          time.Since(start).Round(time.Millisecond).String(),
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	if !result.Passed {
+		return NewTextResult("", fmt.Errorf("%s has %d vulnerabilities at or above %s severity:\n%s",
+			imageName, findingsAtOrAbove(summary, threshold), threshold, jsonResult)), nil
+	}
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSummarizeTrivyReport(t *testing.T) {
+	var report trivyReport
+	if err := json.Unmarshal([]byte(`{"Results": [
+		{"Target": "app (ubi 9.4)", "Vulnerabilities": [
+			{"VulnerabilityID": "CVE-2024-2", "PkgName": "openssl", "InstalledVersion": "3.0.7", "FixedVersion": "3.0.8", "Severity": "HIGH"},
+			{"VulnerabilityID": "CVE-2024-1", "PkgName": "glibc", "InstalledVersion": "2.34", "Severity": "HIGH"},
+			{"VulnerabilityID": "CVE-2024-3", "PkgName": "zlib", "InstalledVersion": "1.2", "Severity": "UNKNOWN"}
+		]},
+		{"Target": "package-lock.json", "Vulnerabilities": [
+			{"VulnerabilityID": "CVE-2024-4", "PkgName": "lodash", "InstalledVersion": "4.17.0", "FixedVersion": "4.17.21", "Severity": "low"}
+		]},
+		{"Target": "requirements.txt"}
+	]}`), &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+
+	findings, summary := summarizeTrivyReport(report)
+	if summary["CRITICAL"] != 0 || summary["HIGH"] != 2 || summary["MEDIUM"] != 0 || summary["LOW"] != 1 {
+		t.Errorf("unexpected summary %v", summary)
+	}
+	if high := findings["HIGH"]; len(high) != 2 || high[0].ID != "CVE-2024-1" || high[1].FixedVersion != "3.0.8" {
+		t.Errorf("expected HIGH findings sorted by ID, got %+v", high)
+	}
+	if low := findings["LOW"]; len(low) != 1 || low[0].Target != "package-lock.json" {
+		t.Errorf("unexpected LOW findings %+v", low)
+	}
+	if findings["CRITICAL"] == nil {
+		t.Error("expected an empty, not nil, list of CRITICAL findings")
+	}
+
+	cases := map[string]int{"CRITICAL": 0, "HIGH": 2, "MEDIUM": 2, "LOW": 3}
+	for threshold, expected := range cases {
+		if count := findingsAtOrAbove(summary, threshold); count != expected {
+			t.Errorf("findingsAtOrAbove(%s) = %d, expected %d", threshold, count, expected)
+		}
+	}
+}
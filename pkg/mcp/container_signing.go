@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// tlogIndexPattern matches the transparency log entry cosign reports after signing
+var tlogIndexPattern = regexp.MustCompile(`tlog entry created with index:\s*(\d+)`)
+
+// SignatureVerification is a signature cosign verified for an image
+type SignatureVerification struct {
+	ImageDigest     string `json:"image_digest"`
+	DockerReference string `json:"docker_reference"`
+	Subject         string `json:"subject,omitempty"` // identity of a keyless signature
+	Issuer          string `json:"issuer,omitempty"`  // OIDC issuer of a keyless signature
+}
+
+// detectCosign finds the cosign binary, COSIGN_PATH takes precedence over the PATH
+func detectCosign() (string, error) {
+	if path := os.Getenv("COSIGN_PATH"); path != "" {
+		if _, err := exec.LookPath(path); err == nil {
+			return path, nil
+		}
+		klog.V(1).Infof("Requested cosign binary %s not found, falling back to the PATH", path)
+	}
+	if path, err := exec.LookPath("cosign"); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("cosign not found in PATH, install it from https://docs.sigstore.dev/cosign/system_config/installation/ to sign and verify images")
+}
+
+// imageReference splits an image into its repository and its tag or digest, defaulting to latest
+func imageReference(imageName string) (string, string) {
+	if repository, digest, found := strings.Cut(imageName, "@"); found {
+		return repository, digest
+	}
+	repository := imageRepository(imageName)
+	if repository == imageName {
+		return repository, "latest"
+	}
+	return repository, imageName[len(repository)+1:]
+}
+
+// cosignCommand prepares a cosign command for an image of the given registry host. Cosign only reads
+// registry credentials from the docker configuration, so the stored credentials of the host are written
+// to a temporary DOCKER_CONFIG readable only by the current user rather than passed as arguments.
+// The returned function removes the temporary configuration.
+func cosignCommand(ctx context.Context, host string, args ...string) (*exec.Cmd, func(), error) {
+	cosign, err := detectCosign()
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd := exec.CommandContext(ctx, cosign, args...)
+	username, password := registryCredentials(host, os.Getenv("REGISTRY_USERNAME"), os.Getenv("REGISTRY_PASSWORD"))
+	if username == "" {
+		return cmd, func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "cosign-docker-config-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary docker configuration: %w", err)
+	}
+	remove := func() { _ = os.RemoveAll(dir) }
+	auths := registryAuthFile{Auths: map[string]registryAuthEntry{
+		registryHost(host): {Auth: base64.StdEncoding.EncodeToString([]byte(username + ":" + password))},
+	}}
+	data, _ := json.Marshal(auths)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), data, 0o600); err != nil {
+		remove()
+		return nil, nil, fmt.Errorf("failed to write temporary docker configuration: %w", err)
+	}
+	cmd.Env = append(os.Environ(), "DOCKER_CONFIG="+dir)
+	return cmd, remove, nil
+}
+
+// parseCosignVerifyOutput reads the JSON cosign verify prints for the verified signatures
+func parseCosignVerifyOutput(output []byte) ([]SignatureVerification, error) {
+	var payloads []struct {
+		Critical struct {
+			Identity struct {
+				DockerReference string `json:"docker-reference"`
+			} `json:"identity"`
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+		Optional map[string]interface{} `json:"optional"`
+	}
+	if err := json.Unmarshal(output, &payloads); err != nil {
+		return nil, fmt.Errorf("failed to parse cosign output: %v", err)
+	}
+	verifications := make([]SignatureVerification, 0, len(payloads))
+	for _, payload := range payloads {
+		verification := SignatureVerification{
+			ImageDigest:     payload.Critical.Image.DockerManifestDigest,
+			DockerReference: payload.Critical.Identity.DockerReference,
+		}
+		verification.Subject, _ = payload.Optional["Subject"].(string)
+		verification.Issuer, _ = payload.Optional["Issuer"].(string)
+		verifications = append(verifications, verification)
+	}
+	return verifications, nil
+}
+
+// containerSign handles signing a pushed image with cosign
+func (s *Server) containerSign(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	imageName, ok := args["image_name"].(string)
+	if !ok || imageName == "" {
+		return NewTextResult("", fmt.Errorf("image_name parameter is required")), nil
+	}
+	key := getStringArg(args, "key", "")
+	if key == "" && os.Getenv("COSIGN_KEY") != "" {
+		key = "env://COSIGN_KEY"
+	}
+	identityToken := getStringArg(args, "identity_token", "")
+	tlogUpload := getBoolArg(args, "tlog_upload", true)
+
+	// Sign the digest rather than the tag, a tag can be moved to another image after signing
+	repository, reference := imageReference(imageName)
+	host, name := splitRepository(repository)
+	client := newRegistryClient(host)
+	digest := reference
+	var err error
+	if !strings.HasPrefix(reference, "sha256:") {
+		if _, digest, err = client.Manifest(ctx, name, reference); err != nil {
+			return NewTextResult("", fmt.Errorf("failed to resolve the digest of %s, is the image pushed? %v", imageName, err)), nil
+		}
+	}
+	signedRef := repository + "@" + digest
+
+	signArgs := []string{"sign", "--yes"}
+	mode := "keyless"
+	if key != "" {
+		mode = "key"
+		signArgs = append(signArgs, "--key", key)
+	} else if identityToken != "" {
+		signArgs = append(signArgs, "--identity-token", identityToken)
+	}
+	if !tlogUpload {
+		signArgs = append(signArgs, "--tlog-upload=false")
+	}
+	signArgs = append(signArgs, signedRef)
+
+	cmd, cleanup, err := cosignCommand(ctx, host, signArgs...)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	defer cleanup()
+	klog.V(2).Infof("Signing image %s with cosign (%s)", signedRef, mode)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("cosign sign of %s failed: %v: %s", signedRef, err, strings.TrimSpace(string(output)))), nil
+	}
+
+	result := map[string]interface{}{
+		"status":       "success",
+		"image":        imageName,
+		"image_digest": digest,
+		"signed_ref":   signedRef,
+		"mode":         mode,
+	}
+	// cosign stores the signature as an image tagged after the signed digest
+	signatureTag := strings.Replace(digest, ":", "-", 1) + ".sig"
+	result["signature_ref"] = repository + ":" + signatureTag
+	if _, signatureDigest, err := client.Manifest(ctx, name, signatureTag); err == nil {
+		result["signature_digest"] = signatureDigest
+	} else {
+		klog.V(1).Infof("Failed to resolve the signature digest of %s: %v", signedRef, err)
+	}
+	if match := tlogIndexPattern.FindStringSubmatch(string(output)); match != nil {
+		result["tlog_entry"] = map[string]interface{}{
+			"index": match[1],
+			"url":   "https://search.sigstore.dev/?logIndex=" + match[1],
+		}
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// containerVerify handles verifying the cosign signatures of an image, without the provenance
+// attestations verify_image also checks
+func (s *Server) containerVerify(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	imageName, ok := args["image_name"].(string)
+	if !ok || imageName == "" {
+		return NewTextResult("", fmt.Errorf("image_name parameter is required")), nil
+	}
+	policy := ImageVerificationPolicy{
+		Key:            getStringArg(args, "public_key", ""),
+		Identity:       getStringArg(args, "certificate_identity", ""),
+		IdentityRegexp: getStringArg(args, "certificate_identity_regexp", ""),
+		OIDCIssuer:     getStringArg(args, "certificate_oidc_issuer", ""),
+	}
+	if policy.Key == "" && policy.OIDCIssuer == "" && os.Getenv("COSIGN_PUBLIC_KEY") != "" {
+		policy.Key = "env://COSIGN_PUBLIC_KEY"
+	}
+	policyArgs, err := cosignPolicyArgs(policy)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("either public_key, or certificate_identity (or certificate_identity_regexp) with certificate_oidc_issuer is required")), nil
+	}
+	if _, err := detectCosign(); err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	repository, _ := imageReference(imageName)
+	host, _ := splitRepository(repository)
+	klog.V(2).Infof("Verifying the signatures of image %s with cosign", imageName)
+	verifications, err := verifyImageSignatures(ctx, host, imageName, policyArgs)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("signature verification of %s failed: %v", imageName, err)), nil
+	}
+	if len(verifications) == 0 {
+		return NewTextResult("", fmt.Errorf("no valid signature found for %s", imageName)), nil
+	}
+
+	// Keyless signatures record the identity and issuer the signing certificate was issued to
+	result := map[string]interface{}{
+		"status":     "verified",
+		"image":      imageName,
+		"digest":     verifications[0].ImageDigest,
+		"signatures": verifications,
+	}
+	if verifications[0].Subject != "" {
+		result["identity"] = verifications[0].Subject
+		result["issuer"] = verifications[0].Issuer
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestImageReference(t *testing.T) {
+	cases := map[string][2]string{
+		"quay.io/user/app:v1.0":          {"quay.io/user/app", "v1.0"},
+		"quay.io/user/app":               {"quay.io/user/app", "latest"},
+		"localhost:5000/app":             {"localhost:5000/app", "latest"},
+		"localhost:5000/app:dev":         {"localhost:5000/app", "dev"},
+		"quay.io/user/app@sha256:abc123": {"quay.io/user/app", "sha256:abc123"},
+	}
+	for image, expected := range cases {
+		if repository, reference := imageReference(image); repository != expected[0] || reference != expected[1] {
+			t.Errorf("imageReference(%q) = %q, %q, expected %q, %q", image, repository, reference, expected[0], expected[1])
+		}
+	}
+}
+
+func TestParseCosignVerifyOutput(t *testing.T) {
+	output := `[{"critical":{"identity":{"docker-reference":"quay.io/user/app"},"image":{"docker-manifest-digest":"sha256:abc123"},"type":"cosign container image signature"},
+		"optional":{"Issuer":"https://token.actions.githubusercontent.com","Subject":"https://github.com/user/app/.github/workflows/release.yml@refs/heads/main"}},
+		{"critical":{"identity":{"docker-reference":"quay.io/user/app"},"image":{"docker-manifest-digest":"sha256:abc123"},"type":"cosign container image signature"},"optional":null}]`
+	verifications, err := parseCosignVerifyOutput([]byte(output))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(verifications) != 2 {
+		t.Fatalf("expected 2 verifications, got %d", len(verifications))
+	}
+	if verifications[0].ImageDigest != "sha256:abc123" || verifications[0].Issuer != "https://token.actions.githubusercontent.com" {
+		t.Errorf("unexpected keyless verification %+v", verifications[0])
+	}
+	if verifications[1].Subject != "" || verifications[1].DockerReference != "quay.io/user/app" {
+		t.Errorf("unexpected key verification %+v", verifications[1])
+	}
+	if _, err := parseCosignVerifyOutput([]byte("Verification for quay.io/user/app")); err == nil {
+		t.Error("expected an error for non-JSON output")
+	}
+}
+
+func TestCosignCommandKeepsCredentialsOutOfArguments(t *testing.T) {
+	t.Setenv("COSIGN_PATH", "true")
+	t.Setenv(registryCredentialsPathEnv, filepath.Join(t.TempDir(), "registry-auth.json"))
+	t.Setenv("REGISTRY_USERNAME", "user")
+	t.Setenv("REGISTRY_PASSWORD", "s3cret")
+	cmd, cleanup, err := cosignCommand(context.Background(), "quay.io", "verify", "quay.io/user/app:v1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, arg := range cmd.Args {
+		if strings.Contains(arg, "s3cret") {
+			t.Fatalf("password passed as an argument: %v", cmd.Args)
+		}
+	}
+	var dockerConfig string
+	for _, env := range cmd.Env {
+		if value, found := strings.CutPrefix(env, "DOCKER_CONFIG="); found {
+			dockerConfig = value
+		}
+	}
+	if dockerConfig == "" {
+		t.Fatal("expected DOCKER_CONFIG to be set")
+	}
+	info, err := os.Stat(filepath.Join(dockerConfig, "config.json"))
+	if err != nil {
+		t.Fatalf("expected a docker configuration: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	cleanup()
+	if _, err := os.Stat(dockerConfig); !os.IsNotExist(err) {
+		t.Errorf("expected the docker configuration to be removed, got %v", err)
+	}
+}
+
+func TestCosignPolicyArgs(t *testing.T) {
+	args, err := cosignPolicyArgs(ImageVerificationPolicy{IdentityRegexp: "^https://github.com/user/", OIDCIssuer: "https://token.actions.githubusercontent.com"})
+	if err != nil || len(args) != 4 || args[0] != "--certificate-identity-regexp" {
+		t.Errorf("unexpected keyless arguments %v, %v", args, err)
+	}
+	if _, err := cosignPolicyArgs(ImageVerificationPolicy{Identity: "user@example.com"}); err == nil {
+		t.Error("expected an error without an OIDC issuer")
+	}
+}
+
+func TestContainerVerifyReturnsSignerIdentity(t *testing.T) {
+	cosign := filepath.Join(t.TempDir(), "cosign")
+	script := `#!/bin/sh
+echo '[{"critical":{"identity":{"docker-reference":"quay.io/user/app"},"image":{"docker-manifest-digest":"sha256:abc123"}},"optional":{"Issuer":"https://token.actions.githubusercontent.com","Subject":"https://github.com/user/app/.github/workflows/release.yml@refs/heads/main"}}]'
+`
+	if err := os.WriteFile(cosign, []byte(script), 0o700); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("COSIGN_PATH", cosign)
+	t.Setenv(registryCredentialsPathEnv, filepath.Join(t.TempDir(), "registry-auth.json"))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"image_name":                  "quay.io/user/app:v1.0",
+		"certificate_identity_regexp": "^https://github.com/user/",
+		"certificate_oidc_issuer":     "https://token.actions.githubusercontent.com",
+	}
+	result, err := (&Server{}).containerVerify(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("container_verify failed: %v %v", err, result)
+	}
+	var verification map[string]interface{}
+	if err := json.Unmarshal([]byte(toolResultText(result)), &verification); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if verification["status"] != "verified" || verification["digest"] != "sha256:abc123" {
+		t.Errorf("unexpected result %v", verification)
+	}
+	if verification["identity"] != "https://github.com/user/app/.github/workflows/release.yml@refs/heads/main" || verification["issuer"] != "https://token.actions.githubusercontent.com" {
+		t.Errorf("expected the signer identity and issuer, got %v", verification)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"image_name": "quay.io/user/app:v1.0", "certificate_identity": "user@example.com"}
+	t.Setenv("COSIGN_PUBLIC_KEY", "")
+	if result, _ := (&Server{}).containerVerify(context.Background(), request); !result.IsError {
+		t.Error("expected an error without a key or OIDC issuer")
+	}
+}
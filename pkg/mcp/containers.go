@@ -10,6 +10,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 )
 
@@ -24,20 +25,36 @@ type ContainerBuildConfig struct {
 	Tags          []string `json:"tags"`         // Image tags
 	BuildArgs     map[string]string `json:"build_args"` // Build arguments
 	Platform      string `json:"platform"`      // Target platform
+	Platforms     []string `json:"platforms"`   // Target platforms of a multi-arch manifest list
+	PersistLogs   bool   `json:"persist_logs"`  // Store the full build log in the configured sink
 }
 
 // ContainerImageInfo represents information about a built container image
 type ContainerImageInfo struct {
 	ImageName     string            `json:"image_name"`
+	ID            string            `json:"id,omitempty"`
 	Tags          []string          `json:"tags"`
 	Size          string            `json:"size"`
+	SizeBytes     int64             `json:"size_bytes,omitempty"`
 	CreatedAt     time.Time         `json:"created_at"`
 	Registry      string            `json:"registry"`
 	Digest        string            `json:"digest"`
+	Architecture  string            `json:"architecture,omitempty"`
+	OS            string            `json:"os,omitempty"`
 	Labels        map[string]string `json:"labels"`
 	BuildDuration string            `json:"build_duration"`
 }
 
+// ContainerInfo represents a container listed by container_ps
+type ContainerInfo struct {
+	ID      string `json:"id"`
+	Image   string `json:"image"`
+	Command string `json:"command"`
+	Status  string `json:"status"`
+	Ports   string `json:"ports"`
+	Names   string `json:"names"`
+}
+
 // initContainers initializes container-related MCP tools
 func (s *Server) initContainers() []server.ServerTool {
 	klog.V(1).Info("Initializing container build and registry tools")
@@ -53,6 +70,7 @@ func (s *Server) initContainers() []server.ServerTool {
 			mcp.WithString("registry", mcp.Description("Target container registry. Examples: 'quay.io', 'docker.io', 'ghcr.io'.")),
 			mcp.WithString("tags", mcp.Description("Comma-separated list of additional tags. Example: 'latest,v1.0,staging'.")),
 			mcp.WithString("platform", mcp.Description("Target platform. Examples: 'linux/amd64', 'linux/arm64'. Defaults to current platform.")),
+			mcp.WithString("platforms", mcp.Description("Comma-separated target platforms to build a multi-arch manifest list for, instead of 'platform'. Example: 'linux/amd64,linux/arm64'. Requires docker buildx or podman manifest support. The manifest list is pushed to the registry of image_name as part of the build.")),
 			mcp.WithString("build_args", mcp.Description("Build arguments as JSON string. Example: '{\"ENV\":\"production\",\"VERSION\":\"1.0\"}'.")),
 			mcp.WithString("git_branch", mcp.Description("Git branch to checkout (only for Git sources). Defaults to 'main'.")),
 			mcp.WithString("git_commit", mcp.Description("Specific Git commit hash to checkout (only for Git sources).")),
@@ -61,6 +79,9 @@ func (s *Server) initContainers() []server.ServerTool {
 			mcp.WithBoolean("validate_ubi", mcp.Description("Validate Red Hat UBI compliance and suggest alternatives. Defaults to true.")),
 			mcp.WithBoolean("generate_ubi_dockerfile", mcp.Description("Generate UBI-compliant Dockerfile if current base image is not UBI. Defaults to false.")),
 			mcp.WithBoolean("security_scan", mcp.Description("Perform security validation on Dockerfile. Defaults to true.")),
+			mcp.WithBoolean("persist_logs", mcp.Description("Store the full build log in the configured build log sink (a PVC directory or S3-compatible bucket) and return a reference to it. The response then only includes the last lines of output. Defaults to false.")),
+			mcp.WithBoolean("generate_sbom", mcp.Description("Generate an SBOM of the built image with syft (or the docker sbom plugin). It is stored in the build log sink when one is configured, and returned inline otherwise. Defaults to false.")),
+			mcp.WithString("sbom_format", mcp.Description("SBOM format: 'spdx-json' (default) or 'cyclonedx-json'.")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("Container: Build Image with UBI Validation"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -71,7 +92,7 @@ func (s *Server) initContainers() []server.ServerTool {
 		{Tool: mcp.NewTool("container_push",
 			mcp.WithDescription("Push a container image to a registry. Supports authentication via environment variables or registry login. Can push single or multiple tags simultaneously. Provides detailed push progress and error handling."),
 			mcp.WithString("image_name", mcp.Description("Container image name to push. Should include registry and tag. Examples: 'quay.io/user/app:latest', 'docker.io/company/product:v1.0', 'ghcr.io/org/service:dev'."), mcp.Required()),
-			mcp.WithString("registry", mcp.Description("Target container registry. Will be extracted from image_name if not provided. Examples: 'quay.io', 'docker.io', 'ghcr.io', 'localhost:5000'.")),
+			mcp.WithString("registry", mcp.Description("Target container registry. Will be extracted from image_name if not provided; images without a registry host are tagged for and pushed to the default registry set with 'registry_configure'. Examples: 'quay.io', 'docker.io', 'ghcr.io', 'localhost:5000'.")),
 			mcp.WithString("username", mcp.Description("Registry username for authentication. Can also be provided via REGISTRY_USERNAME environment variable.")),
 			mcp.WithString("password", mcp.Description("Registry password/token for authentication. Can also be provided via REGISTRY_PASSWORD environment variable. For security, prefer environment variables.")),
 			mcp.WithString("additional_tags", mcp.Description("Comma-separated list of additional tags to push. Example: 'latest,v1.0,stable'. Each tag will be pushed separately.")),
@@ -97,6 +118,18 @@ func (s *Server) initContainers() []server.ServerTool {
 			mcp.WithOpenWorldHintAnnotation(true),
 		), Handler: s.containerList},
 
+		{Tool: mcp.NewTool("container_ps",
+			mcp.WithDescription("List containers started with container_run or by the local runtime, with their ID, image, command, status, ports and names. Only running containers are listed unless 'all' is set."),
+			mcp.WithBoolean("all", mcp.Description("Include stopped and created containers. Defaults to false (running containers only).")),
+			mcp.WithString("filter", mcp.Description("Filter containers by name or status. Examples: 'my-app', 'exited', 'status=paused', 'name=web'. A known status filters by status, anything else by name.")),
+			mcp.WithString("format", mcp.Description("Output format: 'table' (default) or 'json'. Table format is human-readable, JSON for programmatic use.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: List Containers"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.containerPS},
+
 		{Tool: mcp.NewTool("container_remove",
 			mcp.WithDescription("Remove local container images to free up disk space. Can remove by name, tag, or image ID. Supports bulk removal with patterns."),
 			mcp.WithString("image_name", mcp.Description("Container image name or ID to remove. Examples: 'my-app:latest', 'quay.io/user/app:v1.0', 'sha256:abc123...'. Can use partial IDs."), mcp.Required()),
@@ -120,6 +153,60 @@ func (s *Server) initContainers() []server.ServerTool {
 			mcp.WithOpenWorldHintAnnotation(true),
 		), Handler: s.containerInspect},
 
+		{Tool: mcp.NewTool("container_scan",
+			mcp.WithDescription("Scan a container image for known vulnerabilities with Trivy, which must be installed. Findings are grouped by severity (CRITICAL, HIGH, MEDIUM, LOW) with CVE IDs, affected packages and fixed versions. With a severity_threshold the tool fails when findings at or above it exist, so it can gate CI workflows."),
+			mcp.WithString("image_name", mcp.Description("Container image to scan, local or remote. Examples: 'my-app:latest', 'quay.io/user/app:v1.0'."), mcp.Required()),
+			mcp.WithString("severity_threshold", mcp.Description("Fail when findings of this severity or higher exist: 'CRITICAL', 'HIGH', 'MEDIUM' or 'LOW'. Defaults to never failing on findings.")),
+			mcp.WithString("source", mcp.Description("Where the image is read from: 'auto' (default, local images first), 'local' (the container runtime's storage) or 'remote' (the registry).")),
+			mcp.WithBoolean("ignore_unfixed", mcp.Description("Only report vulnerabilities with a fixed version available. Defaults to false.")),
+			mcp.WithString("timeout", mcp.Description("Time the scan may take, including the vulnerability database download. Examples: '5m', '30m'. Defaults to '10m'.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: Scan Image for Vulnerabilities"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.containerScan},
+
+		{Tool: mcp.NewTool("container_sign",
+			mcp.WithDescription("Sign a pushed container image with cosign, which must be installed. Signs with a private key, or keyless through Sigstore OIDC when no key is given. The image digest is signed, not the tag. Returns the signature digest and the transparency log entry. Check the signature with container_verify."),
+			mcp.WithString("image_name", mcp.Description("Pushed image to sign. Examples: 'quay.io/user/app:v1.0', 'quay.io/user/app@sha256:abc123...'."), mcp.Required()),
+			mcp.WithString("key", mcp.Description("Private key reference passed to cosign: a file path, 'env://VAR', 'k8s://namespace/secret' or a KMS URI. Defaults to the COSIGN_KEY environment variable, then keyless signing. The key password is read from COSIGN_PASSWORD.")),
+			mcp.WithString("identity_token", mcp.Description("OIDC identity token for keyless signing, e.g. from a CI provider. Without it cosign starts an interactive OIDC flow.")),
+			mcp.WithBoolean("tlog_upload", mcp.Description("Record the signature in the Rekor transparency log. Defaults to true.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: Sign Image"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.containerSign},
+
+		{Tool: mcp.NewTool("container_verify",
+			mcp.WithDescription("Verify the cosign signatures of a container image against a public key, or for keyless signatures against a signer identity and OIDC issuer. Requires cosign to be installed. Returns the verified digest and signer identity and issuer. Use verify_image to check the provenance attestations as well."),
+			mcp.WithString("image_name", mcp.Description("Image to verify. Examples: 'quay.io/user/app:v1.0', 'quay.io/user/app@sha256:abc123...'."), mcp.Required()),
+			mcp.WithString("public_key", mcp.Description("Public key reference passed to cosign: a file path, 'env://VAR', 'k8s://namespace/secret' or a KMS URI. Defaults to the COSIGN_PUBLIC_KEY environment variable.")),
+			mcp.WithString("certificate_identity", mcp.Description("Expected signer identity of a keyless signature, e.g. an email or a CI workflow URL. Requires certificate_oidc_issuer.")),
+			mcp.WithString("certificate_identity_regexp", mcp.Description("Regular expression the signer identity must match, instead of certificate_identity.")),
+			mcp.WithString("certificate_oidc_issuer", mcp.Description("Expected OIDC issuer of a keyless signature. Examples: 'https://accounts.google.com', 'https://token.actions.githubusercontent.com'.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: Verify Image Signature"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.containerVerify},
+
+		{Tool: mcp.NewTool("container_sbom",
+			mcp.WithDescription("Generate a software bill of materials (SBOM) of a container image as an SPDX or CycloneDX document, using syft or the docker sbom plugin. The SBOM is stored in the build log sink when one is configured and can be attached to the pushed image with cosign or oras."),
+			mcp.WithString("image_name", mcp.Description("Image to describe, local or remote. Examples: 'my-app:latest', 'quay.io/user/app:v1.0'."), mcp.Required()),
+			mcp.WithString("format", mcp.Description("SBOM format: 'spdx-json' (default) or 'cyclonedx-json'.")),
+			mcp.WithString("source", mcp.Description("Where the image is read from: 'auto' (default), 'local' (the container runtime's storage) or 'remote' (the registry).")),
+			mcp.WithString("attach", mcp.Description("Attach the SBOM to the image in its registry with 'cosign' or 'oras'. The image must be pushed. Defaults to not attaching.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: Generate Image SBOM"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.containerSBOM},
+
 		{Tool: mcp.NewTool("container_pull",
 			mcp.WithDescription("Pull a container image from a registry to local storage. Supports authentication via environment variables or registry login. Can pull from Docker Hub, Quay.io, or private registries."),
 			mcp.WithString("image_name", mcp.Description("Container image name to pull. Examples: 'nginx:latest', 'quay.io/user/app:v1.0', 'docker.io/library/redis:alpine'. Registry will be auto-detected or default to docker.io."), mcp.Required()),
@@ -129,6 +216,8 @@ func (s *Server) initContainers() []server.ServerTool {
 			mcp.WithString("platform", mcp.Description("Target platform for multi-arch images. Examples: 'linux/amd64', 'linux/arm64'. Defaults to current platform.")),
 			mcp.WithBoolean("skip_tls_verify", mcp.Description("Skip TLS certificate verification. Only use for private registries with self-signed certificates. Defaults to false.")),
 			mcp.WithBoolean("all_tags", mcp.Description("Pull all tags of the image. Defaults to false (pull only specified tag).")),
+			mcp.WithString("max_size", mcp.Description("Refuse to pull if the image's total compressed size, read from the remote manifest, exceeds this limit. Examples: '500Mi', '2Gi', '1G'. Not supported together with 'all_tags'.")),
+			mcp.WithBoolean("no_cache", mcp.Description("Bypass the registry lookup cache when reading the remote manifest for 'max_size'. Defaults to false.")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("Container: Pull Image from Registry"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -190,6 +279,48 @@ func (s *Server) initContainers() []server.ServerTool {
 			mcp.WithDestructiveHintAnnotation(false),
 			mcp.WithOpenWorldHintAnnotation(true),
 		), Handler: s.containerStop},
+
+		{Tool: mcp.NewTool("container_exec",
+			mcp.WithDescription("Run a command inside a running container, like 'podman exec' or 'docker exec', and return its combined stdout/stderr and exit code. Fails if the container is not running."),
+			mcp.WithString("container_name", mcp.Description("Container name or ID to run the command in. Examples: 'my-app', 'wonderful_turing'. Can use partial IDs."), mcp.Required()),
+			mcp.WithString("command", mcp.Description("Command to run, quoted arguments are kept together. Examples: 'ls -la /app', 'sh -c \"env | sort\"'."), mcp.Required()),
+			mcp.WithString("user", mcp.Description("Run the command as a specific user. Format: 'uid:gid' or 'username'. Defaults to the container's user.")),
+			mcp.WithString("working_dir", mcp.Description("Working directory for the command inside the container. Example: '/app'.")),
+			mcp.WithBoolean("interactive", mcp.Description("Keep STDIN open for the command. Defaults to false.")),
+			mcp.WithString("timeout", mcp.Description("Time the command may run before it is killed. Examples: '30s', '2m', '10'. Defaults to '30s'.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: Exec Command in Container"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.containerExec},
+
+		{Tool: mcp.NewTool("dockerfile_optimize",
+			mcp.WithDescription("Analyze a local source directory before building: suggest (or write) a .dockerignore excluding version control, dependencies, build artifacts and caches, report the estimated build context size reduction, and suggest multi-stage and layer caching improvements for the Dockerfile."),
+			mcp.WithString("source_path", mcp.Description("Local source directory used as build context. Example: './my-app', '/home/user/projects/api'."), mcp.Required()),
+			mcp.WithString("dockerfile", mcp.Description("Path to Dockerfile relative to source_path. Defaults to 'Dockerfile'.")),
+			mcp.WithBoolean("write", mcp.Description("Append the suggested entries to the .dockerignore in source_path, creating it if needed. Defaults to false (suggest only).")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: Optimize Build Context"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.dockerfileOptimize},
+
+		{Tool: mcp.NewTool("validate_dockerfile",
+			mcp.WithDescription("Lint a Dockerfile without building it: check every stage for Red Hat UBI base images (the verdict is the one of the runtime stage) and for security issues such as running as root or secrets in the image. The Dockerfile is given by exactly one of dockerfile_path, content or git_url."),
+			mcp.WithString("dockerfile_path", mcp.Description("Local Dockerfile, or directory containing it. Example: './my-app/Dockerfile', './my-app'.")),
+			mcp.WithString("content", mcp.Description("Inline Dockerfile content to validate.")),
+			mcp.WithString("git_url", mcp.Description("Git repository URL to clone the Dockerfile from. Example: 'https://github.com/user/repo.git'.")),
+			mcp.WithString("git_branch", mcp.Description("Git branch to checkout (only with git_url). Defaults to 'main'.")),
+			mcp.WithString("dockerfile", mcp.Description("Path to Dockerfile relative to the repository root or dockerfile_path directory. Defaults to 'Dockerfile'.")),
+			mcp.WithBoolean("fix", mcp.Description("Also return the Dockerfile content with the non-UBI stages rewritten to their suggested UBI base image. Defaults to false.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Container: Validate Dockerfile"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.validateDockerfile},
 	}
 }
 
@@ -217,6 +348,7 @@ func (s *Server) containerBuild(ctx context.Context, request mcp.CallToolRequest
 	buildContext := getStringArg(args, "build_context", ".")
 	registry := getStringArg(args, "registry", "")
 	platform := getStringArg(args, "platform", "")
+	platformsStr := getStringArg(args, "platforms", "")
 	gitBranch := getStringArg(args, "git_branch", "main")
 	gitCommit := getStringArg(args, "git_commit", "")
 	tagsStr := getStringArg(args, "tags", "")
@@ -226,6 +358,12 @@ func (s *Server) containerBuild(ctx context.Context, request mcp.CallToolRequest
 	validateUBI := getBoolArg(args, "validate_ubi", true)
 	generateUBIDockerfile := getBoolArg(args, "generate_ubi_dockerfile", false)
 	securityScan := getBoolArg(args, "security_scan", true)
+	persistLogs := getBoolArg(args, "persist_logs", false)
+	withSBOM := getBoolArg(args, "generate_sbom", false)
+	sbomOutputFormat, err := lookupSBOMFormat(getStringArg(args, "sbom_format", ""))
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
 
 	// Parse additional tags
 	var additionalTags []string
@@ -236,6 +374,18 @@ func (s *Server) containerBuild(ctx context.Context, request mcp.CallToolRequest
 		}
 	}
 
+	var platforms []string
+	if platformsStr != "" {
+		if platform != "" {
+			return NewTextResult("", fmt.Errorf("set either platform or platforms, not both")), nil
+		}
+		for _, p := range strings.Split(platformsStr, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				platforms = append(platforms, p)
+			}
+		}
+	}
+
 	// Parse build args
 	buildArgs := make(map[string]string)
 	if buildArgsStr != "{}" {
@@ -257,12 +407,30 @@ func (s *Server) containerBuild(ctx context.Context, request mcp.CallToolRequest
 		Tags:         additionalTags,
 		BuildArgs:    buildArgs,
 		Platform:     platform,
+		Platforms:    platforms,
+		PersistLogs:  persistLogs,
 	}, gitBranch, gitCommit, noCache, pull, validateUBI, generateUBIDockerfile, securityScan)
 
 	if err != nil {
 		return NewTextResult("", fmt.Errorf("container build failed: %v", err)), nil
 	}
 
+	// A multi-arch build leaves no local image, its SBOM is read from the registry
+	if withSBOM {
+		source := "local"
+		if len(platforms) > 0 {
+			source = "remote"
+		}
+		if sbom, err := s.imageSBOM(ctx, imageName, sbomOutputFormat, source, ""); err != nil {
+			buildResult["sbom_error"] = err.Error()
+		} else {
+			if sbom["stored_at"] != nil {
+				delete(sbom, "document")
+			}
+			buildResult["sbom"] = sbom
+		}
+	}
+
 	jsonResult, _ := json.MarshalIndent(buildResult, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
 }
@@ -280,8 +448,23 @@ func (s *Server) containerPush(ctx context.Context, request mcp.CallToolRequest)
 	}
 
 	registry := getStringArg(args, "registry", extractRegistryFromImage(imageName))
+	// Images without a registry host go to the default registry set with registry_configure
+	if _, explicit := args["registry"]; !explicit && !imageHasRegistry(imageName) {
+		if defaultRegistry, ok := defaultPushRegistry(); ok {
+			target := defaultRegistry + "/" + imageName
+			containerRuntime, err := detectContainerRuntime()
+			if err != nil {
+				return NewTextResult("", fmt.Errorf("container push failed: no container runtime found: %v", err)), nil
+			}
+			if err := s.tagImage(ctx, containerRuntime, imageName, target); err != nil {
+				return NewTextResult("", fmt.Errorf("failed to tag %s for the default registry %s: %v", imageName, defaultRegistry, err)), nil
+			}
+			imageName, registry = target, defaultRegistry
+		}
+	}
 	username := getStringArg(args, "username", os.Getenv("REGISTRY_USERNAME"))
 	password := getStringArg(args, "password", os.Getenv("REGISTRY_PASSWORD"))
+	username, password = registryCredentials(registry, username, password)
 	additionalTagsStr := getStringArg(args, "additional_tags", "")
 	allTags := getBoolArg(args, "all_tags", false)
 	skipTLSVerify := getBoolArg(args, "skip_tls_verify", false)
@@ -332,6 +515,32 @@ func (s *Server) containerList(ctx context.Context, request mcp.CallToolRequest)
 	return NewTextResult(listResult.(string), nil), nil
 }
 
+// containerPS handles listing containers
+func (s *Server) containerPS(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		args = make(map[string]interface{})
+	}
+
+	all := getBoolArg(args, "all", false)
+	filter := getStringArg(args, "filter", "")
+	format := getStringArg(args, "format", "table")
+
+	klog.V(2).Infof("Listing containers: all=%t, filter=%s", all, filter)
+
+	psResult, err := s.performContainerPS(ctx, all, filter, format)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("container ps failed: %v", err)), nil
+	}
+
+	if format == "json" {
+		jsonResult, _ := json.MarshalIndent(psResult, "", "  ")
+		return NewTextResult(string(jsonResult), nil), nil
+	}
+
+	return NewTextResult(psResult.(string), nil), nil
+}
+
 // containerRemove handles removing local container images
 func (s *Server) containerRemove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args, ok := request.Params.Arguments.(map[string]interface{})
@@ -398,13 +607,27 @@ func (s *Server) containerPull(ctx context.Context, request mcp.CallToolRequest)
 	registry := getStringArg(args, "registry", extractRegistryFromImage(imageName))
 	username := getStringArg(args, "username", os.Getenv("REGISTRY_USERNAME"))
 	password := getStringArg(args, "password", os.Getenv("REGISTRY_PASSWORD"))
+	username, password = registryCredentials(registry, username, password)
 	platform := getStringArg(args, "platform", "")
 	skipTLSVerify := getBoolArg(args, "skip_tls_verify", false)
 	allTags := getBoolArg(args, "all_tags", false)
 
+	var maxSize int64
+	if value := getStringArg(args, "max_size", ""); value != "" {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil || quantity.Value() <= 0 {
+			return NewTextResult("", fmt.Errorf("invalid max_size '%s', expected a positive size such as '500Mi' or '2Gi'", value)), nil
+		}
+		if allTags {
+			return NewTextResult("", fmt.Errorf("max_size cannot be combined with all_tags")), nil
+		}
+		maxSize = quantity.Value()
+	}
+
 	klog.V(2).Infof("Pulling container image: %s from registry: %s", imageName, registry)
 
-	pullResult, err := s.performContainerPull(ctx, imageName, registry, username, password, platform, skipTLSVerify, allTags)
+	noCache := getBoolArg(args, "no_cache", false)
+	pullResult, err := s.performContainerPull(ctx, imageName, registry, username, password, platform, skipTLSVerify, allTags, maxSize, noCache)
 	if err != nil {
 		return NewTextResult("", fmt.Errorf("container pull failed: %v", err)), nil
 	}
@@ -502,6 +725,38 @@ func (s *Server) containerStop(ctx context.Context, request mcp.CallToolRequest)
 	return NewTextResult(string(jsonResult), nil), nil
 }
 
+// containerExec handles running commands inside running containers
+func (s *Server) containerExec(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	containerName, ok := args["container_name"].(string)
+	if !ok || containerName == "" {
+		return NewTextResult("", fmt.Errorf("container_name parameter is required")), nil
+	}
+	command, ok := args["command"].(string)
+	if !ok || strings.TrimSpace(command) == "" {
+		return NewTextResult("", fmt.Errorf("command parameter is required")), nil
+	}
+
+	user := getStringArg(args, "user", "")
+	workingDir := getStringArg(args, "working_dir", "")
+	interactive := getBoolArg(args, "interactive", false)
+	timeout := getStringArg(args, "timeout", "30s")
+
+	klog.V(2).Infof("Executing command in container: %s", containerName)
+
+	execResult, err := s.performContainerExec(ctx, containerName, command, user, workingDir, timeout, interactive)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("container exec failed: %v", err)), nil
+	}
+
+	jsonResult, _ := json.MarshalIndent(execResult, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
 // Helper functions
 
 func detectSourceType(source string) string {
@@ -517,6 +772,12 @@ func detectSourceType(source string) string {
 	return "local"
 }
 
+// imageHasRegistry reports whether an image reference starts with a registry host
+func imageHasRegistry(imageName string) bool {
+	first, _, found := strings.Cut(imageName, "/")
+	return found && (strings.ContainsAny(first, ".:") || first == "localhost")
+}
+
 func extractRegistryFromImage(imageName string) string {
 	parts := strings.Split(imageName, "/")
 	if len(parts) > 1 && strings.Contains(parts[0], ".") {
@@ -532,6 +793,48 @@ func getStringArg(args map[string]interface{}, key, defaultValue string) string
 	return defaultValue
 }
 
+// getStringSliceArg returns an optional array-of-strings argument, rejecting any other type
+func getStringSliceArg(args map[string]interface{}, key string) ([]string, error) {
+	value, exists := args[key]
+	if !exists || value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", key)
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings, got %v", key, item)
+		}
+		result = append(result, str)
+	}
+	return result, nil
+}
+
+// getStringMapArg returns an optional object argument of string values, rejecting any other type
+func getStringMapArg(args map[string]interface{}, key string) (map[string]string, error) {
+	value, exists := args[key]
+	if !exists || value == nil {
+		return nil, nil
+	}
+	items, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object of string values", key)
+	}
+	result := make(map[string]string, len(items))
+	for name, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an object of string values, got %v for %s", key, item, name)
+		}
+		result[name] = str
+	}
+	return result, nil
+}
+
 func getBoolArg(args map[string]interface{}, key string, defaultValue bool) bool {
 	if val, ok := args[key].(bool); ok {
 		return val
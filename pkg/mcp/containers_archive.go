@@ -0,0 +1,255 @@
+package mcp
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxArchiveSize bounds the size of a downloaded source archive
+const maxArchiveSize = 1 << 30
+
+// downloadAndExtractArchive downloads a tar, tar.gz/tgz or zip source archive into a temporary
+// directory and extracts it. An archive holding a single top-level directory, as produced by
+// GitHub and GitLab, is flattened so paths resolve from the source root.
+func (s *Server) downloadAndExtractArchive(ctx context.Context, archiveURL string) (string, error) {
+	tempDir, err := os.MkdirTemp("", "mcp-build-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	if err := downloadArchive(ctx, archiveURL, tempDir); err != nil {
+		_ = os.RemoveAll(tempDir)
+		return "", err
+	}
+	return tempDir, nil
+}
+
+func downloadArchive(ctx context.Context, archiveURL, dir string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid archive URL: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download archive: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download archive: %s", resp.Status)
+	}
+
+	// Zip readers need random access, so the archive is stored before it is extracted
+	archivePath := filepath.Join(dir, ".mcp-archive")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to download archive: %v", err)
+	}
+	defer func() { _ = os.Remove(archivePath) }()
+	written, err := io.Copy(file, io.LimitReader(resp.Body, maxArchiveSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download archive: %v", err)
+	}
+	if written > maxArchiveSize {
+		return fmt.Errorf("archive is larger than %d bytes", int64(maxArchiveSize))
+	}
+
+	format, err := archiveFormat(archiveURL, resp.Header.Get("Content-Type"), archivePath)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "zip":
+		err = extractZip(archivePath, dir)
+	default:
+		err = extractTar(archivePath, dir, format == "tar.gz")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract archive: %v", err)
+	}
+	_ = os.Remove(archivePath)
+	return flattenSingleDirectory(dir)
+}
+
+// archiveFormat detects a tar.gz, tar or zip archive from the URL extension, the content type
+// and, when both are inconclusive, the leading bytes of the archive
+func archiveFormat(archiveURL, contentType, archivePath string) (string, error) {
+	urlPath := strings.ToLower(archiveURL)
+	if before, _, found := strings.Cut(urlPath, "?"); found {
+		urlPath = before
+	}
+	switch {
+	case strings.HasSuffix(urlPath, ".tar.gz") || strings.HasSuffix(urlPath, ".tgz"):
+		return "tar.gz", nil
+	case strings.HasSuffix(urlPath, ".tar"):
+		return "tar", nil
+	case strings.HasSuffix(urlPath, ".zip"):
+		return "zip", nil
+	}
+	switch strings.TrimSpace(strings.Split(contentType, ";")[0]) {
+	case "application/gzip", "application/x-gzip", "application/x-compressed-tar":
+		return "tar.gz", nil
+	case "application/x-tar":
+		return "tar", nil
+	case "application/zip", "application/x-zip-compressed":
+		return "zip", nil
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+	header, _ := bufio.NewReader(file).Peek(262)
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return "tar.gz", nil
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		return "zip", nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return "tar", nil
+	}
+	return "", fmt.Errorf("unsupported archive format (content type %q), use a .tar.gz, .tgz, .tar or .zip archive", contentType)
+}
+
+// archiveTarget resolves an archive entry name inside dir, rejecting entries escaping it
+func archiveTarget(dir, name string) (string, error) {
+	cleaned := path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	if cleaned == "/" {
+		return dir, nil
+	}
+	target := filepath.Join(dir, filepath.FromSlash(cleaned))
+	if !strings.HasPrefix(target, dir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %s is outside the archive root", name)
+	}
+	return target, nil
+}
+
+func extractTar(archivePath, dir string, gzipped bool) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	var reader io.Reader = file
+	if gzipped {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gzipReader.Close() }()
+		reader = gzipReader
+	}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveTarget(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(target, tarReader, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		}
+		// Links and special files are skipped, a build context does not need them
+	}
+}
+
+func extractZip(archivePath, dir string) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zipReader.Close() }()
+	for _, entry := range zipReader.File {
+		target, err := archiveTarget(dir, entry.Name)
+		if err != nil {
+			return err
+		}
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		content, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(target, content, entry.Mode())
+		_ = content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeArchiveFile(target string, content io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// flattenSingleDirectory moves the content of a lone top-level directory up into dir
+func flattenSingleDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil
+	}
+	// The directory is renamed first in case it contains an entry with its own name
+	top := filepath.Join(dir, ".mcp-flatten")
+	if err := os.Rename(filepath.Join(dir, entries[0].Name()), top); err != nil {
+		return err
+	}
+	children, err := os.ReadDir(top)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := os.Rename(filepath.Join(top, child.Name()), filepath.Join(dir, child.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(top)
+}
@@ -9,15 +9,23 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	goruntime "runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	units "github.com/docker/go-units"
 	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+	"github.com/sur309/openshift-mcp-server/pkg/metrics"
 )
 
 // performContainerBuildWithValidation executes container build with UBI and security validation
-func (s *Server) performContainerBuildWithValidation(ctx context.Context, config ContainerBuildConfig, gitBranch, gitCommit string, noCache, pull, validateUBI, generateUBIDockerfile, securityScan bool) (map[string]interface{}, error) {
+func (s *Server) performContainerBuildWithValidation(ctx context.Context, config ContainerBuildConfig, gitBranch, gitCommit string, noCache, pull, validateUBI, generateUBIDockerfile, securityScan bool) (_ map[string]interface{}, err error) {
 	startTime := time.Now()
+	done := metrics.StartOperation(metrics.OperationBuild)
+	defer func() { done(err == nil) }()
 	
 	// Detect container runtime (podman or docker)
 	containerRuntime, err := detectContainerRuntime()
@@ -26,6 +34,11 @@ func (s *Server) performContainerBuildWithValidation(ctx context.Context, config
 	}
 	
 	klog.V(1).Infof("Using container runtime: %s", containerRuntime)
+	if len(config.Platforms) > 0 {
+		if err := checkMultiArchSupport(ctx, containerRuntime); err != nil {
+			return nil, err
+		}
+	}
 
 	// Prepare build directory
 	buildDir, err := s.prepareBuildSource(ctx, config, gitBranch, gitCommit)
@@ -53,7 +66,7 @@ func (s *Server) performContainerBuildWithValidation(ctx context.Context, config
 		if ubiValidation, ok := validation["ubi_compliance"].(*UBIValidation); ok && !ubiValidation.IsUBI {
 			ubiDockerfilePath, err := s.generateUBIDockerfile(ctx, 
 				filepath.Join(buildDir, config.BuildContext, config.Dockerfile), 
-				ubiValidation.SuggestedUBIImage)
+				ubiValidation)
 			if err != nil {
 				klog.V(1).Infof("Failed to generate UBI Dockerfile: %v", err)
 			} else {
@@ -69,12 +82,31 @@ func (s *Server) performContainerBuildWithValidation(ctx context.Context, config
 
 	// Construct build command
 	buildCmd := s.constructBuildCommand(containerRuntime, config, buildDir, noCache, pull)
+	if len(config.Platforms) > 0 {
+		buildCmd = s.constructMultiArchBuildCommand(ctx, containerRuntime, config, buildDir, noCache, pull)
+	}
 	
 	klog.V(2).Infof("Executing build command: %s", strings.Join(buildCmd.Args, " "))
 
 	// Execute build with output capture
 	buildOutput, err := s.executeBuildCommand(ctx, buildCmd)
+	if err == nil && len(config.Platforms) > 0 && containerRuntime == "podman" {
+		var pushOutput string
+		pushOutput, err = pushManifestList(ctx, config)
+		buildOutput += pushOutput
+	}
+	var buildLogRef string
+	var buildLogErr error
+	if config.PersistLogs {
+		if buildLogRef, buildLogErr = s.persistBuildLog(ctx, config.ImageName, buildOutput); buildLogErr != nil {
+			klog.V(1).Infof("Warning: failed to persist build log: %v", buildLogErr)
+		}
+	}
 	if err != nil {
+		if buildLogRef != "" {
+			output, _ := tailLines(buildOutput, buildLogResponseLines)
+			return nil, fmt.Errorf("build failed: %v\nFull log: %s\nOutput: %s", err, buildLogRef, strings.Join(output, "\n"))
+		}
 		return nil, fmt.Errorf("build failed: %v\nOutput: %s", err, buildOutput)
 	}
 
@@ -98,6 +130,7 @@ func (s *Server) performContainerBuildWithValidation(ctx context.Context, config
 		"status":          "success",
 		"message":         fmt.Sprintf("Container image '%s' built successfully", config.ImageName),
 		"image_info":      imageInfo,
+		"full_image":      config.ImageName,
 		"build_duration":  buildDuration.String(),
 		"container_runtime": containerRuntime,
 		"build_output":    strings.Split(buildOutput, "\n"),
@@ -113,13 +146,36 @@ func (s *Server) performContainerBuildWithValidation(ctx context.Context, config
 		},
 	}
 
+	if config.PersistLogs {
+		if buildLogRef != "" {
+			output, truncated := tailLines(buildOutput, buildLogResponseLines)
+			result["build_output"] = output
+			result["build_output_truncated"] = truncated
+			result["build_log"] = buildLogRef
+		} else {
+			result["build_log_error"] = buildLogErr.Error()
+		}
+	}
+
+	if len(config.Platforms) > 0 {
+		result["platforms"] = config.Platforms
+		result["pushed"] = true
+		if digests, err := manifestDigests(ctx, containerRuntime, config.ImageName); err != nil {
+			result["platform_digests_error"] = err.Error()
+		} else {
+			result["platform_digests"] = digests
+		}
+	}
+
 	// Include validation results if performed
 	if validation != nil {
 		result["validation"] = validation
 		
 		// Add UBI-specific recommendations
 		if ubiValidation, ok := validation["ubi_compliance"].(*UBIValidation); ok {
-			if !ubiValidation.IsUBI {
+			if !ubiValidation.IsUBI && ubiValidation.SuggestedUBIImage == "" {
+				result["next_steps"] = append(result["next_steps"].([]string), ubiValidation.ValidationMessage)
+			} else if !ubiValidation.IsUBI {
 				result["next_steps"] = append(result["next_steps"].([]string), 
 					fmt.Sprintf("⚠️ Consider using Red Hat UBI base image: %s", ubiValidation.SuggestedUBIImage))
 				result["next_steps"] = append(result["next_steps"].([]string), 
@@ -135,12 +191,21 @@ func (s *Server) performContainerBuildWithValidation(ctx context.Context, config
 }
 
 // performContainerPush executes the actual container push process
-func (s *Server) performContainerPush(ctx context.Context, imageName, registry, username, password string, additionalTags []string, allTags, skipTLSVerify bool) (map[string]interface{}, error) {
+func (s *Server) performContainerPush(ctx context.Context, imageName, registry, username, password string, additionalTags []string, allTags, skipTLSVerify bool) (_ map[string]interface{}, err error) {
+	done := metrics.StartOperation(metrics.OperationPush)
+	defer func() { done(err == nil) }()
 	containerRuntime, err := detectContainerRuntime()
 	if err != nil {
 		return nil, fmt.Errorf("no container runtime found: %v", err)
 	}
 
+	// ECR only accepts short-lived tokens obtained with the AWS credentials
+	if password == "" && cicd.IsECRRegistry(registry) {
+		if username, password, err = cicd.ECRCredentials(ctx, registry); err != nil {
+			return nil, fmt.Errorf("registry authentication failed: %v", err)
+		}
+	}
+
 	// Authenticate if credentials provided
 	if username != "" && password != "" {
 		if err := s.authenticateRegistry(ctx, containerRuntime, registry, username, password); err != nil {
@@ -158,6 +223,7 @@ func (s *Server) performContainerPush(ctx context.Context, imageName, registry,
 	}
 	pushedImages = append(pushedImages, imageName)
 	pushResults = append(pushResults, pushResult)
+	s.registryCache.invalidate(imageName)
 
 	// Push additional tags
 	for _, tag := range additionalTags {
@@ -177,11 +243,13 @@ func (s *Server) performContainerPush(ctx context.Context, imageName, registry,
 		}
 		pushedImages = append(pushedImages, taggedImage)
 		pushResults = append(pushResults, pushResult)
+		s.registryCache.invalidate(taggedImage)
 	}
 
 	result := map[string]interface{}{
 		"status":             "success",
 		"message":            fmt.Sprintf("Successfully pushed %d image(s)", len(pushedImages)),
+		"full_image":         imageName,
 		"pushed_images":      pushedImages,
 		"push_results":       pushResults,
 		"registry":           registry,
@@ -211,7 +279,8 @@ func (s *Server) performContainerList(ctx context.Context, filter, registry stri
 	}
 
 	if format == "json" {
-		cmd.Args = append(cmd.Args, "--format", "json")
+		// The JSON of the images command differs between runtimes, image inspect does not
+		cmd.Args = append(cmd.Args, "--format", "{{.ID}}")
 	}
 
 	output, err := cmd.Output()
@@ -221,13 +290,23 @@ func (s *Server) performContainerList(ctx context.Context, filter, registry stri
 
 	if format == "json" {
 		// Parse and return structured data
-		images := []map[string]interface{}{}
-		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-		for _, line := range lines {
-			if line != "" {
-				imageInfo := parseImageLine(line)
-				if registry == "" || strings.Contains(imageInfo["repository"].(string), registry) {
-					images = append(images, imageInfo)
+		ids := []string{}
+		seen := make(map[string]bool)
+		for _, id := range strings.Fields(string(output)) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		images := []ContainerImageInfo{}
+		if len(ids) > 0 {
+			inspected, err := inspectImages(ctx, containerRuntime, ids...)
+			if err != nil {
+				return nil, err
+			}
+			for _, image := range inspected {
+				if registry == "" || strings.Contains(image.ImageName, registry) {
+					images = append(images, image)
 				}
 			}
 		}
@@ -248,6 +327,96 @@ func (s *Server) performContainerList(ctx context.Context, filter, registry stri
 	return result, nil
 }
 
+// containerStatuses are the states accepted by the status filter of both runtimes
+var containerStatuses = map[string]bool{
+	"created": true, "restarting": true, "running": true, "removing": true,
+	"paused": true, "exited": true, "dead": true,
+}
+
+// containerPSFormat prints the ps columns tab separated, the JSON of the ps command differs
+// between runtimes
+const containerPSFormat = "{{.ID}}\t{{.Image}}\t{{.Command}}\t{{.Status}}\t{{.Ports}}\t{{.Names}}"
+
+// performContainerPS executes container listing
+func (s *Server) performContainerPS(ctx context.Context, all bool, filter, format string) (interface{}, error) {
+	containerRuntime, err := detectContainerRuntime()
+	if err != nil {
+		return nil, fmt.Errorf("no container runtime found: %v", err)
+	}
+
+	cmd := exec.CommandContext(ctx, containerRuntime, "ps")
+
+	if all {
+		cmd.Args = append(cmd.Args, "--all")
+	}
+
+	if filter != "" {
+		cmd.Args = append(cmd.Args, "--filter", containerPSFilter(filter))
+	}
+
+	if format == "json" {
+		cmd.Args = append(cmd.Args, "--no-trunc", "--format", containerPSFormat)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %v, output: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	if format == "json" {
+		containers := parseContainerPS(string(output))
+		return map[string]interface{}{
+			"runtime":    containerRuntime,
+			"containers": containers,
+			"total":      len(containers),
+			"all":        all,
+			"filter":     filter,
+		}, nil
+	}
+
+	// Return formatted text output
+	result := fmt.Sprintf("Containers (using %s):\n", containerRuntime)
+	result += string(output)
+
+	return result, nil
+}
+
+// containerPSFilter turns a filter argument into a ps --filter, known statuses filter by status
+// and anything else by name
+func containerPSFilter(filter string) string {
+	if strings.Contains(filter, "=") {
+		return filter
+	}
+	if containerStatuses[strings.ToLower(filter)] {
+		return "status=" + strings.ToLower(filter)
+	}
+	return "name=" + filter
+}
+
+// parseContainerPS parses the lines printed with containerPSFormat
+func parseContainerPS(output string) []ContainerInfo {
+	containers := []ContainerInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 6)
+		if len(fields) < 6 {
+			klog.V(2).Infof("Skipping unexpected ps output line: %s", line)
+			continue
+		}
+		containers = append(containers, ContainerInfo{
+			ID:      fields[0],
+			Image:   fields[1],
+			Command: strings.Trim(fields[2], "\""),
+			Status:  fields[3],
+			Ports:   fields[4],
+			Names:   fields[5],
+		})
+	}
+	return containers
+}
+
 // performContainerRemove executes container image removal
 func (s *Server) performContainerRemove(ctx context.Context, imageName string, force, prune bool) (map[string]interface{}, error) {
 	containerRuntime, err := detectContainerRuntime()
@@ -394,6 +563,15 @@ func (s *Server) cloneGitRepository(ctx context.Context, repoURL, branch, commit
 		cloneCmd.Args = append(cloneCmd.Args, "--branch", branch)
 	}
 	cloneCmd.Args = append(cloneCmd.Args, repoURL, tempDir)
+	if key := sshKeyForURL(repoURL); key != nil {
+		sshCommand, cleanup, err := key.GitSSHCommand()
+		if err != nil {
+			os.RemoveAll(tempDir)
+			return "", err
+		}
+		defer cleanup()
+		cloneCmd.Env = append(os.Environ(), "GIT_SSH_COMMAND="+sshCommand)
+	}
 
 	if err := cloneCmd.Run(); err != nil {
 		os.RemoveAll(tempDir)
@@ -412,19 +590,13 @@ func (s *Server) cloneGitRepository(ctx context.Context, repoURL, branch, commit
 	return tempDir, nil
 }
 
-func (s *Server) downloadAndExtractArchive(ctx context.Context, archiveURL string) (string, error) {
-	tempDir, err := os.MkdirTemp("", "mcp-build-*")
+// resolveHeadCommit returns the full SHA of the commit checked out in a cloned repository
+func resolveHeadCommit(ctx context.Context, dir string) (string, error) {
+	output, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "HEAD").Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %v", err)
-	}
-
-	downloadCmd := exec.CommandContext(ctx, "curl", "-L", "-o", filepath.Join(tempDir, "archive"), archiveURL)
-	if err := downloadCmd.Run(); err != nil {
-		os.RemoveAll(tempDir)
-		return "", fmt.Errorf("failed to download archive: %v", err)
+		return "", fmt.Errorf("failed to resolve HEAD commit: %v", err)
 	}
-
-	return tempDir, nil
+	return strings.TrimSpace(string(output)), nil
 }
 
 func (s *Server) constructBuildCommand(runtime string, config ContainerBuildConfig, buildDir string, noCache, pull bool) *exec.Cmd {
@@ -518,18 +690,79 @@ func (s *Server) executeBuildCommand(ctx context.Context, cmd *exec.Cmd) (string
 }
 
 func (s *Server) getImageInfo(ctx context.Context, runtime, imageName string) (*ContainerImageInfo, error) {
-	cmd := exec.CommandContext(ctx, runtime, "inspect", imageName)
-	_, err := cmd.Output()
+	images, err := inspectImages(ctx, runtime, imageName)
 	if err != nil {
 		return nil, err
 	}
+	info := images[0]
+	info.ImageName = imageName
+	return &info, nil
+}
 
-	return &ContainerImageInfo{
-		ImageName: imageName,
-		Tags:      []string{extractTagFromImage(imageName)},
-		CreatedAt: time.Now(),
-		Size:      "unknown",
-	}, nil
+// inspectImages reads the size, creation time, digest, platform and labels of local images from
+// the runtime's inspect output, which Podman and Docker format alike apart from the digest and
+// labels fields
+func inspectImages(ctx context.Context, runtime string, images ...string) ([]ContainerImageInfo, error) {
+	output, err := exec.CommandContext(ctx, runtime, append([]string{"image", "inspect"}, images...)...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("failed to inspect images: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to inspect images: %v", err)
+	}
+	var inspected []struct {
+		ID           string            `json:"Id"`
+		Digest       string            `json:"Digest"`
+		RepoTags     []string          `json:"RepoTags"`
+		RepoDigests  []string          `json:"RepoDigests"`
+		Created      time.Time         `json:"Created"`
+		Size         int64             `json:"Size"`
+		Architecture string            `json:"Architecture"`
+		OS           string            `json:"Os"`
+		Labels       map[string]string `json:"Labels"`
+		Config       struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"Config"`
+	}
+	if err := json.Unmarshal(output, &inspected); err != nil {
+		return nil, fmt.Errorf("failed to parse image inspect output: %v", err)
+	}
+	if len(inspected) == 0 {
+		return nil, fmt.Errorf("no image found for %s", strings.Join(images, ", "))
+	}
+
+	infos := make([]ContainerImageInfo, 0, len(inspected))
+	for _, image := range inspected {
+		info := ContainerImageInfo{
+			ID:           image.ID,
+			Size:         units.HumanSize(float64(image.Size)),
+			SizeBytes:    image.Size,
+			CreatedAt:    image.Created,
+			Digest:       image.Digest,
+			Architecture: image.Architecture,
+			OS:           image.OS,
+			Labels:       image.Config.Labels,
+		}
+		if len(image.RepoTags) > 0 {
+			info.ImageName = image.RepoTags[0]
+			info.Registry = extractRegistryFromImage(image.RepoTags[0])
+		}
+		for _, repoTag := range image.RepoTags {
+			info.Tags = append(info.Tags, extractTagFromImage(repoTag))
+		}
+		// Docker only knows the digest of images pulled or pushed, as repo@digest
+		if info.Digest == "" && len(image.RepoDigests) > 0 {
+			_, info.Digest, _ = strings.Cut(image.RepoDigests[0], "@")
+		}
+		if info.Digest == "" {
+			info.Digest = image.ID
+		}
+		if len(info.Labels) == 0 {
+			info.Labels = image.Labels
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
 }
 
 func (s *Server) authenticateRegistry(ctx context.Context, runtime, registry, username, password string) error {
@@ -564,7 +797,7 @@ func (s *Server) pushSingleImage(ctx context.Context, runtime, imageName string,
 }
 
 // performContainerPull executes the actual container pull process
-func (s *Server) performContainerPull(ctx context.Context, imageName, registry, username, password, platform string, skipTLSVerify, allTags bool) (map[string]interface{}, error) {
+func (s *Server) performContainerPull(ctx context.Context, imageName, registry, username, password, platform string, skipTLSVerify, allTags bool, maxSize int64, noCache bool) (map[string]interface{}, error) {
 	startTime := time.Now()
 	
 	// Detect container runtime (podman or docker)
@@ -603,33 +836,70 @@ func (s *Server) performContainerPull(ctx context.Context, imageName, registry,
 	
 	// Handle authentication if provided
 	if username != "" && password != "" {
-		loginCmd := exec.CommandContext(ctx, containerRuntime, "login", "--username", username, "--password-stdin", registry)
-		loginCmd.Stdin = strings.NewReader(password)
-		if err := loginCmd.Run(); err != nil {
-			klog.V(1).Infof("Registry login failed: %v", err)
+		if err := s.authenticateRegistry(ctx, containerRuntime, registry, username, password); err != nil {
+			return nil, fmt.Errorf("authentication to registry %s failed: %v", registry, err)
+		}
+	}
+
+	// Enforce the size limit against the remote manifest before pulling anything
+	var imageSize int64
+	if maxSize > 0 {
+		imageSize, err = s.remoteImageSize(ctx, containerRuntime, imageName, platform, skipTLSVerify, noCache)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine size of %s before pulling: %v", imageName, err)
+		}
+		if imageSize > maxSize {
+			return nil, fmt.Errorf("image %s is %d bytes compressed, which exceeds max_size of %d bytes; refusing to pull", imageName, imageSize, maxSize)
 		}
+		klog.V(2).Infof("Image %s is %d bytes compressed, within max_size of %d bytes", imageName, imageSize, maxSize)
 	}
 	
 	// Execute pull command
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("pull failed: %v, output: %s", err, string(output))
+		return nil, fmt.Errorf("%s: %v, output: %s", classifyPullFailure(string(output), imageName), err, strings.TrimSpace(string(output)))
 	}
-	
+
 	duration := time.Since(startTime)
-	
-	// Parse output for pulled images
+
+	// Parse output for pulled images, the digest and the resolved reference
 	pulledImages := []string{}
+	digest, reference := "", ""
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "Trying to pull") || strings.Contains(line, "Getting image") {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "Trying to pull"):
+			// podman: "Trying to pull docker.io/library/nginx:latest..."
+			reference = strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(line, "Trying to pull")), "...")
 			pulledImages = append(pulledImages, line)
+		case strings.Contains(line, "Getting image"):
+			pulledImages = append(pulledImages, line)
+		case strings.HasPrefix(line, "Digest:"):
+			// docker: "Digest: sha256:..."
+			digest = strings.TrimSpace(strings.TrimPrefix(line, "Digest:"))
+		case line != "" && !strings.Contains(line, " ") && strings.Contains(line, "/"):
+			// docker prints the fully qualified reference last
+			reference = line
 		}
 	}
-	
+	// podman does not print the digest, read it from the pulled image
+	if digest == "" && !allTags {
+		if repoDigest, err := exec.CommandContext(ctx, containerRuntime, "image", "inspect", "--format", "{{index .RepoDigests 0}}", imageName).Output(); err == nil {
+			if _, d, found := strings.Cut(strings.TrimSpace(string(repoDigest)), "@"); found {
+				digest = d
+			}
+		}
+	}
+	if reference == "" {
+		reference = imageName
+	}
+
 	result := map[string]interface{}{
+		"runtime":         containerRuntime,
 		"image_name":      imageName,
+		"image_reference": reference,
+		"digest":          digest,
 		"registry":        registry,
 		"platform":        platform,
 		"all_tags":        allTags,
@@ -639,10 +909,138 @@ func (s *Server) performContainerPull(ctx context.Context, imageName, registry,
 		"status":          "success",
 		"timestamp":       time.Now().Format(time.RFC3339),
 	}
+	if maxSize > 0 {
+		result["compressed_size"] = imageSize
+		result["max_size"] = maxSize
+	}
 	
 	return result, nil
 }
 
+// classifyPullFailure describes why a pull failed from the runtime's output, separating missing images,
+// missing credentials and network problems
+func classifyPullFailure(output, imageName string) string {
+	output = strings.ToLower(output)
+	switch {
+	case containsAny(output, "unauthorized", "authentication required", "access denied", "denied:"):
+		return fmt.Sprintf("authentication required to pull %s, provide username and password", imageName)
+	case containsAny(output, "manifest unknown", "not found", "name unknown", "does not exist", "no such image"):
+		return fmt.Sprintf("image %s not found, check the repository name and tag", imageName)
+	case containsAny(output, "dial tcp", "no such host", "connection refused", "i/o timeout", "tls handshake", "x509", "network is unreachable", "connection reset"):
+		return fmt.Sprintf("network error while pulling %s, check registry connectivity or use skip_tls_verify for self-signed registries", imageName)
+	}
+	return "pull failed"
+}
+
+func containsAny(s string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteManifest is the subset of an OCI/Docker image manifest or index used to compute image size
+type remoteManifest struct {
+	Config struct {
+		Size int64 `json:"size"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// remoteImageSize returns the total compressed size of an image from its registry manifest without pulling it.
+// For multi-arch images the manifest matching platform (or linux on the host architecture) is used.
+func (s *Server) remoteImageSize(ctx context.Context, containerRuntime, imageName, platform string, skipTLSVerify, noCache bool) (int64, error) {
+	manifest, err := s.inspectRemoteManifest(ctx, containerRuntime, imageName, skipTLSVerify, noCache)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(manifest.Manifests) > 0 {
+		if platform == "" {
+			platform = "linux/" + goruntime.GOARCH
+		}
+		digest := ""
+		for _, m := range manifest.Manifests {
+			candidate := m.Platform.OS + "/" + m.Platform.Architecture
+			if candidate == platform || (m.Platform.Variant != "" && candidate+"/"+m.Platform.Variant == platform) {
+				digest = m.Digest
+				break
+			}
+		}
+		if digest == "" {
+			return 0, fmt.Errorf("no manifest for platform %s in image index", platform)
+		}
+		manifest, err = s.inspectRemoteManifest(ctx, containerRuntime, imageRepository(imageName)+"@"+digest, skipTLSVerify, noCache)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	size := manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size, nil
+}
+
+// inspectRemoteManifest fetches an image manifest from its registry, reusing cached lookups unless noCache is set
+func (s *Server) inspectRemoteManifest(ctx context.Context, containerRuntime, imageName string, skipTLSVerify, noCache bool) (*remoteManifest, error) {
+	manifest, err := s.registryCache.lookup(registryCacheKey("manifest", imageName), noCache, func() (interface{}, error) {
+		return fetchRemoteManifest(ctx, containerRuntime, imageName, skipTLSVerify)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest.(*remoteManifest), nil
+}
+
+func fetchRemoteManifest(ctx context.Context, containerRuntime, imageName string, skipTLSVerify bool) (*remoteManifest, error) {
+	args := []string{"manifest", "inspect"}
+	if skipTLSVerify {
+		if containerRuntime == "podman" {
+			args = append(args, "--tls-verify=false")
+		} else {
+			args = append(args, "--insecure")
+		}
+	}
+	args = append(args, imageName)
+	output, err := exec.CommandContext(ctx, containerRuntime, args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("manifest inspect failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("manifest inspect failed: %v", err)
+	}
+	manifest := &remoteManifest{}
+	if err := json.Unmarshal(output, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+// imageRepository strips the tag or digest from an image reference
+func imageRepository(imageName string) string {
+	if idx := strings.Index(imageName, "@"); idx >= 0 {
+		imageName = imageName[:idx]
+	}
+	if idx := strings.LastIndex(imageName, ":"); idx > strings.LastIndex(imageName, "/") {
+		imageName = imageName[:idx]
+	}
+	return imageName
+}
+
 // performContainerRun executes the actual container run process
 func (s *Server) performContainerRun(ctx context.Context, imageName, containerName, command, workingDir, user, restart string, ports, environment, volumes []string, detached, interactive, remove, publishAll bool) (map[string]interface{}, error) {
 	startTime := time.Now()
@@ -717,31 +1115,60 @@ func (s *Server) performContainerRun(ctx context.Context, imageName, containerNa
 	
 	// Add command if specified
 	if command != "" {
-		// Split command into parts
-		cmdParts := strings.Fields(command)
+		// Split command into parts, keeping quoted arguments together
+		cmdParts, err := splitCommandLine(command)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command: %v", err)
+		}
 		args = append(args, cmdParts...)
 	}
-	
+
+	// Detached runs print the container ID and return at once; attached runs block until the
+	// container exits, so stdout is the container's own output
 	cmd := exec.CommandContext(ctx, containerRuntime, args...)
-	
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
 	// Execute run command
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("container run failed: %v, output: %s", err, string(output))
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("container run failed: %v, output: %s", err, strings.TrimSpace(stderr.String()+stdout.String()))
 	}
-	
+
 	duration := time.Since(startTime)
-	
-	// Extract container ID from output
-	containerID := strings.TrimSpace(string(output))
-	
+
+	containerID := ""
+	status := "exited"
+	if detached {
+		containerID = strings.TrimSpace(stdout.String())
+		status = "running"
+	} else if containerName != "" && !remove {
+		if output, err := exec.CommandContext(ctx, containerRuntime, "inspect", "--format", "{{.Id}}", containerName).Output(); err == nil {
+			containerID = strings.TrimSpace(string(output))
+		}
+	}
+
+	// The runtime reports the host side of published ports, including randomly assigned ones
+	mappedPorts := []string{}
+	if detached && containerID != "" && (publishAll || len(ports) > 0) {
+		if output, err := exec.CommandContext(ctx, containerRuntime, "port", containerID).Output(); err == nil {
+			for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					mappedPorts = append(mappedPorts, line)
+				}
+			}
+		}
+	}
+
 	result := map[string]interface{}{
+		"runtime":        containerRuntime,
 		"image_name":     imageName,
 		"container_name": containerName,
 		"container_id":   containerID,
 		"detached":       detached,
 		"interactive":    interactive,
 		"ports":          ports,
+		"mapped_ports":   mappedPorts,
 		"environment":    environment,
 		"volumes":        volumes,
 		"command":        command,
@@ -749,62 +1176,209 @@ func (s *Server) performContainerRun(ctx context.Context, imageName, containerNa
 		"user":           user,
 		"restart":        restart,
 		"run_duration":   duration.String(),
-		"output":         string(output),
-		"status":         "success",
+		"status":         status,
 		"timestamp":      time.Now().Format(time.RFC3339),
 	}
-	
+	if !detached {
+		result["output"] = stdout.String()
+		result["stderr"] = stderr.String()
+	}
+
 	return result, nil
 }
 
+// splitCommandLine splits a command into arguments on whitespace, honouring single and double
+// quotes and backslash escapes the way a POSIX shell would
+func splitCommandLine(command string) ([]string, error) {
+	var parts []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				parts = append(parts, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		parts = append(parts, current.String())
+	}
+	return parts, nil
+}
+
 // performContainerStop executes the actual container stop process
 func (s *Server) performContainerStop(ctx context.Context, containerName, timeout string, force bool) (map[string]interface{}, error) {
 	startTime := time.Now()
-	
+
 	// Detect container runtime (podman or docker)
 	containerRuntime, err := detectContainerRuntime()
 	if err != nil {
 		return nil, fmt.Errorf("no container runtime found: %v", err)
 	}
-	
+
 	klog.V(1).Infof("Stopping container %s using %s", containerName, containerRuntime)
 
 	// Prepare stop command
+	timeoutSeconds := parseStopTimeout(timeout)
 	var cmd *exec.Cmd
 	if force {
 		// Use kill for immediate stop
 		cmd = exec.CommandContext(ctx, containerRuntime, "kill", containerName)
 	} else {
-		// Use stop with timeout
-		args := []string{"stop"}
-		if timeout != "" {
-			args = append(args, "--time", timeout)
-		}
-		args = append(args, containerName)
-		cmd = exec.CommandContext(ctx, containerRuntime, args...)
+		// Use stop with timeout, in whole seconds as both runtimes expect
+		cmd = exec.CommandContext(ctx, containerRuntime, "stop", "--time", strconv.Itoa(timeoutSeconds), containerName)
 	}
-	
+
 	// Execute stop command
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("container stop failed: %v, output: %s", err, string(output))
+		if isNoSuchContainer(string(output)) {
+			return nil, fmt.Errorf("container '%s' not found", containerName)
+		}
+		return nil, fmt.Errorf("container stop failed: %v, output: %s", err, strings.TrimSpace(string(output)))
 	}
-	
+
 	duration := time.Since(startTime)
-	
+
 	result := map[string]interface{}{
-		"container_name": containerName,
-		"timeout":        timeout,
-		"force":          force,
-		"stop_duration":  duration.String(),
-		"output":         string(output),
-		"status":         "success",
-		"timestamp":      time.Now().Format(time.RFC3339),
+		"runtime":         containerRuntime,
+		"container_name":  containerName,
+		"timeout_seconds": timeoutSeconds,
+		"force_killed":    force,
+		"stop_duration":   duration.String(),
+		"output":          strings.TrimSpace(string(output)),
+		"status":          "success",
+		"timestamp":       time.Now().Format(time.RFC3339),
 	}
-	
+
+	return result, nil
+}
+
+// performContainerExec runs a command in a running container. A command exiting with a non-zero
+// code is reported in the result, not as an error.
+func (s *Server) performContainerExec(ctx context.Context, containerName, command, user, workingDir, timeout string, interactive bool) (map[string]interface{}, error) {
+	startTime := time.Now()
+
+	// Detect container runtime (podman or docker)
+	containerRuntime, err := detectContainerRuntime()
+	if err != nil {
+		return nil, fmt.Errorf("no container runtime found: %v", err)
+	}
+
+	cmdParts, err := splitCommandLine(command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command: %v", err)
+	}
+
+	// exec against a stopped container fails with runtime specific messages, check it first
+	state, err := exec.CommandContext(ctx, containerRuntime, "inspect", "--format", "{{.State.Status}}", containerName).CombinedOutput()
+	if err != nil {
+		if isNoSuchContainer(string(state)) {
+			return nil, fmt.Errorf("container '%s' not found", containerName)
+		}
+		return nil, fmt.Errorf("failed to inspect container '%s': %v, output: %s", containerName, err, strings.TrimSpace(string(state)))
+	}
+	if status := strings.TrimSpace(string(state)); status != "running" {
+		return nil, fmt.Errorf("container '%s' is not running (status: %s)", containerName, status)
+	}
+
+	args := []string{"exec"}
+	if interactive {
+		args = append(args, "--interactive")
+	}
+	if user != "" {
+		args = append(args, "--user", user)
+	}
+	if workingDir != "" {
+		args = append(args, "--workdir", workingDir)
+	}
+	args = append(args, containerName)
+	args = append(args, cmdParts...)
+
+	timeoutSeconds := parseStopTimeout(timeout)
+	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+	klog.V(1).Infof("Executing %v in container %s using %s", cmdParts, containerName, containerRuntime)
+	output, err := exec.CommandContext(execCtx, containerRuntime, args...).CombinedOutput()
+	exitCode := 0
+	if err != nil {
+		if execCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("command did not finish within %ds, output: %s", timeoutSeconds, strings.TrimSpace(string(output)))
+		}
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, fmt.Errorf("failed to run command: %v", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	status := "success"
+	if exitCode != 0 {
+		status = "failed"
+	}
+	result := map[string]interface{}{
+		"runtime":         containerRuntime,
+		"container_name":  containerName,
+		"command":         cmdParts,
+		"exit_code":       exitCode,
+		"output":          string(output),
+		"exec_duration":   time.Since(startTime).String(),
+		"timeout_seconds": timeoutSeconds,
+		"status":          status,
+		"timestamp":       time.Now().Format(time.RFC3339),
+	}
+
 	return result, nil
 }
 
+// parseStopTimeout converts a timeout such as "30s", "1m" or "5" to whole seconds, defaulting to 10
+func parseStopTimeout(timeout string) int {
+	const defaultSeconds = 10
+	timeout = strings.TrimSpace(timeout)
+	if seconds, err := strconv.Atoi(timeout); err == nil && seconds >= 0 {
+		return seconds
+	}
+	if duration, err := time.ParseDuration(timeout); err == nil && duration >= 0 {
+		return int(duration.Round(time.Second).Seconds())
+	}
+	return defaultSeconds
+}
+
+// isNoSuchContainer reports whether runtime output says the container does not exist
+func isNoSuchContainer(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "no such container") || strings.Contains(output, "no container with name or id")
+}
+
 func (s *Server) tagImage(ctx context.Context, runtime, sourceImage, targetImage string) error {
 	cmd := exec.CommandContext(ctx, runtime, "tag", sourceImage, targetImage)
 	return cmd.Run()
@@ -830,20 +1404,6 @@ func addTagToImage(imageName, tag string) string {
 	return imageName + ":" + tag
 }
 
-func parseImageLine(line string) map[string]interface{} {
-	fields := strings.Fields(line)
-	if len(fields) >= 5 {
-		return map[string]interface{}{
-			"repository": fields[0],
-			"tag":        fields[1],
-			"image_id":   fields[2],
-			"created":    fields[3],
-			"size":       fields[4],
-		}
-	}
-	return map[string]interface{}{"raw": line}
-}
-
 func parseJSON(jsonStr string, v interface{}) error {
 	return json.Unmarshal([]byte(jsonStr), v)
 }
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// checkMultiArchSupport verifies the runtime can assemble manifest lists, through the buildx plugin
+// for Docker or the manifest commands for Podman
+func checkMultiArchSupport(ctx context.Context, runtime string) error {
+	switch runtime {
+	case "docker":
+		if err := exec.CommandContext(ctx, "docker", "buildx", "version").Run(); err != nil {
+			return fmt.Errorf("docker cannot build multi-platform images without the buildx plugin, install it or use podman")
+		}
+	case "podman":
+		if err := exec.CommandContext(ctx, "podman", "manifest", "--help").Run(); err != nil {
+			return fmt.Errorf("this podman version does not support manifest lists, upgrade podman to build multi-platform images")
+		}
+	default:
+		return fmt.Errorf("container runtime %s cannot build multi-platform images", runtime)
+	}
+	return nil
+}
+
+// constructMultiArchBuildCommand builds every platform into a manifest list named after the image.
+// Docker pushes the list as it builds it, as buildx cannot load manifest lists into the local image
+// store; Podman assembles it locally and pushManifestList pushes it.
+func (s *Server) constructMultiArchBuildCommand(ctx context.Context, runtime string, config ContainerBuildConfig, buildDir string, noCache, pull bool) *exec.Cmd {
+	args := []string{"build", "--platform", strings.Join(config.Platforms, ",")}
+	if runtime == "docker" {
+		args = append([]string{"buildx"}, append(args, "--push")...)
+	} else {
+		// A list left by a previous build would keep its stale entries
+		_ = exec.CommandContext(ctx, runtime, "manifest", "rm", config.ImageName).Run()
+		args = append(args, "--manifest", config.ImageName)
+	}
+	if noCache {
+		args = append(args, "--no-cache")
+	}
+	if pull {
+		args = append(args, "--pull")
+	}
+	if config.Dockerfile != "" {
+		args = append(args, "-f", filepath.Join(buildDir, config.BuildContext, config.Dockerfile))
+	}
+	for key, value := range config.BuildArgs {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", key, value))
+	}
+	if runtime == "docker" {
+		args = append(args, "-t", config.ImageName)
+		for _, tag := range config.Tags {
+			args = append(args, "-t", addTagToImage(config.ImageName, tag))
+		}
+	}
+	args = append(args, filepath.Join(buildDir, config.BuildContext))
+	return exec.Command(runtime, args...)
+}
+
+// pushManifestList pushes a Podman manifest list with all its images, under the image name and
+// every additional tag
+func pushManifestList(ctx context.Context, config ContainerBuildConfig) (string, error) {
+	var output strings.Builder
+	targets := []string{config.ImageName}
+	for _, tag := range config.Tags {
+		targets = append(targets, addTagToImage(config.ImageName, tag))
+	}
+	for _, target := range targets {
+		out, err := exec.CommandContext(ctx, "podman", "manifest", "push", "--all", config.ImageName, "docker://"+target).CombinedOutput()
+		output.Write(out)
+		if err != nil {
+			return output.String(), fmt.Errorf("failed to push manifest list %s: %v", target, err)
+		}
+	}
+	return output.String(), nil
+}
+
+// manifestDigests returns the digest of the image built for each platform of a manifest list, by
+// os/architecture[/variant]
+func manifestDigests(ctx context.Context, runtime, image string) (map[string]string, error) {
+	var cmd *exec.Cmd
+	if runtime == "docker" {
+		cmd = exec.CommandContext(ctx, "docker", "buildx", "imagetools", "inspect", "--raw", image)
+	} else {
+		cmd = exec.CommandContext(ctx, "podman", "manifest", "inspect", image)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect manifest list %s: %v", image, err)
+	}
+	var list struct {
+		Manifests []struct {
+			Digest   string `json:"digest"`
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest list %s: %v", image, err)
+	}
+	digests := make(map[string]string, len(list.Manifests))
+	for _, manifest := range list.Manifests {
+		platform := manifest.Platform.OS + "/" + manifest.Platform.Architecture
+		if manifest.Platform.Variant != "" {
+			platform += "/" + manifest.Platform.Variant
+		}
+		// Build attestations are listed as unknown/unknown
+		if manifest.Platform.OS == "unknown" {
+			continue
+		}
+		digests[platform] = manifest.Digest
+	}
+	return digests, nil
+}
@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	units "github.com/docker/go-units"
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// largeDirectoryThreshold is the size above which a top-level directory is reported for review
+const largeDirectoryThreshold = 50 * 1024 * 1024
+
+// dockerignoreRule is a .dockerignore entry suggested when the build context contains matching paths
+type dockerignoreRule struct {
+	pattern string
+	reason  string
+	dir     string // directory name
+	glob    string // file name glob
+}
+
+var dockerignoreRules = []dockerignoreRule{
+	{pattern: ".git", reason: "version control", dir: ".git"},
+	{pattern: ".hg", reason: "version control", dir: ".hg"},
+	{pattern: ".svn", reason: "version control", dir: ".svn"},
+	{pattern: "**/node_modules", reason: "dependencies are installed in the image", dir: "node_modules"},
+	{pattern: "**/__pycache__", reason: "Python bytecode cache", dir: "__pycache__"},
+	{pattern: "**/*.pyc", reason: "Python bytecode", glob: "*.pyc"},
+	{pattern: ".venv", reason: "local virtualenv", dir: ".venv"},
+	{pattern: "venv", reason: "local virtualenv", dir: "venv"},
+	{pattern: ".tox", reason: "test environments", dir: ".tox"},
+	{pattern: ".pytest_cache", reason: "test cache", dir: ".pytest_cache"},
+	{pattern: ".mypy_cache", reason: "type checker cache", dir: ".mypy_cache"},
+	{pattern: "coverage", reason: "test coverage output", dir: "coverage"},
+	{pattern: "dist", reason: "build artifacts", dir: "dist"},
+	{pattern: "build", reason: "build artifacts", dir: "build"},
+	{pattern: "target", reason: "build artifacts", dir: "target"},
+	{pattern: ".next", reason: "build artifacts", dir: ".next"},
+	{pattern: ".idea", reason: "editor settings", dir: ".idea"},
+	{pattern: ".vscode", reason: "editor settings", dir: ".vscode"},
+	{pattern: "**/*.log", reason: "log files", glob: "*.log"},
+	{pattern: "**/.DS_Store", reason: "OS metadata", glob: ".DS_Store"},
+	{pattern: ".env", reason: "local secrets", glob: ".env"},
+}
+
+// DockerignoreSuggestion is a suggested .dockerignore entry and the context size it removes
+type DockerignoreSuggestion struct {
+	Pattern string `json:"pattern"`
+	Reason  string `json:"reason"`
+	Bytes   int64  `json:"bytes"`
+}
+
+var (
+	copySourcePattern   = regexp.MustCompile(`(?i)^\s*(COPY|ADD)\s+(.*)$`)
+	dependencyInstall   = regexp.MustCompile(`(?i)\b(npm (install|ci)|yarn install|pip3? install -r|go mod download|mvn .*dependency|bundle install|composer install)\b`)
+	buildToolInvocation = regexp.MustCompile(`(?i)\b(npm run build|yarn build|go build|mvn (package|install)|gradle(w)? (build|assemble)|cargo build|dotnet publish)\b`)
+)
+
+// dockerfileOptimize suggests a .dockerignore for a source directory and multi-stage improvements for its Dockerfile
+func (s *Server) dockerfileOptimize(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	sourcePath, ok := args["source_path"].(string)
+	if !ok || sourcePath == "" {
+		return NewTextResult("", fmt.Errorf("source_path parameter is required")), nil
+	}
+	if info, err := os.Stat(sourcePath); err != nil || !info.IsDir() {
+		return NewTextResult("", fmt.Errorf("source_path '%s' is not a directory", sourcePath)), nil
+	}
+	dockerfile := getStringArg(args, "dockerfile", "Dockerfile")
+	write := getBoolArg(args, "write", false)
+
+	klog.V(2).Infof("Analyzing build context: %s", sourcePath)
+
+	dockerfileLines, err := readLines(filepath.Join(sourcePath, dockerfile))
+	if err != nil && !os.IsNotExist(err) {
+		return NewTextResult("", fmt.Errorf("failed to read Dockerfile: %v", err)), nil
+	}
+	ignorePath := filepath.Join(sourcePath, ".dockerignore")
+	existing, err := readLines(ignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return NewTextResult("", fmt.Errorf("failed to read .dockerignore: %v", err)), nil
+	}
+	existingPatterns := make(map[string]bool)
+	for _, line := range existing {
+		existingPatterns[strings.TrimSpace(line)] = true
+	}
+	copied := copiedPaths(dockerfileLines)
+
+	// Walk the context once, attributing each file to the first matching rule
+	ruleBytes := make([]int64, len(dockerignoreRules))
+	topLevel := make(map[string]int64)
+	var totalBytes int64
+	err = filepath.WalkDir(sourcePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(sourcePath, path)
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		totalBytes += info.Size()
+		if len(parts) > 1 {
+			topLevel[parts[0]] += info.Size()
+		}
+		for i, rule := range dockerignoreRules {
+			if matchesRule(rule, parts) {
+				ruleBytes[i] += info.Size()
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to analyze build context: %v", err)), nil
+	}
+
+	suggestions := make([]DockerignoreSuggestion, 0)
+	var excludedBytes int64
+	for i, rule := range dockerignoreRules {
+		if ruleBytes[i] == 0 || existingPatterns[rule.pattern] || copied[strings.TrimPrefix(rule.pattern, "**/")] {
+			continue
+		}
+		suggestions = append(suggestions, DockerignoreSuggestion{Pattern: rule.pattern, Reason: rule.reason, Bytes: ruleBytes[i]})
+		excludedBytes += ruleBytes[i]
+	}
+
+	// Large directories are only reported, they may well be needed by the build
+	largeDirectories := make([]string, 0)
+	for dir, size := range topLevel {
+		if size >= largeDirectoryThreshold && !existingPatterns[dir] && !copied[dir] {
+			largeDirectories = append(largeDirectories, fmt.Sprintf("%s (%s)", dir, units.HumanSize(float64(size))))
+		}
+	}
+	sort.Strings(largeDirectories)
+
+	result := map[string]interface{}{
+		"source_path":          sourcePath,
+		"context_size":         units.HumanSize(float64(totalBytes)),
+		"estimated_reduction":  units.HumanSize(float64(excludedBytes)),
+		"estimated_size_after": units.HumanSize(float64(totalBytes - excludedBytes)),
+		"dockerignore":         suggestions,
+		"large_directories":    largeDirectories,
+		"dockerfile_advice":    dockerfileAdvice(dockerfileLines),
+		"written":              false,
+	}
+
+	if write && len(suggestions) > 0 {
+		content := strings.Join(existing, "\n")
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += "# Added by dockerfile_optimize\n"
+		for _, suggestion := range suggestions {
+			content += suggestion.Pattern + "\n"
+		}
+		if err := os.WriteFile(ignorePath, []byte(content), 0644); err != nil {
+			return NewTextResult("", fmt.Errorf("failed to write .dockerignore: %v", err)), nil
+		}
+		result["written"] = true
+		result["dockerignore_path"] = ignorePath
+	}
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// matchesRule reports whether the file at parts is excluded by rule. Patterns without a '**/'
+// prefix only match at the root of the context, as in .dockerignore.
+func matchesRule(rule dockerignoreRule, parts []string) bool {
+	anyDepth := strings.HasPrefix(rule.pattern, "**/")
+	dirs, name := parts[:len(parts)-1], parts[len(parts)-1]
+	if rule.dir != "" {
+		if !anyDepth && len(dirs) > 0 {
+			dirs = dirs[:1]
+		}
+		for _, dir := range dirs {
+			if dir == rule.dir {
+				return true
+			}
+		}
+		return false
+	}
+	if !anyDepth && len(dirs) > 0 {
+		return false
+	}
+	matched, _ := filepath.Match(rule.glob, name)
+	return matched
+}
+
+// copiedPaths returns the first path element of every explicit COPY/ADD source, so paths the
+// Dockerfile needs are never suggested for exclusion
+func copiedPaths(lines []string) map[string]bool {
+	copied := make(map[string]bool)
+	for _, line := range lines {
+		match := copySourcePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		fields := strings.Fields(match[2])
+		sources := make([]string, 0)
+		for _, field := range fields {
+			if !strings.HasPrefix(field, "--") {
+				sources = append(sources, field)
+			}
+		}
+		// The last field is the destination
+		for i := 0; i < len(sources)-1; i++ {
+			source := strings.TrimPrefix(strings.Trim(sources[i], `"[],`), "./")
+			copied[strings.SplitN(source, "/", 2)[0]] = true
+		}
+	}
+	return copied
+}
+
+// dockerfileAdvice suggests multi-stage and layer caching improvements
+func dockerfileAdvice(lines []string) []string {
+	if len(lines) == 0 {
+		return []string{"No Dockerfile found, only the build context was analyzed"}
+	}
+	advice := make([]string, 0)
+	stages, copyAll, builds := 0, -1, false
+	for i, line := range lines {
+		upper := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(upper, "FROM "):
+			stages++
+		case strings.HasPrefix(upper, "COPY . ") || strings.HasPrefix(upper, "ADD . "):
+			if copyAll < 0 {
+				copyAll = i
+			}
+		case strings.HasPrefix(upper, "RUN "):
+			if buildToolInvocation.MatchString(line) {
+				builds = true
+			}
+			if copyAll >= 0 && dependencyInstall.MatchString(line) {
+				advice = append(advice, fmt.Sprintf("Line %d: dependencies are installed after copying the whole context, so any source change invalidates the install layer; copy only the dependency manifests (package.json, requirements.txt, go.mod, pom.xml) before installing", i+1))
+			}
+			if strings.Contains(line, "pip install") && !strings.Contains(line, "--no-cache-dir") {
+				advice = append(advice, fmt.Sprintf("Line %d: add --no-cache-dir to pip install to keep the cache out of the image", i+1))
+			}
+		}
+	}
+	if stages == 1 && builds {
+		advice = append(advice, "The image is built and run in a single stage, so compilers and build dependencies ship with it; use a multi-stage build and copy only the build output into a minimal runtime image such as registry.access.redhat.com/ubi9/ubi-minimal")
+	}
+	return advice
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
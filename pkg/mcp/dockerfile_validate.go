@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// validateDockerfile runs the UBI compliance and security checks of container_build on a Dockerfile
+// given by path, inline content or git repository, without building it
+func (s *Server) validateDockerfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	dockerfilePath := getStringArg(args, "dockerfile_path", "")
+	content := getStringArg(args, "content", "")
+	gitURL := getStringArg(args, "git_url", "")
+	fix := getBoolArg(args, "fix", false)
+
+	sources := 0
+	for _, source := range []string{dockerfilePath, content, gitURL} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return NewTextResult("", fmt.Errorf("exactly one of dockerfile_path, content or git_url is required")), nil
+	}
+
+	source := dockerfilePath
+	switch {
+	case content != "":
+		dir, err := os.MkdirTemp("", "mcp-dockerfile-*")
+		if err != nil {
+			return NewTextResult("", fmt.Errorf("failed to create temp directory: %v", err)), nil
+		}
+		defer os.RemoveAll(dir)
+		dockerfilePath = filepath.Join(dir, "Dockerfile")
+		if err := os.WriteFile(dockerfilePath, []byte(content), 0600); err != nil {
+			return NewTextResult("", fmt.Errorf("failed to write Dockerfile: %v", err)), nil
+		}
+		source = "inline"
+	case gitURL != "":
+		dir, err := s.cloneGitRepository(ctx, gitURL, getStringArg(args, "git_branch", "main"), "")
+		if err != nil {
+			return NewTextResult("", err), nil
+		}
+		defer os.RemoveAll(dir)
+		dockerfilePath = filepath.Join(dir, getStringArg(args, "dockerfile", "Dockerfile"))
+		source = gitURL
+	default:
+		// A directory is validated with the Dockerfile it contains
+		if info, err := os.Stat(dockerfilePath); err == nil && info.IsDir() {
+			dockerfilePath = filepath.Join(dockerfilePath, getStringArg(args, "dockerfile", "Dockerfile"))
+		}
+	}
+
+	klog.V(2).Infof("Validating Dockerfile %s", dockerfilePath)
+	dockerfile, err := os.ReadFile(dockerfilePath)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to read Dockerfile: %v", err)), nil
+	}
+	ubiValidation, err := s.validateUBICompliance(ctx, dockerfilePath)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	warnings, recommendations, err := s.validateDockerfileForSecurity(ctx, dockerfilePath)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	result := map[string]interface{}{
+		"source":                   source,
+		"ubi_compliance":           ubiValidation,
+		"security_warnings":        warnings,
+		"security_recommendations": recommendations,
+		"passed":                   ubiValidation.IsUBI && len(warnings) == 0,
+	}
+	if source != "inline" {
+		result["dockerfile"] = getStringArg(args, "dockerfile", filepath.Base(dockerfilePath))
+	}
+	if fix {
+		if fixed := ubiDockerfileContent(string(dockerfile), ubiValidation); fixed != string(dockerfile) {
+			result["ubi_dockerfile"] = fixed
+		} else {
+			result["ubi_dockerfile_message"] = "No stage needs a UBI base image replacement"
+		}
+	}
+
+	jsonResult, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to marshal result: %v", err)), nil
+	}
+	return NewTextResult(string(jsonResult), nil), nil
+}
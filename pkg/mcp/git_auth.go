@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+// sshKey returns the SSH key configured for the repository, nil when it is accessed anonymously
+// or over HTTPS
+func (c *RepoConfig) sshKey() *cicd.SSHKey {
+	if c.SSHKeyPath == "" {
+		return nil
+	}
+	return &cicd.SSHKey{Path: c.SSHKeyPath, Passphrase: c.SSHKeyPassphrase}
+}
+
+// sshKeyForURL returns the SSH key of the configured repository with the given URL, if any
+func sshKeyForURL(url string) *cicd.SSHKey {
+	normalized := cicd.NormalizeRepoURL(url)
+	for _, config := range listRepos() {
+		if key := config.sshKey(); key != nil && cicd.NormalizeRepoURL(config.URL) == normalized {
+			return key
+		}
+	}
+	return nil
+}
+
+// gitAuthForURL returns the go-git auth for a repository URL, nil when no SSH key is configured
+func gitAuthForURL(url string) (transport.AuthMethod, error) {
+	key := sshKeyForURL(url)
+	if key == nil {
+		return nil, nil
+	}
+	return key.AuthMethod()
+}
+
+// storeRepoSSHKey writes a private key given as PEM content next to the repository store, readable
+// only by the current user, and returns its path
+func storeRepoSSHKey(repoName, pem string) (string, error) {
+	path := filepath.Join(filepath.Dir(repoStorePath(DefaultStoreDir())), "keys", repoName)
+	if err := writeFileAtomic(path, []byte(pem)); err != nil {
+		return "", fmt.Errorf("failed to store SSH key: %w", err)
+	}
+	return path, nil
+}
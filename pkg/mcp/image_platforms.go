@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+)
+
+var nodeGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Node"}
+
+// PlatformCheck compares the platforms an image supports with the platforms of the cluster nodes
+type PlatformCheck struct {
+	Image             string              `json:"image"`
+	ImagePlatforms    []string            `json:"image_platforms"`
+	NodePlatforms     map[string][]string `json:"node_platforms"` // os/arch to node names
+	IncompatibleNodes []string            `json:"incompatible_nodes,omitempty"`
+	Compatible        bool                `json:"compatible"`
+	Warning           string              `json:"warning,omitempty"`
+	Error             string              `json:"error,omitempty"`
+}
+
+// imagePlatforms returns the os/arch platforms an image can run on. Local images are inspected with
+// the container runtime; remote images are read from the registry manifest without pulling them.
+func (s *Server) imagePlatforms(ctx context.Context, image string, local, skipTLSVerify, noCache bool) ([]string, error) {
+	containerRuntime, err := detectContainerRuntime()
+	if local {
+		if err != nil {
+			return nil, fmt.Errorf("no container runtime found: %v", err)
+		}
+		output, err := exec.CommandContext(ctx, containerRuntime, "image", "inspect", "--format", "{{.Os}}/{{.Architecture}}", image).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect local image %s: %v", image, err)
+		}
+		return []string{strings.TrimSpace(string(output))}, nil
+	}
+
+	// Multi-arch images list their platforms in the image index
+	if err == nil {
+		if manifest, err := s.inspectRemoteManifest(ctx, containerRuntime, image, skipTLSVerify, noCache); err == nil && len(manifest.Manifests) > 0 {
+			platforms := make([]string, 0, len(manifest.Manifests))
+			for _, m := range manifest.Manifests {
+				// Attestation manifests are listed with an unknown platform
+				if m.Platform.Architecture == "" || m.Platform.Architecture == "unknown" {
+					continue
+				}
+				platforms = append(platforms, m.Platform.OS+"/"+m.Platform.Architecture)
+			}
+			return platforms, nil
+		}
+	}
+
+	// Single-arch images only record their platform in the image config
+	platform, err := s.registryCache.lookup(registryCacheKey("platform", image), noCache, func() (interface{}, error) {
+		return fetchImageConfigPlatform(ctx, image, skipTLSVerify)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []string{platform.(string)}, nil
+}
+
+// fetchImageConfigPlatform reads the os/arch of a single-arch remote image using skopeo
+func fetchImageConfigPlatform(ctx context.Context, image string, skipTLSVerify bool) (string, error) {
+	if _, err := exec.LookPath("skopeo"); err != nil {
+		return "", fmt.Errorf("skopeo not found in PATH, cannot determine the architecture of a single-arch image")
+	}
+	args := []string{"inspect", "--format", "{{.Os}}/{{.Architecture}}"}
+	if skipTLSVerify {
+		args = append(args, "--tls-verify=false")
+	}
+	output, err := exec.CommandContext(ctx, "skopeo", append(args, "docker://"+image)...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("failed to inspect image: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("failed to inspect image: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// fetchNodePlatforms groups the schedulable nodes by their kubernetes.io/os and kubernetes.io/arch labels
+func fetchNodePlatforms(ctx context.Context, k *internalk8s.Kubernetes) (map[string][]string, error) {
+	list, err := k.ResourcesList(ctx, &nodeGVK, "", internalk8s.ResourceListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected node list type %T", list)
+	}
+	platforms := make(map[string][]string)
+	for _, node := range nodes.Items {
+		if unschedulable, _, _ := unstructured.NestedBool(node.Object, "spec", "unschedulable"); unschedulable {
+			continue
+		}
+		labels := node.GetLabels()
+		platform := labels["kubernetes.io/os"] + "/" + labels["kubernetes.io/arch"]
+		platforms[platform] = append(platforms[platform], node.GetName())
+	}
+	return platforms, nil
+}
+
+// checkImagePlatforms reports whether an image can run on the cluster nodes. Compatible is false only
+// when no schedulable node matches; a partial match is reported as a warning since pods may still be
+// scheduled onto an incompatible node and crashloop with 'exec format error'.
+func (s *Server) checkImagePlatforms(ctx context.Context, k *internalk8s.Kubernetes, image string, local, skipTLSVerify, noCache bool) (*PlatformCheck, error) {
+	imagePlatforms, err := s.imagePlatforms(ctx, image, local, skipTLSVerify, noCache)
+	if err != nil {
+		return nil, err
+	}
+	nodePlatforms, err := fetchNodePlatforms(ctx, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	check := &PlatformCheck{Image: image, ImagePlatforms: imagePlatforms, NodePlatforms: nodePlatforms}
+	supported := make(map[string]bool)
+	for _, platform := range imagePlatforms {
+		supported[platform] = true
+	}
+	for platform, nodes := range nodePlatforms {
+		if supported[platform] {
+			check.Compatible = true
+		} else {
+			check.IncompatibleNodes = append(check.IncompatibleNodes, nodes...)
+		}
+	}
+	sort.Strings(check.IncompatibleNodes)
+
+	switch {
+	case len(nodePlatforms) == 0:
+		check.Error = "no schedulable nodes found"
+	case !check.Compatible:
+		check.Error = fmt.Sprintf("image %s supports %s, but no schedulable node runs one of these platforms (nodes: %s); pods would crashloop with 'exec format error'",
+			image, strings.Join(imagePlatforms, ", "), strings.Join(sortedKeys(nodePlatforms), ", "))
+	case len(check.IncompatibleNodes) > 0:
+		check.Warning = fmt.Sprintf("image %s does not support the platform of nodes %s; use a nodeSelector on kubernetes.io/arch or build a multi-arch image",
+			image, strings.Join(check.IncompatibleNodes, ", "))
+	}
+	return check, nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// imagePlatformCheck handles checking an image's platforms against the cluster nodes
+func (s *Server) imagePlatformCheck(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	image, ok := args["image_name"].(string)
+	if !ok || image == "" {
+		return NewTextResult("", fmt.Errorf("image_name parameter is required")), nil
+	}
+
+	if s.k == nil {
+		return NewTextResult("", fmt.Errorf("kubernetes manager is not initialized")), nil
+	}
+	k, err := s.k.Derived(ctx)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to access cluster: %v", err)), nil
+	}
+
+	check, err := s.checkImagePlatforms(ctx, k, image, getBoolArg(args, "local", false), getBoolArg(args, "skip_tls_verify", false), getBoolArg(args, "no_cache", false))
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("platform check failed for '%s': %v", image, err)), nil
+	}
+
+	jsonResult, _ := json.MarshalIndent(check, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,288 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/klog/v2"
+)
+
+// ImageVerificationPolicy describes which signer is trusted when verifying an image
+type ImageVerificationPolicy struct {
+	Key             string `json:"key,omitempty"`
+	Identity        string `json:"certificate_identity,omitempty"`
+	IdentityRegexp  string `json:"certificate_identity_regexp,omitempty"`
+	OIDCIssuer      string `json:"certificate_oidc_issuer,omitempty"`
+	AttestationType string `json:"attestation_type,omitempty"`
+}
+
+// ImageVerificationResult contains the outcome of a signature and attestation check
+type ImageVerificationResult struct {
+	Image            string                  `json:"image"`
+	Verified         bool                    `json:"verified"`
+	Status           string                  `json:"status"`
+	Digest           string                  `json:"digest,omitempty"`
+	Signatures       int                     `json:"signatures"`
+	Signers          []SignatureVerification `json:"signers,omitempty"`
+	SignatureError   string                  `json:"signature_error,omitempty"`
+	Attestations     []ImageAttestation      `json:"attestations,omitempty"`
+	AttestationError string                  `json:"attestation_error,omitempty"`
+	Policy           ImageVerificationPolicy `json:"policy"`
+	Timestamp        string                  `json:"timestamp"`
+}
+
+// ImageAttestation is the subset of an in-toto statement reported to the user
+type ImageAttestation struct {
+	PredicateType string                   `json:"predicate_type"`
+	Builder       string                   `json:"builder,omitempty"`
+	BuildType     string                   `json:"build_type,omitempty"`
+	Materials     []map[string]interface{} `json:"materials,omitempty"`
+	Invocation    map[string]interface{}   `json:"invocation,omitempty"`
+}
+
+// initImageSecurity initializes image supply-chain security MCP tools
+func (s *Server) initImageSecurity() []server.ServerTool {
+	klog.V(1).Info("Initializing image supply-chain security tools")
+
+	return []server.ServerTool{
+		{Tool: mcp.NewTool("verify_image",
+			mcp.WithDescription("Verify the cosign signature and SLSA provenance attestations of a pushed container image against the configured trusted key or keyless identity policy. Returns verified/failed together with the attested build inputs."),
+			mcp.WithString("image_name", mcp.Description("Fully qualified image reference to verify. Examples: 'quay.io/user/app:v1.0', 'quay.io/user/app@sha256:...'."), mcp.Required()),
+			mcp.WithString("key", mcp.Description("Cosign public key: a file path, 'env://VAR', 'k8s://namespace/secret' or a KMS URI. Defaults to the server's configured image_verification_key, then the COSIGN_PUBLIC_KEY environment variable.")),
+			mcp.WithString("certificate_identity", mcp.Description("Expected signer identity for keyless verification. Defaults to the server's configured image_verification_identity.")),
+			mcp.WithString("certificate_identity_regexp", mcp.Description("Regular expression the signer identity must match for keyless verification, instead of certificate_identity.")),
+			mcp.WithString("certificate_oidc_issuer", mcp.Description("Expected OIDC issuer for keyless verification. Defaults to the server's configured image_verification_issuer.")),
+			mcp.WithString("attestation_type", mcp.Description("Attestation predicate type to verify. Defaults to 'slsaprovenance'.")),
+			mcp.WithBoolean("require_attestation", mcp.Description("Fail verification if no valid attestation is found. Defaults to false.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Security: Verify Image Signature and Provenance"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.verifyImage},
+	}
+}
+
+// verifyImage handles image signature and provenance verification
+func (s *Server) verifyImage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	imageName, ok := args["image_name"].(string)
+	if !ok || imageName == "" {
+		return NewTextResult("", fmt.Errorf("image_name parameter is required")), nil
+	}
+
+	policy := s.imageVerificationPolicy()
+	policy.Key = getStringArg(args, "key", policy.Key)
+	policy.Identity = getStringArg(args, "certificate_identity", policy.Identity)
+	policy.IdentityRegexp = getStringArg(args, "certificate_identity_regexp", policy.IdentityRegexp)
+	if policy.IdentityRegexp != "" && args["certificate_identity"] == nil {
+		policy.Identity = ""
+	}
+	policy.OIDCIssuer = getStringArg(args, "certificate_oidc_issuer", policy.OIDCIssuer)
+	policy.AttestationType = getStringArg(args, "attestation_type", policy.AttestationType)
+	requireAttestation := getBoolArg(args, "require_attestation", false)
+
+	klog.V(2).Infof("Verifying container image: %s", imageName)
+
+	result, err := s.performImageVerification(ctx, imageName, policy, requireAttestation)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("image verification failed: %v", err)), nil
+	}
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// imageVerificationPolicy returns the trust policy configured for the server
+func (s *Server) imageVerificationPolicy() ImageVerificationPolicy {
+	policy := ImageVerificationPolicy{AttestationType: "slsaprovenance"}
+	if s.configuration != nil && s.configuration.StaticConfig != nil {
+		policy.Key = s.configuration.StaticConfig.ImageVerificationKey
+		policy.Identity = s.configuration.StaticConfig.ImageVerificationIdentity
+		policy.OIDCIssuer = s.configuration.StaticConfig.ImageVerificationIssuer
+	}
+	if policy.Key == "" && policy.Identity == "" && os.Getenv("COSIGN_PUBLIC_KEY") != "" {
+		policy.Key = "env://COSIGN_PUBLIC_KEY"
+	}
+	return policy
+}
+
+// performImageVerification checks the signature and attestations of an image with cosign
+func (s *Server) performImageVerification(ctx context.Context, imageName string, policy ImageVerificationPolicy, requireAttestation bool) (*ImageVerificationResult, error) {
+	if _, err := detectCosign(); err != nil {
+		return nil, err
+	}
+	policyArgs, err := cosignPolicyArgs(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, _ := imageReference(imageName)
+	host, _ := splitRepository(repository)
+	result := &ImageVerificationResult{
+		Image:     imageName,
+		Policy:    policy,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	// Verify signatures
+	if result.Signers, err = verifyImageSignatures(ctx, host, imageName, policyArgs); err != nil {
+		result.SignatureError = err.Error()
+	} else if result.Signatures = len(result.Signers); result.Signatures > 0 {
+		result.Digest = result.Signers[0].ImageDigest
+	}
+
+	// Verify attestations
+	attestArgs := append([]string{"verify-attestation", "--type", policy.AttestationType}, policyArgs...)
+	attestArgs = append(attestArgs, imageName)
+	output, err := runCosign(ctx, host, attestArgs...)
+	if err != nil {
+		result.AttestationError = cosignErrorMessage(err)
+	} else {
+		result.Attestations = parseCosignAttestations(output)
+	}
+
+	result.Verified = result.Signatures > 0 && (!requireAttestation || len(result.Attestations) > 0)
+	if result.Verified {
+		result.Status = "verified"
+	} else {
+		result.Status = "failed"
+	}
+	return result, nil
+}
+
+// cosignPolicyArgs converts a verification policy to cosign flags
+func cosignPolicyArgs(policy ImageVerificationPolicy) ([]string, error) {
+	if policy.Key != "" {
+		return []string{"--key", policy.Key}, nil
+	}
+	if policy.Identity != "" && policy.OIDCIssuer != "" {
+		return []string{"--certificate-identity", policy.Identity, "--certificate-oidc-issuer", policy.OIDCIssuer}, nil
+	}
+	if policy.IdentityRegexp != "" && policy.OIDCIssuer != "" {
+		return []string{"--certificate-identity-regexp", policy.IdentityRegexp, "--certificate-oidc-issuer", policy.OIDCIssuer}, nil
+	}
+	return nil, fmt.Errorf("no trusted key or keyless identity/issuer configured for image verification")
+}
+
+// verifyImageSignatures runs cosign verify with the policy flags and returns the verified signatures
+func verifyImageSignatures(ctx context.Context, host, imageName string, policyArgs []string) ([]SignatureVerification, error) {
+	verifyArgs := append([]string{"verify", "--output", "json"}, policyArgs...)
+	output, err := runCosign(ctx, host, append(verifyArgs, imageName)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s", cosignErrorMessage(err))
+	}
+	return parseCosignVerifyOutput(output)
+}
+
+// runCosign runs cosign with the registry credentials of host and returns its standard output
+func runCosign(ctx context.Context, host string, args ...string) ([]byte, error) {
+	cmd, cleanup, err := cosignCommand(ctx, host, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return cmd.Output()
+}
+
+func cosignErrorMessage(err error) string {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return err.Error()
+}
+
+// parseCosignAttestations decodes the in-toto statements printed by cosign verify-attestation
+func parseCosignAttestations(output []byte) []ImageAttestation {
+	attestations := []ImageAttestation{}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var envelope struct {
+			Payload string `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &envelope); err != nil || envelope.Payload == "" {
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		if err != nil {
+			continue
+		}
+		var statement struct {
+			PredicateType string                 `json:"predicateType"`
+			Predicate     map[string]interface{} `json:"predicate"`
+		}
+		if err := json.Unmarshal(payload, &statement); err != nil {
+			continue
+		}
+		attestations = append(attestations, summarizeProvenance(statement.PredicateType, statement.Predicate))
+	}
+	return attestations
+}
+
+// summarizeProvenance extracts builder and build inputs from SLSA v0.2 and v1 predicates
+func summarizeProvenance(predicateType string, predicate map[string]interface{}) ImageAttestation {
+	attestation := ImageAttestation{PredicateType: predicateType}
+
+	// SLSA v0.2
+	if builder, ok := predicate["builder"].(map[string]interface{}); ok {
+		attestation.Builder, _ = builder["id"].(string)
+	}
+	attestation.BuildType, _ = predicate["buildType"].(string)
+	if invocation, ok := predicate["invocation"].(map[string]interface{}); ok {
+		attestation.Invocation = invocation
+	}
+	attestation.Materials = toMapSlice(predicate["materials"])
+
+	// SLSA v1
+	if buildDefinition, ok := predicate["buildDefinition"].(map[string]interface{}); ok {
+		if attestation.BuildType == "" {
+			attestation.BuildType, _ = buildDefinition["buildType"].(string)
+		}
+		if attestation.Materials == nil {
+			attestation.Materials = toMapSlice(buildDefinition["resolvedDependencies"])
+		}
+		if attestation.Invocation == nil {
+			if params, ok := buildDefinition["externalParameters"].(map[string]interface{}); ok {
+				attestation.Invocation = params
+			}
+		}
+	}
+	if runDetails, ok := predicate["runDetails"].(map[string]interface{}); ok && attestation.Builder == "" {
+		if builder, ok := runDetails["builder"].(map[string]interface{}); ok {
+			attestation.Builder, _ = builder["id"].(string)
+		}
+	}
+	return attestation
+}
+
+func toMapSlice(value interface{}) []map[string]interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
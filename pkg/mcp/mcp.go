@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -13,8 +14,10 @@ import (
 	authenticationapiv1 "k8s.io/api/authentication/v1"
 	"k8s.io/utils/ptr"
 
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
 	"github.com/sur309/openshift-mcp-server/pkg/config"
 	internalk8s "github.com/sur309/openshift-mcp-server/pkg/kubernetes"
+	"github.com/sur309/openshift-mcp-server/pkg/metrics"
 	"github.com/sur309/openshift-mcp-server/pkg/output"
 	"github.com/sur309/openshift-mcp-server/pkg/version"
 )
@@ -24,6 +27,12 @@ type Configuration struct {
 	ListOutput output.Output
 
 	StaticConfig *config.StaticConfig
+
+	// StoreDir is the directory the repository, registry and workflow configurations are persisted
+	// to. Empty keeps them in memory only and does not poll the repositories for new commits.
+	StoreDir string
+	// Registries are configured when the server starts
+	Registries []RegistryConfig
 }
 
 func (c *Configuration) isToolApplicable(tool server.ServerTool) bool {
@@ -47,21 +56,54 @@ type Server struct {
 	server               *server.MCPServer
 	k                    *internalk8s.Manager
 	workflowOrchestrator *WorkflowOrchestrator
+	audit                *auditLog
+	registryCache        *registryCache
+	gitWatcher           *cicd.GitWatcher
+	// stopGitPolling stops polling the repositories for new commits, nil when they are not polled
+	stopGitPolling context.CancelFunc
+	// liveData is the last-known-good data of read tools, served while the cluster is unreachable
+	liveData *liveDataCache
+	// tools are the applicable tools by name, which workflow steps are dispatched to
+	tools map[string]server.ServerTool
+	// workflowStorePath is where the workflow orchestrator persists custom workflows
+	workflowStorePath string
 }
 
 func NewServer(configuration Configuration) (*Server, error) {
 	s := &Server{
 		configuration: &configuration,
-		server: server.NewMCPServer(
-			version.BinaryName,
-			version.Version,
-			server.WithResourceCapabilities(true, true),
-			server.WithPromptCapabilities(true),
-			server.WithToolCapabilities(true),
-			server.WithLogging(),
-			server.WithToolHandlerMiddleware(toolCallLoggingMiddleware),
-		),
+		audit:         newAuditLog(defaultAuditCapacity),
+	}
+	cacheTTL, liveTTL := "", ""
+	if configuration.StaticConfig != nil {
+		cacheTTL = configuration.StaticConfig.RegistryCacheTTL
+		liveTTL = configuration.StaticConfig.LiveDataTTL
+	}
+	s.registryCache = newRegistryCache(registryCacheTTL(cacheTTL), registryCacheCapacity)
+	s.liveData = newLiveDataCache(liveDataTTL(liveTTL))
+	if configuration.StoreDir != "" {
+		s.workflowStorePath = workflowStorePathIn(configuration.StoreDir)
+		// A store that cannot be read leaves the repositories in memory only
+		if err := hydrateRepositoryStore(NewFileRepoStore(repoStorePath(configuration.StoreDir))); err != nil {
+			klog.Errorf("Failed to load the repository store, repository configurations will not be persisted: %v", err)
+		}
+		if err := hydrateRegistryStore(registryStorePathIn(configuration.StoreDir)); err != nil {
+			klog.Errorf("Failed to load the registry store, registry configurations will not be persisted: %v", err)
+		}
 	}
+	configureRegistries(configuration.Registries)
+	s.initGitWatcher(configuration.StoreDir != "")
+	s.server = server.NewMCPServer(
+		version.BinaryName,
+		version.Version,
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
+		server.WithToolCapabilities(true),
+		server.WithLogging(),
+		server.WithToolHandlerMiddleware(toolCallLoggingMiddleware),
+		server.WithToolHandlerMiddleware(toolCallMetricsMiddleware),
+		server.WithToolHandlerMiddleware(s.toolCallAuditMiddleware),
+	)
 	if err := s.reloadKubernetesClient(); err != nil {
 		return nil, err
 	}
@@ -77,12 +119,15 @@ func (s *Server) reloadKubernetesClient() error {
 	}
 	s.k = k
 	applicableTools := make([]server.ServerTool, 0)
+	tools := make(map[string]server.ServerTool)
 	for _, tool := range s.configuration.Profile.GetTools(s) {
 		if !s.configuration.isToolApplicable(tool) {
 			continue
 		}
 		applicableTools = append(applicableTools, tool)
+		tools[tool.Tool.Name] = tool
 	}
+	s.tools = tools
 	s.server.SetTools(applicableTools...)
 	return nil
 }
@@ -127,6 +172,9 @@ func (s *Server) GetKubernetesAPIServerHost() string {
 }
 
 func (s *Server) Close() {
+	if s.stopGitPolling != nil {
+		s.stopGitPolling()
+	}
 	if s.k != nil {
 		s.k.Close()
 	}
@@ -155,6 +203,9 @@ func NewTextResult(content string, err error) *mcp.CallToolResult {
 }
 
 func contextFunc(ctx context.Context, r *http.Request) context.Context {
+	if user, ok := r.Context().Value(verifiedUserKey{}).(string); ok {
+		ctx = WithVerifiedUser(ctx, user)
+	}
 	// Get the standard Authorization header (OAuth compliant)
 	authHeader := r.Header.Get(string(internalk8s.OAuthAuthorizationHeader))
 	if authHeader != "" {
@@ -176,3 +227,13 @@ func toolCallLoggingMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFu
 		return next(ctx, ctr)
 	}
 }
+
+// toolCallMetricsMiddleware counts every tool call by result and records its duration
+func toolCallMetricsMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, ctr mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, ctr)
+		metrics.ObserveTool(ctr.Params.Name, start, err == nil && (result == nil || !result.IsError))
+		return result, err
+	}
+}
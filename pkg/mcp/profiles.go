@@ -39,14 +39,17 @@ func (p *FullProfile) GetDescription() string {
 func (p *FullProfile) GetTools(s *Server) []server.ServerTool {
 	return slices.Concat(
 		s.initConfiguration(),
+		s.initActivity(),
 		s.initEvents(),
 		s.initNamespaces(),
 		s.initPods(),
 		s.initResources(),
 		s.initHelm(),
 		s.initCicdSimple(),
+		s.initStateSnapshots(),
 		s.initContainers(),
 		s.initRegistryTools(),
+		s.initImageSecurity(),
 		s.initWorkflowTools(),
 	)
 }
@@ -62,12 +65,15 @@ func (p *CicdProfile) GetDescription() string {
 func (p *CicdProfile) GetTools(s *Server) []server.ServerTool {
 	return slices.Concat(
 		s.initConfiguration(),
+		s.initActivity(),
 		s.initNamespaces(),
 		s.initPods(),
 		s.initResources(),
 		s.initCicdSimple(),
+		s.initStateSnapshots(),
 		s.initContainers(),
 		s.initRegistryTools(),
+		s.initImageSecurity(),
 		s.initWorkflowTools(),
 	)
 }
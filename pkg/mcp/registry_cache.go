@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultRegistryCacheTTL is how long manifest and tag lookups are reused when not configured
+	defaultRegistryCacheTTL = 5 * time.Minute
+	// registryCacheCapacity bounds the number of cached registry lookups
+	registryCacheCapacity = 512
+)
+
+// registryCacheEntry is a cached registry lookup
+type registryCacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// registryCache is a bounded, concurrency-safe TTL cache for registry manifest, digest and tag
+// lookups keyed by image reference. The least recently used entry is evicted when full.
+type registryCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+func newRegistryCache(ttl time.Duration, capacity int) *registryCache {
+	return &registryCache{
+		ttl:      ttl,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// registryCacheTTL returns the configured lookup TTL, zero disables caching
+func registryCacheTTL(configuredTTL string) time.Duration {
+	if configuredTTL == "" {
+		return defaultRegistryCacheTTL
+	}
+	ttl, err := time.ParseDuration(configuredTTL)
+	if err != nil || ttl < 0 {
+		klog.Warningf("Invalid registry_cache_ttl %q, using %s", configuredTTL, defaultRegistryCacheTTL)
+		return defaultRegistryCacheTTL
+	}
+	return ttl
+}
+
+func registryCacheKey(kind, reference string) string {
+	return kind + "|" + reference
+}
+
+func (c *registryCache) get(key string) (interface{}, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*registryCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *registryCache) put(key string, value interface{}) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*registryCacheEntry)
+		entry.value = value
+		entry.expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&registryCacheEntry{key: key, value: value, expiresAt: time.Now().Add(c.ttl)})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*registryCacheEntry).key)
+	}
+}
+
+// lookup returns the cached value for key, or calls fetch and caches its result on success
+func (c *registryCache) lookup(key string, noCache bool, fetch func() (interface{}, error)) (interface{}, error) {
+	if !noCache {
+		if value, ok := c.get(key); ok {
+			return value, nil
+		}
+	}
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, value)
+	return value, nil
+}
+
+// invalidate drops every cached lookup for an image reference and the tag list of its repository,
+// e.g. after the reference was pushed to
+func (c *registryCache) invalidate(reference string) {
+	if c == nil {
+		return
+	}
+	repository := imageRepository(reference)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, element := range c.entries {
+		_, ref, _ := strings.Cut(key, "|")
+		if ref == reference || ref == repository || strings.HasPrefix(ref, repository+"@") {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"os"
+	"strings"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+// registryHost strips the scheme and any path from a registry URL
+func registryHost(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	host, _, _ := strings.Cut(registry, "/")
+	return host
+}
+
+// splitRepository splits a repository reference such as quay.io/org/app into the registry host and
+// repository name. References without a registry host refer to Docker Hub.
+func splitRepository(repository string) (string, string) {
+	repository = strings.TrimPrefix(strings.TrimPrefix(repository, "https://"), "http://")
+	first, rest, found := strings.Cut(repository, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		if first == "docker.io" && !strings.Contains(rest, "/") {
+			rest = "library/" + rest
+		}
+		return first, rest
+	}
+	if !found {
+		return "docker.io", "library/" + repository
+	}
+	return "docker.io", repository
+}
+
+// newRegistryClient returns a client for a registry name or URL, using the credentials stored by
+// registry_configure, or REGISTRY_USERNAME/REGISTRY_PASSWORD, or those stored by registry_login
+func newRegistryClient(registry string) *cicd.RegistryClient {
+	host := registryHost(registry)
+	insecure := strings.HasPrefix(registry, "http://")
+	var username, password string
+	if configured := lookupConfiguredRegistry(registry); configured != nil {
+		host = registryHost(configured.info.URL)
+		// Passwords are not persisted with the registry, after a restart they come from the credentials file
+		username, password = registryCredentials(host, configured.info.Metadata["username"], configured.password)
+		insecure = insecure || configured.info.Metadata["secure"] == "false"
+	} else {
+		username, password = registryCredentials(host, os.Getenv("REGISTRY_USERNAME"), os.Getenv("REGISTRY_PASSWORD"))
+	}
+	if insecure {
+		return cicd.NewRegistryClient("http://"+host, username, password)
+	}
+	return cicd.NewRegistryClient(host, username, password)
+}
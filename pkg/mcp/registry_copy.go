@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// imageCopyOptions holds the credentials and TLS settings of both ends of an image copy
+type imageCopyOptions struct {
+	SourceUsername string
+	SourcePassword string
+	TargetUsername string
+	TargetPassword string
+	SrcTLSVerify   bool
+	DestTLSVerify  bool
+}
+
+// ImageCopy is an image copied by registry_copy
+type ImageCopy struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Digest string `json:"digest,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// copyImageWithSkopeo copies an image, with every platform of a manifest list, and returns the
+// digest written to the target
+func copyImageWithSkopeo(ctx context.Context, source, target string, opts imageCopyOptions) (string, error) {
+	digestFile, err := os.CreateTemp("", "registry-copy-digest-*")
+	if err != nil {
+		return "", err
+	}
+	_ = digestFile.Close()
+	defer func() { _ = os.Remove(digestFile.Name()) }()
+
+	// Credentials go through temporary auth files, arguments are visible to every local user
+	args := []string{"copy", "--all", "--digestfile", digestFile.Name()}
+	if opts.SourceUsername != "" {
+		host, _ := splitRepository(imageRepository(source))
+		authFile, remove, err := tempRegistryAuthFile(host, opts.SourceUsername, opts.SourcePassword)
+		if err != nil {
+			return "", err
+		}
+		defer remove()
+		args = append(args, "--src-authfile", authFile)
+	}
+	if opts.TargetUsername != "" {
+		host, _ := splitRepository(imageRepository(target))
+		authFile, remove, err := tempRegistryAuthFile(host, opts.TargetUsername, opts.TargetPassword)
+		if err != nil {
+			return "", err
+		}
+		defer remove()
+		args = append(args, "--dest-authfile", authFile)
+	}
+	if !opts.SrcTLSVerify {
+		args = append(args, "--src-tls-verify=false")
+	}
+	if !opts.DestTLSVerify {
+		args = append(args, "--dest-tls-verify=false")
+	}
+	args = append(args, "docker://"+source, "docker://"+target)
+	if output, err := exec.CommandContext(ctx, "skopeo", args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("skopeo copy failed: %s", strings.TrimSpace(string(output)))
+	}
+	digest, err := os.ReadFile(digestFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read the copied digest: %v", err)
+	}
+	return strings.TrimSpace(string(digest)), nil
+}
+
+// orasRepository opens a remote repository for an image and returns it with the image tag or digest
+func orasRepository(image, username, password string, tlsVerify bool) (*remote.Repository, string, error) {
+	repository, reference := imageReference(image)
+	host, name := splitRepository(repository)
+	repo, err := remote.NewRepository(host + "/" + name)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image '%s': %v", image, err)
+	}
+	httpClient := retry.DefaultClient
+	if !tlsVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // requested for internal registries
+		httpClient = &http.Client{Transport: retry.NewTransport(transport)}
+	}
+	client := &auth.Client{Client: httpClient, Cache: auth.NewCache()}
+	if username != "" {
+		client.Credential = auth.StaticCredential(repo.Reference.Host(), auth.Credential{Username: username, Password: password})
+	}
+	repo.Client = client
+	return repo, reference, nil
+}
+
+// copyImageInProcess copies an image between registries through the Registry v2 API, keeping the
+// manifest list of a multi-arch image and every manifest and blob it references
+func copyImageInProcess(ctx context.Context, source, target string, opts imageCopyOptions) (string, error) {
+	src, srcRef, err := orasRepository(source, opts.SourceUsername, opts.SourcePassword, opts.SrcTLSVerify)
+	if err != nil {
+		return "", err
+	}
+	dst, dstRef, err := orasRepository(target, opts.TargetUsername, opts.TargetPassword, opts.DestTLSVerify)
+	if err != nil {
+		return "", err
+	}
+	desc, err := oras.Copy(ctx, src, srcRef, dst, dstRef, oras.DefaultCopyOptions)
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+// registryCopy handles copying images between registries without pulling them locally
+func (s *Server) registryCopy(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	source, ok := args["source_image"].(string)
+	if !ok || source == "" {
+		return NewTextResult("", fmt.Errorf("source_image parameter is required")), nil
+	}
+	target, ok := args["target_image"].(string)
+	if !ok || target == "" {
+		return NewTextResult("", fmt.Errorf("target_image parameter is required")), nil
+	}
+	allTags := getBoolArg(args, "all_tags", false)
+
+	// Credentials not given fall back to the ones stored by registry_login for each registry
+	sourceHost, sourceName := splitRepository(imageRepository(source))
+	targetHost, _ := splitRepository(imageRepository(target))
+	opts := imageCopyOptions{SrcTLSVerify: getBoolArg(args, "src_tls_verify", true), DestTLSVerify: getBoolArg(args, "dest_tls_verify", true)}
+	opts.SourceUsername, opts.SourcePassword = registryCredentials(sourceHost, getStringArg(args, "source_username", ""), getStringArg(args, "source_password", ""))
+	opts.TargetUsername, opts.TargetPassword = registryCredentials(targetHost, getStringArg(args, "target_username", ""), getStringArg(args, "target_password", ""))
+
+	method := getStringArg(args, "method", "auto")
+	switch method {
+	case "auto":
+		method = "in-process"
+		if _, err := exec.LookPath("skopeo"); err == nil {
+			method = "skopeo"
+		}
+	case "skopeo":
+		if _, err := exec.LookPath("skopeo"); err != nil {
+			return NewTextResult("", fmt.Errorf("skopeo not found in PATH, use method 'in-process' instead")), nil
+		}
+	case "in-process":
+	default:
+		return NewTextResult("", fmt.Errorf("method must be 'auto', 'skopeo' or 'in-process', got '%s'", method)), nil
+	}
+	copyImage := copyImageInProcess
+	if method == "skopeo" {
+		copyImage = copyImageWithSkopeo
+	}
+
+	// Pairs of source and target images, every tag of the source repository in all_tags mode
+	pairs := [][2]string{{source, target}}
+	if allTags {
+		if imageRepository(source) != source || imageRepository(target) != target {
+			return NewTextResult("", fmt.Errorf("all_tags copies whole repositories, give source_image and target_image without tag or digest")), nil
+		}
+		client := newRegistryClient(sourceHost)
+		client.Username, client.Password = opts.SourceUsername, opts.SourcePassword
+		tags, err := client.Tags(ctx, sourceName)
+		if err != nil {
+			return NewTextResult("", fmt.Errorf("failed to list the tags of %s: %v", source, err)), nil
+		}
+		pairs = pairs[:0]
+		for _, tag := range tags {
+			pairs = append(pairs, [2]string{source + ":" + tag, target + ":" + tag})
+		}
+	} else if imageRepository(target) == target {
+		// A target without tag keeps the source tag
+		if _, sourceReference := imageReference(source); !strings.HasPrefix(sourceReference, "sha256:") {
+			pairs[0][1] = target + ":" + sourceReference
+		}
+	}
+
+	copies := make([]ImageCopy, 0, len(pairs))
+	failed := 0
+	for _, pair := range pairs {
+		klog.V(2).Infof("Copying image %s to %s (%s)", pair[0], pair[1], method)
+		copied := ImageCopy{Source: pair[0], Target: pair[1]}
+		digest, err := copyImage(ctx, pair[0], pair[1], opts)
+		if err != nil {
+			copied.Error = err.Error()
+			failed++
+		} else {
+			s.registryCache.invalidate(pair[1])
+		}
+		copied.Digest = digest
+		copies = append(copies, copied)
+	}
+
+	result := map[string]interface{}{
+		"status": "success",
+		"method": method,
+		"copied": len(copies) - failed,
+		"images": copies,
+	}
+	if failed == len(copies) && failed > 0 {
+		return NewTextResult("", fmt.Errorf("failed to copy %s to %s: %s", source, target, copies[0].Error)), nil
+	}
+	if failed > 0 {
+		result["status"] = "partial"
+		result["failed"] = failed
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
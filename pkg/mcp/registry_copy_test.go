@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTempRegistryAuthFile(t *testing.T) {
+	path, remove, err := tempRegistryAuthFile("https://quay.io", "user", "p@ss:word")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the auth file to be readable by its owner only, got %v", info.Mode().Perm())
+	}
+	auths, err := readRegistryAuthFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry, ok := auths.Auths["quay.io"]; !ok || entry.Auth != "dXNlcjpwQHNzOndvcmQ=" {
+		t.Errorf("unexpected auths %+v", auths.Auths)
+	}
+	remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the auth file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// registryCredentialsPathEnv overrides the file registry_login stores credentials in
+const registryCredentialsPathEnv = "REGISTRY_CREDENTIALS_PATH"
+
+// registryCredentialsMu serializes reads and writes of the credentials file
+var registryCredentialsMu sync.Mutex
+
+// registryAuthFile is the stored credentials file, in the format of the 'auths' section of a
+// Docker config.json so it can also be passed to podman/skopeo with --authfile
+type registryAuthFile struct {
+	Auths map[string]registryAuthEntry `json:"auths"`
+}
+
+type registryAuthEntry struct {
+	Auth string `json:"auth"` // base64 of username:password
+}
+
+// registryCredentialsPath returns $REGISTRY_CREDENTIALS_PATH, or ~/.openshift-mcp/registry-auth.json
+func registryCredentialsPath() string {
+	if path := os.Getenv(registryCredentialsPathEnv); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".openshift-mcp", "registry-auth.json")
+}
+
+// readRegistryAuthFile returns the stored credentials, an empty set when the file does not exist yet
+func readRegistryAuthFile(path string) (*registryAuthFile, error) {
+	auths := &registryAuthFile{Auths: make(map[string]registryAuthEntry)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return auths, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry credentials %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, auths); err != nil {
+		return nil, fmt.Errorf("failed to parse registry credentials %s: %w", path, err)
+	}
+	if auths.Auths == nil {
+		auths.Auths = make(map[string]registryAuthEntry)
+	}
+	return auths, nil
+}
+
+// storeRegistryCredentials saves credentials for a registry host in a file only readable by the
+// current user
+func storeRegistryCredentials(host, username, password string) (string, error) {
+	registryCredentialsMu.Lock()
+	defer registryCredentialsMu.Unlock()
+	path := registryCredentialsPath()
+	auths, err := readRegistryAuthFile(path)
+	if err != nil {
+		return path, err
+	}
+	auths.Auths[host] = registryAuthEntry{Auth: base64.StdEncoding.EncodeToString([]byte(username + ":" + password))}
+	data, err := json.MarshalIndent(auths, "", "  ")
+	if err != nil {
+		return path, err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return path, fmt.Errorf("failed to write registry credentials %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// storedRegistryCredentials returns the credentials registry_login stored for a registry host
+func storedRegistryCredentials(host string) (string, string, bool) {
+	registryCredentialsMu.Lock()
+	defer registryCredentialsMu.Unlock()
+	auths, err := readRegistryAuthFile(registryCredentialsPath())
+	if err != nil {
+		return "", "", false
+	}
+	entry, exists := auths.Auths[host]
+	if !exists {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return "", "", false
+	}
+	username, password, found := strings.Cut(string(decoded), ":")
+	return username, password, found
+}
+
+// registryCredentials returns the given credentials, or the stored ones for the registry when
+// none were given
+func registryCredentials(registry, username, password string) (string, string) {
+	if username != "" && password != "" {
+		return username, password
+	}
+	if storedUsername, storedPassword, ok := storedRegistryCredentials(registryHost(registry)); ok {
+		return storedUsername, storedPassword
+	}
+	return username, password
+}
+
+// tempRegistryAuthFile writes the credentials of a registry host to a temporary auth file, readable
+// only by the current user, so they can be handed to tools such as skopeo without appearing in
+// their arguments. The returned function removes the file.
+func tempRegistryAuthFile(host, username, password string) (string, func(), error) {
+	file, err := os.CreateTemp("", "registry-auth-*.json")
+	if err != nil {
+		return "", nil, err
+	}
+	remove := func() { _ = os.Remove(file.Name()) }
+	auths := registryAuthFile{Auths: map[string]registryAuthEntry{
+		registryHost(host): {Auth: base64.StdEncoding.EncodeToString([]byte(username + ":" + password))},
+	}}
+	err = file.Chmod(0o600)
+	if err == nil {
+		err = json.NewEncoder(file).Encode(auths)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		remove()
+		return "", nil, fmt.Errorf("failed to write temporary registry credentials: %w", err)
+	}
+	return file.Name(), remove, nil
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+// ManifestInspection describes the manifest, or manifest list, of a remote image
+type ManifestInspection struct {
+	Image               string             `json:"image"`
+	Digest              string             `json:"digest"`
+	MediaType           string             `json:"media_type"`
+	MultiArch           bool               `json:"multi_arch"`
+	Annotations         map[string]string  `json:"annotations,omitempty"`
+	Platforms           []PlatformManifest `json:"platforms"`
+	TotalCompressedSize int64              `json:"total_compressed_size"` // layers and configs of every platform
+	TotalSize           string             `json:"total_size"`
+}
+
+// PlatformManifest is the image manifest of one platform
+type PlatformManifest struct {
+	Platform       string            `json:"platform"`
+	Digest         string            `json:"digest"`
+	MediaType      string            `json:"media_type"`
+	Layers         int               `json:"layers"`
+	CompressedSize int64             `json:"compressed_size"`
+	Size           string            `json:"size"`
+	Created        *time.Time        `json:"created,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"` // from the image config
+	Error          string            `json:"error,omitempty"`
+}
+
+// inspectImageManifest fetches the manifest of an image and, for a manifest list, the manifest of
+// every platform. Image configs are read for their labels unless skipConfig is set.
+func inspectImageManifest(ctx context.Context, client *cicd.RegistryClient, name, reference string, skipConfig bool) (*ManifestInspection, error) {
+	manifest, digest, err := client.Manifest(ctx, name, reference)
+	if err != nil {
+		return nil, err
+	}
+	if digest == "" && strings.HasPrefix(reference, "sha256:") {
+		digest = reference
+	}
+	inspection := &ManifestInspection{
+		Image:       client.Host + "/" + name + imageReferenceSuffix(reference),
+		Digest:      digest,
+		MediaType:   manifest.MediaType,
+		MultiArch:   len(manifest.Manifests) > 0,
+		Annotations: manifest.Annotations,
+		Platforms:   make([]PlatformManifest, 0),
+	}
+
+	describe := func(platform PlatformManifest, manifest *cicd.RegistryManifest) PlatformManifest {
+		platform.Layers = len(manifest.Layers)
+		platform.CompressedSize = manifest.Config.Size
+		for _, layer := range manifest.Layers {
+			platform.CompressedSize += layer.Size
+		}
+		platform.Size = units.HumanSize(float64(platform.CompressedSize))
+		if len(manifest.Annotations) > 0 {
+			platform.Annotations = manifest.Annotations
+		}
+		if !skipConfig && manifest.Config.Digest != "" {
+			if config, err := client.ImageConfig(ctx, name, manifest.Config.Digest); err != nil {
+				platform.Error = fmt.Sprintf("failed to read image config: %v", err)
+			} else {
+				if !config.Created.IsZero() {
+					platform.Created = &config.Created
+				}
+				platform.Labels = config.Config.Labels
+				if platform.Platform == "" {
+					platform.Platform = config.OS + "/" + config.Architecture
+				}
+			}
+		}
+		return platform
+	}
+
+	if !inspection.MultiArch {
+		inspection.Platforms = append(inspection.Platforms, describe(PlatformManifest{Digest: digest, MediaType: manifest.MediaType}, manifest))
+	}
+	for _, entry := range manifest.Manifests {
+		platform := PlatformManifest{Digest: entry.Digest, MediaType: entry.MediaType, Annotations: entry.Annotations}
+		if entry.Platform.OS != "" {
+			platform.Platform = entry.Platform.OS + "/" + entry.Platform.Architecture
+			if entry.Platform.Variant != "" {
+				platform.Platform += "/" + entry.Platform.Variant
+			}
+		}
+		child, _, err := client.Manifest(ctx, name, entry.Digest)
+		if err != nil {
+			platform.Error = err.Error()
+			inspection.Platforms = append(inspection.Platforms, platform)
+			continue
+		}
+		inspection.Platforms = append(inspection.Platforms, describe(platform, child))
+	}
+	for _, platform := range inspection.Platforms {
+		inspection.TotalCompressedSize += platform.CompressedSize
+	}
+	inspection.TotalSize = units.HumanSize(float64(inspection.TotalCompressedSize))
+	return inspection, nil
+}
+
+// imageReferenceSuffix formats a tag or digest to append to a repository
+func imageReferenceSuffix(reference string) string {
+	if strings.HasPrefix(reference, "sha256:") {
+		return "@" + reference
+	}
+	return ":" + reference
+}
+
+// registryManifest handles inspecting the manifest of a remote image
+func (s *Server) registryManifest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	image, ok := args["image"].(string)
+	if !ok || image == "" {
+		return NewTextResult("", fmt.Errorf("image parameter is required")), nil
+	}
+	repository, reference := imageReference(image)
+	host, name := splitRepository(repository)
+
+	klog.V(2).Infof("Inspecting manifest of %s/%s%s", host, name, imageReferenceSuffix(reference))
+	inspection, err := inspectImageManifest(ctx, newRegistryClient(host), name, reference, !getBoolArg(args, "include_config", true))
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to fetch the manifest of %s: %v", image, err)), nil
+	}
+
+	if platform := getStringArg(args, "platform", ""); platform != "" {
+		selected := make([]PlatformManifest, 0, 1)
+		for _, p := range inspection.Platforms {
+			if p.Platform == platform {
+				selected = append(selected, p)
+			}
+		}
+		if len(selected) == 0 {
+			return NewTextResult("", fmt.Errorf("%s has no %s image", image, platform)), nil
+		}
+		inspection.Platforms = selected
+	}
+
+	jsonResult, _ := json.MarshalIndent(inspection, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
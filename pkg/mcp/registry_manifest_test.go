@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestInspectImageManifest(t *testing.T) {
+	t.Setenv(registryCredentialsPathEnv, filepath.Join(t.TempDir(), "auth.json"))
+	documents := map[string]struct{ contentType, body string }{
+		"/v2/team/app/manifests/v1": {"application/vnd.oci.image.index.v1+json", `{"schemaVersion": 2,
+			"mediaType": "application/vnd.oci.image.index.v1+json",
+			"annotations": {"org.opencontainers.image.source": "https://github.com/team/app"},
+			"manifests": [
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:amd64", "size": 500, "platform": {"os": "linux", "architecture": "amd64"}},
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:arm64", "size": 500, "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}}
+			]}`},
+		"/v2/team/app/manifests/sha256:amd64": {"application/vnd.oci.image.manifest.v1+json", `{"schemaVersion": 2,
+			"config": {"digest": "sha256:config", "size": 100},
+			"layers": [{"size": 1000}, {"size": 2000}]}`},
+		"/v2/team/app/manifests/sha256:arm64": {"application/vnd.docker.distribution.manifest.v2+json; charset=utf-8", `{"schemaVersion": 2,
+			"config": {"digest": "sha256:config", "size": 100},
+			"layers": [{"size": 4000}]}`},
+		"/v2/team/app/blobs/sha256:config": {"application/json", `{"os": "linux", "architecture": "amd64",
+			"created": "2025-01-02T03:04:05Z", "config": {"Labels": {"version": "1.0"}}}`},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		document, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", document.contentType)
+		if r.URL.Path == "/v2/team/app/manifests/v1" {
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+		}
+		_, _ = w.Write([]byte(document.body))
+	}))
+	defer server.Close()
+
+	inspection, err := inspectImageManifest(context.Background(), newRegistryClient(server.URL), "team/app", "v1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !inspection.MultiArch || inspection.Digest != "sha256:index" || inspection.MediaType != "application/vnd.oci.image.index.v1+json" {
+		t.Errorf("unexpected manifest list %+v", inspection)
+	}
+	if inspection.Annotations["org.opencontainers.image.source"] != "https://github.com/team/app" {
+		t.Errorf("expected the index annotations, got %v", inspection.Annotations)
+	}
+	if len(inspection.Platforms) != 2 {
+		t.Fatalf("expected 2 platforms, got %d", len(inspection.Platforms))
+	}
+	amd64, arm64 := inspection.Platforms[0], inspection.Platforms[1]
+	if amd64.Platform != "linux/amd64" || amd64.CompressedSize != 3100 || amd64.Layers != 2 || amd64.Labels["version"] != "1.0" || amd64.Created == nil {
+		t.Errorf("unexpected linux/amd64 image %+v", amd64)
+	}
+	if arm64.Platform != "linux/arm64/v8" || arm64.CompressedSize != 4100 {
+		t.Errorf("unexpected linux/arm64 image %+v", arm64)
+	}
+	if inspection.TotalCompressedSize != 7200 {
+		t.Errorf("expected a total compressed size of 7200, got %d", inspection.TotalCompressedSize)
+	}
+
+	single, err := inspectImageManifest(context.Background(), newRegistryClient(server.URL), "team/app", "sha256:arm64", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if single.MultiArch || single.Digest != "sha256:arm64" || single.MediaType != "application/vnd.docker.distribution.manifest.v2+json" {
+		t.Errorf("unexpected single image manifest %+v", single)
+	}
+	if len(single.Platforms) != 1 || single.Platforms[0].Labels != nil || single.Platforms[0].CompressedSize != 4100 {
+		t.Errorf("unexpected single image platforms %+v", single.Platforms)
+	}
+}
@@ -0,0 +1,287 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
+)
+
+const (
+	// dockerHubSearchURL is the Docker Hub repository search endpoint
+	dockerHubSearchURL = "https://hub.docker.com/v2/search/repositories/"
+	// quaySearchURL is the Quay repository search endpoint
+	quaySearchURL = "https://quay.io/api/v1/find/repositories"
+	// quaySearchMaxPages bounds the pages read from the Quay search API, which returns 10 results per page
+	quaySearchMaxPages = 5
+)
+
+// SearchResult is a repository found by registry_search, normalized across registries
+type SearchResult struct {
+	Name          string   `json:"name"`
+	Registry      string   `json:"registry"`
+	Description   string   `json:"description"`
+	Stars         int      `json:"stars"`
+	Pulls         int64    `json:"pulls"`
+	Official      bool     `json:"official"`
+	Architectures []string `json:"architectures,omitempty"`
+}
+
+// SearchSkip is a registry that could not be searched
+type SearchSkip struct {
+	Registry string `json:"registry"`
+	Error    string `json:"error"`
+}
+
+// searchRegistries returns the registries searched when none is given: Docker Hub, Quay and every
+// registry configured with registry_configure
+func searchRegistries() []string {
+	registries := []string{"docker.io", "quay.io"}
+	for _, configured := range listConfiguredRegistries() {
+		host := registryHost(configured.URL)
+		if !slices.Contains(registries, host) {
+			registries = append(registries, host)
+		}
+	}
+	sort.Strings(registries[2:])
+	return registries
+}
+
+// searchRegistry searches one registry. Docker Hub and Quay have search APIs; other registries are
+// searched by matching the query against their catalog.
+func searchRegistry(ctx context.Context, registry, query string, limit int) ([]SearchResult, error) {
+	client := newRegistryClient(registry)
+	switch client.Host {
+	case "docker.io", "index.docker.io":
+		return searchDockerHub(ctx, client, query, limit)
+	case "quay.io":
+		return searchQuay(ctx, client, query, limit)
+	}
+	names, _, err := client.Catalog(ctx, limit, func(name string) bool {
+		return strings.Contains(strings.ToLower(name), strings.ToLower(query))
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(names))
+	for _, name := range names {
+		results = append(results, SearchResult{Name: name, Registry: client.Host})
+	}
+	return results, nil
+}
+
+func searchDockerHub(ctx context.Context, client *cicd.RegistryClient, query string, limit int) ([]SearchResult, error) {
+	var body struct {
+		Results []struct {
+			RepoName         string `json:"repo_name"`
+			ShortDescription string `json:"short_description"`
+			StarCount        int    `json:"star_count"`
+			PullCount        int64  `json:"pull_count"`
+			IsOfficial       bool   `json:"is_official"`
+		} `json:"results"`
+	}
+	params := url.Values{"query": {query}, "page_size": {fmt.Sprint(limit)}}
+	if err := searchGetJSON(ctx, client.HTTPClient, dockerHubSearchURL+"?"+params.Encode(), &body); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(body.Results))
+	for _, r := range body.Results {
+		results = append(results, SearchResult{
+			Name:        r.RepoName,
+			Registry:    "docker.io",
+			Description: r.ShortDescription,
+			Stars:       r.StarCount,
+			Pulls:       r.PullCount,
+			Official:    r.IsOfficial,
+		})
+	}
+	return results, nil
+}
+
+func searchQuay(ctx context.Context, client *cicd.RegistryClient, query string, limit int) ([]SearchResult, error) {
+	results := make([]SearchResult, 0)
+	for page := 1; page <= quaySearchMaxPages && len(results) < limit; page++ {
+		var body struct {
+			Results []struct {
+				Kind      string `json:"kind"`
+				Name      string `json:"name"`
+				Namespace struct {
+					Name string `json:"name"`
+				} `json:"namespace"`
+				Description string `json:"description"`
+				Stars       int    `json:"stars"`
+			} `json:"results"`
+			HasAdditional bool `json:"has_additional"`
+		}
+		params := url.Values{"query": {query}, "page": {fmt.Sprint(page)}}
+		if err := searchGetJSON(ctx, client.HTTPClient, quaySearchURL+"?"+params.Encode(), &body); err != nil {
+			return nil, err
+		}
+		for _, r := range body.Results {
+			if r.Kind != "repository" || len(results) >= limit {
+				continue
+			}
+			results = append(results, SearchResult{
+				Name:        r.Namespace.Name + "/" + r.Name,
+				Registry:    "quay.io",
+				Description: r.Description,
+				Stars:       r.Stars,
+			})
+		}
+		if !body.HasAdditional {
+			break
+		}
+	}
+	return results, nil
+}
+
+// searchGetJSON fetches a search API URL into v
+func searchGetJSON(ctx context.Context, client *http.Client, searchURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("search request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("search API returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse search response: %v", err)
+	}
+	return nil
+}
+
+// searchAll searches the registries concurrently. Registries whose search fails are reported as
+// skipped instead of failing the whole search.
+func searchAll(ctx context.Context, registries []string, query string, limit int) ([]SearchResult, []SearchSkip) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make([]SearchResult, 0)
+	skipped := make([]SearchSkip, 0)
+	for _, registry := range registries {
+		wg.Add(1)
+		go func(registry string) {
+			defer wg.Done()
+			found, err := searchRegistry(ctx, registry, query, limit)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				skipped = append(skipped, SearchSkip{Registry: registry, Error: err.Error()})
+				return
+			}
+			results = append(results, found...)
+		}(registry)
+	}
+	wg.Wait()
+
+	// Official images first, then by popularity
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Official != results[j].Official {
+			return results[i].Official
+		}
+		if results[i].Stars != results[j].Stars {
+			return results[i].Stars > results[j].Stars
+		}
+		return results[i].Pulls > results[j].Pulls
+	})
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Registry < skipped[j].Registry })
+	return results, skipped
+}
+
+// imagePlatforms returns the os/arch[/variant] platforms of an image reference. Multi-arch images list
+// them in their index; single-arch images only record theirs in the image config.
+func imagePlatforms(ctx context.Context, c *cicd.RegistryClient, name, reference string) ([]string, error) {
+	manifest, _, err := c.Manifest(ctx, name, reference)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Manifests) == 0 {
+		metadata, err := c.TagMetadata(ctx, name, reference)
+		if err != nil {
+			return nil, err
+		}
+		return []string{metadata.OS + "/" + metadata.Arch}, nil
+	}
+	platforms := make([]string, 0, len(manifest.Manifests))
+	for _, m := range manifest.Manifests {
+		// Attestation manifests are listed with an unknown platform
+		if m.Platform.Architecture == "" || m.Platform.Architecture == "unknown" {
+			continue
+		}
+		platform := m.Platform.OS + "/" + m.Platform.Architecture
+		if m.Platform.Variant != "" {
+			platform += "/" + m.Platform.Variant
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms, nil
+}
+
+// supportsArchitecture reports whether any platform matches an architecture such as 'arm64',
+// 'arm' (any variant) or 'linux/arm/v7'
+func supportsArchitecture(platforms []string, architecture string) bool {
+	for _, platform := range platforms {
+		arch := strings.TrimPrefix(platform, "linux/")
+		if platform == architecture || arch == architecture || strings.HasPrefix(arch, architecture+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByArchitecture keeps the results whose latest tag supports architecture, reading the
+// manifests with a bounded number of workers. Results whose manifest cannot be read are dropped
+// and counted.
+func (s *Server) filterByArchitecture(ctx context.Context, results []SearchResult, architecture string) ([]SearchResult, int) {
+	var wg sync.WaitGroup
+	work := make(chan int)
+	unknown := make([]bool, len(results))
+	for i := 0; i < registryMetadataWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				host, name := splitRepository(results[index].Registry + "/" + results[index].Name)
+				reference := host + "/" + name + ":latest"
+				value, err := s.registryCache.lookup(registryCacheKey("platforms", reference), false, func() (interface{}, error) {
+					return imagePlatforms(ctx, newRegistryClient(host), name, "latest")
+				})
+				if err != nil {
+					unknown[index] = true
+					continue
+				}
+				results[index].Architectures = value.([]string)
+			}
+		}()
+	}
+	for i := range results {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	filtered := make([]SearchResult, 0, len(results))
+	skipped := 0
+	for i, result := range results {
+		if unknown[i] {
+			skipped++
+			continue
+		}
+		if supportsArchitecture(result.Architectures, architecture) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered, skipped
+}
@@ -0,0 +1,210 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// registryStorePathEnv overrides the file configured registries are persisted to
+const registryStorePathEnv = "REGISTRY_STORE_PATH"
+
+// configuredRegistry is a registry stored by registry_configure, including the password that is
+// never returned in tool results. Only the info is persisted, passwords go to the credentials file.
+type configuredRegistry struct {
+	info     *RegistryInfo
+	password string
+}
+
+// RegistryConfig is a registry configured when the server starts, as with registry_configure. The
+// password is read from the environment variable named by PasswordEnv so it is never kept in a
+// configuration file.
+type RegistryConfig struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Type        string `json:"type,omitempty"`
+	Username    string `json:"username,omitempty"`
+	PasswordEnv string `json:"password_env,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Insecure    bool   `json:"insecure,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+}
+
+// configureRegistries configures the registries of the server configuration, replacing stored
+// registries of the same name
+func configureRegistries(registries []RegistryConfig) {
+	for _, registry := range registries {
+		password := ""
+		if registry.PasswordEnv != "" {
+			password = os.Getenv(registry.PasswordEnv)
+		}
+		if _, _, err := configureRegistry(registry, password); err != nil {
+			klog.Errorf("Failed to configure registry %s: %v", registry.Name, err)
+		}
+	}
+}
+
+var (
+	// configuredRegistriesMu guards configuredRegistries and registryStorePath
+	configuredRegistriesMu sync.RWMutex
+	configuredRegistries   = make(map[string]*configuredRegistry)
+	// registryStorePath is where configuredRegistries is flushed to, empty keeps it in memory only
+	registryStorePath string
+)
+
+// registryStorePathIn returns $REGISTRY_STORE_PATH, or registries.json in dir
+func registryStorePathIn(dir string) string {
+	if path := os.Getenv(registryStorePathEnv); path != "" {
+		return path
+	}
+	return filepath.Join(dir, "registries.json")
+}
+
+// hydrateRegistryStore loads the registries persisted at path and persists every later change there
+func hydrateRegistryStore(path string) error {
+	registries := make(map[string]*RegistryInfo)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read registry store %s: %w", path, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &registries); err != nil {
+			return fmt.Errorf("failed to parse registry store %s: %w", path, err)
+		}
+	}
+	configuredRegistriesMu.Lock()
+	defer configuredRegistriesMu.Unlock()
+	for name, info := range registries {
+		configuredRegistries[name] = &configuredRegistry{info: info}
+	}
+	registryStorePath = path
+	klog.V(1).Infof("Loaded %d registries from the registry store", len(registries))
+	return nil
+}
+
+// saveConfiguredRegistry stores and persists a registry configuration. A default registry replaces
+// the previous default.
+func saveConfiguredRegistry(info *RegistryInfo, password string) error {
+	configuredRegistriesMu.Lock()
+	defer configuredRegistriesMu.Unlock()
+	if info.Metadata["default"] == "true" {
+		for _, configured := range configuredRegistries {
+			if configured.info.Metadata != nil {
+				configured.info.Metadata["default"] = "false"
+			}
+		}
+	}
+	configuredRegistries[info.Name] = &configuredRegistry{info: info, password: password}
+	return flushRegistryStore()
+}
+
+// flushRegistryStore writes the configured registries to the registry store, if any; callers must
+// hold configuredRegistriesMu
+func flushRegistryStore() error {
+	if registryStorePath == "" {
+		return nil
+	}
+	registries := make(map[string]*RegistryInfo, len(configuredRegistries))
+	for name, configured := range configuredRegistries {
+		registries[name] = configured.info
+	}
+	data, err := json.MarshalIndent(registries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(registryStorePath, data); err != nil {
+		return fmt.Errorf("failed to write registry store %s: %w", registryStorePath, err)
+	}
+	return nil
+}
+
+// snapshotConfiguredRegistries returns deep copies of the configured registries and their passwords
+// by name
+func snapshotConfiguredRegistries() (map[string]*RegistryInfo, map[string]string, error) {
+	configuredRegistriesMu.RLock()
+	defer configuredRegistriesMu.RUnlock()
+	registries := make(map[string]*RegistryInfo, len(configuredRegistries))
+	passwords := make(map[string]string)
+	for name, configured := range configuredRegistries {
+		registries[name] = configured.info
+		if configured.password != "" {
+			passwords[name] = configured.password
+		}
+	}
+	copied, err := copyRegistryInfos(registries)
+	return copied, passwords, err
+}
+
+// restoreConfiguredRegistries replaces the configured registries and persists them
+func restoreConfiguredRegistries(registries map[string]*RegistryInfo, passwords map[string]string) error {
+	configuredRegistriesMu.Lock()
+	defer configuredRegistriesMu.Unlock()
+	configuredRegistries = make(map[string]*configuredRegistry, len(registries))
+	for name, info := range registries {
+		configuredRegistries[name] = &configuredRegistry{info: info, password: passwords[name]}
+	}
+	return flushRegistryStore()
+}
+
+// copyRegistryInfos returns a deep copy of registry configurations
+func copyRegistryInfos(registries map[string]*RegistryInfo) (map[string]*RegistryInfo, error) {
+	data, err := json.Marshal(registries)
+	if err != nil {
+		return nil, err
+	}
+	copied := make(map[string]*RegistryInfo, len(registries))
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// lookupConfiguredRegistry finds a configured registry by name or URL
+func lookupConfiguredRegistry(registry string) *configuredRegistry {
+	configuredRegistriesMu.RLock()
+	defer configuredRegistriesMu.RUnlock()
+	if configured, exists := configuredRegistries[registry]; exists {
+		return configured
+	}
+	host := registryHost(registry)
+	for _, configured := range configuredRegistries {
+		if registryHost(configured.info.URL) == host {
+			return configured
+		}
+	}
+	return nil
+}
+
+// listConfiguredRegistries returns copies of the configured registries sorted by name
+func listConfiguredRegistries() []RegistryInfo {
+	configuredRegistriesMu.RLock()
+	defer configuredRegistriesMu.RUnlock()
+	registries := make([]RegistryInfo, 0, len(configuredRegistries))
+	for _, configured := range configuredRegistries {
+		info := *configured.info
+		info.Metadata = make(map[string]string, len(configured.info.Metadata))
+		for key, value := range configured.info.Metadata {
+			info.Metadata[key] = value
+		}
+		registries = append(registries, info)
+	}
+	sort.Slice(registries, func(i, j int) bool { return registries[i].Name < registries[j].Name })
+	return registries
+}
+
+// defaultPushRegistry returns the host of the registry configured with set_default, if any
+func defaultPushRegistry() (string, bool) {
+	configuredRegistriesMu.RLock()
+	defer configuredRegistriesMu.RUnlock()
+	for _, configured := range configuredRegistries {
+		if configured.info.Metadata["default"] == "true" {
+			return registryHost(configured.info.URL), true
+		}
+	}
+	return "", false
+}
@@ -3,14 +3,23 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
+	units "github.com/docker/go-units"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
 )
 
 // RegistryInfo contains information about a container registry
@@ -64,16 +73,17 @@ func (s *Server) initRegistryTools() []server.ServerTool {
 		{Tool: mcp.NewTool("registry_list",
 			mcp.WithDescription("List all configured container registries with their status and capabilities. Shows authentication status, accessibility, and available features for each registry."),
 			mcp.WithString("format", mcp.Description("Output format: 'table' (default), 'json', 'detailed'. Table for human reading, JSON for programmatic use.")),
-			mcp.WithBoolean("test_connectivity", mcp.Description("Test connectivity and authentication for each registry. Defaults to false for faster listing.")),
+			mcp.WithBoolean("test_connectivity", mcp.Description("Probe each registry's /v2/ endpoint, reporting latency, HTTP status and whether a login is required. Defaults to false for faster listing.")),
+			mcp.WithNumber("timeout", mcp.Description("Per-registry connectivity probe timeout in seconds. Defaults to 5.")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("Registry: List Configured Registries"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithDestructiveHintAnnotation(false),
-			mcp.WithOpenWorldHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
 		), Handler: s.registryList},
 
 		{Tool: mcp.NewTool("registry_repositories",
-			mcp.WithDescription("List repositories in a specific container registry. Shows repository metadata, tags, and statistics when available."),
+			mcp.WithDescription("List repositories in a specific container registry through the Registry v2 catalog API, using the credentials stored with 'registry_configure'. Docker Hub does not support catalog listing, use 'registry_search' for it."),
 			mcp.WithString("registry", mcp.Description("Registry name or URL to query. Must be a configured registry or public registry. Examples: 'quay.io', 'docker.io', 'my-registry'."), mcp.Required()),
 			mcp.WithString("namespace", mcp.Description("Repository namespace or organization to filter by. Examples: 'redhat', 'library', 'myorg'.")),
 			mcp.WithString("filter", mcp.Description("Filter repositories by name pattern. Supports wildcards. Examples: 'app-*', '*-service', 'my-project/*'.")),
@@ -87,12 +97,13 @@ func (s *Server) initRegistryTools() []server.ServerTool {
 		), Handler: s.registryRepositories},
 
 		{Tool: mcp.NewTool("registry_tags",
-			mcp.WithDescription("List all tags for a specific repository in a container registry. Shows tag metadata including creation date, size, and digest information."),
+			mcp.WithDescription("List all tags for a specific repository in a container registry through the Registry v2 API. Shows tag metadata including creation date, size, and digest information, read from at most 'limit' manifests."),
 			mcp.WithString("repository", mcp.Description("Full repository name including registry. Examples: 'quay.io/user/app', 'docker.io/library/nginx', 'ghcr.io/org/service'."), mcp.Required()),
 			mcp.WithString("filter", mcp.Description("Filter tags by pattern. Supports wildcards and regex. Examples: 'v*', '*-prod', 'latest', '^v[0-9]+\\.[0-9]+$'.")),
-			mcp.WithString("sort", mcp.Description("Sort order: 'name' (default), 'date', 'size'. Use '-' prefix for descending order (e.g., '-date').")),
+			mcp.WithString("sort", mcp.Description("Sort order: 'name' (default), 'date', 'size', 'semver'. Use '-' prefix for descending order (e.g., '-date', '-semver'). 'semver' orders tags by semantic version; tags that are not valid versions are listed after them by name.")),
 			mcp.WithString("format", mcp.Description("Output format: 'table' (default), 'json', 'list'. Table shows full details, list shows tag names only.")),
 			mcp.WithNumber("limit", mcp.Description("Maximum number of tags to return. Defaults to 100.")),
+			mcp.WithBoolean("no_cache", mcp.Description("Bypass the registry lookup cache when reading tag metadata, e.g. after a tag was pushed outside this server. Defaults to false.")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("Registry: List Repository Tags"),
 			mcp.WithReadOnlyHintAnnotation(true),
@@ -100,13 +111,56 @@ func (s *Server) initRegistryTools() []server.ServerTool {
 			mcp.WithOpenWorldHintAnnotation(true),
 		), Handler: s.registryTags},
 
+		{Tool: mcp.NewTool("registry_manifest",
+			mcp.WithDescription("Inspect the manifest, or manifest list, of a remote image through the Registry v2 API without pulling it. Shows the media type, the digest and compressed size of every platform, the total compressed size, manifest annotations and image config labels."),
+			mcp.WithString("image", mcp.Description("Remote image reference. Examples: 'docker.io/library/nginx:1.27', 'quay.io/user/app@sha256:abc123...'. Defaults to the 'latest' tag."), mcp.Required()),
+			mcp.WithString("platform", mcp.Description("Only show the image of this platform. Examples: 'linux/amd64', 'linux/arm64/v8'.")),
+			mcp.WithBoolean("include_config", mcp.Description("Read the config of every image for its creation date and labels. Defaults to true.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Registry: Inspect Image Manifest"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.registryManifest},
+
+		{Tool: mcp.NewTool("registry_delete_tag",
+			mcp.WithDescription("Delete an image tag from a container registry through the Registry v2 API. The manifest the tag points to is deleted, which also removes any other tag with the same digest. Many registries disable deletion and reject it."),
+			mcp.WithString("repository", mcp.Description("Full repository name including registry. Examples: 'quay.io/user/app', 'registry.example.com:5000/team/service'."), mcp.Required()),
+			mcp.WithString("tag", mcp.Description("Tag to delete. Example: 'v1.2.3'."), mcp.Required()),
+			mcp.WithBoolean("force", mcp.Description("Confirm the deletion. Must be true, the tag cannot be restored once deleted.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Registry: Delete Image Tag"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.registryDeleteTag},
+
+		{Tool: mcp.NewTool("registry_copy",
+			mcp.WithDescription("Copy an image from one registry to another without pulling it locally, e.g. to mirror a Docker Hub image into an internal Quay. Multi-arch images keep their manifest list. Uses skopeo when installed, the Registry v2 API otherwise. Returns the digest of every copied image."),
+			mcp.WithString("source_image", mcp.Description("Image to copy. Examples: 'docker.io/library/nginx:1.27', 'quay.io/user/app@sha256:abc123...'. A repository without tag in all_tags mode."), mcp.Required()),
+			mcp.WithString("target_image", mcp.Description("Destination image. Examples: 'quay.example.com/mirror/nginx:1.27'. Without a tag the source tag is kept. A repository without tag in all_tags mode."), mcp.Required()),
+			mcp.WithString("source_username", mcp.Description("Username for the source registry. Defaults to the credentials stored by registry_login.")),
+			mcp.WithString("source_password", mcp.Description("Password or token for the source registry.")),
+			mcp.WithString("target_username", mcp.Description("Username for the target registry. Defaults to the credentials stored by registry_login.")),
+			mcp.WithString("target_password", mcp.Description("Password or token for the target registry.")),
+			mcp.WithBoolean("all_tags", mcp.Description("Copy every tag of the source repository to the target repository. Defaults to false.")),
+			mcp.WithBoolean("src_tls_verify", mcp.Description("Verify the TLS certificate of the source registry. Defaults to true.")),
+			mcp.WithBoolean("dest_tls_verify", mcp.Description("Verify the TLS certificate of the target registry. Set to false for internal registries with self-signed certificates. Defaults to true.")),
+			mcp.WithString("method", mcp.Description("Copy method: 'auto' (default, skopeo when installed), 'skopeo' or 'in-process'.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Registry: Copy Image Between Registries"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+		), Handler: s.registryCopy},
+
 		{Tool: mcp.NewTool("registry_login",
-			mcp.WithDescription("Authenticate with a container registry using credentials. Supports various authentication methods including username/password, tokens, and service account keys."),
+			mcp.WithDescription("Authenticate with a container registry and verify the credentials. Registry v2 token and basic auth are supported, Docker Hub logins use its JWT login endpoint."),
 			mcp.WithString("registry", mcp.Description("Registry URL or configured registry name. Examples: 'quay.io', 'docker.io', 'gcr.io', 'my-registry'."), mcp.Required()),
 			mcp.WithString("username", mcp.Description("Registry username or service account. Can also be provided via REGISTRY_USERNAME environment variable.")),
 			mcp.WithString("password", mcp.Description("Registry password, token, or key. Can also be provided via REGISTRY_PASSWORD environment variable. For security, prefer environment variables.")),
 			mcp.WithBoolean("interactive", mcp.Description("Prompt for credentials interactively if not provided. Defaults to false.")),
-			mcp.WithBoolean("store_credentials", mcp.Description("Store credentials for future use (in secure keystore). Defaults to true.")),
+			mcp.WithBoolean("store_credentials", mcp.Description("Store the verified credentials so container_push, container_pull and the registry tools reuse them. They are kept in a file only readable by the current user (REGISTRY_CREDENTIALS_PATH, default ~/.openshift-mcp/registry-auth.json). Defaults to true.")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("Registry: Authenticate with Registry"),
 			mcp.WithReadOnlyHintAnnotation(false),
@@ -115,12 +169,12 @@ func (s *Server) initRegistryTools() []server.ServerTool {
 		), Handler: s.registryLogin},
 
 		{Tool: mcp.NewTool("registry_search",
-			mcp.WithDescription("Search for container images across configured registries or specific registry. Provides unified search across multiple registries with ranking and filtering."),
+			mcp.WithDescription("Search for container images on Docker Hub, Quay and the configured registries, or a specific registry. Docker Hub and Quay are searched through their search APIs, other registries by matching their catalog. Registries that cannot be searched are skipped and reported."),
 			mcp.WithString("query", mcp.Description("Search query for image names and descriptions. Examples: 'nginx', 'redis:alpine', 'python:3.9', 'myorg/app'."), mcp.Required()),
 			mcp.WithString("registry", mcp.Description("Specific registry to search in. If not provided, searches across all configured registries. Examples: 'docker.io', 'quay.io'.")),
-			mcp.WithString("category", mcp.Description("Filter by image category: 'official', 'verified', 'community', 'private'. Helps find trusted images.")),
+			mcp.WithString("category", mcp.Description("Filter by image category: 'official' or 'community'. Helps find trusted images.")),
 			mcp.WithBoolean("official_only", mcp.Description("Show only official images (for Docker Hub) or verified images (for other registries). Defaults to false.")),
-			mcp.WithString("architecture", mcp.Description("Filter by architecture: 'amd64', 'arm64', 'arm', 'ppc64le', 's390x'. Shows multi-arch compatible images.")),
+			mcp.WithString("architecture", mcp.Description("Filter by architecture: 'amd64', 'arm64', 'arm', 'ppc64le', 's390x'. Checked against the manifest list of each result's 'latest' tag.")),
 			mcp.WithNumber("limit", mcp.Description("Maximum number of results to return per registry. Defaults to 25.")),
 			mcp.WithString("format", mcp.Description("Output format: 'table' (default), 'json', 'compact'. Table shows detailed results, compact shows names only.")),
 			// Tool annotations
@@ -158,29 +212,27 @@ func (s *Server) registryConfigure(ctx context.Context, request mcp.CallToolRequ
 
 	klog.V(2).Infof("Configuring registry: %s (%s)", registryName, registryURL)
 
-	// Store registry configuration (in a real implementation, this would be persisted)
-	registryInfo := &RegistryInfo{
-		Name:          registryName,
-		URL:           registryURL,
-		Type:          registryType,
-		Public:        isPublicRegistry(registryURL),
-		Authenticated: username != "" && password != "",
-		Capabilities:  getRegistryCapabilities(registryType),
-		Metadata: map[string]string{
-			"username": username,
-			"email":    email,
-			"secure":   fmt.Sprintf("%t", secure),
-			"default":  fmt.Sprintf("%t", setDefault),
-		},
+	registryInfo, credentialsStored, err := configureRegistry(RegistryConfig{
+		Name:     registryName,
+		URL:      registryURL,
+		Type:     registryType,
+		Username: username,
+		Email:    email,
+		Insecure: !secure,
+		Default:  setDefault,
+	}, password)
+	if err != nil {
+		return NewTextResult("", err), nil
 	}
 
 	result := map[string]interface{}{
-		"status":         "success",
-		"message":        fmt.Sprintf("Registry '%s' configured successfully", registryName),
-		"registry_info":  registryInfo,
-		"authentication": registryInfo.Authenticated,
-		"capabilities":   registryInfo.Capabilities,
-		"next_steps":     []string{},
+		"status":             "success",
+		"message":            fmt.Sprintf("Registry '%s' configured successfully", registryName),
+		"registry_info":      registryInfo,
+		"authentication":     registryInfo.Authenticated,
+		"credentials_stored": credentialsStored,
+		"capabilities":       registryInfo.Capabilities,
+		"next_steps":         []string{},
 	}
 
 	if !registryInfo.Authenticated {
@@ -199,6 +251,42 @@ func (s *Server) registryConfigure(ctx context.Context, request mcp.CallToolRequ
 	return NewTextResult(string(jsonResult), nil), nil
 }
 
+// configureRegistry persists a registry configuration, used by registry_list and the tools that call
+// the registry API, and stores its credentials. It reports whether the credentials were stored.
+func configureRegistry(config RegistryConfig, password string) (*RegistryInfo, bool, error) {
+	registryType := config.Type
+	if registryType == "" {
+		registryType = detectRegistryType(config.URL)
+	}
+	registryInfo := &RegistryInfo{
+		Name:          config.Name,
+		URL:           config.URL,
+		Type:          registryType,
+		Public:        isPublicRegistry(config.URL),
+		Authenticated: config.Username != "" && password != "",
+		Capabilities:  getRegistryCapabilities(registryType),
+		Metadata: map[string]string{
+			"username": config.Username,
+			"email":    config.Email,
+			"secure":   fmt.Sprintf("%t", !config.Insecure),
+			"default":  fmt.Sprintf("%t", config.Default),
+		},
+	}
+
+	if err := saveConfiguredRegistry(registryInfo, password); err != nil {
+		return nil, false, fmt.Errorf("failed to save registry '%s': %v", config.Name, err)
+	}
+	credentialsStored := false
+	if registryInfo.Authenticated {
+		if _, err := storeRegistryCredentials(registryHost(config.URL), config.Username, password); err != nil {
+			klog.Errorf("Failed to store credentials for registry %s: %v", config.Name, err)
+		} else {
+			credentialsStored = true
+		}
+	}
+	return registryInfo, credentialsStored, nil
+}
+
 // registryList handles listing configured registries
 func (s *Server) registryList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args, ok := request.Params.Arguments.(map[string]interface{})
@@ -209,8 +297,7 @@ func (s *Server) registryList(ctx context.Context, request mcp.CallToolRequest)
 	format := getStringArg(args, "format", "table")
 	testConnectivity := getBoolArg(args, "test_connectivity", false)
 
-	// In a real implementation, this would load from persistent storage
-	// For now, return common registries
+	// Well-known public registries, followed by the ones added with registry_configure
 	registries := []RegistryInfo{
 		{
 			Name:          "docker-hub",
@@ -238,14 +325,37 @@ func (s *Server) registryList(ctx context.Context, request mcp.CallToolRequest)
 		},
 	}
 
+	// A configured registry replaces the well-known entry for the same host
+	for _, configured := range listConfiguredRegistries() {
+		replaced := false
+		for i := range registries {
+			if registryHost(registries[i].URL) == registryHost(configured.URL) {
+				registries[i] = configured
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			registries = append(registries, configured)
+		}
+	}
+
+	var probes []*cicd.RegistryProbe
 	if testConnectivity {
-		// Test connectivity for each registry
+		timeout := time.Duration(getIntArg(args, "timeout", 5)) * time.Second
+		probes = probeRegistries(ctx, registries, timeout)
 		for i := range registries {
-			// Simulate connectivity test
-			registries[i].Metadata = map[string]string{
-				"connectivity":  "✅ Online",
-				"response_time": "150ms",
+			metadata := make(map[string]string, len(registries[i].Metadata)+4)
+			for key, value := range registries[i].Metadata {
+				metadata[key] = value
+			}
+			metadata["connectivity"] = probeStatus(probes[i])
+			metadata["response_time"] = fmt.Sprintf("%dms", probes[i].LatencyMs)
+			metadata["auth_status"] = probes[i].Auth
+			if probes[i].HTTPStatus != 0 {
+				metadata["http_status"] = fmt.Sprint(probes[i].HTTPStatus)
 			}
+			registries[i].Metadata = metadata
 		}
 	}
 
@@ -255,6 +365,13 @@ func (s *Server) registryList(ctx context.Context, request mcp.CallToolRequest)
 			"total":      len(registries),
 			"tested":     testConnectivity,
 		}
+		if testConnectivity {
+			connectivity := make(map[string]*cicd.RegistryProbe, len(registries))
+			for i, registry := range registries {
+				connectivity[registry.Name] = probes[i]
+			}
+			result["connectivity"] = connectivity
+		}
 		jsonResult, _ := json.MarshalIndent(result, "", "  ")
 		return NewTextResult(string(jsonResult), nil), nil
 	}
@@ -279,7 +396,7 @@ func (s *Server) registryList(ctx context.Context, request mcp.CallToolRequest)
 
 		if testConnectivity && registry.Metadata != nil {
 			if connectivity, ok := registry.Metadata["connectivity"]; ok {
-				result += fmt.Sprintf("   Status: %s\n", connectivity)
+				result += fmt.Sprintf("   Status: %s (%s)\n", connectivity, registry.Metadata["response_time"])
 			}
 		}
 		result += "\n"
@@ -290,6 +407,45 @@ func (s *Server) registryList(ctx context.Context, request mcp.CallToolRequest)
 	return NewTextResult(result, nil), nil
 }
 
+// registryProbeWorkers bounds the registries probed at the same time by registry_list
+const registryProbeWorkers = 8
+
+// probeRegistries probes every registry concurrently, returning the results in the same order
+func probeRegistries(ctx context.Context, registries []RegistryInfo, timeout time.Duration) []*cicd.RegistryProbe {
+	probes := make([]*cicd.RegistryProbe, len(registries))
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for i := 0; i < registryProbeWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				probes[index] = newRegistryClient(registries[index].URL).Probe(ctx, timeout)
+			}
+		}()
+	}
+	for i := range registries {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return probes
+}
+
+// probeStatus summarizes a probe, telling an unreachable registry from one that needs a login
+func probeStatus(probe *cicd.RegistryProbe) string {
+	switch {
+	case !probe.Reachable && probe.HTTPStatus == 0:
+		return "❌ Unreachable: " + probe.Error
+	case !probe.Reachable:
+		return fmt.Sprintf("⚠️  Problem (HTTP %d)", probe.HTTPStatus)
+	case probe.Auth == "required":
+		return "🔒 Online, login required (HTTP 401)"
+	default:
+		return "✅ Online, anonymous access (HTTP 200)"
+	}
+}
+
 // registryRepositories handles listing repositories in a registry
 func (s *Server) registryRepositories(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args, ok := request.Params.Arguments.(map[string]interface{})
@@ -309,53 +465,32 @@ func (s *Server) registryRepositories(ctx context.Context, request mcp.CallToolR
 
 	klog.V(2).Infof("Listing repositories in registry: %s", registry)
 
-	// Simulate repository listing (in real implementation, this would call registry APIs)
-	repositories := []RegistryRepository{
-		{
-			Name:        "nginx",
-			FullName:    fmt.Sprintf("%s/library/nginx", registry),
-			Registry:    registry,
-			Public:      true,
-			Tags:        []string{"latest", "alpine", "1.21", "1.20"},
-			LastPush:    time.Now().AddDate(0, 0, -2),
-			Size:        "142MB",
-			Downloads:   1000000000,
-			Stars:       15000,
-			Description: "Official Nginx Docker image",
-		},
-		{
-			Name:        "redis",
-			FullName:    fmt.Sprintf("%s/library/redis", registry),
-			Registry:    registry,
-			Public:      true,
-			Tags:        []string{"latest", "alpine", "6.2", "7.0"},
-			LastPush:    time.Now().AddDate(0, 0, -1),
-			Size:        "117MB",
-			Downloads:   500000000,
-			Stars:       8000,
-			Description: "Official Redis Docker image",
-		},
+	client := newRegistryClient(registry)
+	names, truncated, err := client.Catalog(ctx, limit, func(name string) bool {
+		if namespace != "" && !strings.HasPrefix(name, strings.Trim(namespace, "/")+"/") {
+			return false
+		}
+		return matchesNameFilter(name, filter)
+	})
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to list repositories in %s: %v", registry, err)), nil
 	}
 
-	// Apply filters
-	filteredRepos := []RegistryRepository{}
-	for _, repo := range repositories {
-		if namespace != "" && !strings.Contains(repo.FullName, namespace) {
-			continue
-		}
-		if filter != "" && !strings.Contains(repo.Name, filter) {
-			continue
-		}
-		filteredRepos = append(filteredRepos, repo)
-		if len(filteredRepos) >= limit {
-			break
-		}
+	filteredRepos := make([]RegistryRepository, 0, len(names))
+	for _, name := range names {
+		filteredRepos = append(filteredRepos, RegistryRepository{
+			Name:     name,
+			FullName: client.Host + "/" + name,
+			Registry: client.Host,
+			Public:   isPublicRegistry(client.Host),
+		})
 	}
 
 	if format == "json" {
 		result := map[string]interface{}{
 			"repositories": filteredRepos,
 			"total":        len(filteredRepos),
+			"truncated":    truncated,
 			"registry":     registry,
 			"namespace":    namespace,
 			"filter":       filter,
@@ -369,20 +504,19 @@ func (s *Server) registryRepositories(ctx context.Context, request mcp.CallToolR
 	result += strings.Repeat("=", 60) + "\n\n"
 
 	for _, repo := range filteredRepos {
+		if format == "compact" {
+			result += repo.FullName + "\n"
+			continue
+		}
 		result += fmt.Sprintf("📦 %s\n", repo.Name)
 		result += fmt.Sprintf("   Full Name: %s\n", repo.FullName)
-		result += fmt.Sprintf("   Tags: %s\n", strings.Join(repo.Tags, ", "))
-		result += fmt.Sprintf("   Size: %s\n", repo.Size)
-		result += fmt.Sprintf("   Downloads: %d\n", repo.Downloads)
-		result += fmt.Sprintf("   Stars: %d\n", repo.Stars)
-		result += fmt.Sprintf("   Last Push: %s\n", repo.LastPush.Format("2006-01-02"))
-		if repo.Description != "" {
-			result += fmt.Sprintf("   Description: %s\n", repo.Description)
-		}
 		result += "\n"
 	}
 
 	result += fmt.Sprintf("Showing %d repositories\n", len(filteredRepos))
+	if truncated {
+		result += fmt.Sprintf("More repositories are available, increase limit (currently %d) to see them\n", limit)
+	}
 
 	return NewTextResult(result, nil), nil
 }
@@ -400,49 +534,46 @@ func (s *Server) registryTags(ctx context.Context, request mcp.CallToolRequest)
 	}
 
 	filter := getStringArg(args, "filter", "")
-	sort := getStringArg(args, "sort", "name")
+	sortOrder := getStringArg(args, "sort", "name")
 	format := getStringArg(args, "format", "table")
 	limit := getIntArg(args, "limit", 100)
+	noCache := getBoolArg(args, "no_cache", false)
 
 	klog.V(2).Infof("Listing tags for repository: %s", repository)
 
-	// Simulate tag listing
-	tags := []map[string]interface{}{
-		{
-			"name":    "latest",
-			"digest":  "sha256:abc123...",
-			"size":    "142MB",
-			"created": time.Now().AddDate(0, 0, -1),
-			"os":      "linux",
-			"arch":    "amd64",
-		},
-		{
-			"name":    "alpine",
-			"digest":  "sha256:def456...",
-			"size":    "23MB",
-			"created": time.Now().AddDate(0, 0, -2),
-			"os":      "linux",
-			"arch":    "amd64",
-		},
-		{
-			"name":    "1.21",
-			"digest":  "sha256:ghi789...",
-			"size":    "142MB",
-			"created": time.Now().AddDate(0, 0, -30),
-			"os":      "linux",
-			"arch":    "amd64",
-		},
+	host, name := splitRepository(repository)
+	client := newRegistryClient(host)
+	names, err := client.Tags(ctx, name)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to list tags of %s: %v", repository, err)), nil
 	}
 
 	// Apply filter
-	if filter != "" {
-		filteredTags := []map[string]interface{}{}
-		for _, tag := range tags {
-			if strings.Contains(tag["name"].(string), filter) {
-				filteredTags = append(filteredTags, tag)
-			}
+	match, err := tagFilter(filter)
+	if err != nil {
+		return NewTextResult("", err), nil
+	}
+	tags := make([]map[string]interface{}, 0, len(names))
+	for _, tag := range names {
+		if match(tag) {
+			tags = append(tags, map[string]interface{}{"name": tag})
 		}
-		tags = filteredTags
+	}
+	matching := len(tags)
+
+	// Sorting by date or size needs each tag's manifest; only limit manifests are fetched, so
+	// for larger repositories the tags first in name order are the ones sorted
+	sortKey := strings.TrimPrefix(sortOrder, "-")
+	needsMetadata := sortKey == "date" || sortKey == "size"
+	warnings := make([]string, 0)
+	if !needsMetadata {
+		// Apply sort before limiting so the limit keeps the first tags in order
+		if err := sortRegistryTags(tags, sortOrder); err != nil {
+			return NewTextResult("", err), nil
+		}
+	} else if len(tags) > limit {
+		_ = sortRegistryTags(tags, "name")
+		warnings = append(warnings, fmt.Sprintf("sorted %d of %d matching tags by %s to bound registry requests; narrow the filter or raise limit to consider more", limit, matching, sortKey))
 	}
 
 	// Apply limit
@@ -450,13 +581,34 @@ func (s *Server) registryTags(ctx context.Context, request mcp.CallToolRequest)
 		tags = tags[:limit]
 	}
 
+	if needsMetadata || format != "list" {
+		s.fetchTagMetadata(ctx, client, host, name, tags, noCache)
+	}
+	if needsMetadata {
+		if err := sortRegistryTags(tags, sortOrder); err != nil {
+			return NewTextResult("", err), nil
+		}
+	}
+
+	if format == "list" {
+		result := ""
+		for _, tag := range tags {
+			result += tag["name"].(string) + "\n"
+		}
+		return NewTextResult(result, nil), nil
+	}
+
 	if format == "json" {
 		result := map[string]interface{}{
 			"repository": repository,
 			"tags":       tags,
 			"total":      len(tags),
+			"matching":   matching,
 			"filter":     filter,
-			"sort":       sort,
+			"sort":       sortOrder,
+		}
+		if len(warnings) > 0 {
+			result["warnings"] = warnings
 		}
 		jsonResult, _ := json.MarshalIndent(result, "", "  ")
 		return NewTextResult(string(jsonResult), nil), nil
@@ -469,20 +621,74 @@ func (s *Server) registryTags(ctx context.Context, request mcp.CallToolRequest)
 	result += strings.Repeat("-", 60) + "\n"
 
 	for _, tag := range tags {
-		result += fmt.Sprintf("%-12s %-11s %-8s %-12s %s\n",
-			tag["name"].(string),
-			tag["digest"].(string)[:12]+"...",
-			tag["size"].(string),
-			tag["created"].(time.Time).Format("2006-01-02"),
-			tag["arch"].(string),
-		)
+		digest, _ := tag["digest"].(string)
+		if len(digest) > 15 {
+			digest = strings.TrimPrefix(digest, "sha256:")[:8] + "..."
+		}
+		size, _ := tag["size"].(string)
+		created := ""
+		if t, ok := tag["created"].(time.Time); ok && !t.IsZero() {
+			created = t.Format("2006-01-02")
+		}
+		arch, _ := tag["arch"].(string)
+		result += fmt.Sprintf("%-12s %-11s %-8s %-12s %s\n", tag["name"].(string), digest, size, created, arch)
 	}
 
-	result += fmt.Sprintf("\nTotal: %d tags\n", len(tags))
+	result += fmt.Sprintf("\nTotal: %d of %d matching tags\n", len(tags), matching)
+	for _, warning := range warnings {
+		result += "Note: " + warning + "\n"
+	}
 
 	return NewTextResult(result, nil), nil
 }
 
+// registryDeleteTag handles deleting a tag from a registry
+func (s *Server) registryDeleteTag(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	repository, ok := args["repository"].(string)
+	if !ok || repository == "" {
+		return NewTextResult("", fmt.Errorf("repository parameter is required")), nil
+	}
+	tag, ok := args["tag"].(string)
+	if !ok || tag == "" {
+		return NewTextResult("", fmt.Errorf("tag parameter is required")), nil
+	}
+	if !getBoolArg(args, "force", false) {
+		return NewTextResult("", fmt.Errorf("deleting %s:%s cannot be undone, set force to true to confirm", repository, tag)), nil
+	}
+
+	klog.V(2).Infof("Deleting tag %s from repository: %s", tag, repository)
+
+	host, name := splitRepository(repository)
+	client := newRegistryClient(host)
+	digest, err := client.DeleteTag(ctx, name, tag)
+	s.registryCache.invalidate(host + "/" + name + ":" + tag)
+	result := map[string]interface{}{
+		"repository":         repository,
+		"tag":                tag,
+		"digest":             digest,
+		"deleted":            err == nil,
+		"deletion_supported": !errors.Is(err, cicd.ErrDeleteUnsupported),
+	}
+	switch {
+	case errors.Is(err, cicd.ErrDeleteUnsupported):
+		result["status"] = "unsupported"
+		result["message"] = fmt.Sprintf("%s does not allow deleting manifests through the API (405 Method Not Allowed); delete the tag in the registry's UI or enable deletion (REGISTRY_STORAGE_DELETE_ENABLED=true for the distribution registry)", host)
+	case err != nil:
+		return NewTextResult("", fmt.Errorf("failed to delete %s:%s: %v", repository, tag, err)), nil
+	default:
+		result["status"] = "success"
+		result["message"] = fmt.Sprintf("Deleted %s:%s (%s); other tags with the same digest were removed too. Storage is reclaimed by the registry's garbage collection", repository, tag, digest)
+	}
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
 // registryLogin handles registry authentication
 func (s *Server) registryLogin(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	args, ok := request.Params.Arguments.(map[string]interface{})
@@ -510,15 +716,32 @@ func (s *Server) registryLogin(ctx context.Context, request mcp.CallToolRequest)
 
 	klog.V(2).Infof("Authenticating with registry: %s", registry)
 
-	// Simulate authentication (in real implementation, this would actually authenticate)
+	client := newRegistryClient(registry)
+	client.Username, client.Password = username, password
+	method, err := client.Login(ctx)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("authentication with %s failed: %v", registry, err)), nil
+	}
+
 	result := map[string]interface{}{
 		"status":             "success",
-		"message":            fmt.Sprintf("Successfully authenticated with %s", registry),
-		"registry":           registry,
+		"message":            fmt.Sprintf("Successfully authenticated with %s", client.Host),
+		"registry":           client.Host,
 		"username":           username,
-		"credentials_stored": storeCredentials,
-		"expires":            time.Now().Add(24 * time.Hour).Format(time.RFC3339),
-		"capabilities":       []string{"pull", "push", "delete"},
+		"auth_method":        method,
+		"credentials_stored": false,
+	}
+	if method == "anonymous" {
+		result["message"] = fmt.Sprintf("%s allows anonymous access, the credentials were not verified", client.Host)
+	}
+	if storeCredentials {
+		path, err := storeRegistryCredentials(client.Host, username, password)
+		if err != nil {
+			result["warning"] = fmt.Sprintf("credentials were verified but not stored: %v", err)
+		} else {
+			result["credentials_stored"] = true
+			result["credentials_path"] = path
+		}
 	}
 
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
@@ -546,64 +769,36 @@ func (s *Server) registrySearch(ctx context.Context, request mcp.CallToolRequest
 
 	klog.V(2).Infof("Searching for images: %s", query)
 
-	// Simulate search results
-	searchResults := []map[string]interface{}{
-		{
-			"name":          "nginx",
-			"description":   "Official build of Nginx",
-			"registry":      "docker.io",
-			"official":      true,
-			"stars":         15000,
-			"pulls":         "1B+",
-			"automated":     false,
-			"tags":          []string{"latest", "alpine", "1.21"},
-			"architectures": []string{"amd64", "arm64", "arm/v7"},
-		},
-		{
-			"name":          "nginx/nginx-ingress",
-			"description":   "NGINX Ingress Controller for Kubernetes",
-			"registry":      "docker.io",
-			"official":      false,
-			"stars":         1200,
-			"pulls":         "10M+",
-			"automated":     true,
-			"tags":          []string{"latest", "1.9.0", "edge"},
-			"architectures": []string{"amd64", "arm64"},
-		},
+	if category == "official" {
+		officialOnly = true
+	}
+	registries := searchRegistries()
+	if registry != "" {
+		registries = []string{registry}
 	}
+	searchResults, skipped := searchAll(ctx, registries, query, limit)
 
-	// Apply filters
-	filteredResults := []map[string]interface{}{}
+	filteredResults := make([]SearchResult, 0, len(searchResults))
 	for _, result := range searchResults {
-		if registry != "" && result["registry"].(string) != registry {
+		if officialOnly && !result.Official {
 			continue
 		}
-		if officialOnly && !result["official"].(bool) {
+		if category == "community" && result.Official {
 			continue
 		}
-		if category != "" {
-			// Simple category filtering
-			if category == "official" && !result["official"].(bool) {
-				continue
-			}
-		}
-		if architecture != "" {
-			archs := result["architectures"].([]string)
-			found := false
-			for _, arch := range archs {
-				if strings.Contains(arch, architecture) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
 		filteredResults = append(filteredResults, result)
-		if len(filteredResults) >= limit {
-			break
-		}
+	}
+	unverified := 0
+	if architecture != "" {
+		filteredResults, unverified = s.filterByArchitecture(ctx, filteredResults, architecture)
+	}
+
+	warnings := make([]string, 0)
+	for _, skip := range skipped {
+		warnings = append(warnings, fmt.Sprintf("%s was not searched: %s", skip.Registry, skip.Error))
+	}
+	if unverified > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d results were dropped because the platforms of their 'latest' tag could not be read", unverified))
 	}
 
 	if format == "json" {
@@ -611,42 +806,51 @@ func (s *Server) registrySearch(ctx context.Context, request mcp.CallToolRequest
 			"query":         query,
 			"results":       filteredResults,
 			"total":         len(filteredResults),
-			"registry":      registry,
+			"registries":    registries,
+			"skipped":       skipped,
 			"category":      category,
 			"official_only": officialOnly,
 			"architecture":  architecture,
+			"warnings":      warnings,
 		}
 		jsonResult, _ := json.MarshalIndent(result, "", "  ")
 		return NewTextResult(string(jsonResult), nil), nil
 	}
 
+	if format == "compact" {
+		names := make([]string, 0, len(filteredResults))
+		for _, searchResult := range filteredResults {
+			names = append(names, searchResult.Registry+"/"+searchResult.Name)
+		}
+		return NewTextResult(strings.Join(names, "\n"), nil), nil
+	}
+
 	// Format as table
 	result := fmt.Sprintf("Search results for '%s':\n", query)
 	result += strings.Repeat("=", 80) + "\n\n"
 
 	for _, searchResult := range filteredResults {
 		official := ""
-		if searchResult["official"].(bool) {
+		if searchResult.Official {
 			official = " [OFFICIAL]"
 		}
 
-		result += fmt.Sprintf("📦 %s%s\n", searchResult["name"].(string), official)
-		result += fmt.Sprintf("   Registry: %s\n", searchResult["registry"].(string))
-		result += fmt.Sprintf("   Description: %s\n", searchResult["description"].(string))
-		result += fmt.Sprintf("   Stars: %d | Pulls: %s\n", searchResult["stars"].(int), searchResult["pulls"].(string))
-
-		tags := searchResult["tags"].([]string)
-		if len(tags) > 3 {
-			tags = append(tags[:3], "...")
+		result += fmt.Sprintf("📦 %s%s\n", searchResult.Name, official)
+		result += fmt.Sprintf("   Registry: %s\n", searchResult.Registry)
+		if searchResult.Description != "" {
+			result += fmt.Sprintf("   Description: %s\n", searchResult.Description)
+		}
+		result += fmt.Sprintf("   Stars: %d | Pulls: %d\n", searchResult.Stars, searchResult.Pulls)
+		if len(searchResult.Architectures) > 0 {
+			result += fmt.Sprintf("   Architectures: %s\n", strings.Join(searchResult.Architectures, ", "))
 		}
-		result += fmt.Sprintf("   Tags: %s\n", strings.Join(tags, ", "))
-
-		archs := searchResult["architectures"].([]string)
-		result += fmt.Sprintf("   Architectures: %s\n", strings.Join(archs, ", "))
 		result += "\n"
 	}
 
-	result += fmt.Sprintf("Found %d results\n", len(filteredResults))
+	result += fmt.Sprintf("Found %d results in %s\n", len(filteredResults), strings.Join(registries, ", "))
+	for _, warning := range warnings {
+		result += fmt.Sprintf("⚠️  %s\n", warning)
+	}
 
 	return NewTextResult(result, nil), nil
 }
@@ -701,6 +905,151 @@ func getRegistryCapabilities(registryType string) []string {
 	}
 }
 
+// registryMetadataWorkers bounds the concurrent manifest requests made for one listing
+const registryMetadataWorkers = 4
+
+// fetchTagMetadata adds the digest, size, creation date and platform of each tag, reusing cached
+// lookups unless noCache is set. Tags whose manifest cannot be read get an error entry instead.
+func (s *Server) fetchTagMetadata(ctx context.Context, client *cicd.RegistryClient, host, name string, tags []map[string]interface{}, noCache bool) {
+	var wg sync.WaitGroup
+	work := make(chan map[string]interface{})
+	for i := 0; i < registryMetadataWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tag := range work {
+				reference := host + "/" + name + ":" + tag["name"].(string)
+				value, err := s.registryCache.lookup(registryCacheKey("tag", reference), noCache, func() (interface{}, error) {
+					return client.TagMetadata(ctx, name, tag["name"].(string))
+				})
+				if err != nil {
+					tag["error"] = err.Error()
+					continue
+				}
+				metadata := value.(*cicd.TagMetadata)
+				tag["digest"] = metadata.Digest
+				tag["size"] = units.HumanSize(float64(metadata.SizeBytes))
+				tag["size_bytes"] = metadata.SizeBytes
+				tag["created"] = metadata.Created
+				tag["os"] = metadata.OS
+				tag["arch"] = metadata.Arch
+			}
+		}()
+	}
+	for _, tag := range tags {
+		work <- tag
+	}
+	close(work)
+	wg.Wait()
+}
+
+// tagFilter returns a matcher for a tag filter: a regular expression when anchored with '^' or '$',
+// a wildcard pattern when it contains '*' or '?', otherwise a substring
+func tagFilter(filter string) (func(string) bool, error) {
+	switch {
+	case filter == "":
+		return func(string) bool { return true }, nil
+	case strings.HasPrefix(filter, "^") || strings.HasSuffix(filter, "$"):
+		re, err := regexp.Compile(filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter regex '%s': %v", filter, err)
+		}
+		return re.MatchString, nil
+	default:
+		return func(tag string) bool { return matchesNameFilter(tag, filter) }, nil
+	}
+}
+
+// matchesNameFilter reports whether name matches a filter, a wildcard pattern when it contains
+// '*', '?' or '[', otherwise a substring
+func matchesNameFilter(name, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	if strings.ContainsAny(filter, "*?[") {
+		// Match against the full name and the last path element, so 'app-*' matches 'org/app-web'
+		if matched, _ := path.Match(filter, name); matched {
+			return true
+		}
+		matched, _ := path.Match(filter, path.Base(name))
+		return matched
+	}
+	return strings.Contains(name, filter)
+}
+
+// sortRegistryTags sorts tags in place by the given order ('name', 'date',
+// 'size' or 'semver', optionally prefixed with '-' for descending). With
+// 'semver', tags that do not parse as versions always follow the versioned
+// ones, ordered by name, regardless of direction.
+func sortRegistryTags(tags []map[string]interface{}, order string) error {
+	descending := strings.HasPrefix(order, "-")
+	key := strings.TrimPrefix(order, "-")
+
+	name := func(i int) string { s, _ := tags[i]["name"].(string); return s }
+
+	var less func(i, j int) bool
+	switch key {
+	case "", "name":
+		less = func(i, j int) bool { return name(i) < name(j) }
+	case "date":
+		less = func(i, j int) bool {
+			ti, _ := tags[i]["created"].(time.Time)
+			tj, _ := tags[j]["created"].(time.Time)
+			return ti.Before(tj)
+		}
+	case "size":
+		size := func(i int) int64 {
+			if n, ok := tags[i]["size_bytes"].(int64); ok {
+				return n
+			}
+			s, _ := tags[i]["size"].(string)
+			n, err := units.FromHumanSize(s)
+			if err != nil {
+				return 0
+			}
+			return n
+		}
+		less = func(i, j int) bool { return size(i) < size(j) }
+	case "semver":
+		versions := make(map[string]*semver.Version, len(tags))
+		for i := range tags {
+			if v, err := semver.NewVersion(name(i)); err == nil {
+				versions[name(i)] = v
+			}
+		}
+		sort.SliceStable(tags, func(i, j int) bool {
+			vi, vj := versions[name(i)], versions[name(j)]
+			switch {
+			case vi != nil && vj != nil:
+				if vi.Equal(vj) {
+					return name(i) < name(j)
+				}
+				if descending {
+					return vi.GreaterThan(vj)
+				}
+				return vi.LessThan(vj)
+			case vi != nil:
+				return true
+			case vj != nil:
+				return false
+			default:
+				return name(i) < name(j)
+			}
+		})
+		return nil
+	default:
+		return fmt.Errorf("invalid sort order '%s': must be one of name, date, size, semver (optionally prefixed with '-')", order)
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if descending {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+	return nil
+}
+
 func getIntArg(args map[string]interface{}, key string, defaultValue int) int {
 	if val, ok := args[key].(float64); ok {
 		return int(val)
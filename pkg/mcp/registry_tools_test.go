@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFetchTagMetadataNoCache(t *testing.T) {
+	t.Setenv(registryCredentialsPathEnv, filepath.Join(t.TempDir(), "auth.json"))
+	var manifestRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/team/app/manifests/v1" {
+			http.NotFound(w, r)
+			return
+		}
+		manifestRequests.Add(1)
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", "sha256:v1")
+		_, _ = w.Write([]byte(`{"schemaVersion": 2, "layers": [{"size": 1000}]}`))
+	}))
+	defer server.Close()
+
+	s := &Server{registryCache: newRegistryCache(defaultRegistryCacheTTL, registryCacheCapacity)}
+	client := newRegistryClient(server.URL)
+	for _, noCache := range []bool{false, false, true} {
+		tags := []map[string]interface{}{{"name": "v1"}}
+		s.fetchTagMetadata(context.Background(), client, client.Host, "team/app", tags, noCache)
+		if tags[0]["digest"] != "sha256:v1" {
+			t.Fatalf("expected the tag digest, got %v", tags[0])
+		}
+	}
+	if requests := manifestRequests.Load(); requests != 2 {
+		t.Errorf("expected the cached lookup to be reused once and bypassed once, got %d manifest requests", requests)
+	}
+}
@@ -0,0 +1,251 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// repoStorePathEnv overrides the file the repository configurations are persisted to
+const repoStorePathEnv = "REPO_STORE_PATH"
+
+// RepoStore persists repository configurations across server restarts
+type RepoStore interface {
+	// Load returns every persisted repository keyed like repositoryStore
+	Load() (map[string]*RepoConfig, error)
+	// Save persists the repository stored under key, replacing any previous value
+	Save(key string, config *RepoConfig) error
+	// Delete removes the repository stored under key
+	Delete(key string) error
+}
+
+// fileRepoStore is a RepoStore backed by a single JSON file that is replaced atomically on write
+type fileRepoStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileRepoStore returns a RepoStore persisting to the JSON file at path
+func NewFileRepoStore(path string) RepoStore {
+	return &fileRepoStore{path: path}
+}
+
+// DefaultStoreDir returns ~/.openshift-mcp, the directory the server state is persisted to by default
+func DefaultStoreDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".openshift-mcp")
+}
+
+// repoStorePath returns $REPO_STORE_PATH, or repos.json in dir
+func repoStorePath(dir string) string {
+	if path := os.Getenv(repoStorePathEnv); path != "" {
+		return path
+	}
+	return filepath.Join(dir, "repos.json")
+}
+
+func (f *fileRepoStore) Load() (map[string]*RepoConfig, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.read()
+}
+
+func (f *fileRepoStore) Save(key string, config *RepoConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repos, err := f.read()
+	if err != nil {
+		return err
+	}
+	repos[key] = config
+	return f.write(repos)
+}
+
+func (f *fileRepoStore) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repos, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, exists := repos[key]; !exists {
+		return nil
+	}
+	delete(repos, key)
+	return f.write(repos)
+}
+
+// read returns the persisted repositories, an empty map when the file does not exist yet
+func (f *fileRepoStore) read() (map[string]*RepoConfig, error) {
+	repos := make(map[string]*RepoConfig)
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return repos, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository store %s: %w", f.path, err)
+	}
+	if err := json.Unmarshal(data, &repos); err != nil {
+		return nil, fmt.Errorf("failed to parse repository store %s: %w", f.path, err)
+	}
+	return repos, nil
+}
+
+// write replaces the store file atomically
+func (f *fileRepoStore) write(repos map[string]*RepoConfig) error {
+	data, err := json.MarshalIndent(repos, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(f.path, data); err != nil {
+		return fmt.Errorf("failed to write repository store %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at path through a temporary file and rename, so a crash never
+// leaves it half written. The file is only readable by the current user.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// CreateTemp creates the file with mode 0600
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+var (
+	// repositoryStoreMu guards repositoryStore and the fields of the configs it holds
+	repositoryStoreMu sync.RWMutex
+	// repoPersistence is where repositoryStore is flushed to, nil keeps it in memory only
+	repoPersistence RepoStore
+)
+
+// hydrateRepositoryStore loads the persisted repositories into repositoryStore and persists
+// every later change to store
+func hydrateRepositoryStore(store RepoStore) error {
+	repos, err := store.Load()
+	if err != nil {
+		return err
+	}
+	repositoryStoreMu.Lock()
+	defer repositoryStoreMu.Unlock()
+	for key, config := range repos {
+		repositoryStore[key] = config
+	}
+	repoPersistence = store
+	klog.V(1).Infof("Loaded %d repositories from the repository store", len(repos))
+	return nil
+}
+
+// persistRepo flushes the repository stored under key; callers must hold repositoryStoreMu.
+// Persistence failures are logged, the in-memory state stays authoritative.
+func persistRepo(key string) {
+	if repoPersistence == nil {
+		return
+	}
+	var err error
+	if config, exists := repositoryStore[key]; exists {
+		err = repoPersistence.Save(key, config)
+	} else {
+		err = repoPersistence.Delete(key)
+	}
+	if err != nil {
+		klog.Errorf("Failed to persist repository %s: %v", key, err)
+	}
+}
+
+// putRepo stores and persists a repository configuration
+func putRepo(key string, config *RepoConfig) {
+	repositoryStoreMu.Lock()
+	defer repositoryStoreMu.Unlock()
+	repositoryStore[key] = config
+	persistRepo(key)
+}
+
+// removeRepo deletes a repository configuration from memory and disk
+func removeRepo(key string) {
+	repositoryStoreMu.Lock()
+	defer repositoryStoreMu.Unlock()
+	delete(repositoryStore, key)
+	persistRepo(key)
+}
+
+// updateRepo applies update to a repository configuration returned by findRepo or listRepos and
+// to the stored configuration it is a copy of, then persists it
+func updateRepo(config *RepoConfig, update func(*RepoConfig)) {
+	repositoryStoreMu.Lock()
+	defer repositoryStoreMu.Unlock()
+	update(config)
+	if stored, exists := repositoryStore[config.Name]; exists {
+		if stored != config {
+			update(stored)
+		}
+		persistRepo(config.Name)
+	}
+}
+
+// setRepoStatus records a status transition and persists it
+func setRepoStatus(config *RepoConfig, status string) {
+	updateRepo(config, func(config *RepoConfig) { config.Status = status })
+}
+
+// listRepos returns copies of the stored repositories keyed like repositoryStore, which are changed
+// through updateRepo
+func listRepos() map[string]*RepoConfig {
+	repositoryStoreMu.RLock()
+	defer repositoryStoreMu.RUnlock()
+	repos := make(map[string]*RepoConfig, len(repositoryStore))
+	for key, config := range repositoryStore {
+		repo := *config
+		repos[key] = &repo
+	}
+	return repos
+}
+
+// copyRepo returns a copy of the repository configuration stored under key, for tools that
+// reconfigure a repository while keeping the fields they do not set, or an empty configuration
+func copyRepo(key string) *RepoConfig {
+	repositoryStoreMu.RLock()
+	defer repositoryStoreMu.RUnlock()
+	config := &RepoConfig{Active: true}
+	if existing, exists := repositoryStore[key]; exists {
+		*config = *existing
+	}
+	return config
+}
+
+// UnmarshalJSON decodes a repository configuration, repositories stored before the active flag
+// existed are active
+func (c *RepoConfig) UnmarshalJSON(data []byte) error {
+	type plain RepoConfig
+	decoded := plain{Active: true}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*c = RepoConfig(decoded)
+	return nil
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileRepoStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store", "repos.json")
+	store := NewFileRepoStore(path)
+
+	t.Run("Load returns an empty store when the file does not exist", func(t *testing.T) {
+		repos, err := store.Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repos) != 0 {
+			t.Errorf("expected no repositories, got %v", repos)
+		}
+	})
+	t.Run("Save persists the repositories", func(t *testing.T) {
+		if err := store.Save("app", &RepoConfig{Name: "app", URL: "https://github.com/example/app.git", Branch: "main", Active: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := store.Save("api", &RepoConfig{Name: "api", URL: "https://github.com/example/api.git", Branch: "develop"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		repos, err := NewFileRepoStore(path).Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repos) != 2 || repos["app"].URL != "https://github.com/example/app.git" || repos["api"].Branch != "develop" {
+			t.Errorf("unexpected repositories %+v", repos)
+		}
+		if repos["api"].Active {
+			t.Error("expected the stored active flag to be kept")
+		}
+	})
+	t.Run("Save replaces the repository stored under the key", func(t *testing.T) {
+		if err := store.Save("app", &RepoConfig{Name: "app", URL: "https://github.com/example/app.git", Branch: "release", Active: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		repos, err := store.Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repos["app"].Branch != "release" {
+			t.Errorf("expected the repository to be replaced, got %+v", repos["app"])
+		}
+	})
+	t.Run("Delete removes the repository", func(t *testing.T) {
+		if err := store.Delete("api"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := store.Delete("missing"); err != nil {
+			t.Fatalf("expected deleting a missing repository to succeed, got %v", err)
+		}
+		repos, err := store.Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, exists := repos["api"]; exists || len(repos) != 1 {
+			t.Errorf("expected only app to remain, got %+v", repos)
+		}
+	})
+	t.Run("Load fails on a corrupted file", func(t *testing.T) {
+		corrupted := filepath.Join(t.TempDir(), "repos.json")
+		if err := os.WriteFile(corrupted, []byte("{not json"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := NewFileRepoStore(corrupted).Load(); err == nil {
+			t.Error("expected an error for a corrupted store")
+		}
+	})
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	path := filepath.Join(dir, "state.json")
+	if err := writeFileAtomic(path, []byte("first")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("second")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("expected the file to be replaced, got %q", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the file to be readable by the current user only, got %v", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary file to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestRepoConfigUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   string
+		active bool
+	}{
+		{"stored before the active flag existed", `{"name": "app"}`, true},
+		{"active", `{"name": "app", "active": true}`, true},
+		{"inactive", `{"name": "app", "active": false}`, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var config RepoConfig
+			if err := json.Unmarshal([]byte(c.data), &config); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.Active != c.active || config.Name != "app" {
+				t.Errorf("expected active=%t, got %+v", c.active, config)
+			}
+		})
+	}
+}
+
+func TestFindRepoReturnsCopy(t *testing.T) {
+	putRepo("copy-test", &RepoConfig{Name: "copy-test", URL: "https://github.com/example/copy-test.git", Branch: "main", Active: true})
+	t.Cleanup(func() { removeRepo("copy-test") })
+
+	config := findRepo("copy-test")
+	config.Branch = "changed"
+	if findRepo("copy-test").Branch != "main" {
+		t.Error("expected changes to the returned configuration to leave the store intact")
+	}
+	setRepoStatus(config, "deployed")
+	if stored := findRepo("copy-test"); stored.Status != "deployed" || stored.Branch != "main" {
+		t.Errorf("expected updateRepo to apply the update to the store, got %+v", stored)
+	}
+}
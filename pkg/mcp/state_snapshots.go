@@ -0,0 +1,324 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/klog/v2"
+)
+
+// StateSnapshot is a point-in-time copy of the server's CI/CD configuration state: the repositories
+// with their pipeline settings, the configured registries and the workflows changed at runtime
+type StateSnapshot struct {
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description,omitempty"`
+	CreatedAt    time.Time                `json:"created_at"`
+	Repositories map[string]*RepoConfig   `json:"repositories"`
+	Registries   map[string]*RegistryInfo `json:"registries"`
+	Workflows    workflowStore            `json:"workflows"`
+	// registryPasswords are kept in memory only, like the snapshot itself
+	registryPasswords map[string]string
+}
+
+// stateSnapshots holds named snapshots in memory
+var (
+	stateSnapshotsMu sync.Mutex
+	stateSnapshots   = make(map[string]*StateSnapshot)
+)
+
+// copyRepositoryStore returns a deep copy of a repository store
+func copyRepositoryStore(store map[string]*RepoConfig) (map[string]*RepoConfig, error) {
+	data, err := json.Marshal(store)
+	if err != nil {
+		return nil, err
+	}
+	repositories := make(map[string]*RepoConfig)
+	if err := json.Unmarshal(data, &repositories); err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
+
+// orchestrator returns the workflow orchestrator, creating it on first use
+func (s *Server) orchestrator() *WorkflowOrchestrator {
+	if s.workflowOrchestrator == nil {
+		s.workflowOrchestrator = NewWorkflowOrchestrator(s)
+	}
+	return s.workflowOrchestrator
+}
+
+// initStateSnapshots initializes configuration snapshot MCP tools
+func (s *Server) initStateSnapshots() []server.ServerTool {
+	klog.V(1).Info("Initializing configuration snapshot tools")
+
+	return []server.ServerTool{
+		{Tool: mcp.NewTool("state_snapshot",
+			mcp.WithDescription("Save the current CI/CD configuration state (monitored repositories with their pipeline settings and environment overrides, configured registries and custom workflows) under a named point-in-time snapshot that can later be restored with 'state_restore'. An existing snapshot with the same name is replaced."),
+			mcp.WithString("name", mcp.Description("Snapshot name. Example: 'before-bulk-edit'."), mcp.Required()),
+			mcp.WithString("description", mcp.Description("Optional description of the snapshot.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("State: Create Snapshot"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.stateSnapshot},
+
+		{Tool: mcp.NewTool("state_restore",
+			mcp.WithDescription("Restore the CI/CD configuration state from a named snapshot, replacing the current repositories, registries and custom workflows and updating the git watches of the repositories. Reports the repositories added, removed and changed by the restore."),
+			mcp.WithString("name", mcp.Description("Name of the snapshot to restore."), mcp.Required()),
+			// Tool annotations
+			mcp.WithTitleAnnotation("State: Restore Snapshot"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.stateRestore},
+
+		{Tool: mcp.NewTool("state_snapshot_list",
+			mcp.WithDescription("List the saved configuration snapshots, newest first."),
+			// Tool annotations
+			mcp.WithTitleAnnotation("State: List Snapshots"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.stateSnapshotList},
+
+		{Tool: mcp.NewTool("state_snapshot_delete",
+			mcp.WithDescription("Delete a saved configuration snapshot."),
+			mcp.WithString("name", mcp.Description("Name of the snapshot to delete."), mcp.Required()),
+			// Tool annotations
+			mcp.WithTitleAnnotation("State: Delete Snapshot"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.stateSnapshotDelete},
+	}
+}
+
+// stateSnapshot handles saving a named snapshot of the configuration state
+func (s *Server) stateSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	repositoryStoreMu.RLock()
+	repositories, err := copyRepositoryStore(repositoryStore)
+	repositoryStoreMu.RUnlock()
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to copy repository state: %v", err)), nil
+	}
+	registries, passwords, err := snapshotConfiguredRegistries()
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to copy registry state: %v", err)), nil
+	}
+	workflows, err := s.orchestrator().snapshotWorkflows()
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to copy workflow state: %v", err)), nil
+	}
+	snapshot := &StateSnapshot{
+		Name:              name,
+		Description:       getStringArg(args, "description", ""),
+		CreatedAt:         time.Now(),
+		Repositories:      repositories,
+		Registries:        registries,
+		Workflows:         workflows,
+		registryPasswords: passwords,
+	}
+
+	stateSnapshotsMu.Lock()
+	_, replaced := stateSnapshots[name]
+	stateSnapshots[name] = snapshot
+	stateSnapshotsMu.Unlock()
+
+	klog.V(2).Infof("Saved state snapshot %s with %d repositories, %d registries and %d custom workflows", name, len(repositories), len(registries), len(workflows.Workflows))
+
+	result := map[string]interface{}{
+		"status":           "success",
+		"message":          fmt.Sprintf("Snapshot '%s' saved", name),
+		"snapshot":         name,
+		"repositories":     len(repositories),
+		"registries":       len(registries),
+		"custom_workflows": len(workflows.Workflows),
+		"replaced":         replaced,
+		"created_at":       snapshot.CreatedAt.Format(time.RFC3339),
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// stateRestore handles restoring the configuration state from a named snapshot
+func (s *Server) stateRestore(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	stateSnapshotsMu.Lock()
+	snapshot, exists := stateSnapshots[name]
+	stateSnapshotsMu.Unlock()
+	if !exists {
+		return NewTextResult("", fmt.Errorf("snapshot '%s' not found", name)), nil
+	}
+
+	// Copy so that later edits don't modify the snapshot
+	repositories, err := copyRepositoryStore(snapshot.Repositories)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to copy snapshot state: %v", err)), nil
+	}
+	registries, err := copyRegistryInfos(snapshot.Registries)
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to copy snapshot state: %v", err)), nil
+	}
+	var workflows workflowStore
+	data, err := json.Marshal(snapshot.Workflows)
+	if err == nil {
+		err = json.Unmarshal(data, &workflows)
+	}
+	if err != nil {
+		return NewTextResult("", fmt.Errorf("failed to copy snapshot state: %v", err)), nil
+	}
+
+	repositoryStoreMu.Lock()
+	previous := maps.Clone(repositoryStore)
+	added, removed, changed := []string{}, []string{}, []string{}
+	for key, repo := range repositories {
+		current, exists := repositoryStore[key]
+		if !exists {
+			added = append(added, key)
+		} else if !reflect.DeepEqual(current, repo) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range repositoryStore {
+		if _, exists := repositories[key]; !exists {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+
+	for key := range repositoryStore {
+		delete(repositoryStore, key)
+	}
+	for key, repo := range repositories {
+		repositoryStore[key] = repo
+	}
+	for _, key := range slices.Concat(added, removed, changed) {
+		persistRepo(key)
+	}
+	repositoryStoreMu.Unlock()
+
+	// Watches follow the restored repositories: the removed and changed ones are unwatched first,
+	// then the added and changed ones are watched with their restored settings
+	for _, key := range slices.Concat(removed, changed) {
+		s.unwatchRepo(previous[key])
+	}
+	for _, key := range slices.Concat(added, changed) {
+		s.watchRepo(repositories[key])
+	}
+
+	warnings := make([]string, 0)
+	if err := restoreConfiguredRegistries(registries, snapshot.registryPasswords); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	if err := s.orchestrator().restoreWorkflows(workflows); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
+	klog.V(2).Infof("Restored state snapshot %s: %d added, %d removed, %d changed", name, len(added), len(removed), len(changed))
+
+	result := map[string]interface{}{
+		"status":       "success",
+		"message":      fmt.Sprintf("Snapshot '%s' restored", name),
+		"snapshot":     name,
+		"created_at":   snapshot.CreatedAt.Format(time.RFC3339),
+		"repositories": len(repositories),
+		"added":        added,
+		"removed":      removed,
+		"changed":      changed,
+		"registries":   len(registries),
+		"workflows":    len(workflows.Workflows),
+	}
+	// The restored state is kept in memory when it cannot be persisted
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// stateSnapshotList handles listing saved snapshots
+func (s *Server) stateSnapshotList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	stateSnapshotsMu.Lock()
+	snapshots := make([]map[string]interface{}, 0, len(stateSnapshots))
+	for _, snapshot := range stateSnapshots {
+		snapshots = append(snapshots, map[string]interface{}{
+			"name":         snapshot.Name,
+			"description":  snapshot.Description,
+			"created_at":   snapshot.CreatedAt,
+			"repositories": len(snapshot.Repositories),
+			"registries":   len(snapshot.Registries),
+			"workflows":    len(snapshot.Workflows.Workflows),
+		})
+	}
+	stateSnapshotsMu.Unlock()
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i]["created_at"].(time.Time).After(snapshots[j]["created_at"].(time.Time))
+	})
+
+	result := map[string]interface{}{
+		"snapshots": snapshots,
+		"total":     len(snapshots),
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
+
+// stateSnapshotDelete handles deleting a saved snapshot
+func (s *Server) stateSnapshotDelete(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+
+	stateSnapshotsMu.Lock()
+	_, exists := stateSnapshots[name]
+	delete(stateSnapshots, name)
+	stateSnapshotsMu.Unlock()
+	if !exists {
+		return NewTextResult("", fmt.Errorf("snapshot '%s' not found", name)), nil
+	}
+
+	result := map[string]interface{}{
+		"status":  "success",
+		"message": fmt.Sprintf("Snapshot '%s' deleted", name),
+	}
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// isolateRegistryStore persists the configured registries to a temporary file during a test, and
+// puts back the registries configured before it
+func isolateRegistryStore(t *testing.T) {
+	t.Helper()
+	registries, passwords, err := snapshotConfiguredRegistries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configuredRegistriesMu.Lock()
+	previousPath := registryStorePath
+	registryStorePath = filepath.Join(t.TempDir(), "registries.json")
+	configuredRegistriesMu.Unlock()
+	t.Cleanup(func() {
+		_ = restoreConfiguredRegistries(registries, passwords)
+		configuredRegistriesMu.Lock()
+		registryStorePath = previousPath
+		configuredRegistriesMu.Unlock()
+	})
+}
+
+func TestStateSnapshotRestoresRegistriesAndWorkflows(t *testing.T) {
+	isolateRegistryStore(t)
+	// The workflows of this server are persisted to a temporary file, not to the user's store
+	s := &Server{workflowStorePath: filepath.Join(t.TempDir(), "workflows.json")}
+	if err := saveConfiguredRegistry(&RegistryInfo{Name: "snapshot-registry", URL: "https://quay.io"}, "secret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.orchestrator().AddCustomWorkflow(&Workflow{Name: "snapshot workflow"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"name": "registries-and-workflows"}
+	if result, _ := s.stateSnapshot(context.Background(), request); result.IsError {
+		t.Fatalf("state_snapshot failed: %v", result)
+	}
+	t.Cleanup(func() { delete(stateSnapshots, "registries-and-workflows") })
+
+	_ = restoreConfiguredRegistries(nil, nil)
+	if err := s.orchestrator().DeleteWorkflow("snapshot workflow", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.orchestrator().DeleteWorkflow("build_and_push", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result, _ := s.stateRestore(context.Background(), request); result.IsError {
+		t.Fatalf("state_restore failed: %v", result)
+	}
+	configured := lookupConfiguredRegistry("snapshot-registry")
+	if configured == nil || configured.password != "secret" {
+		t.Errorf("expected the registry to be restored with its password, got %+v", configured)
+	}
+	if _, exists := s.orchestrator().GetWorkflow("snapshot_workflow"); !exists {
+		t.Error("expected the custom workflow to be restored")
+	}
+	if _, exists := s.orchestrator().GetWorkflow("build_and_push"); !exists {
+		t.Error("expected the deleted built-in workflow to be restored")
+	}
+}
@@ -1,14 +1,18 @@
 package mcp
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"k8s.io/klog/v2"
+
+	"github.com/sur309/openshift-mcp-server/pkg/cicd"
 )
 
 // UBIValidation represents UBI validation results
@@ -19,34 +23,142 @@ type UBIValidation struct {
 	SecurityBenefits   []string `json:"security_benefits"`
 	ComplianceBenefits []string `json:"compliance_benefits"`
 	ValidationMessage  string   `json:"validation_message"`
+	// Stages are the FROM instructions in order, the verdict above is the one of the last
+	Stages []UBIStageValidation `json:"stages,omitempty"`
 }
 
-// Red Hat UBI image mappings
-var ubiImageMappings = map[string]string{
-	// Standard OS bases
-	"alpine":              "registry.access.redhat.com/ubi8/ubi-minimal:latest",
-	"ubuntu":              "registry.access.redhat.com/ubi8/ubi:latest",
-	"centos":              "registry.access.redhat.com/ubi8/ubi:latest",
-	"debian":              "registry.access.redhat.com/ubi8/ubi:latest",
-	"fedora":              "registry.access.redhat.com/ubi8/ubi:latest",
-	"rhel":                "registry.access.redhat.com/ubi8/ubi:latest",
-	
-	// Language-specific bases
-	"node":                "registry.access.redhat.com/ubi8/nodejs-18:latest",
-	"python":              "registry.access.redhat.com/ubi8/python-39:latest",
-	"golang":              "registry.access.redhat.com/ubi8/go-toolset:latest",
-	"openjdk":             "registry.access.redhat.com/ubi8/openjdk-11:latest",
-	"java":                "registry.access.redhat.com/ubi8/openjdk-11:latest",
-	"nginx":               "registry.access.redhat.com/ubi8/nginx-120:latest",
-	"httpd":               "registry.access.redhat.com/ubi8/httpd-24:latest",
-	"php":                 "registry.access.redhat.com/ubi8/php-74:latest",
-	"ruby":                "registry.access.redhat.com/ubi8/ruby-27:latest",
-	"postgres":            "registry.access.redhat.com/rhel8/postgresql-13:latest",
-	"mysql":               "registry.access.redhat.com/rhel8/mysql-80:latest",
-	"redis":               "registry.access.redhat.com/rhel8/redis-6:latest",
-	
-	// Default fallback
-	"default":             "registry.access.redhat.com/ubi8/ubi:latest",
+// UBIStageValidation is the UBI compliance of a stage of a multi-stage Dockerfile
+type UBIStageValidation struct {
+	Index     int    `json:"index"`
+	Alias     string `json:"alias,omitempty"`
+	BaseImage string `json:"base_image"`
+	// FromStage is set when the stage builds on an earlier stage, whose compliance it inherits
+	FromStage string `json:"from_stage,omitempty"`
+	// DeclaredImage is the base image as written when BaseImage was resolved from build arguments
+	DeclaredImage string `json:"declared_base_image,omitempty"`
+	// Undeterminable is set when the base image is only known by digest or an unset build argument
+	Undeterminable    bool   `json:"undeterminable,omitempty"`
+	IsUBI             bool   `json:"is_ubi"`
+	IsRuntime         bool   `json:"is_runtime"`
+	SuggestedUBIImage string `json:"suggested_ubi_image,omitempty"`
+}
+
+// defaultUBIVersion is the UBI release suggested when none is configured
+const defaultUBIVersion = "9"
+
+// Red Hat UBI image mappings by UBI release
+var ubiImageMappings = map[string]map[string]string{
+	"8": {
+		// Standard OS bases
+		"alpine": "registry.access.redhat.com/ubi8/ubi-minimal:latest",
+		"ubuntu": "registry.access.redhat.com/ubi8/ubi:latest",
+		"centos": "registry.access.redhat.com/ubi8/ubi:latest",
+		"debian": "registry.access.redhat.com/ubi8/ubi:latest",
+		"fedora": "registry.access.redhat.com/ubi8/ubi:latest",
+		"rhel":   "registry.access.redhat.com/ubi8/ubi:latest",
+
+		// Language-specific bases
+		"node":     "registry.access.redhat.com/ubi8/nodejs-18:latest",
+		"python":   "registry.access.redhat.com/ubi8/python-39:latest",
+		"golang":   "registry.access.redhat.com/ubi8/go-toolset:latest",
+		"openjdk":  "registry.access.redhat.com/ubi8/openjdk-11:latest",
+		"java":     "registry.access.redhat.com/ubi8/openjdk-11:latest",
+		"nginx":    "registry.access.redhat.com/ubi8/nginx-120:latest",
+		"httpd":    "registry.access.redhat.com/ubi8/httpd-24:latest",
+		"php":      "registry.access.redhat.com/ubi8/php-74:latest",
+		"ruby":     "registry.access.redhat.com/ubi8/ruby-27:latest",
+		"postgres": "registry.access.redhat.com/rhel8/postgresql-13:latest",
+		"mysql":    "registry.access.redhat.com/rhel8/mysql-80:latest",
+		"redis":    "registry.access.redhat.com/rhel8/redis-6:latest",
+
+		// Default fallback
+		"default": "registry.access.redhat.com/ubi8/ubi:latest",
+	},
+	"9": {
+		// Standard OS bases
+		"alpine": "registry.access.redhat.com/ubi9/ubi-minimal:latest",
+		"ubuntu": "registry.access.redhat.com/ubi9/ubi:latest",
+		"centos": "registry.access.redhat.com/ubi9/ubi:latest",
+		"debian": "registry.access.redhat.com/ubi9/ubi:latest",
+		"fedora": "registry.access.redhat.com/ubi9/ubi:latest",
+		"rhel":   "registry.access.redhat.com/ubi9/ubi:latest",
+
+		// Language-specific bases, shared with the s2i builder images
+		"node":     cicd.UBIBuilderImages["nodejs"],
+		"python":   cicd.UBIBuilderImages["python"],
+		"golang":   cicd.UBIBuilderImages["golang"],
+		"openjdk":  cicd.UBIBuilderImages["java"],
+		"java":     cicd.UBIBuilderImages["java"],
+		"nginx":    "registry.access.redhat.com/ubi9/nginx-124:latest",
+		"httpd":    "registry.access.redhat.com/ubi9/httpd-24:latest",
+		"php":      cicd.UBIBuilderImages["php"],
+		"ruby":     cicd.UBIBuilderImages["ruby"],
+		"dotnet":   cicd.UBIBuilderImages["dotnet"],
+		"postgres": "registry.access.redhat.com/rhel9/postgresql-16:latest",
+		"mysql":    "registry.access.redhat.com/rhel9/mysql-80:latest",
+		"redis":    "registry.access.redhat.com/rhel9/redis-7:latest",
+
+		// Default fallback
+		"default": "registry.access.redhat.com/ubi9/ubi:latest",
+	},
+}
+
+// ubiVersion returns the configured UBI release, ubi_version or $UBI_VERSION, 9 by default
+func (s *Server) ubiVersion() string {
+	version := os.Getenv("UBI_VERSION")
+	if s.configuration != nil && s.configuration.StaticConfig != nil && s.configuration.StaticConfig.UBIVersion != "" {
+		version = s.configuration.StaticConfig.UBIVersion
+	}
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "ubi")
+	if version == "" {
+		return defaultUBIVersion
+	}
+	if _, ok := ubiImageMappings[version]; !ok {
+		klog.Warningf("Unsupported UBI version %q, using UBI %s", version, defaultUBIVersion)
+		return defaultUBIVersion
+	}
+	return version
+}
+
+// ubiImageMappingsFile returns the configured mappings override, ubi_image_mappings_file or
+// $UBI_IMAGE_MAPPINGS_FILE
+func (s *Server) ubiImageMappingsFile() string {
+	if s.configuration != nil && s.configuration.StaticConfig != nil && s.configuration.StaticConfig.UBIImageMappingsFile != "" {
+		return s.configuration.StaticConfig.UBIImageMappingsFile
+	}
+	return os.Getenv("UBI_IMAGE_MAPPINGS_FILE")
+}
+
+// ubiMappings returns the built-in mappings of the configured UBI release, overridden and
+// extended by the mappings file. A file that cannot be read leaves the built-in mappings.
+func (s *Server) ubiMappings() map[string]string {
+	mappings := maps.Clone(ubiImageMappings[s.ubiVersion()])
+	path := s.ubiImageMappingsFile()
+	if path == "" {
+		return mappings
+	}
+	overrides, err := readUBIImageMappings(path)
+	if err != nil {
+		klog.Warningf("Using the built-in UBI image mappings: %v", err)
+		return mappings
+	}
+	for name, image := range overrides {
+		mappings[strings.ToLower(name)] = image
+	}
+	return mappings
+}
+
+// readUBIImageMappings reads a JSON object of image name to UBI image mappings
+func readUBIImageMappings(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read UBI image mappings %s: %w", path, err)
+	}
+	mappings := make(map[string]string)
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return nil, fmt.Errorf("failed to parse UBI image mappings %s: %w", path, err)
+	}
+	return mappings, nil
 }
 
 // Red Hat UBI registry patterns
@@ -56,49 +168,130 @@ var ubiRegistryPatterns = []string{
 	"quay.io/redhat/ubi",
 }
 
-// validateUBICompliance checks if the Dockerfile uses Red Hat UBI base images
+// imageIDPattern matches a reference made of a digest or image ID only, e.g. sha256:<hex>
+var imageIDPattern = regexp.MustCompile(`^@?(sha256:)?[a-f0-9]{12,64}$|^@?sha256:`)
+
+// dockerfileArgPattern matches $NAME, ${NAME} and ${NAME:-default} references to build arguments
+var dockerfileArgPattern = regexp.MustCompile(`\$\{(\w+)(?::?-([^}]*))?\}|\$(\w+)`)
+
+// dockerfileStage is a FROM instruction of a Dockerfile
+type dockerfileStage struct {
+	// line is the index of the FROM line in the Dockerfile
+	line int
+	// baseImage has the build arguments declared before the first FROM resolved to their defaults
+	baseImage string
+	// declaredImage is the base image as written, when it references build arguments
+	declaredImage string
+	alias         string
+}
+
+// parseDockerfileStages returns the stages of a Dockerfile in order, the last one is the runtime
+// stage
+func parseDockerfileStages(content string) []dockerfileStage {
+	stages := make([]dockerfileStage, 0)
+	// Only the ARGs declared before the first FROM can be used in FROM lines
+	args := make(map[string]string)
+	for i, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(stages) == 0 && len(fields) >= 2 && strings.EqualFold(fields[0], "ARG") {
+			for _, arg := range fields[1:] {
+				name, value, _ := strings.Cut(arg, "=")
+				args[name] = strings.Trim(value, `"'`)
+			}
+			continue
+		}
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		// Skip flags such as --platform
+		fields = fields[1:]
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		stage := dockerfileStage{line: i, baseImage: resolveDockerfileArgs(fields[0], args)}
+		if stage.baseImage != fields[0] {
+			stage.declaredImage = fields[0]
+		}
+		if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+			stage.alias = fields[2]
+		}
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+// resolveDockerfileArgs replaces the build arguments referenced in value with their defaults,
+// leaving the references of arguments without a value
+func resolveDockerfileArgs(value string, args map[string]string) string {
+	return dockerfileArgPattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := dockerfileArgPattern.FindStringSubmatch(reference)
+		name, fallback := match[1], match[2]
+		if name == "" {
+			name = match[3]
+		}
+		if arg := args[name]; arg != "" {
+			return arg
+		}
+		if fallback != "" {
+			return fallback
+		}
+		return reference
+	})
+}
+
+// validateUBICompliance checks if the stages of a Dockerfile use Red Hat UBI base images. The
+// overall verdict is the one of the runtime stage, the last FROM, which is what the image ships.
 func (s *Server) validateUBICompliance(ctx context.Context, dockerfilePath string) (*UBIValidation, error) {
 	klog.V(2).Infof("Validating UBI compliance for Dockerfile: %s", dockerfilePath)
-	
-	// Read Dockerfile
-	file, err := os.Open(dockerfilePath)
+
+	content, err := os.ReadFile(dockerfilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open Dockerfile: %v", err)
 	}
-	defer file.Close()
+	stages := parseDockerfileStages(string(content))
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("no FROM instruction found in Dockerfile")
+	}
 
-	var baseImage string
-	scanner := bufio.NewScanner(file)
-	
-	// Find the first FROM instruction
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(strings.ToUpper(line), "FROM ") {
-			// Extract base image (handle multi-stage builds)
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				baseImage = parts[1]
-				break
+	// A stage built FROM an earlier stage inherits its compliance
+	stageResults := make([]UBIStageValidation, 0, len(stages))
+	byAlias := make(map[string]UBIStageValidation)
+	for i, stage := range stages {
+		result := UBIStageValidation{
+			Index:         i,
+			Alias:         stage.alias,
+			BaseImage:     stage.baseImage,
+			DeclaredImage: stage.declaredImage,
+			IsRuntime:     i == len(stages)-1,
+		}
+		if parent, ok := byAlias[strings.ToLower(stage.baseImage)]; ok {
+			result.FromStage = stage.baseImage
+			result.IsUBI = parent.IsUBI
+			result.Undeterminable = parent.Undeterminable
+			result.SuggestedUBIImage = parent.SuggestedUBIImage
+		} else if s.extractImageName(strings.ToLower(stage.baseImage)) == "" {
+			result.Undeterminable = true
+		} else {
+			result.IsUBI = s.isUBIImage(stage.baseImage)
+			if !result.IsUBI {
+				result.SuggestedUBIImage = s.suggestUBIAlternative(stage.baseImage)
 			}
 		}
+		if stage.alias != "" {
+			byAlias[strings.ToLower(stage.alias)] = result
+		}
+		stageResults = append(stageResults, result)
 	}
+	runtime := stageResults[len(stageResults)-1]
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading Dockerfile: %v", err)
-	}
-
-	if baseImage == "" {
-		return nil, fmt.Errorf("no FROM instruction found in Dockerfile")
-	}
-
-	// Check if current image is UBI-based
-	isUBI := s.isUBIImage(baseImage)
-	suggestedUBI := s.suggestUBIAlternative(baseImage)
-	
 	validation := &UBIValidation{
-		IsUBI:             isUBI,
-		CurrentBaseImage:  baseImage,
-		SuggestedUBIImage: suggestedUBI,
+		IsUBI:             runtime.IsUBI,
+		CurrentBaseImage:  runtime.BaseImage,
+		SuggestedUBIImage: runtime.SuggestedUBIImage,
+		Stages:            stageResults,
 		SecurityBenefits: []string{
 			"Enhanced security with Red Hat's security patches",
 			"Regular vulnerability scanning and updates",
@@ -112,11 +305,25 @@ func (s *Server) validateUBICompliance(ctx context.Context, dockerfilePath strin
 			"OpenShift and Kubernetes optimized",
 		},
 	}
+	if runtime.FromStage != "" {
+		validation.CurrentBaseImage = runtime.FromStage
+	}
 
-	if isUBI {
-		validation.ValidationMessage = fmt.Sprintf("✅ Base image '%s' is Red Hat UBI compliant", baseImage)
-	} else {
-		validation.ValidationMessage = fmt.Sprintf("⚠️ Base image '%s' is not Red Hat UBI. Suggested alternative: '%s'", baseImage, suggestedUBI)
+	nonUBIBuildStages := make([]string, 0)
+	for _, stage := range stageResults[:len(stageResults)-1] {
+		if !stage.IsUBI && !stage.Undeterminable && stage.FromStage == "" {
+			nonUBIBuildStages = append(nonUBIBuildStages, stage.BaseImage)
+		}
+	}
+	switch {
+	case runtime.Undeterminable:
+		validation.ValidationMessage = fmt.Sprintf("⚠️ Runtime base image '%s' is undeterminable, UBI compliance could not be checked. Pin it by name or give its build argument a default", runtime.BaseImage)
+	case !runtime.IsUBI:
+		validation.ValidationMessage = fmt.Sprintf("⚠️ Runtime base image '%s' is not Red Hat UBI. Suggested alternative: '%s'", runtime.BaseImage, runtime.SuggestedUBIImage)
+	case len(nonUBIBuildStages) > 0:
+		validation.ValidationMessage = fmt.Sprintf("✅ Runtime base image '%s' is Red Hat UBI compliant, build stages use non-UBI images: %s", runtime.BaseImage, strings.Join(nonUBIBuildStages, ", "))
+	default:
+		validation.ValidationMessage = fmt.Sprintf("✅ Base image '%s' is Red Hat UBI compliant", runtime.BaseImage)
 	}
 
 	return validation, nil
@@ -131,6 +338,14 @@ func (s *Server) isUBIImage(image string) bool {
 			return true
 		}
 	}
+
+	// Images of the mappings are UBI, including those of an internal mirror
+	repository := imageRepository(imageLower)
+	for _, ubiImage := range s.ubiMappings() {
+		if repository == imageRepository(strings.ToLower(ubiImage)) {
+			return true
+		}
+	}
 	
 	return false
 }
@@ -141,86 +356,84 @@ func (s *Server) suggestUBIAlternative(image string) string {
 	
 	// Extract image name without tag and registry
 	imageName := s.extractImageName(imageLower)
-	
+	if imageName == "" {
+		// No suggestion rather than a misleading default
+		return ""
+	}
+
+	mappings := s.ubiMappings()
+
 	// Check for exact matches first
-	if ubiImage, exists := ubiImageMappings[imageName]; exists {
+	if ubiImage, exists := mappings[imageName]; exists {
 		return ubiImage
 	}
 	
 	// Check for partial matches
-	for pattern, ubiImage := range ubiImageMappings {
+	for pattern, ubiImage := range mappings {
 		if strings.Contains(imageName, pattern) {
 			return ubiImage
 		}
 	}
 	
 	// Return default UBI image
-	return ubiImageMappings["default"]
+	return mappings["default"]
 }
 
-// extractImageName extracts the image name from a full image reference
+// extractImageName extracts the image name from a full image reference, without registry, tag or
+// digest. It is empty when the reference does not name an image: an unresolved build argument or
+// a bare digest or image ID.
 func (s *Server) extractImageName(image string) string {
-	// Remove registry part if present
-	parts := strings.Split(image, "/")
-	imagePart := parts[len(parts)-1]
-	
-	// Remove tag if present
-	if colonIndex := strings.Index(imagePart, ":"); colonIndex != -1 {
-		imagePart = imagePart[:colonIndex]
-	}
-	
-	// Remove digest if present
-	if atIndex := strings.Index(imagePart, "@"); atIndex != -1 {
-		imagePart = imagePart[:atIndex]
+	if strings.Contains(image, "$") || imageIDPattern.MatchString(image) {
+		return ""
 	}
-	
-	return imagePart
+	repository := imageRepository(image)
+	return repository[strings.LastIndex(repository, "/")+1:]
 }
 
-// generateUBIDockerfile generates a new Dockerfile with UBI base image
-func (s *Server) generateUBIDockerfile(ctx context.Context, originalDockerfile, suggestedUBI string) (string, error) {
+// generateUBIDockerfile generates a Dockerfile.ubi next to the original Dockerfile where every
+// non-UBI stage uses its suggested UBI base image
+func (s *Server) generateUBIDockerfile(ctx context.Context, originalDockerfile string, validation *UBIValidation) (string, error) {
 	// Read original Dockerfile
 	content, err := os.ReadFile(originalDockerfile)
 	if err != nil {
 		return "", fmt.Errorf("failed to read original Dockerfile: %v", err)
 	}
 
-	// Replace the FROM instruction
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(strings.ToUpper(trimmedLine), "FROM ") {
-			// Replace with UBI image
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				// Preserve any alias (AS name)
-				if len(parts) >= 4 && strings.ToUpper(parts[2]) == "AS" {
-					newLines = append(newLines, fmt.Sprintf("FROM %s AS %s", suggestedUBI, parts[3]))
-				} else {
-					newLines = append(newLines, fmt.Sprintf("FROM %s", suggestedUBI))
-				}
-			} else {
-				newLines = append(newLines, line)
-			}
-		} else {
-			newLines = append(newLines, line)
-		}
-	}
-	
 	// Write to new file
 	newDockerfilePath := filepath.Join(filepath.Dir(originalDockerfile), "Dockerfile.ubi")
-	newContent := strings.Join(newLines, "\n")
-	
-	err = os.WriteFile(newDockerfilePath, []byte(newContent), 0644)
-	if err != nil {
+	if err := os.WriteFile(newDockerfilePath, []byte(ubiDockerfileContent(string(content), validation)), 0644); err != nil {
 		return "", fmt.Errorf("failed to write UBI Dockerfile: %v", err)
 	}
-	
+
 	return newDockerfilePath, nil
 }
 
+// ubiDockerfileContent rewrites the FROM lines of the non-UBI stages of a Dockerfile to their
+// suggested UBI base image. Stages built from earlier stages, flags and AS aliases are preserved.
+func ubiDockerfileContent(content string, validation *UBIValidation) string {
+	lines := strings.Split(content, "\n")
+	for i, stage := range parseDockerfileStages(content) {
+		if i >= len(validation.Stages) {
+			break
+		}
+		result := validation.Stages[i]
+		if result.IsUBI || result.FromStage != "" || result.SuggestedUBIImage == "" {
+			continue
+		}
+		line := lines[stage.line]
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		fields := strings.Fields(line)
+		for f := 1; f < len(fields); f++ {
+			if !strings.HasPrefix(fields[f], "--") {
+				fields[f] = result.SuggestedUBIImage
+				break
+			}
+		}
+		lines[stage.line] = indent + strings.Join(fields, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
 // validateDockerfileForSecurity performs additional security validations
 func (s *Server) validateDockerfileForSecurity(ctx context.Context, dockerfilePath string) ([]string, []string, error) {
 	var warnings []string
@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/sur309/openshift-mcp-server/pkg/config"
+)
+
+func TestSuggestUBIAlternative(t *testing.T) {
+	t.Run("suggests UBI 9 by default", func(t *testing.T) {
+		t.Setenv("UBI_VERSION", "")
+		s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{}}}
+		if image := s.suggestUBIAlternative("node:20-alpine"); image != "registry.access.redhat.com/ubi9/nodejs-20:latest" {
+			t.Fatalf("expected the UBI 9 Node.js image, got %s", image)
+		}
+	})
+	t.Run("suggests UBI 8 when configured", func(t *testing.T) {
+		t.Setenv("UBI_VERSION", "8")
+		s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{}}}
+		if image := s.suggestUBIAlternative("ubuntu:22.04"); image != "registry.access.redhat.com/ubi8/ubi:latest" {
+			t.Fatalf("expected the UBI 8 image, got %s", image)
+		}
+	})
+	t.Run("falls back to UBI 9 for unsupported versions", func(t *testing.T) {
+		s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{UBIVersion: "7"}}}
+		if version := s.ubiVersion(); version != "9" {
+			t.Fatalf("expected UBI 9, got %s", version)
+		}
+	})
+	t.Run("uses the mappings file over the built-in mappings", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ubi-mappings.json")
+		mappings := `{"node": "mirror.example.com/ubi9/nodejs-20:latest", "Elixir": "mirror.example.com/ubi9/ubi:latest"}`
+		if err := os.WriteFile(path, []byte(mappings), 0644); err != nil {
+			t.Fatalf("failed to write mappings: %v", err)
+		}
+		s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{UBIImageMappingsFile: path}}}
+		if image := s.suggestUBIAlternative("node:20"); image != "mirror.example.com/ubi9/nodejs-20:latest" {
+			t.Fatalf("expected the mirrored Node.js image, got %s", image)
+		}
+		if image := s.suggestUBIAlternative("elixir:1.16"); image != "mirror.example.com/ubi9/ubi:latest" {
+			t.Fatalf("expected the added mapping, got %s", image)
+		}
+		if image := s.suggestUBIAlternative("ruby:3.3"); image != "registry.access.redhat.com/ubi9/ruby-33:latest" {
+			t.Fatalf("expected the built-in Ruby mapping, got %s", image)
+		}
+		if !s.isUBIImage("mirror.example.com/ubi9/nodejs-20:1-45") {
+			t.Fatalf("expected the mirrored image to be UBI")
+		}
+	})
+	t.Run("falls back to the built-in mappings when the file is missing", func(t *testing.T) {
+		s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{UBIImageMappingsFile: filepath.Join(t.TempDir(), "missing.json")}}}
+		if image := s.suggestUBIAlternative("python:3.12"); image != "registry.access.redhat.com/ubi9/python-311:latest" {
+			t.Fatalf("expected the built-in Python mapping, got %s", image)
+		}
+	})
+}
+
+func TestValidateUBIComplianceMultiStage(t *testing.T) {
+	t.Setenv("UBI_VERSION", "9")
+	s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{}}}
+	dockerfile := filepath.Join(t.TempDir(), "Dockerfile")
+	content := "FROM --platform=$BUILDPLATFORM registry.access.redhat.com/ubi9/go-toolset:latest AS build\n" +
+		"RUN go build -o /app .\n" +
+		"FROM build AS test\n" +
+		"RUN go test ./...\n" +
+		"FROM alpine:3.20\n" +
+		"COPY --from=build /app /app\n"
+	if err := os.WriteFile(dockerfile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write Dockerfile: %v", err)
+	}
+	validation, err := s.validateUBICompliance(context.Background(), dockerfile)
+	if err != nil {
+		t.Fatalf("validateUBICompliance failed %v", err)
+	}
+	t.Run("reports every stage", func(t *testing.T) {
+		if len(validation.Stages) != 3 {
+			t.Fatalf("expected 3 stages, got %d", len(validation.Stages))
+		}
+		if validation.Stages[0].Alias != "build" || !validation.Stages[0].IsUBI {
+			t.Fatalf("expected the UBI build stage, got %+v", validation.Stages[0])
+		}
+		if validation.Stages[1].FromStage != "build" || !validation.Stages[1].IsUBI {
+			t.Fatalf("expected the test stage to inherit the build stage, got %+v", validation.Stages[1])
+		}
+	})
+	t.Run("judges the runtime stage", func(t *testing.T) {
+		if validation.IsUBI || !validation.Stages[2].IsRuntime {
+			t.Fatalf("expected the non-UBI runtime stage to fail, got %+v", validation)
+		}
+		if validation.CurrentBaseImage != "alpine:3.20" || validation.SuggestedUBIImage != "registry.access.redhat.com/ubi9/ubi-minimal:latest" {
+			t.Fatalf("unexpected runtime verdict %+v", validation)
+		}
+	})
+	t.Run("rewrites the non-UBI stages only", func(t *testing.T) {
+		path, err := s.generateUBIDockerfile(context.Background(), dockerfile, validation)
+		if err != nil {
+			t.Fatalf("generateUBIDockerfile failed %v", err)
+		}
+		generated, _ := os.ReadFile(path)
+		expected := "FROM --platform=$BUILDPLATFORM registry.access.redhat.com/ubi9/go-toolset:latest AS build\n" +
+			"RUN go build -o /app .\n" +
+			"FROM build AS test\n" +
+			"RUN go test ./...\n" +
+			"FROM registry.access.redhat.com/ubi9/ubi-minimal:latest\n" +
+			"COPY --from=build /app /app\n"
+		if string(generated) != expected {
+			t.Fatalf("unexpected Dockerfile:\n%s", generated)
+		}
+	})
+}
+
+func TestValidateUBIComplianceBaseImageForms(t *testing.T) {
+	t.Setenv("UBI_VERSION", "9")
+	s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{}}}
+	for name, tc := range map[string]struct {
+		dockerfile     string
+		baseImage      string
+		suggested      string
+		undeterminable bool
+	}{
+		"argument default": {
+			dockerfile: "ARG BASE=alpine:3.20\nFROM ${BASE}\n",
+			baseImage:  "alpine:3.20",
+			suggested:  "registry.access.redhat.com/ubi9/ubi-minimal:latest",
+		},
+		"argument fallback": {
+			dockerfile: "ARG NODE_VERSION\nFROM node:${NODE_VERSION:-20}\n",
+			baseImage:  "node:20",
+			suggested:  "registry.access.redhat.com/ubi9/nodejs-20:latest",
+		},
+		"digest": {
+			dockerfile: "FROM docker.io/library/python@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef\n",
+			baseImage:  "docker.io/library/python@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			suggested:  "registry.access.redhat.com/ubi9/python-311:latest",
+		},
+		"tag and digest": {
+			dockerfile: "FROM golang:1.22@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef\n",
+			baseImage:  "golang:1.22@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			suggested:  "registry.access.redhat.com/ubi9/go-toolset:latest",
+		},
+		"unset argument": {
+			dockerfile:     "ARG BASE\nFROM ${BASE}@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef\n",
+			baseImage:      "${BASE}@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			undeterminable: true,
+		},
+		"digest only": {
+			dockerfile:     "FROM sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef\n",
+			baseImage:      "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			undeterminable: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dockerfile := filepath.Join(t.TempDir(), "Dockerfile")
+			if err := os.WriteFile(dockerfile, []byte(tc.dockerfile), 0644); err != nil {
+				t.Fatalf("failed to write Dockerfile: %v", err)
+			}
+			validation, err := s.validateUBICompliance(context.Background(), dockerfile)
+			if err != nil {
+				t.Fatalf("validateUBICompliance failed %v", err)
+			}
+			stage := validation.Stages[0]
+			if stage.BaseImage != tc.baseImage {
+				t.Fatalf("expected base image %s, got %s", tc.baseImage, stage.BaseImage)
+			}
+			if stage.Undeterminable != tc.undeterminable || validation.SuggestedUBIImage != tc.suggested {
+				t.Fatalf("expected undeterminable %t and suggestion %q, got %+v", tc.undeterminable, tc.suggested, stage)
+			}
+		})
+	}
+}
+
+func TestValidateDockerfile(t *testing.T) {
+	t.Setenv("UBI_VERSION", "9")
+	s := &Server{configuration: &Configuration{StaticConfig: &config.StaticConfig{}}}
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"content": "FROM golang:1.22 AS build\nRUN go build -o /app .\nFROM alpine:3.20\nCOPY --from=build /app /app\n",
+		"fix":     true,
+	}
+	result, err := s.validateDockerfile(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("validateDockerfile failed %v %v", err, result.Content)
+	}
+	report := struct {
+		Source        string        `json:"source"`
+		Passed        bool          `json:"passed"`
+		UBICompliance UBIValidation `json:"ubi_compliance"`
+		UBIDockerfile string        `json:"ubi_dockerfile"`
+	}{}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report); err != nil {
+		t.Fatalf("failed to parse report: %v", err)
+	}
+	t.Run("reports the inline Dockerfile as failing", func(t *testing.T) {
+		if report.Source != "inline" || report.Passed || len(report.UBICompliance.Stages) != 2 {
+			t.Fatalf("unexpected report %+v", report)
+		}
+	})
+	t.Run("returns the fixed Dockerfile", func(t *testing.T) {
+		expected := "FROM registry.access.redhat.com/ubi9/go-toolset:latest AS build\nRUN go build -o /app .\nFROM registry.access.redhat.com/ubi9/ubi-minimal:latest\nCOPY --from=build /app /app\n"
+		if report.UBIDockerfile != expected {
+			t.Fatalf("unexpected fixed Dockerfile:\n%s", report.UBIDockerfile)
+		}
+	})
+	t.Run("requires exactly one source", func(t *testing.T) {
+		request.Params.Arguments = map[string]interface{}{"content": "FROM alpine", "git_url": "https://github.com/org/app.git"}
+		if result, _ := s.validateDockerfile(context.Background(), request); !result.IsError {
+			t.Fatalf("expected an error for two sources")
+		}
+	})
+}
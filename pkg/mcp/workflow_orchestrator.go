@@ -3,18 +3,29 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"maps"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"k8s.io/klog/v2"
 )
 
 // WorkflowOrchestrator manages intelligent tool invocation based on user prompts
 type WorkflowOrchestrator struct {
-	server    *Server
+	server *Server
+	// mu guards workflows once the orchestrator is created
+	mu        sync.RWMutex
 	workflows map[string]*Workflow
+	// handlers overrides the tools steps invoke, by tool name
+	handlers map[string]server.ToolHandlerFunc
+	// builtIns are the workflows defined in code, by key
+	builtIns map[string]*Workflow
+	// storePath is where custom workflows are persisted to, empty keeps them in memory only
+	storePath string
 }
 
 // Workflow represents a sequence of tool invocations
@@ -24,6 +35,10 @@ type Workflow struct {
 	Keywords    []string            `json:"keywords"`
 	Steps       []WorkflowStep      `json:"steps"`
 	Conditions  []WorkflowCondition `json:"conditions"`
+
+	// patterns caches the compiled patterns of regex conditions, by condition index
+	patternsOnce sync.Once
+	patterns     []*regexp.Regexp
 }
 
 // WorkflowStep represents a single step in a workflow
@@ -62,6 +77,8 @@ type WorkflowStepResult struct {
 	Success    bool                   `json:"success"`
 	Error      string                 `json:"error,omitempty"`
 	Duration   time.Duration          `json:"duration"`
+	// Compensation is set on OnFailure steps, run because an earlier step failed
+	Compensation bool `json:"compensation,omitempty"`
 }
 
 // NewWorkflowOrchestrator creates a new workflow orchestrator
@@ -73,6 +90,19 @@ func NewWorkflowOrchestrator(server *Server) *WorkflowOrchestrator {
 
 	// Initialize built-in workflows
 	wo.initializeBuiltInWorkflows()
+	wo.builtIns = make(map[string]*Workflow, len(wo.workflows))
+	for key, workflow := range wo.workflows {
+		wo.builtIns[key] = workflow
+	}
+
+	// A store that cannot be read leaves the custom workflows in memory only
+	if server.workflowStorePath != "" {
+		wo.storePath = server.workflowStorePath
+		if err := wo.loadWorkflows(); err != nil {
+			klog.Errorf("Failed to load the workflow store, custom workflows will not be persisted: %v", err)
+			wo.storePath = ""
+		}
+	}
 
 	return wo
 }
@@ -100,7 +130,9 @@ func (wo *WorkflowOrchestrator) initializeBuiltInWorkflows() {
 					{
 						Tool:        "container_push",
 						Description: "Push built image to registry",
-						Parameters:  map[string]interface{}{},
+						Parameters: map[string]interface{}{
+							"image_name": "{{ steps.container_build.full_image }}",
+						},
 					},
 				},
 			},
@@ -127,10 +159,16 @@ func (wo *WorkflowOrchestrator) initializeBuiltInWorkflows() {
 					{
 						Tool:        "container_push",
 						Description: "Push to registry",
+						Parameters: map[string]interface{}{
+							"image_name": "{{ steps.container_build.full_image }}",
+						},
 						OnSuccess: []WorkflowStep{
 							{
 								Tool:        "repo_auto_deploy",
 								Description: "Deploy to OpenShift",
+								Parameters: map[string]interface{}{
+									"url": "{{ steps.container_build.source_info.source }}",
+								},
 							},
 						},
 					},
@@ -155,6 +193,10 @@ func (wo *WorkflowOrchestrator) initializeBuiltInWorkflows() {
 					"format": "security",
 				},
 			},
+			{
+				Tool:        "container_scan",
+				Description: "Scan container image for vulnerabilities",
+			},
 		},
 	}
 
@@ -189,7 +231,8 @@ func (wo *WorkflowOrchestrator) AnalyzePrompt(prompt string) (*Workflow, map[str
 	extractedParams = wo.extractParametersFromPrompt(prompt)
 
 	// Score each workflow
-	for name, workflow := range wo.workflows {
+	workflows := wo.ListWorkflows()
+	for name, workflow := range workflows {
 		score := wo.scoreWorkflow(prompt, workflow)
 		klog.V(2).Infof("Workflow %s scored %d for prompt: %s", name, score, prompt)
 
@@ -203,7 +246,7 @@ func (wo *WorkflowOrchestrator) AnalyzePrompt(prompt string) (*Workflow, map[str
 		return nil, extractedParams, fmt.Errorf("no suitable workflow found for prompt (best score: %d)", bestScore)
 	}
 
-	selectedWorkflow := wo.workflows[bestMatch]
+	selectedWorkflow := workflows[bestMatch]
 	klog.V(1).Infof("Selected workflow: %s (score: %d)", selectedWorkflow.Name, bestScore)
 
 	return selectedWorkflow, extractedParams, nil
@@ -221,7 +264,7 @@ func (wo *WorkflowOrchestrator) scoreWorkflow(prompt string, workflow *Workflow)
 	}
 
 	// Check conditions
-	for _, condition := range workflow.Conditions {
+	for i, condition := range workflow.Conditions {
 		matched := false
 		switch condition.Type {
 		case "keyword":
@@ -229,8 +272,8 @@ func (wo *WorkflowOrchestrator) scoreWorkflow(prompt string, workflow *Workflow)
 				matched = true
 			}
 		case "regex":
-			if matched, _ := regexp.MatchString(condition.Pattern, prompt); matched {
-				matched = true
+			if pattern := workflow.conditionPattern(i); pattern != nil {
+				matched = pattern.MatchString(prompt)
 			}
 		case "context":
 			// Simple context matching
@@ -309,31 +352,14 @@ func (wo *WorkflowOrchestrator) ExecuteWorkflow(ctx context.Context, workflow *W
 
 	klog.V(1).Infof("Starting workflow execution: %s", workflow.Name)
 
-	// Execute each step
+	// Execute each step, a failed step ends the workflow once its OnFailure steps ran
 	for _, step := range workflow.Steps {
-		stepResult, err := wo.executeWorkflowStep(ctx, step, userParams)
-		result.ExecutedSteps = append(result.ExecutedSteps, *stepResult)
-
-		if err != nil || !stepResult.Success {
+		if err := wo.runWorkflowStep(ctx, step, userParams, result, false); err != nil {
 			result.Success = false
-			result.Error = fmt.Sprintf("Step %s failed: %v", step.Tool, err)
-			klog.V(1).Infof("Workflow step failed: %s - %v", step.Tool, err)
+			result.Error = err.Error()
+			klog.V(1).Infof("Workflow step failed: %v", err)
 			break
 		}
-
-		// Execute conditional next steps
-		if stepResult.Success && len(step.OnSuccess) > 0 {
-			for _, nextStep := range step.OnSuccess {
-				nextStepResult, err := wo.executeWorkflowStep(ctx, nextStep, userParams)
-				result.ExecutedSteps = append(result.ExecutedSteps, *nextStepResult)
-
-				if err != nil || !nextStepResult.Success {
-					result.Success = false
-					result.Error = fmt.Sprintf("Conditional step %s failed: %v", nextStep.Tool, err)
-					break
-				}
-			}
-		}
 	}
 
 	result.Duration = time.Since(startTime)
@@ -346,8 +372,38 @@ func (wo *WorkflowOrchestrator) ExecuteWorkflow(ctx context.Context, workflow *W
 	return result, nil
 }
 
-// executeWorkflowStep executes a single workflow step
-func (wo *WorkflowOrchestrator) executeWorkflowStep(ctx context.Context, step WorkflowStep, userParams map[string]interface{}) (*WorkflowStepResult, error) {
+// runWorkflowStep executes a step and records it, then its OnSuccess chain or, when it fails, its
+// OnFailure steps. OnFailure steps run as compensation: all of them run even if one fails, and a
+// failing compensation step does not trigger further OnFailure steps.
+func (wo *WorkflowOrchestrator) runWorkflowStep(ctx context.Context, step WorkflowStep, userParams map[string]interface{}, result *WorkflowResult, compensation bool) error {
+	stepResult, err := wo.executeWorkflowStep(ctx, step, userParams, stepOutputs(result.ExecutedSteps))
+	stepResult.Compensation = compensation
+	result.ExecutedSteps = append(result.ExecutedSteps, *stepResult)
+
+	if err != nil || !stepResult.Success {
+		failure := fmt.Errorf("step %s failed: %s", step.Tool, stepResult.Error)
+		if compensation {
+			return failure
+		}
+		for _, failureStep := range step.OnFailure {
+			if compensationErr := wo.runWorkflowStep(ctx, failureStep, userParams, result, true); compensationErr != nil {
+				klog.V(1).Infof("Compensation step failed: %v", compensationErr)
+			}
+		}
+		return failure
+	}
+
+	for _, nextStep := range step.OnSuccess {
+		if err := wo.runWorkflowStep(ctx, nextStep, userParams, result, compensation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeWorkflowStep executes a single workflow step, resolving its references to the outputs of
+// earlier steps
+func (wo *WorkflowOrchestrator) executeWorkflowStep(ctx context.Context, step WorkflowStep, userParams map[string]interface{}, outputs map[string]map[string]interface{}) (*WorkflowStepResult, error) {
 	startTime := time.Now()
 	stepResult := &WorkflowStepResult{
 		Tool:       step.Tool,
@@ -359,8 +415,22 @@ func (wo *WorkflowOrchestrator) executeWorkflowStep(ctx context.Context, step Wo
 		stepResult.Parameters[k] = v
 	}
 	for k, v := range userParams {
+		// Parameters wired to the output of an earlier step are not overridden
+		if hasStepReference(step.Parameters[k]) {
+			continue
+		}
 		stepResult.Parameters[k] = v
 	}
+	for k, v := range stepResult.Parameters {
+		resolved, err := resolveStepReferences(v, outputs)
+		if err != nil {
+			stepResult.Success = false
+			stepResult.Error = fmt.Sprintf("invalid parameter %s: %v", k, err)
+			stepResult.Duration = time.Since(startTime)
+			return stepResult, err
+		}
+		stepResult.Parameters[k] = resolved
+	}
 
 	klog.V(2).Infof("Executing workflow step: %s with parameters: %v", step.Tool, stepResult.Parameters)
 
@@ -395,25 +465,48 @@ func (wo *WorkflowOrchestrator) executeWorkflowStep(ctx context.Context, step Wo
 
 // executeTool executes a specific MCP tool
 func (wo *WorkflowOrchestrator) executeTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// This would normally use the server's tool registry
-	// For now, we'll call the tools directly based on the tool name
-	switch request.Params.Name {
-	case "container_build":
-		return wo.server.containerBuild(ctx, request)
-	case "container_push":
-		return wo.server.containerPush(ctx, request)
-	case "container_list":
-		return wo.server.containerList(ctx, request)
-	case "container_remove":
-		return wo.server.containerRemove(ctx, request)
-	case "container_inspect":
-		return wo.server.containerInspect(ctx, request)
-	case "repo_auto_deploy":
-		// This would call the repo deployment tool
-		return &mcp.CallToolResult{Content: []mcp.Content{mcp.TextContent{Type: "text", Text: "Deployment initiated"}}}, nil
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", request.Params.Name)
+	if handler, exists := wo.handlers[request.Params.Name]; exists {
+		return handler(ctx, request)
+	}
+	handler, err := wo.server.workflowToolHandler(request.Params.Name)
+	if err != nil {
+		return nil, err
+	}
+	// Steps are recorded in the audit log like the tool calls of clients
+	return wo.server.toolCallAuditMiddleware(handler)(ctx, request)
+}
+
+// validateSteps checks that every step, including the OnSuccess and OnFailure chains, invokes a
+// tool workflows can run
+func (wo *WorkflowOrchestrator) validateSteps(steps []WorkflowStep) error {
+	for _, step := range steps {
+		if _, exists := wo.handlers[step.Tool]; !exists {
+			if _, err := wo.server.workflowToolHandler(step.Tool); err != nil {
+				return err
+			}
+		}
+		if err := wo.validateSteps(step.OnSuccess); err != nil {
+			return err
+		}
+		if err := wo.validateSteps(step.OnFailure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workflowToolHandler returns the handler of a registered tool for a workflow step. Tools disabled
+// by the configuration cannot be run by workflows either, nor can the workflow tools themselves so
+// workflows do not recurse.
+func (s *Server) workflowToolHandler(name string) (server.ToolHandlerFunc, error) {
+	if strings.HasPrefix(name, "workflow_") {
+		return nil, fmt.Errorf("workflow steps cannot invoke the workflow tool %s", name)
+	}
+	tool, exists := s.tools[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown tool: %s, it is not registered or is disabled by the server configuration", name)
 	}
+	return tool.Handler, nil
 }
 
 // generateRecommendations generates recommendations based on workflow results
@@ -436,25 +529,78 @@ func (wo *WorkflowOrchestrator) generateRecommendations(result *WorkflowResult)
 		}
 	} else {
 		recommendations = append(recommendations, "❌ Workflow failed - check the error details above")
+		for _, step := range result.ExecutedSteps {
+			if step.Compensation {
+				recommendations = append(recommendations, "↩️ Failure steps ran to compensate, check their results before retrying")
+				break
+			}
+		}
 		recommendations = append(recommendations, "🔍 Try running individual tools to debug the issue")
 	}
 
 	return recommendations
 }
 
-// AddCustomWorkflow allows adding custom workflows
-func (wo *WorkflowOrchestrator) AddCustomWorkflow(workflow *Workflow) {
-	wo.workflows[strings.ToLower(strings.ReplaceAll(workflow.Name, " ", "_"))] = workflow
+// conditionPattern returns the compiled pattern of the i-th condition, nil when it is not a regex
+// condition or its pattern does not compile. Patterns are compiled on first use.
+func (w *Workflow) conditionPattern(i int) *regexp.Regexp {
+	w.patternsOnce.Do(func() {
+		w.patterns = make([]*regexp.Regexp, len(w.Conditions))
+		for i, condition := range w.Conditions {
+			if condition.Type != "regex" {
+				continue
+			}
+			pattern, err := regexp.Compile(condition.Pattern)
+			if err != nil {
+				klog.V(1).Infof("Ignoring invalid regex condition %q of workflow %s: %v", condition.Pattern, w.Name, err)
+				continue
+			}
+			w.patterns[i] = pattern
+		}
+	})
+	if i >= len(w.patterns) {
+		return nil
+	}
+	return w.patterns[i]
+}
+
+// AddCustomWorkflow adds or replaces a custom workflow and persists it
+func (wo *WorkflowOrchestrator) AddCustomWorkflow(workflow *Workflow) error {
+	wo.mu.Lock()
+	defer wo.mu.Unlock()
+	wo.workflows[workflowKey(workflow.Name)] = workflow
 	klog.V(1).Infof("Added custom workflow: %s", workflow.Name)
+	return wo.saveWorkflows()
+}
+
+// DeleteWorkflow removes a workflow by key or name and persists the removal. Built-in workflows
+// are only removed when forced.
+func (wo *WorkflowOrchestrator) DeleteWorkflow(name string, force bool) error {
+	wo.mu.Lock()
+	defer wo.mu.Unlock()
+	key := workflowKey(name)
+	if _, exists := wo.workflows[key]; !exists {
+		return fmt.Errorf("workflow not found: %s", name)
+	}
+	if wo.isBuiltInWorkflow(key) && !force {
+		return fmt.Errorf("workflow %s is built in, set force to delete it", key)
+	}
+	delete(wo.workflows, key)
+	klog.V(1).Infof("Deleted workflow: %s", key)
+	return wo.saveWorkflows()
 }
 
 // ListWorkflows returns all available workflows
 func (wo *WorkflowOrchestrator) ListWorkflows() map[string]*Workflow {
-	return wo.workflows
+	wo.mu.RLock()
+	defer wo.mu.RUnlock()
+	return maps.Clone(wo.workflows)
 }
 
 // GetWorkflow returns a specific workflow by name
 func (wo *WorkflowOrchestrator) GetWorkflow(name string) (*Workflow, bool) {
+	wo.mu.RLock()
+	defer wo.mu.RUnlock()
 	workflow, exists := wo.workflows[name]
 	return workflow, exists
 }
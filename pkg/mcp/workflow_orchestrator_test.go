@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestExecuteWorkflowRunsNestedOnSuccessSteps(t *testing.T) {
+	var called []string
+	record := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = append(called, request.Params.Name)
+		return NewTextResult(`{"full_image": "quay.io/org/app:v1", "source_info": {"source": "https://github.com/org/app.git"}}`, nil), nil
+	}
+	wo := NewWorkflowOrchestrator(&Server{})
+	wo.handlers = map[string]server.ToolHandlerFunc{
+		"container_build":  record,
+		"container_push":   record,
+		"repo_auto_deploy": record,
+	}
+	result, err := wo.ExecuteWorkflow(context.Background(), wo.workflows["complete_cicd"], map[string]interface{}{"image_name": "app"})
+	t.Run("ExecuteWorkflow returns no error", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("ExecuteWorkflow failed %v", err)
+		}
+		if !result.Success {
+			t.Fatalf("workflow failed: %s", result.Error)
+		}
+	})
+	t.Run("ExecuteWorkflow runs all three levels in order", func(t *testing.T) {
+		expected := []string{"container_build", "container_push", "repo_auto_deploy"}
+		if len(called) != len(expected) {
+			t.Fatalf("expected tools %v to be called, got %v", expected, called)
+		}
+		for i, tool := range expected {
+			if called[i] != tool {
+				t.Fatalf("expected tools %v to be called, got %v", expected, called)
+			}
+		}
+	})
+	t.Run("ExecuteWorkflow records every executed step", func(t *testing.T) {
+		if len(result.ExecutedSteps) != 3 {
+			t.Fatalf("expected 3 executed steps, got %d", len(result.ExecutedSteps))
+		}
+		for _, step := range result.ExecutedSteps {
+			if !step.Success || step.Compensation {
+				t.Fatalf("unexpected step result %+v", step)
+			}
+		}
+	})
+}
+
+func TestExecuteWorkflowRunsOnFailureSteps(t *testing.T) {
+	var called []string
+	succeed := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = append(called, request.Params.Name)
+		return NewTextResult("ok", nil), nil
+	}
+	fail := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = append(called, request.Params.Name)
+		return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{mcp.NewTextContent("failed")}}, nil
+	}
+	wo := NewWorkflowOrchestrator(&Server{})
+	wo.handlers = map[string]server.ToolHandlerFunc{"build": fail, "cleanup": fail, "notify": succeed, "push": succeed}
+	workflow := &Workflow{
+		Name: "failing",
+		Steps: []WorkflowStep{
+			{
+				Tool:      "build",
+				OnSuccess: []WorkflowStep{{Tool: "push"}},
+				OnFailure: []WorkflowStep{
+					// A failing compensation step must not trigger its own failure steps
+					{Tool: "cleanup", OnFailure: []WorkflowStep{{Tool: "cleanup"}}},
+					{Tool: "notify"},
+				},
+			},
+			{Tool: "push"},
+		},
+	}
+	result, err := wo.ExecuteWorkflow(context.Background(), workflow, map[string]interface{}{})
+	t.Run("ExecuteWorkflow reports the failure", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("ExecuteWorkflow failed %v", err)
+		}
+		if result.Success {
+			t.Fatalf("expected workflow to fail")
+		}
+	})
+	t.Run("ExecuteWorkflow runs every OnFailure step once and stops", func(t *testing.T) {
+		expected := []string{"build", "cleanup", "notify"}
+		if len(called) != len(expected) {
+			t.Fatalf("expected tools %v to be called, got %v", expected, called)
+		}
+		for i, tool := range expected {
+			if called[i] != tool {
+				t.Fatalf("expected tools %v to be called, got %v", expected, called)
+			}
+		}
+	})
+	t.Run("ExecuteWorkflow flags OnFailure steps as compensation", func(t *testing.T) {
+		for _, step := range result.ExecutedSteps {
+			if step.Compensation != (step.Tool != "build") {
+				t.Fatalf("unexpected compensation flag on %+v", step)
+			}
+		}
+	})
+}
+
+func TestScoreWorkflowMatchesRegexConditions(t *testing.T) {
+	wo := &WorkflowOrchestrator{}
+	workflow := &Workflow{
+		Name: "regex",
+		Conditions: []WorkflowCondition{
+			{Type: "regex", Pattern: `deploy\s+v\d+`, Required: true, Confidence: 40},
+			{Type: "regex", Pattern: `(`, Confidence: 40},
+		},
+	}
+	t.Run("scoreWorkflow adds the confidence of matching regex conditions", func(t *testing.T) {
+		if score := wo.scoreWorkflow("deploy v2 now", workflow); score != 40 {
+			t.Fatalf("expected score 40, got %d", score)
+		}
+	})
+	t.Run("scoreWorkflow penalizes required regex conditions that do not match", func(t *testing.T) {
+		if score := wo.scoreWorkflow("build the image", workflow); score != -30 {
+			t.Fatalf("expected score -30, got %d", score)
+		}
+	})
+	t.Run("scoreWorkflow caches compiled patterns", func(t *testing.T) {
+		if len(workflow.patterns) != 2 || workflow.patterns[0] == nil || workflow.patterns[1] != nil {
+			t.Fatalf("unexpected compiled patterns %v", workflow.patterns)
+		}
+	})
+}
+
+func TestExecuteWorkflowDispatchesToRegisteredTools(t *testing.T) {
+	var called []string
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = append(called, request.Params.Name)
+		return NewTextResult("ok", nil), nil
+	}
+	s := &Server{audit: newAuditLog(defaultAuditCapacity), tools: map[string]server.ServerTool{
+		"namespaces_list":  {Tool: mcp.NewTool("namespaces_list"), Handler: handler},
+		"workflow_execute": {Tool: mcp.NewTool("workflow_execute"), Handler: handler},
+	}}
+	wo := NewWorkflowOrchestrator(s)
+	t.Run("ExecuteWorkflow runs steps with the registered tool handler", func(t *testing.T) {
+		result, err := wo.ExecuteWorkflow(context.Background(), &Workflow{Name: "custom", Steps: []WorkflowStep{{Tool: "namespaces_list"}}}, map[string]interface{}{})
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteWorkflow failed %v %+v", err, result)
+		}
+		if len(called) != 1 || called[0] != "namespaces_list" {
+			t.Fatalf("expected namespaces_list to be called, got %v", called)
+		}
+	})
+	t.Run("ExecuteWorkflow records steps in the audit log", func(t *testing.T) {
+		records := s.audit.recent(10, func(AuditRecord) bool { return true })
+		if len(records) != 1 || records[0].Tool != "namespaces_list" {
+			t.Fatalf("expected an audit record for namespaces_list, got %v", records)
+		}
+	})
+	t.Run("validateSteps rejects unknown tools", func(t *testing.T) {
+		err := wo.validateSteps([]WorkflowStep{{Tool: "namespaces_list", OnFailure: []WorkflowStep{{Tool: "not_a_tool"}}}})
+		if err == nil || err.Error() != "unknown tool: not_a_tool, it is not registered or is disabled by the server configuration" {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+	t.Run("validateSteps rejects workflow tools", func(t *testing.T) {
+		if err := wo.validateSteps([]WorkflowStep{{Tool: "workflow_execute"}}); err == nil {
+			t.Fatalf("expected workflow_execute to be rejected")
+		}
+	})
+}
+
+func TestExecuteWorkflowResolvesStepReferences(t *testing.T) {
+	var arguments []map[string]interface{}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments = append(arguments, request.Params.Arguments.(map[string]interface{}))
+		return NewTextResult(`{"full_image": "quay.io/org/app:v1", "image_info": {"tags": ["v1", "latest"]}}`, nil), nil
+	}
+	wo := NewWorkflowOrchestrator(&Server{})
+	wo.handlers = map[string]server.ToolHandlerFunc{"container_build": handler, "container_push": handler}
+	t.Run("ExecuteWorkflow substitutes outputs of earlier steps", func(t *testing.T) {
+		arguments = nil
+		workflow := &Workflow{Name: "references", Steps: []WorkflowStep{{
+			Tool: "container_build",
+			OnSuccess: []WorkflowStep{{
+				Tool: "container_push",
+				Parameters: map[string]interface{}{
+					"image_name":      "{{ steps.container_build.full_image }}",
+					"message":         "pushing {{steps.container_build.full_image}}",
+					"additional_tags": "{{ steps.container_build.image_info.tags }}",
+				},
+			}},
+		}}}
+		result, err := wo.ExecuteWorkflow(context.Background(), workflow, map[string]interface{}{"image_name": "app"})
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteWorkflow failed %v %+v", err, result)
+		}
+		push := arguments[1]
+		if push["image_name"] != "quay.io/org/app:v1" {
+			t.Fatalf("expected image_name to be the built image, got %v", push["image_name"])
+		}
+		if push["message"] != "pushing quay.io/org/app:v1" {
+			t.Fatalf("expected reference formatted into message, got %v", push["message"])
+		}
+		if tags, ok := push["additional_tags"].([]interface{}); !ok || len(tags) != 2 {
+			t.Fatalf("expected additional_tags to keep the referenced list, got %v", push["additional_tags"])
+		}
+	})
+	t.Run("ExecuteWorkflow fails steps with unresolved references", func(t *testing.T) {
+		arguments = nil
+		workflow := &Workflow{Name: "unresolved", Steps: []WorkflowStep{{
+			Tool:       "container_push",
+			Parameters: map[string]interface{}{"image_name": "{{ steps.container_build.digest }}"},
+		}}}
+		result, err := wo.ExecuteWorkflow(context.Background(), workflow, map[string]interface{}{})
+		if err != nil || result.Success {
+			t.Fatalf("expected workflow to fail, got %v %+v", err, result)
+		}
+		if len(arguments) != 0 {
+			t.Fatalf("expected container_push not to be called, got %v", arguments)
+		}
+	})
+}
+
+func TestWorkflowStore(t *testing.T) {
+	s := &Server{workflowStorePath: filepath.Join(t.TempDir(), "workflows.json")}
+	wo := NewWorkflowOrchestrator(s)
+	if err := wo.AddCustomWorkflow(&Workflow{Name: "My Flow", Steps: []WorkflowStep{{Tool: "container_list"}}}); err != nil {
+		t.Fatalf("AddCustomWorkflow failed %v", err)
+	}
+	t.Run("DeleteWorkflow protects built-in workflows", func(t *testing.T) {
+		if err := wo.DeleteWorkflow("complete_cicd", false); err == nil {
+			t.Fatalf("expected complete_cicd not to be deleted without force")
+		}
+		if err := wo.DeleteWorkflow("complete_cicd", true); err != nil {
+			t.Fatalf("DeleteWorkflow failed %v", err)
+		}
+	})
+	t.Run("DeleteWorkflow reports unknown workflows", func(t *testing.T) {
+		if err := wo.DeleteWorkflow("not_a_workflow", false); err == nil {
+			t.Fatalf("expected an error for an unknown workflow")
+		}
+	})
+	t.Run("NewWorkflowOrchestrator reloads custom workflows and deletions", func(t *testing.T) {
+		reloaded := NewWorkflowOrchestrator(s)
+		if workflow, exists := reloaded.GetWorkflow("my_flow"); !exists || workflow.Steps[0].Tool != "container_list" {
+			t.Fatalf("expected my_flow to be reloaded, got %v", workflow)
+		}
+		if _, exists := reloaded.GetWorkflow("complete_cicd"); exists {
+			t.Fatalf("expected complete_cicd to stay deleted")
+		}
+		if len(reloaded.ListWorkflows()) != 4 {
+			t.Fatalf("expected 4 workflows, got %d", len(reloaded.ListWorkflows()))
+		}
+	})
+	t.Run("DeleteWorkflow removes custom workflows from the store", func(t *testing.T) {
+		if err := wo.DeleteWorkflow("My Flow", false); err != nil {
+			t.Fatalf("DeleteWorkflow failed %v", err)
+		}
+		if _, exists := NewWorkflowOrchestrator(s).GetWorkflow("my_flow"); exists {
+			t.Fatalf("expected my_flow to be deleted from the store")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// stepReference matches {{ steps.<tool>.<output> }} in step parameters. The output is a key of the
+// JSON result of the latest successful step running the tool, dot-separated for nested keys.
+//
+// Outputs of the container tools workflows usually chain:
+//   - container_build: full_image, status, build_duration, image_info.digest, image_info.tags,
+//     source_info.source, source_info.type
+//   - container_push: full_image, pushed_images, registry, total_pushed
+//   - repo_auto_deploy: execution_id, application.name, application.namespace, application.url
+var stepReference = regexp.MustCompile(`\{\{\s*steps\.([A-Za-z0-9_-]+)\.([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// hasStepReference reports whether a parameter value is a string referencing a step output
+func hasStepReference(value interface{}) bool {
+	text, ok := value.(string)
+	return ok && stepReference.MatchString(text)
+}
+
+// stepOutputs parses the JSON results of the successful steps by tool name, a later step replacing
+// the outputs of an earlier step running the same tool. Results that are not JSON objects have no
+// outputs.
+func stepOutputs(steps []WorkflowStepResult) map[string]map[string]interface{} {
+	outputs := make(map[string]map[string]interface{})
+	for _, step := range steps {
+		if !step.Success || step.Result == nil || len(step.Result.Content) == 0 {
+			continue
+		}
+		text, ok := step.Result.Content[0].(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		var output map[string]interface{}
+		if err := json.Unmarshal([]byte(text.Text), &output); err == nil {
+			outputs[step.Tool] = output
+		}
+	}
+	return outputs
+}
+
+// resolveStepReferences substitutes the step references in a parameter value. A string made of a
+// single reference takes the referenced value with its type, references within a longer string are
+// formatted into it.
+func resolveStepReferences(value interface{}, outputs map[string]map[string]interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		if match := stepReference.FindStringSubmatch(value); match != nil && match[0] == value {
+			return lookupStepOutput(outputs, match[1], match[2])
+		}
+		var err error
+		resolved := stepReference.ReplaceAllStringFunc(value, func(reference string) string {
+			match := stepReference.FindStringSubmatch(reference)
+			output, lookupErr := lookupStepOutput(outputs, match[1], match[2])
+			if lookupErr != nil {
+				if err == nil {
+					err = lookupErr
+				}
+				return reference
+			}
+			return fmt.Sprint(output)
+		})
+		return resolved, err
+	case []interface{}:
+		resolved := make([]interface{}, len(value))
+		for i, item := range value {
+			var err error
+			if resolved[i], err = resolveStepReferences(item, outputs); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			var err error
+			if resolved[key], err = resolveStepReferences(item, outputs); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+// lookupStepOutput returns the output of the latest successful step running a tool
+func lookupStepOutput(outputs map[string]map[string]interface{}, tool, key string) (interface{}, error) {
+	output, exists := outputs[tool]
+	if !exists {
+		return nil, fmt.Errorf("steps.%s.%s references no earlier successful %s step with a JSON result", tool, key, tool)
+	}
+	var value interface{} = output
+	for _, field := range strings.Split(key, ".") {
+		fields, ok := value.(map[string]interface{})
+		if ok {
+			value, ok = fields[field]
+		}
+		if !ok {
+			return nil, fmt.Errorf("steps.%s.%s is not an output of %s", tool, key, tool)
+		}
+	}
+	return value, nil
+}
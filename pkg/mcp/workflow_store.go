@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// workflowStorePathEnv overrides the file custom workflows are persisted to
+const workflowStorePathEnv = "WORKFLOW_STORE_PATH"
+
+// workflowStore is the persisted form of the workflow changes made at runtime. Built-in workflows
+// are defined in code and only recorded when they were deleted.
+type workflowStore struct {
+	Workflows       map[string]*Workflow `json:"workflows"`
+	DeletedBuiltIns []string             `json:"deleted_built_ins,omitempty"`
+}
+
+// workflowStorePathIn returns $WORKFLOW_STORE_PATH, or workflows.json in dir
+func workflowStorePathIn(dir string) string {
+	if path := os.Getenv(workflowStorePathEnv); path != "" {
+		return path
+	}
+	return filepath.Join(dir, "workflows.json")
+}
+
+// workflowKey returns the key a workflow is stored under, its lowercase name with underscores
+func workflowKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+}
+
+// isBuiltInWorkflow reports whether a key is one of the workflows defined in code
+func (wo *WorkflowOrchestrator) isBuiltInWorkflow(key string) bool {
+	_, exists := wo.builtIns[key]
+	return exists
+}
+
+// loadWorkflows adds the workflows persisted at the store path and removes the deleted built-ins
+func (wo *WorkflowOrchestrator) loadWorkflows() error {
+	data, err := os.ReadFile(wo.storePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read workflow store %s: %w", wo.storePath, err)
+	}
+	var store workflowStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return fmt.Errorf("failed to parse workflow store %s: %w", wo.storePath, err)
+	}
+	for _, key := range store.DeletedBuiltIns {
+		delete(wo.workflows, key)
+	}
+	for key, workflow := range store.Workflows {
+		wo.workflows[key] = workflow
+	}
+	klog.V(1).Infof("Loaded %d workflows from the workflow store", len(store.Workflows))
+	return nil
+}
+
+// storeForm returns the custom workflows and the deleted built-ins; callers must hold wo.mu
+func (wo *WorkflowOrchestrator) storeForm() workflowStore {
+	store := workflowStore{Workflows: make(map[string]*Workflow)}
+	for key, workflow := range wo.workflows {
+		if wo.builtIns[key] != workflow {
+			store.Workflows[key] = workflow
+		}
+	}
+	for key := range wo.builtIns {
+		if _, exists := wo.workflows[key]; !exists {
+			store.DeletedBuiltIns = append(store.DeletedBuiltIns, key)
+		}
+	}
+	sort.Strings(store.DeletedBuiltIns)
+	return store
+}
+
+// saveWorkflows persists the custom workflows and the deleted built-ins, nothing when the
+// orchestrator has no store path; callers must hold wo.mu
+func (wo *WorkflowOrchestrator) saveWorkflows() error {
+	if wo.storePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(wo.storeForm(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(wo.storePath, data); err != nil {
+		return fmt.Errorf("failed to write workflow store %s: %w", wo.storePath, err)
+	}
+	return nil
+}
+
+// snapshotWorkflows returns a deep copy of the workflow changes made at runtime
+func (wo *WorkflowOrchestrator) snapshotWorkflows() (workflowStore, error) {
+	wo.mu.RLock()
+	data, err := json.Marshal(wo.storeForm())
+	wo.mu.RUnlock()
+	var store workflowStore
+	if err == nil {
+		err = json.Unmarshal(data, &store)
+	}
+	return store, err
+}
+
+// restoreWorkflows replaces the workflows with the built-ins changed by a snapshot of the runtime
+// changes, and persists them
+func (wo *WorkflowOrchestrator) restoreWorkflows(store workflowStore) error {
+	wo.mu.Lock()
+	defer wo.mu.Unlock()
+	wo.workflows = maps.Clone(wo.builtIns)
+	for _, key := range store.DeletedBuiltIns {
+		delete(wo.workflows, key)
+	}
+	for key, workflow := range store.Workflows {
+		wo.workflows[key] = workflow
+	}
+	return wo.saveWorkflows()
+}
@@ -57,11 +57,11 @@ func (s *Server) initWorkflowTools() []server.ServerTool {
 		), Handler: s.workflowAnalyze},
 
 		{Tool: mcp.NewTool("workflow_create",
-			mcp.WithDescription("Create a custom workflow by defining a sequence of container operations. Allows users to create reusable automation for their specific use cases."),
+			mcp.WithDescription("Create a custom workflow by defining a sequence of container operations. Allows users to create reusable automation for their specific use cases. Custom workflows are persisted and survive restarts."),
 			mcp.WithString("name", mcp.Description("Unique name for the workflow. Use lowercase with underscores. Example: 'my_custom_build_flow'."), mcp.Required()),
 			mcp.WithString("description", mcp.Description("Human-readable description of what this workflow does."), mcp.Required()),
 			mcp.WithString("keywords", mcp.Description("Comma-separated keywords that trigger this workflow. Example: 'build,test,deploy,custom'.")),
-			mcp.WithString("steps", mcp.Description("JSON array of workflow steps. Each step should have 'tool', 'description', and 'parameters' fields."), mcp.Required()),
+			mcp.WithString("steps", mcp.Description("JSON array of workflow steps. Each step should have 'tool', 'description', and 'parameters' fields, and may chain 'on_success' and 'on_failure' steps. A step can invoke any tool registered on the server except the workflow tools. Parameters can reference the JSON result of an earlier step as '{{ steps.<tool>.<key> }}', e.g. '{{ steps.container_build.full_image }}'."), mcp.Required()),
 			mcp.WithString("conditions", mcp.Description("JSON array of trigger conditions. Each condition should have 'type', 'pattern', 'required', and 'confidence' fields.")),
 			// Tool annotations
 			mcp.WithTitleAnnotation("Workflow: Create Custom Workflow"),
@@ -69,6 +69,18 @@ func (s *Server) initWorkflowTools() []server.ServerTool {
 			mcp.WithDestructiveHintAnnotation(false),
 			mcp.WithOpenWorldHintAnnotation(false),
 		), Handler: s.workflowCreate},
+
+		{Tool: mcp.NewTool("workflow_delete",
+			mcp.WithDescription("Delete a custom workflow created with 'workflow_create'. Built-in workflows are protected unless force is set. The deletion is persisted and survives restarts."),
+			mcp.WithString("name", mcp.Description("Name of the workflow to delete, as listed by 'workflow_list'. Example: 'my_custom_build_flow'."), mcp.Required()),
+			mcp.WithBoolean("force", mcp.Description("Delete a built-in workflow ('build_and_push', 'complete_cicd', 'security_scan', 'registry_management'). Defaults to false.")),
+			// Tool annotations
+			mcp.WithTitleAnnotation("Workflow: Delete Workflow"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+		), Handler: s.workflowDelete},
 	}
 }
 
@@ -309,6 +321,14 @@ func (s *Server) workflowCreate(ctx context.Context, request mcp.CallToolRequest
 		return NewTextResult("", fmt.Errorf("invalid steps JSON: %v", err)), nil
 	}
 
+	// Initialize workflow orchestrator if not already done
+	if s.workflowOrchestrator == nil {
+		s.workflowOrchestrator = NewWorkflowOrchestrator(s)
+	}
+	if err := s.workflowOrchestrator.validateSteps(steps); err != nil {
+		return NewTextResult("", fmt.Errorf("invalid steps: %v", err)), nil
+	}
+
 	// Parse keywords
 	keywordsStr := getStringArg(args, "keywords", "")
 	var keywords []string
@@ -335,13 +355,10 @@ func (s *Server) workflowCreate(ctx context.Context, request mcp.CallToolRequest
 		Conditions:  conditions,
 	}
 
-	// Initialize workflow orchestrator if not already done
-	if s.workflowOrchestrator == nil {
-		s.workflowOrchestrator = NewWorkflowOrchestrator(s)
-	}
-
 	// Add the workflow
-	s.workflowOrchestrator.AddCustomWorkflow(workflow)
+	if err := s.workflowOrchestrator.AddCustomWorkflow(workflow); err != nil {
+		return NewTextResult("", fmt.Errorf("failed to save workflow '%s': %v", name, err)), nil
+	}
 
 	result := map[string]interface{}{
 		"status":               "success",
@@ -356,3 +373,36 @@ func (s *Server) workflowCreate(ctx context.Context, request mcp.CallToolRequest
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
 	return NewTextResult(string(jsonResult), nil), nil
 }
+
+// workflowDelete handles deleting a workflow
+func (s *Server) workflowDelete(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return NewTextResult("", fmt.Errorf("invalid arguments format")), nil
+	}
+
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return NewTextResult("", fmt.Errorf("name parameter is required")), nil
+	}
+	force := getBoolArg(args, "force", false)
+
+	// Initialize workflow orchestrator if not already done
+	if s.workflowOrchestrator == nil {
+		s.workflowOrchestrator = NewWorkflowOrchestrator(s)
+	}
+
+	if err := s.workflowOrchestrator.DeleteWorkflow(name, force); err != nil {
+		return NewTextResult("", err), nil
+	}
+
+	result := map[string]interface{}{
+		"status":              "success",
+		"message":             fmt.Sprintf("Workflow '%s' deleted successfully", name),
+		"workflow_name":       workflowKey(name),
+		"remaining_workflows": len(s.workflowOrchestrator.ListWorkflows()),
+	}
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	return NewTextResult(string(jsonResult), nil), nil
+}
@@ -0,0 +1,93 @@
+// Package metrics holds the Prometheus collectors of the server: tool invocations and the
+// durations of the long running build, push and deploy operations.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "openshift_mcp"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// operationBuckets span seconds to tens of minutes, builds and rollouts are far slower than tool calls
+var operationBuckets = prometheus.ExponentialBuckets(1, 2, 12)
+
+var (
+	// Registry holds the collectors served by Handler
+	Registry = prometheus.NewRegistry()
+
+	ToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Number of MCP tool calls by tool and result.",
+	}, []string{"tool", "result"})
+
+	ToolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_duration_seconds",
+		Help:      "Duration of MCP tool calls by tool.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"tool"})
+
+	BuildDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "build_duration_seconds",
+		Help:      "Duration of container image builds by result.",
+		Buckets:   operationBuckets,
+	}, []string{"result"})
+
+	PushDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "push_duration_seconds",
+		Help:      "Duration of container image pushes by result.",
+		Buckets:   operationBuckets,
+	}, []string{"result"})
+
+	DeployDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "deploy_duration_seconds",
+		Help:      "Duration of application deployments by result.",
+		Buckets:   operationBuckets,
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ToolCalls,
+		ToolDuration,
+		BuildDuration,
+		PushDuration,
+		DeployDuration,
+		OperationsInFlight,
+	)
+}
+
+// Result returns the result label of an operation that succeeded when ok is true
+func Result(ok bool) string {
+	if ok {
+		return ResultSuccess
+	}
+	return ResultError
+}
+
+// ObserveTool records a tool call that started at start
+func ObserveTool(tool string, start time.Time, ok bool) {
+	ToolCalls.WithLabelValues(tool, Result(ok)).Inc()
+	ToolDuration.WithLabelValues(tool).Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
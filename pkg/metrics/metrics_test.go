@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveTool(t *testing.T) {
+	ObserveTool("pods_list", time.Now(), true)
+	ObserveTool("pods_list", time.Now(), false)
+	ObserveTool("pods_list", time.Now(), false)
+	t.Run("counts calls by result", func(t *testing.T) {
+		if count := testutil.ToFloat64(ToolCalls.WithLabelValues("pods_list", ResultSuccess)); count != 1 {
+			t.Fatalf("Expected 1 successful call, got %v", count)
+		}
+		if count := testutil.ToFloat64(ToolCalls.WithLabelValues("pods_list", ResultError)); count != 2 {
+			t.Fatalf("Expected 2 failed calls, got %v", count)
+		}
+	})
+	t.Run("records durations", func(t *testing.T) {
+		if count := testutil.CollectAndCount(ToolDuration, "openshift_mcp_tool_duration_seconds"); count != 1 {
+			t.Fatalf("Expected 1 duration series, got %d", count)
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	StartOperation(OperationBuild)(true)
+	StartOperation(OperationPush)(false)
+	StartOperation(OperationDeploy)(true)
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	body, _ := io.ReadAll(recorder.Body)
+	for _, expected := range []string{
+		`openshift_mcp_build_duration_seconds_count{result="success"} 1`,
+		`openshift_mcp_push_duration_seconds_count{result="error"} 1`,
+		`openshift_mcp_deploy_duration_seconds_count{result="success"} 1`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Expected %q in metrics output:\n%s", expected, body)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Operation is a long running build, push or deploy
+type Operation string
+
+const (
+	OperationBuild  Operation = "build"
+	OperationPush   Operation = "push"
+	OperationDeploy Operation = "deploy"
+)
+
+// OperationsInFlight is the number of builds, pushes and deploys running, which shutdown drains
+var OperationsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "operations_in_flight",
+	Help:      "Number of builds, pushes and deploys in progress by operation.",
+}, []string{"operation"})
+
+func (o Operation) duration() *prometheus.HistogramVec {
+	switch o {
+	case OperationBuild:
+		return BuildDuration
+	case OperationPush:
+		return PushDuration
+	default:
+		return DeployDuration
+	}
+}
+
+// operations counts the operations in flight, drained is closed when the count drops to zero
+var operations struct {
+	sync.Mutex
+	active  int
+	drained chan struct{}
+}
+
+// StartOperation tracks an operation until the returned function is called with its outcome,
+// which records its duration
+func StartOperation(operation Operation) func(ok bool) {
+	start := time.Now()
+	operations.Lock()
+	operations.active++
+	operations.Unlock()
+	OperationsInFlight.WithLabelValues(string(operation)).Inc()
+
+	var once sync.Once
+	return func(ok bool) {
+		once.Do(func() {
+			operation.duration().WithLabelValues(Result(ok)).Observe(time.Since(start).Seconds())
+			OperationsInFlight.WithLabelValues(string(operation)).Dec()
+			operations.Lock()
+			defer operations.Unlock()
+			operations.active--
+			if operations.active == 0 && operations.drained != nil {
+				close(operations.drained)
+				operations.drained = nil
+			}
+		})
+	}
+}
+
+// ActiveOperations returns the number of operations in flight
+func ActiveOperations() int {
+	operations.Lock()
+	defer operations.Unlock()
+	return operations.active
+}
+
+// WaitForOperations blocks until no operation is in flight or the context is done
+func WaitForOperations(ctx context.Context) error {
+	operations.Lock()
+	if operations.active == 0 {
+		operations.Unlock()
+		return nil
+	}
+	if operations.drained == nil {
+		operations.drained = make(chan struct{})
+	}
+	drained := operations.drained
+	operations.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStartOperation(t *testing.T) {
+	done := StartOperation(OperationBuild)
+	t.Run("counts the operation in flight", func(t *testing.T) {
+		if active := ActiveOperations(); active != 1 {
+			t.Fatalf("Expected 1 active operation, got %d", active)
+		}
+		if gauge := testutil.ToFloat64(OperationsInFlight.WithLabelValues("build")); gauge != 1 {
+			t.Fatalf("Expected 1 build in flight, got %v", gauge)
+		}
+	})
+	t.Run("waits for the operation until the deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := WaitForOperations(ctx); err == nil {
+			t.Fatal("Expected the wait to time out while the operation runs")
+		}
+	})
+	t.Run("returns once the operation is done", func(t *testing.T) {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			done(true)
+			done(true)
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := WaitForOperations(ctx); err != nil {
+			t.Fatalf("Expected the operations to drain, got %v", err)
+		}
+		if active := ActiveOperations(); active != 0 {
+			t.Fatalf("Expected no active operation after finishing twice, got %d", active)
+		}
+	})
+}
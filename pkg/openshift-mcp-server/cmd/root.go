@@ -199,7 +199,12 @@ func (m *MCPServerOptions) loadFlags(cmd *cobra.Command) {
 func (m *MCPServerOptions) initializeLogging() {
 	flagSet := flag.NewFlagSet("klog", flag.ContinueOnError)
 	klog.InitFlags(flagSet)
-	loggerOptions := []textlogger.ConfigOption{textlogger.Output(m.Out)}
+	// Over stdio, stdout carries the JSON-RPC stream
+	out := m.Out
+	if m.StaticConfig.Port == "" && !m.Version {
+		out = m.ErrOut
+	}
+	loggerOptions := []textlogger.ConfigOption{textlogger.Output(out)}
 	if m.StaticConfig.LogLevel >= 0 {
 		loggerOptions = append(loggerOptions, textlogger.Verbosity(m.StaticConfig.LogLevel))
 		_ = flagSet.Parse([]string{"--v", strconv.Itoa(m.StaticConfig.LogLevel)})
@@ -312,6 +317,7 @@ func (m *MCPServerOptions) Run() error {
 		Profile:      profile,
 		ListOutput:   listOutput,
 		StaticConfig: m.StaticConfig,
+		StoreDir:     mcp.DefaultStoreDir(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize MCP server: %w", err)
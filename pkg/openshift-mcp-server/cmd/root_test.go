@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/klog/v2"
 )
 
 func captureOutput(f func() error) (string, error) {
@@ -280,3 +281,27 @@ func TestServerURL(t *testing.T) {
 		}
 	})
 }
+
+func TestLoggingOutput(t *testing.T) {
+	t.Run("logs to stderr over stdio", func(t *testing.T) {
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		options := NewMCPServerOptions(genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: out, ErrOut: errOut})
+		options.initializeLogging()
+		klog.Warning("stdio warning")
+		klog.Flush()
+		if out.Len() != 0 || !strings.Contains(errOut.String(), "stdio warning") {
+			t.Fatalf("Expected logs on stderr only, got stdout %q and stderr %q", out.String(), errOut.String())
+		}
+	})
+	t.Run("logs to stdout over http", func(t *testing.T) {
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		options := NewMCPServerOptions(genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: out, ErrOut: errOut})
+		options.StaticConfig.Port = "8080"
+		options.initializeLogging()
+		klog.Warning("http warning")
+		klog.Flush()
+		if !strings.Contains(out.String(), "http warning") {
+			t.Fatalf("Expected logs on stdout, got %q", out.String())
+		}
+	})
+}